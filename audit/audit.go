@@ -0,0 +1,165 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit records changes made to the graph, together with the information about who made them.
+//
+// Changes are recorded by a Writer, which wraps any graph.QuadWriter and is registered as "audit" writer.
+// Records are written to a Sink, for example to a rotating JSON lines file (see FileSink).
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/nquads"
+)
+
+// RequestIDHeader is the HTTP header used to pass request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// Operations recorded by the Writer.
+const (
+	OpAdd        = "add"
+	OpRemove     = "remove"
+	OpRemoveNode = "remove_node"
+	OpApply      = "apply"
+)
+
+// Meta describes the origin of a change.
+type Meta struct {
+	// Principal is the name of an authenticated user or service that made a change.
+	Principal string
+	// Remote is the address of the client.
+	Remote string
+	// RequestID identifies the request that made a change.
+	RequestID string
+	// Request is a short description of the request, for example "POST /api/v2/write".
+	Request string
+}
+
+type metaKey struct{}
+
+// NewContext returns a new context with a given change origin.
+func NewContext(ctx context.Context, m Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, m)
+}
+
+// MetaFromContext returns the change origin stored in the context, if any.
+func MetaFromContext(ctx context.Context) (Meta, bool) {
+	m, ok := ctx.Value(metaKey{}).(Meta)
+	return m, ok
+}
+
+// Record is a single change of the graph.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Op        string    `json:"op"`
+	Database  string    `json:"database,omitempty"` // empty for the default database
+	Principal string    `json:"principal,omitempty"`
+	Remote    string    `json:"remote,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Request   string    `json:"request,omitempty"`
+	// Node is the node removed with OpRemoveNode, in N-Quads notation.
+	Node    string `json:"node,omitempty"`
+	Added   Quads  `json:"added,omitempty"`
+	Removed Quads  `json:"removed,omitempty"`
+}
+
+// Quads is a list of quads that is encoded to JSON as a list of N-Quads statements.
+//
+// Unlike the JSON encoding of quad.Quad, it preserves value types.
+type Quads []quad.Quad
+
+// MarshalJSON implements json.Marshaler.
+func (qs Quads) MarshalJSON() ([]byte, error) {
+	arr := make([]string, 0, len(qs))
+	for _, q := range qs {
+		arr = append(arr, q.NQuad())
+	}
+	return json.Marshal(arr)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (qs *Quads) UnmarshalJSON(data []byte) error {
+	var arr []string
+	if err := json.Unmarshal(data, &arr); err != nil {
+		return err
+	}
+	out := make(Quads, 0, len(arr))
+	for _, s := range arr {
+		q, err := nquads.Parse(s)
+		if err != nil {
+			return fmt.Errorf("audit: cannot parse quad %q: %w", s, err)
+		}
+		out = append(out, q)
+	}
+	*qs = out
+	return nil
+}
+
+// HasNode checks if the record changes any quads with a given node.
+func (r *Record) HasNode(v quad.Value) bool {
+	s := v.String()
+	if r.Node == s {
+		return true
+	}
+	for _, list := range []Quads{r.Added, r.Removed} {
+		for _, q := range list {
+			for _, d := range quad.Directions {
+				if qv := q.Get(d); qv != nil && qv.String() == s {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Filter selects records.
+type Filter struct {
+	// Since and Until limit records by time. Zero value means no limit.
+	Since, Until time.Time
+	// Principal selects changes made by a given principal.
+	Principal string
+	// Node selects changes of quads that have a given node in any direction.
+	Node quad.Value
+}
+
+// Match checks if the record satisfies the filter.
+func (f Filter) Match(r *Record) bool {
+	if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && r.Time.After(f.Until) {
+		return false
+	}
+	if f.Principal != "" && r.Principal != f.Principal {
+		return false
+	}
+	if f.Node != nil && !r.HasNode(f.Node) {
+		return false
+	}
+	return true
+}
+
+// Sink stores audit records.
+type Sink interface {
+	// WriteRecord stores a record. It must be safe for concurrent use.
+	WriteRecord(r *Record) error
+	// Close closes the sink.
+	Close() error
+}
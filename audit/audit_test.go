@@ -0,0 +1,145 @@
+package audit_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/audit"
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/quad"
+)
+
+func search(t testing.TB, path string, f audit.Filter) []*audit.Record {
+	var out []*audit.Record
+	err := audit.Search(path, f, func(r *audit.Record) error {
+		out = append(out, r)
+		return nil
+	})
+	require.NoError(t, err)
+	return out
+}
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	qs := memstore.New()
+	qw, err := graph.NewQuadWriter(audit.WriterName, qs, graph.Options{
+		"audit_file":        path,
+		"audit_max_size_mb": 1,
+	})
+	require.NoError(t, err)
+	defer qw.Close()
+
+	alice := audit.WithMeta(qw, audit.Meta{Principal: "alice", Remote: "10.0.0.1:1234", RequestID: "r1"})
+	bob := audit.WithMeta(qw, audit.Meta{Principal: "bob", RequestID: "r2"})
+
+	q1 := quad.Make(quad.IRI("a"), quad.IRI("name"), quad.String("Alice"), nil)
+	q2 := quad.Make(quad.IRI("a"), quad.IRI("follows"), quad.IRI("b"), quad.IRI("g"))
+	q3 := quad.Make(quad.IRI("b"), quad.IRI("age"), quad.Int(42), nil)
+	require.NoError(t, alice.AddQuadSet([]quad.Quad{q1, q2}))
+	require.NoError(t, bob.AddQuad(q3))
+	require.NoError(t, bob.RemoveQuad(q1))
+	// failed changes are not recorded
+	require.Error(t, bob.RemoveQuad(q1))
+	require.NoError(t, alice.RemoveNode(quad.IRI("b")))
+	require.Equal(t, graph.ErrNodeNotExists, alice.RemoveNode(quad.IRI("b")))
+
+	all := search(t, path, audit.Filter{})
+	require.Len(t, all, 4)
+	require.Equal(t, audit.OpAdd, all[0].Op)
+	require.Equal(t, "alice", all[0].Principal)
+	require.Equal(t, "10.0.0.1:1234", all[0].Remote)
+	require.Equal(t, "r1", all[0].RequestID)
+	// value types must be preserved
+	require.Equal(t, audit.Quads{q1, q2}, all[0].Added)
+	require.Equal(t, audit.Quads{q3}, all[1].Added)
+	require.Equal(t, audit.Quads{q1}, all[2].Removed)
+	require.Equal(t, audit.OpRemoveNode, all[3].Op)
+	require.Equal(t, "<b>", all[3].Node)
+	require.ElementsMatch(t, []quad.Quad{q2, q3}, all[3].Removed)
+
+	got := search(t, path, audit.Filter{Principal: "bob"})
+	require.Len(t, got, 2)
+	got = search(t, path, audit.Filter{Node: quad.String("Alice")})
+	require.Len(t, got, 2)
+	got = search(t, path, audit.Filter{Node: quad.IRI("g")})
+	require.Len(t, got, 2)
+	got = search(t, path, audit.Filter{Since: all[3].Time.Add(time.Second)})
+	require.Len(t, got, 0)
+	got = search(t, path, audit.Filter{Until: all[0].Time})
+	require.Len(t, got, 1)
+
+	// writers of named databases share the sink
+	aw := qw.(*audit.Writer)
+	require.Equal(t, "", aw.Database())
+	dw, err := graph.NewQuadWriter(audit.WriterName, memstore.New(), graph.Options{
+		"audit_sink":     aw.Sink(),
+		"audit_database": "people",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "people", dw.(*audit.Writer).Database())
+	require.NoError(t, dw.AddQuad(q3))
+	require.NoError(t, dw.Close())
+	got = search(t, path, audit.Filter{})
+	require.Len(t, got, 5)
+	require.Equal(t, "people", got[4].Database)
+	require.Equal(t, "", got[0].Database)
+}
+
+func TestWriterChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	q1 := quad.Make(quad.IRI("a"), quad.IRI("name"), quad.String("Alice"), nil)
+	q2 := quad.Make(quad.IRI("a"), quad.IRI("name"), quad.String("Alice"), quad.IRI("g"))
+	q3 := quad.Make(quad.IRI("b"), quad.IRI("name"), quad.String("Bob"), nil)
+	qs := memstore.New(q1)
+	qw, err := graph.NewQuadWriter(audit.WriterName, qs, graph.Options{
+		"audit_file":     path,
+		"ignore_missing": true,
+	})
+	require.NoError(t, err)
+	defer qw.Close()
+
+	// quads that are already stored, or are not stored, are ignored by the writer and are not recorded
+	require.NoError(t, qw.AddQuad(q1))
+	require.NoError(t, qw.RemoveQuad(q3))
+	require.Empty(t, search(t, path, audit.Filter{}))
+
+	// the label is a part of the quad
+	tx := graph.NewTransaction()
+	tx.AddQuad(q1)
+	tx.AddQuad(q2)
+	tx.RemoveQuad(q3)
+	require.NoError(t, qw.ApplyTransaction(tx))
+	require.NoError(t, qw.AddQuadSet([]quad.Quad{q2, q3, q3}))
+	all := search(t, path, audit.Filter{})
+	require.Len(t, all, 2)
+	require.Equal(t, audit.Quads{q2}, all[0].Added)
+	require.Empty(t, all[0].Removed)
+	require.Equal(t, audit.Quads{q3}, all[1].Added)
+}
+
+func TestFileSinkRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	s, err := audit.NewFileSink(path, 200, 2)
+	require.NoError(t, err)
+	q := quad.Make(quad.IRI("a"), quad.IRI("b"), quad.String("some long value"), nil)
+	for i := 0; i < 10; i++ {
+		err = s.WriteRecord(&audit.Record{Time: time.Unix(int64(i), 0).UTC(), Op: audit.OpAdd, Added: audit.Quads{q}})
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Close())
+
+	files, err := audit.Files(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{path + ".2", path + ".1", path}, files)
+
+	// oldest records are dropped, the rest are returned in order
+	recs := search(t, path, audit.Filter{})
+	require.True(t, len(recs) > 0 && len(recs) < 10)
+	for i, r := range recs {
+		require.Equal(t, int64(10-len(recs)+i), r.Time.Unix())
+	}
+}
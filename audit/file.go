@@ -0,0 +1,222 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	// DefaultMaxSize is the default size of the audit file, after which it is rotated.
+	DefaultMaxSize = 100 << 20
+	// DefaultMaxFiles is the default number of rotated audit files to keep.
+	DefaultMaxFiles = 10
+)
+
+var _ Sink = (*FileSink)(nil)
+
+// FileSink appends records to a file, one JSON object per line.
+//
+// When the file grows larger than the size limit, it is renamed to "<path>.1", the previous "<path>.1"
+// is renamed to "<path>.2", and so on. Files with a number larger than the limit are removed.
+type FileSink struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewFileSink opens or creates an audit file.
+//
+// The file is rotated when it grows larger than maxSize bytes, and at most maxFiles rotated files are kept.
+// Defaults are used if the values are not positive.
+func NewFileSink(path string, maxSize int64, maxFiles int) (*FileSink, error) {
+	if path == "" {
+		return nil, errors.New("audit: file path is not set")
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	s := &FileSink{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Path returns the path of the current audit file.
+func (s *FileSink) Path() string {
+	return s.path
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.f, s.size = f, st.Size()
+	return nil
+}
+
+func rotatedName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	s.f = nil
+	err := os.Remove(rotatedName(s.path, s.maxFiles))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := s.maxFiles - 1; i >= 1; i-- {
+		err = os.Rename(rotatedName(s.path, i), rotatedName(s.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err = os.Rename(s.path, rotatedName(s.path, 1)); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+// WriteRecord implements Sink.
+func (s *FileSink) WriteRecord(r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		// rotation failed previously
+		if err = s.open(); err != nil {
+			return err
+		}
+	}
+	if s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		if err = s.rotate(); err != nil {
+			return fmt.Errorf("audit: cannot rotate file: %w", err)
+		}
+	}
+	n, err := s.f.Write(data)
+	s.size += int64(n)
+	return err
+}
+
+// Close implements Sink.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// Files returns the audit file and all its rotated versions that exist, from the oldest to the newest.
+func Files(path string) ([]string, error) {
+	var files []string
+	for i := 1; ; i++ {
+		name := rotatedName(path, i)
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		files = append([]string{name}, files...)
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return files, nil
+}
+
+// ReadRecords reads records from r and calls fn for each of them.
+func ReadRecords(r io.Reader, fn func(*Record) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(&rec); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// Search reads records from the audit file and its rotated versions and calls fn for records matching the filter.
+// Records are returned in the order they were written.
+func Search(path string, f Filter, fn func(*Record) error) error {
+	files, err := Files(path)
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		if err = searchFile(name, f, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func searchFile(name string, f Filter, fn func(*Record) error) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = ReadRecords(file, func(r *Record) error {
+		if !f.Match(r) {
+			return nil
+		}
+		return fn(r)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+
+	// Writer is imported for the default underlying writer to be registered
+	_ "github.com/cayleygraph/cayley/writer"
+)
+
+// WriterName is the name of the audit writer in the writer registry.
+const WriterName = "audit"
+
+func init() {
+	graph.RegisterWriter(WriterName, NewWriterFromOptions)
+}
+
+var _ graph.QuadWriter = (*Writer)(nil)
+
+// Writer is a graph.QuadWriter that records all successful changes to a Sink.
+//
+// Changes are recorded after they were applied. If the record cannot be written, the error is returned
+// to the caller, even though the change is already applied.
+type Writer struct {
+	qs   graph.QuadStore
+	qw   graph.QuadWriter
+	sink Sink
+	own  bool
+	db   string
+	meta Meta
+	now  func() time.Time
+}
+
+// NewWriter wraps a quad writer for a given store and records changes made through it to the sink.
+//
+// The sink is not closed when the writer is closed.
+func NewWriter(qs graph.QuadStore, qw graph.QuadWriter, sink Sink) *Writer {
+	return &Writer{qs: qs, qw: qw, sink: sink, now: time.Now}
+}
+
+// NewWriterFromOptions creates an audit writer from the options. It's registered as "audit" writer.
+//
+// Supported options:
+//
+//	audit_writer       - name of the underlying writer; "single" by default
+//	audit_sink         - an instance of Sink to use
+//	audit_file         - path of the audit file; used if audit_sink is not set
+//	audit_max_size_mb  - size of the audit file after which it's rotated
+//	audit_max_files    - number of rotated audit files to keep
+//	audit_database     - name of the database recorded with each change; empty for the default database
+//
+// All options are passed to the underlying writer as well.
+func NewWriterFromOptions(qs graph.QuadStore, opts graph.Options) (graph.QuadWriter, error) {
+	wtyp, err := opts.StringKey("audit_writer", "single")
+	if err != nil {
+		return nil, err
+	} else if wtyp == WriterName {
+		return nil, errors.New("audit: cannot wrap an audit writer")
+	}
+	var (
+		sink Sink
+		own  bool
+	)
+	if v, ok := opts["audit_sink"]; ok {
+		if sink, ok = v.(Sink); !ok {
+			return nil, fmt.Errorf("audit: invalid sink type: %T", v)
+		}
+	} else {
+		path, err := opts.StringKey("audit_file", "")
+		if err != nil {
+			return nil, err
+		} else if path == "" {
+			return nil, errors.New("audit: either audit_file or audit_sink option must be set")
+		}
+		sizeMB, err := opts.IntKey("audit_max_size_mb", DefaultMaxSize>>20)
+		if err != nil {
+			return nil, err
+		}
+		files, err := opts.IntKey("audit_max_files", DefaultMaxFiles)
+		if err != nil {
+			return nil, err
+		}
+		sink, err = NewFileSink(path, int64(sizeMB)<<20, files)
+		if err != nil {
+			return nil, err
+		}
+		own = true
+	}
+	qw, err := graph.NewQuadWriter(wtyp, qs, opts)
+	if err != nil {
+		if own {
+			sink.Close()
+		}
+		return nil, err
+	}
+	w := NewWriter(qs, qw, sink)
+	w.own = own
+	if w.db, err = opts.StringKey("audit_database", ""); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Sink returns the sink the writer records changes to.
+func (w *Writer) Sink() Sink {
+	return w.sink
+}
+
+// Database returns the name of the database recorded with each change. It's empty for the default database.
+func (w *Writer) Database() string {
+	return w.db
+}
+
+// Unwrap returns the underlying writer.
+func (w *Writer) Unwrap() graph.QuadWriter {
+	return w.qw
+}
+
+// WithMeta returns a copy of the writer that records a given origin with each change.
+//
+// Closing the copy has no effect.
+func (w *Writer) WithMeta(m Meta) *Writer {
+	w2 := *w
+	w2.meta = m
+	w2.own = false
+	w2.qw = nopCloser{w.qw}
+	return &w2
+}
+
+// WithMeta returns a writer that records a given origin with each change, if qw is an audit writer.
+// Otherwise, qw is returned as-is.
+func WithMeta(qw graph.QuadWriter, m Meta) graph.QuadWriter {
+	if w, ok := qw.(*Writer); ok {
+		return w.WithMeta(m)
+	}
+	return qw
+}
+
+type nopCloser struct {
+	graph.QuadWriter
+}
+
+func (nopCloser) Close() error { return nil }
+
+func (w *Writer) record(op string, node quad.Value, added, removed []quad.Quad) error {
+	r := &Record{
+		Time:      w.now().UTC(),
+		Op:        op,
+		Database:  w.db,
+		Principal: w.meta.Principal,
+		Remote:    w.meta.Remote,
+		RequestID: w.meta.RequestID,
+		Request:   w.meta.Request,
+		Added:     added,
+		Removed:   removed,
+	}
+	if node != nil {
+		r.Node = node.String()
+	}
+	if err := w.sink.WriteRecord(r); err != nil {
+		return fmt.Errorf("audit: cannot record the change: %w", err)
+	}
+	return nil
+}
+
+// write applies the deltas with a given function and records the changes it made.
+//
+// Changes are determined before applying the deltas: quads that are already stored are not recorded as added,
+// and quads that are not stored are not recorded as removed, since writers ignore them or fail.
+func (w *Writer) write(op string, deltas []graph.Delta, apply func() error) error {
+	changes, err := graph.Changes(w.qs, deltas)
+	if err != nil {
+		return err
+	}
+	if err = apply(); err != nil {
+		return err
+	}
+	var added, removed []quad.Quad
+	for _, d := range changes {
+		switch d.Action {
+		case graph.Add:
+			added = append(added, d.Quad)
+		case graph.Delete:
+			removed = append(removed, d.Quad)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	return w.record(op, nil, added, removed)
+}
+
+// deltas converts quads to deltas with a given action.
+func deltas(act graph.Procedure, quads []quad.Quad) []graph.Delta {
+	out := make([]graph.Delta, 0, len(quads))
+	for _, q := range quads {
+		out = append(out, graph.Delta{Quad: q, Action: act})
+	}
+	return out
+}
+
+// AddQuad implements graph.QuadWriter.
+func (w *Writer) AddQuad(q quad.Quad) error {
+	return w.write(OpAdd, deltas(graph.Add, []quad.Quad{q}), func() error {
+		return w.qw.AddQuad(q)
+	})
+}
+
+// AddQuadSet implements graph.QuadWriter.
+func (w *Writer) AddQuadSet(set []quad.Quad) error {
+	return w.write(OpAdd, deltas(graph.Add, set), func() error {
+		return w.qw.AddQuadSet(set)
+	})
+}
+
+// RemoveQuad implements graph.QuadWriter.
+func (w *Writer) RemoveQuad(q quad.Quad) error {
+	return w.write(OpRemove, deltas(graph.Delete, []quad.Quad{q}), func() error {
+		return w.qw.RemoveQuad(q)
+	})
+}
+
+// ApplyTransaction implements graph.QuadWriter.
+func (w *Writer) ApplyTransaction(tx *graph.Transaction) error {
+	return w.write(OpApply, tx.Deltas, func() error {
+		return w.qw.ApplyTransaction(tx)
+	})
+}
+
+// RemoveNode implements graph.QuadWriter.
+//
+// Quads of the node are collected before removing them, to record the exact list of removed quads.
+func (w *Writer) RemoveNode(v quad.Value) error {
+	removed, err := graph.NodeQuads(w.qs, v)
+	if err != nil {
+		return err
+	}
+	tx := graph.NewTransactionN(len(removed))
+	for _, q := range removed {
+		tx.RemoveQuad(q)
+	}
+	if err = w.qw.ApplyTransaction(tx); err != nil {
+		return err
+	}
+	return w.record(OpRemoveNode, v, nil, removed)
+}
+
+// Close closes the underlying writer, and the sink if it was opened by the writer.
+func (w *Writer) Close() error {
+	err := w.qw.Close()
+	if w.own {
+		if err2 := w.sink.Close(); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
@@ -46,7 +46,7 @@ func (e errRequestFailed) Error() string {
 	return fmt.Sprintf("request failed: %d %v", e.StatusCode, e.Status)
 }
 func (c *Client) QuadReader() (quad.ReadCloser, error) {
-	resp, err := http.Get(c.url("/api/v2/read", map[string]string{
+	resp, err := c.cli.Get(c.url("/api/v2/read", map[string]string{
 		"format": "pquads",
 	}))
 	if err != nil {
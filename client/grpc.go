@@ -0,0 +1,269 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/cayleygraph/cayley/server/grpc/pb"
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/pquads"
+)
+
+// GRPCClient communicates with a Cayley server through the gRPC API.
+type GRPCClient struct {
+	conn  *grpc.ClientConn
+	c     pb.CayleyClient
+	batch int
+}
+
+// DialGRPC connects to the gRPC API of a Cayley server.
+// If no options are given, the connection is not encrypted. See DialGRPCTLS for encrypted connections.
+func DialGRPC(addr string, opts ...grpc.DialOption) (*GRPCClient, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := NewGRPCClient(conn)
+	c.conn = conn
+	return c, nil
+}
+
+// DialGRPCTLS connects to the gRPC API of a Cayley server over TLS.
+func DialGRPCTLS(addr string, o TLSOptions, opts ...grpc.DialOption) (*GRPCClient, error) {
+	conf, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(conf)))
+	return DialGRPC(addr, opts...)
+}
+
+// NewGRPCClient creates a client for an existing gRPC connection. The connection is not closed by Close.
+func NewGRPCClient(conn grpc.ClientConnInterface) *GRPCClient {
+	return &GRPCClient{c: pb.NewCayleyClient(conn), batch: quad.DefaultBatch}
+}
+
+// SetBatchSize sets the number of quads sent in a single write request.
+func (c *GRPCClient) SetBatchSize(n int) {
+	c.batch = n
+}
+
+// Close closes the connection opened by DialGRPC.
+func (c *GRPCClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// QuadFilter selects quads by their values in each direction. Any value matches if the list is empty.
+type QuadFilter struct {
+	Subject, Predicate, Object, Label []quad.Value
+	// Limit is the maximal number of quads to return. No limit if it's zero.
+	Limit int
+}
+
+func makeValues(arr []quad.Value) []*pquads.Value {
+	if len(arr) == 0 {
+		return nil
+	}
+	out := make([]*pquads.Value, 0, len(arr))
+	for _, v := range arr {
+		out = append(out, pquads.MakeValue(v))
+	}
+	return out
+}
+
+// QuadReader streams quads matching the filter.
+func (c *GRPCClient) QuadReader(ctx context.Context, f QuadFilter) (quad.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	st, err := c.c.Read(ctx, &pb.ReadRequest{
+		Subject:   makeValues(f.Subject),
+		Predicate: makeValues(f.Predicate),
+		Object:    makeValues(f.Object),
+		Label:     makeValues(f.Label),
+		Limit:     int64(f.Limit),
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &grpcReader{st: st, cancel: cancel}, nil
+}
+
+type grpcReader struct {
+	st     pb.Cayley_ReadClient
+	cancel func()
+	buf    []*pquads.Quad
+	err    error
+}
+
+func (r *grpcReader) ReadQuad() (quad.Quad, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return quad.Quad{}, r.err
+		}
+		resp, err := r.st.Recv()
+		if err != nil {
+			r.err = err
+			return quad.Quad{}, err
+		}
+		r.buf = resp.Quads
+	}
+	q := r.buf[0]
+	r.buf = r.buf[1:]
+	return q.ToNative(), nil
+}
+
+func (r *grpcReader) Close() error {
+	r.cancel()
+	return nil
+}
+
+// QuadWriter returns a writer that adds quads to the database.
+// Quads are sent in batches, and Close must be called to send the last batch.
+func (c *GRPCClient) QuadWriter(ctx context.Context) (quad.WriteCloser, error) {
+	st, err := c.c.Write(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcWriter{st: st, batch: c.batch}, nil
+}
+
+// QuadRemover returns a writer that removes quads from the database.
+// Quads are sent in batches, and Close must be called to send the last batch.
+func (c *GRPCClient) QuadRemover(ctx context.Context) (quad.WriteCloser, error) {
+	st, err := c.c.Delete(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcWriter{st: st, batch: c.batch}, nil
+}
+
+// writeClient is implemented by both Write and Delete streams.
+type writeClient interface {
+	Send(*pb.WriteRequest) error
+	Recv() (*pb.WriteResponse, error)
+	CloseSend() error
+}
+
+// grpcWriter sends quads in batches and waits for each batch to be acknowledged.
+type grpcWriter struct {
+	st    writeClient
+	batch int
+	buf   []*pquads.Quad
+	err   error
+}
+
+func (w *grpcWriter) flush() error {
+	if w.err != nil || len(w.buf) == 0 {
+		return w.err
+	}
+	if err := w.st.Send(&pb.WriteRequest{Quads: w.buf}); err != nil {
+		if err == io.EOF {
+			// the server closed the stream; the actual error is returned by Recv
+			_, err = w.st.Recv()
+		}
+		w.err = err
+		return err
+	}
+	w.buf = w.buf[:0]
+	if _, err := w.st.Recv(); err != nil {
+		w.err = err
+	}
+	return w.err
+}
+
+func (w *grpcWriter) WriteQuad(q quad.Quad) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.buf = append(w.buf, pquads.MakeQuad(q))
+	if len(w.buf) >= w.batch {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *grpcWriter) WriteQuads(buf []quad.Quad) (int, error) {
+	for i, q := range buf {
+		if err := w.WriteQuad(q); err != nil {
+			return i, err
+		}
+	}
+	return len(buf), nil
+}
+
+func (w *grpcWriter) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.st.CloseSend(); err != nil {
+		return err
+	}
+	if _, err := w.st.Recv(); err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// Query runs a query in a given language and returns an iterator of its results.
+// Server default limit is used if limit is zero.
+func (c *GRPCClient) Query(ctx context.Context, lang, qu string, limit int) (*QueryResults, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	st, err := c.c.Query(ctx, &pb.QueryRequest{Lang: lang, Query: qu, Limit: int64(limit)})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &QueryResults{st: st, cancel: cancel}, nil
+}
+
+// QueryResults is an iterator of query results.
+type QueryResults struct {
+	st     pb.Cayley_QueryClient
+	cancel func()
+	cur    json.RawMessage
+	err    error
+}
+
+// Next advances the iterator to the next result.
+func (r *QueryResults) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	resp, err := r.st.Recv()
+	if err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+		r.cur = nil
+		return false
+	}
+	r.cur = resp.Result
+	return true
+}
+
+// Result returns the current result, encoded as JSON.
+func (r *QueryResults) Result() json.RawMessage {
+	return r.cur
+}
+
+// Err returns an error that stopped the iteration, if any.
+func (r *QueryResults) Err() error {
+	return r.err
+}
+
+// Close stops the query.
+func (r *QueryResults) Close() error {
+	r.cancel()
+	return nil
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// TLSOptions configures TLS connections to the server.
+type TLSOptions struct {
+	// CAFile is a path to PEM-encoded bundle of CA certificates used to verify the server.
+	// System roots are used if it's empty.
+	CAFile string
+	// CertFile and KeyFile are paths to PEM-encoded client certificate and private key, used for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name used to verify the server certificate.
+	ServerName string
+	// Insecure disables the verification of the server certificate.
+	Insecure bool
+}
+
+// RegisterFlags registers command line flags for the options.
+func (o *TLSOptions) RegisterFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.CAFile, "tls-ca", "", "path to CA bundle to verify the server certificate (system roots by default)")
+	flags.StringVar(&o.CertFile, "tls-cert", "", "path to client certificate file for mutual TLS")
+	flags.StringVar(&o.KeyFile, "tls-key", "", "path to client private key file for mutual TLS")
+	flags.BoolVar(&o.Insecure, "tls-insecure", false, "do not verify the server certificate")
+}
+
+// Config creates a TLS config for the client.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	conf := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.Insecure,
+	}
+	if o.CAFile != "" {
+		data, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", o.CAFile)
+		}
+		conf.RootCAs = pool
+	}
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, errors.New("both client certificate and key files must be set")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+// NewHTTPClient creates an HTTP client that uses given TLS options.
+func NewHTTPClient(o TLSOptions) (*http.Client, error) {
+	conf, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = conf
+	return &http.Client{Transport: tr}, nil
+}
+
+// SetTLS configures the client to use given TLS options.
+func (c *Client) SetTLS(o TLSOptions) error {
+	cli, err := NewHTTPClient(o)
+	if err != nil {
+		return err
+	}
+	c.cli = cli
+	return nil
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/cayleygraph/cayley/clog"
 	_ "github.com/cayleygraph/cayley/clog/glog"
 	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/trace"
 	"github.com/cayleygraph/cayley/version"
 	"github.com/cayleygraph/quad"
 
@@ -89,6 +90,14 @@ var (
 					}
 				}()
 			}
+			if path := viper.GetString("trace.file"); path != "" {
+				exp, err := trace.NewFileExporter(path)
+				if err != nil {
+					return err
+				}
+				trace.SetExporter(exp)
+				clog.Infof("writing traces to %s", path)
+			}
 			if host, _ := cmd.Flags().GetString("metrics"); host != "" {
 				go func() {
 					if err := http.ListenAndServe(host, promhttp.Handler()); err != nil {
@@ -135,14 +144,19 @@ func init() {
 		versionCmd,
 		command.NewInitDatabaseCmd(),
 		command.NewLoadDatabaseCmd(),
+		command.NewBulkLoadCmd(),
+		command.NewCompactCmd(),
 		command.NewDumpDatabaseCmd(),
 		command.NewUpgradeCmd(),
 		command.NewReplCmd(),
 		command.NewQueryCmd(),
 		command.NewHTTPCmd(),
+		command.NewGRPCCmd(),
 		command.NewConvertCmd(),
 		command.NewDedupCommand(),
 		command.NewHealthCmd(),
+		command.NewAuditCmd(),
+		command.NewValidateCmd(),
 		command.NewSchemaCommand(),
 	)
 	rootCmd.PersistentFlags().StringP("config", "c", "", "path to an explicit configuration file")
@@ -161,6 +175,7 @@ func init() {
 
 	rootCmd.PersistentFlags().String("pprof", "", "host to serve pprof on (disabled by default)")
 	rootCmd.PersistentFlags().String("metrics", "", "host to serve metrics on (disabled by default)")
+	rootCmd.PersistentFlags().String("trace", "", "path to a file to write trace spans to (disabled by default)")
 
 	// bind flags to config variables
 	viper.BindPFlag(command.KeyBackend, rootCmd.PersistentFlags().Lookup("db"))
@@ -169,6 +184,7 @@ func init() {
 	viper.BindPFlag("load.ignore_duplicates", rootCmd.PersistentFlags().Lookup("dup"))
 	viper.BindPFlag("load.ignore_missing", rootCmd.PersistentFlags().Lookup("missing"))
 	viper.BindPFlag(command.KeyLoadBatch, rootCmd.PersistentFlags().Lookup("batch"))
+	viper.BindPFlag("trace.file", rootCmd.PersistentFlags().Lookup("trace"))
 
 	// make both store.path and store.address work
 	viper.RegisterAlias(command.KeyPath, command.KeyAddress)
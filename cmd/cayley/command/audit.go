@@ -0,0 +1,84 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cayleygraph/cayley/audit"
+	"github.com/cayleygraph/quad"
+)
+
+const (
+	keyAuditFile     = "audit.file"
+	keyAuditMaxSize  = "audit.max_size_mb"
+	keyAuditMaxFiles = "audit.max_files"
+)
+
+// parseAuditTime parses a time in RFC 3339 format or a duration before now, for example "24h".
+func parseAuditTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if dt, err := time.ParseDuration(s); err == nil {
+		return now.Add(-dt), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 time or a duration: %q", s)
+	}
+	return t, nil
+}
+
+func NewAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Search audit records of database changes.",
+		Long: "Search records of changes made to the database, written when audit is enabled (audit.file).\n" +
+			"Matching records are printed as JSON lines, from the oldest to the newest.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("unexpected arguments: %q", args)
+			}
+			path, _ := cmd.Flags().GetString("file")
+			if path == "" {
+				path = viper.GetString(keyAuditFile)
+			}
+			if path == "" {
+				return errors.New("audit file is not set; use --file flag or audit.file config option")
+			}
+			now := time.Now()
+			var (
+				f   audit.Filter
+				err error
+			)
+			since, _ := cmd.Flags().GetString("since")
+			if f.Since, err = parseAuditTime(since, now); err != nil {
+				return err
+			}
+			until, _ := cmd.Flags().GetString("until")
+			if f.Until, err = parseAuditTime(until, now); err != nil {
+				return err
+			}
+			f.Principal, _ = cmd.Flags().GetString("principal")
+			if node, _ := cmd.Flags().GetString("node"); node != "" {
+				f.Node = quad.StringToValue(node)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			return audit.Search(path, f, func(r *audit.Record) error {
+				return enc.Encode(r)
+			})
+		},
+	}
+	cmd.Flags().String("file", "", "audit file to search (audit.file config option is used by default)")
+	cmd.Flags().String("since", "", "only show changes made after a given time (RFC 3339) or during a given duration, for example 24h")
+	cmd.Flags().String("until", "", "only show changes made before a given time (RFC 3339) or a given duration ago")
+	cmd.Flags().String("principal", "", "only show changes made by a given principal")
+	cmd.Flags().String("node", "", `only show changes of quads with a given node, for example "<alice>"`)
+	return cmd
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cayleygraph/cayley/clog"
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/kv"
+	"github.com/cayleygraph/cayley/internal"
+)
+
+func NewBulkLoadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bulkload",
+		Short: "Bulk-load a quad file into an empty KV database, bypassing duplicate checks.",
+		Long: `Bulk-load a quad file into an empty KV database, bypassing duplicate checks.
+
+Index entries are sorted on the local disk in the temporary directory, which is next to the database by default.
+If the load is interrupted, running the same command with the same input and temporary directory resumes it
+from the last checkpoint.
+The input must not contain duplicate quads.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printBackendInfo()
+			p := mustSetupProfile(cmd)
+			defer mustFinishProfile(p)
+			load, _ := cmd.Flags().GetString(flagLoad)
+			if load == "" && len(args) == 1 {
+				load = args[0]
+			}
+			if load == "" {
+				return errors.New("one quads file must be specified")
+			} else if load == "-" {
+				return errors.New("bulk load from stdin is not supported")
+			}
+			if init, err := cmd.Flags().GetBool("init"); err != nil {
+				return err
+			} else if init {
+				// the database might be initialized by an interrupted load
+				if err = initDatabase(); err == graph.ErrDatabaseExists {
+					clog.Infof("database already initialized, skipping init")
+				} else if err != nil {
+					return err
+				}
+			}
+			h, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer h.Close()
+
+			qs, ok := h.QuadStore.(*kv.QuadStore)
+			if !ok {
+				return errors.New("bulk load is only supported by KV backends")
+			}
+			typ, _ := cmd.Flags().GetString(flagLoadFormat)
+			qr, err := internal.QuadReaderFor(load, typ)
+			if err != nil {
+				return err
+			}
+			defer qr.Close()
+
+			dir, _ := cmd.Flags().GetString("tmp")
+			auto := false
+			if path := viper.GetString(KeyAddress); dir == "" && path != "" {
+				// a directory that is specific to the database, thus the load can be resumed;
+				// databases without a path are loaded using a new temporary directory
+				dir, auto = filepath.Clean(path)+".bulkload", true
+			}
+			runSize, _ := cmd.Flags().GetInt("run_size")
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			start := time.Now()
+			var last time.Time
+			err = qs.BulkLoad(ctx, qr, kv.BulkOptions{
+				Dir:     dir,
+				RunSize: runSize,
+				Progress: func(p kv.BulkProgress) {
+					if time.Since(last) < 5*time.Second && p.Phase != kv.BulkPhaseDone {
+						return
+					}
+					last = time.Now()
+					switch p.Phase {
+					case kv.BulkPhaseRead:
+						clog.Infof("bulkload: read %d quads, %d nodes (%v)", p.Quads, p.Nodes, time.Since(start))
+					case kv.BulkPhaseIndex:
+						clog.Infof("bulkload: writing index %d/%d: %d keys (%v)", p.Index+1, p.Total, p.Keys, time.Since(start))
+					}
+				},
+			})
+			if err == context.Canceled {
+				clog.Infof("bulkload: interrupted; run the same command to resume")
+				return err
+			} else if err != nil {
+				return err
+			}
+			if auto {
+				// all files are removed by the load
+				_ = os.Remove(dir)
+			}
+			clog.Infof("bulkload: loaded %q in %v", load, time.Since(start))
+			return nil
+		},
+	}
+	cmd.Flags().Bool("init", false, "initialize the database before using it")
+	cmd.Flags().String("tmp", "", "directory for sorted runs and checkpoints (default is the database path with the .bulkload suffix)")
+	cmd.Flags().Int("run_size", kv.DefaultBulkRunSize, "number of quads to sort in memory before writing a run to disk")
+	registerLoadFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cayleygraph/cayley/clog"
+	"github.com/cayleygraph/cayley/graph/kv"
+)
+
+func NewCompactCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Remove deleted quads from a KV database.",
+		Long: `Remove deleted quads from a KV database.
+
+Deleted quads are only marked as dead in the log of KV backends. This command removes them from the log and quad indexes,
+and reports the reclaimed space. The database is processed in small transactions, thus it's safe to interrupt the compaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printBackendInfo()
+			p := mustSetupProfile(cmd)
+			defer mustFinishProfile(p)
+			h, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			defer h.Close()
+
+			qs, ok := h.QuadStore.(*kv.QuadStore)
+			if !ok {
+				return errors.New("compaction is only supported by KV backends")
+			}
+			batch, _ := cmd.Flags().GetInt("tx_size")
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			start := time.Now()
+			var last time.Time
+			st, err := qs.Compact(ctx, kv.CompactOptions{
+				Batch: batch,
+				Progress: func(st kv.CompactStats) {
+					if time.Since(last) < 5*time.Second {
+						return
+					}
+					last = time.Now()
+					clog.Infof("compact: scanned %d log entries, removed %d quads (%v)", st.Scanned, st.Quads, time.Since(start))
+				},
+			})
+			if err == context.Canceled {
+				clog.Infof("compact: interrupted")
+			} else if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d dead quads, %d index entries and %d empty index keys.\nReclaimed %d bytes in %v.\n",
+				st.Quads, st.Entries, st.EmptyKeys, st.Bytes, time.Since(start))
+			return err
+		},
+	}
+	cmd.Flags().Int("tx_size", kv.DefaultCompactBatch, "number of log entries to process in a single transaction")
+	return cmd
+}
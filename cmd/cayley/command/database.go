@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -13,9 +14,12 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/cayleygraph/cayley/audit"
 	"github.com/cayleygraph/cayley/clog"
 	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/reasoning"
 	"github.com/cayleygraph/cayley/internal"
+	"github.com/cayleygraph/cayley/validate"
 	"github.com/cayleygraph/quad"
 )
 
@@ -111,7 +115,7 @@ func NewLoadDatabaseCmd() *cobra.Command {
 			}
 			defer h.Close()
 
-			qw, err := h.NewQuadWriter()
+			qw, err := newLoadWriter(h)
 			if err != nil {
 				return err
 			}
@@ -208,13 +212,82 @@ func openDatabase() (*graph.Handle, error) {
 	if err != nil {
 		return nil, err
 	}
-	qw, err := graph.NewQuadWriter("single", qs, opts)
+	if qs, err = withReasoning(qs, opts); err != nil {
+		return nil, err
+	}
+	qw, err := newQuadWriter(qs, opts)
 	if err != nil {
+		qs.Close()
 		return nil, err
 	}
 	return &graph.Handle{QuadStore: qs, QuadWriter: qw}, nil
 }
 
+// withReasoning wraps the store if reasoning is enabled in the store options. The store is closed on error.
+func withReasoning(qs graph.QuadStore, opts graph.Options) (graph.QuadStore, error) {
+	rqs, err := reasoning.FromOptions(context.Background(), qs, opts)
+	if err != nil {
+		qs.Close()
+		return nil, err
+	}
+	return rqs, nil
+}
+
+// validateWriter returns the type and options of a quad writer for the store, which validates changes
+// if the validation on write is enabled. Shapes are loaded from the store, unless the shapes file is configured.
+func validateWriter(qs graph.QuadStore, opts graph.Options) (string, graph.Options, error) {
+	if !viper.GetBool(keyValidateOnWrite) {
+		return "single", opts, nil
+	}
+	shapes, err := loadShapes(context.Background(), qs, viper.GetString(keyValidateShapes))
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot load shapes: %w", err)
+	}
+	wopts := make(graph.Options, len(opts)+1)
+	for k, v := range opts {
+		wopts[k] = v
+	}
+	wopts["validate_shapes"] = shapes
+	return validate.WriterName, wopts, nil
+}
+
+// newQuadWriter creates a quad writer for the store, which validates changes if the validation on write is enabled
+// and records all changes if the audit is enabled.
+func newQuadWriter(qs graph.QuadStore, opts graph.Options) (graph.QuadWriter, error) {
+	wtyp, opts, err := validateWriter(qs, opts)
+	if err != nil {
+		return nil, err
+	}
+	path := viper.GetString(keyAuditFile)
+	if path == "" {
+		return graph.NewQuadWriter(wtyp, qs, opts)
+	}
+	wopts := make(graph.Options, len(opts)+4)
+	for k, v := range opts {
+		wopts[k] = v
+	}
+	wopts["audit_writer"] = wtyp
+	wopts["audit_file"] = path
+	if viper.IsSet(keyAuditMaxSize) {
+		wopts["audit_max_size_mb"] = viper.GetInt(keyAuditMaxSize)
+	}
+	if viper.IsSet(keyAuditMaxFiles) {
+		wopts["audit_max_files"] = viper.GetInt(keyAuditMaxFiles)
+	}
+	return graph.NewQuadWriter(audit.WriterName, qs, wopts)
+}
+
+// newLoadWriter returns a writer for loading quads into the database.
+//
+// Quads are written directly to the store, unless the audit or the validation on write is enabled.
+func newLoadWriter(h *graph.Handle) (quad.WriteCloser, error) {
+	switch h.QuadWriter.(type) {
+	case *audit.Writer, *validate.Writer:
+		return graph.NewWriter(h.QuadWriter), nil
+	}
+	return h.NewQuadWriter()
+}
+
 func openForQueries(cmd *cobra.Command) (*graph.Handle, error) {
 	if init, err := cmd.Flags().GetBool("init"); err != nil {
 		return nil, err
@@ -246,7 +319,7 @@ func openForQueries(cmd *cobra.Command) (*graph.Handle, error) {
 		load = load2
 	}
 	if load != "" {
-		qw, err := h.NewQuadWriter()
+		qw, err := newLoadWriter(h)
 		if err != nil {
 			h.Close()
 			return nil, err
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/cayleygraph/cayley/audit"
+	"github.com/cayleygraph/cayley/clog"
+	"github.com/cayleygraph/cayley/graph"
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
+)
+
+const (
+	keyDatabases    = "databases"
+	keyDatabasesDir = "databases_dir"
+)
+
+// databaseConfigs returns the named databases declared in the config.
+func databaseConfigs() ([]cayleyhttp.DatabaseConfig, error) {
+	var dbs []cayleyhttp.DatabaseConfig
+	if err := viper.UnmarshalKey(keyDatabases, &dbs); err != nil {
+		return nil, err
+	}
+	return dbs, nil
+}
+
+// databaseOpener returns a function that opens named databases.
+// If changes to the default database are recorded, changes to named databases are recorded to the same sink.
+// Changes are validated with shapes of each database, if the validation on write is enabled.
+func databaseOpener(def *graph.Handle) cayleyhttp.OpenFunc {
+	return func(c cayleyhttp.DatabaseConfig) (*graph.Handle, error) {
+		if graph.IsRegistered(c.Backend) && graph.IsPersistent(c.Backend) && !c.ReadOnly {
+			if err := graph.InitQuadStore(c.Backend, c.Address, c.Options); err != nil && err != graph.ErrDatabaseExists {
+				return nil, err
+			}
+		}
+		qs, err := graph.NewQuadStore(c.Backend, c.Address, c.Options)
+		if err != nil {
+			return nil, err
+		}
+		if qs, err = withReasoning(qs, c.Options); err != nil {
+			return nil, err
+		}
+		wtyp, wopts, err := validateWriter(qs, c.Options)
+		if err != nil {
+			qs.Close()
+			return nil, err
+		}
+		if aw, ok := def.QuadWriter.(*audit.Writer); ok {
+			opts := make(graph.Options, len(wopts)+3)
+			for k, v := range wopts {
+				opts[k] = v
+			}
+			opts["audit_writer"] = wtyp
+			opts["audit_sink"] = aw.Sink()
+			opts["audit_database"] = c.Name
+			wtyp, wopts = audit.WriterName, opts
+		}
+		qw, err := graph.NewQuadWriter(wtyp, qs, wopts)
+		if err != nil {
+			qs.Close()
+			return nil, err
+		}
+		return &graph.Handle{QuadStore: qs, QuadWriter: qw}, nil
+	}
+}
+
+// openDatabases opens the named databases declared in the config.
+func openDatabases(dbs *cayleyhttp.Databases) error {
+	confs, err := databaseConfigs()
+	if err != nil {
+		return err
+	}
+	for _, c := range confs {
+		clog.Infof("opening database %q using backend %q", c.Name, c.Backend)
+		if err = dbs.Open(c); err != nil {
+			return fmt.Errorf("cannot open database %q: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// replDatabases opens named databases declared in the config for the REPL.
+type replDatabases struct {
+	confs []cayleyhttp.DatabaseConfig
+	open  cayleyhttp.OpenFunc
+}
+
+func (d replDatabases) Names() []string {
+	names := make([]string, 0, len(d.confs))
+	for _, c := range d.confs {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func (d replDatabases) Open(name string) (*graph.Handle, error) {
+	for _, c := range d.confs {
+		if c.Name == name {
+			return d.open(c)
+		}
+	}
+	return nil, cayleyhttp.ErrDatabaseNotFound
+}
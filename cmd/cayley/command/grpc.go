@@ -0,0 +1,131 @@
+package command
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/cayleygraph/cayley/audit"
+	"github.com/cayleygraph/cayley/clog"
+	"github.com/cayleygraph/cayley/graph"
+	cayleygrpc "github.com/cayleygraph/cayley/server/grpc"
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
+	"github.com/cayleygraph/cayley/validate"
+)
+
+// requestWriter returns the type and options of quad writers that servers create for each request,
+// if requests are restricted to a subset of the graph.
+func requestWriter(h *graph.Handle) (string, graph.Options) {
+	var (
+		wtyp  string
+		wopts = make(graph.Options)
+		qw    = h.QuadWriter
+	)
+	if aw, ok := qw.(*audit.Writer); ok {
+		// writers created for each request must share the audit file
+		wtyp = audit.WriterName
+		wopts["audit_sink"] = aw.Sink()
+		wopts["audit_database"] = aw.Database()
+		qw = aw.Unwrap()
+	}
+	if vw, ok := qw.(*validate.Writer); ok {
+		wopts["validate_shapes"] = vw.Shapes()
+		if wtyp == "" {
+			wtyp = validate.WriterName
+		} else {
+			wopts["audit_writer"] = validate.WriterName
+		}
+	}
+	if wtyp == "" {
+		return "", nil
+	}
+	return wtyp, wopts
+}
+
+// newGRPCServer creates a gRPC server with the API of the database. TLS is disabled if tlsConf is nil.
+func newGRPCServer(h *graph.Handle, auth *cayleyhttp.Auth, tlsConf *tls.Config) (*grpc.Server, error) {
+	api := cayleygrpc.NewServer(h)
+	api.SetReadOnly(viper.GetBool(KeyReadOnly))
+	api.SetQueryTimeout(viper.GetDuration(keyQueryTimeout))
+	api.SetAuth(auth)
+	if wtyp, wopts := requestWriter(h); wtyp != "" {
+		api.SetWriter(wtyp, wopts)
+	}
+	if err := api.Namespaces().Load(context.Background()); err != nil {
+		return nil, fmt.Errorf("cannot load namespace rules: %w", err)
+	}
+	var opts []grpc.ServerOption
+	if tlsConf != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConf)))
+	}
+	s := grpc.NewServer(opts...)
+	api.Register(s)
+	return s, nil
+}
+
+// listenGRPC starts listening on a given address and returns a function that serves gRPC API on it.
+func listenGRPC(h *graph.Handle, auth *cayleyhttp.Auth, tlsConf *tls.Config, addr string) (func() error, error) {
+	s, err := newGRPCServer(h, auth, tlsConf)
+	if err != nil {
+		return nil, err
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	clog.Infof("gRPC API listening on %s", lis.Addr())
+	return func() error {
+		return s.Serve(lis)
+	}, nil
+}
+
+func NewGRPCCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grpc",
+		Short: "Serve a gRPC API on the given host and port.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// the same key is bound by other commands
+			viper.BindPFlag(keyQueryTimeout, cmd.Flags().Lookup("timeout"))
+			printBackendInfo()
+			p := mustSetupProfile(cmd)
+			defer mustFinishProfile(p)
+
+			h, err := openForQueries(cmd)
+			if err != nil {
+				return err
+			}
+			defer h.Close()
+
+			auth, err := httpAuth()
+			if err != nil {
+				return err
+			} else if auth == nil {
+				clog.Warningf("authentication is not configured, all clients have full access")
+			}
+			bindTLSFlags(cmd)
+			tlsConf, err := httpTLS()
+			if err != nil {
+				return err
+			}
+			host, _ := cmd.Flags().GetString("host")
+			serve, err := listenGRPC(h, auth, tlsConf, host)
+			if err != nil {
+				return err
+			}
+			return serve()
+		},
+	}
+	cmd.Flags().String("host", "127.0.0.1:64211", "host:port to listen on")
+	cmd.Flags().Bool("init", false, "initialize the database before using it")
+	cmd.Flags().DurationP("timeout", "t", 30*time.Second, "elapsed time until an individual query times out")
+	registerLoadFlags(cmd)
+	registerTLSFlags(cmd)
+	return cmd
+}
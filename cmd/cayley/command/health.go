@@ -1,20 +1,26 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	chttp "github.com/cayleygraph/cayley/internal/http"
 )
 
 const defaultAddress = "http://localhost:64210/"
 
 func NewHealthCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:     "health",
 		Aliases: []string{},
 		Short:   "Health check HTTP server",
+		Long:    "Check if the HTTP server and its database are ready to serve requests, and print the database state.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 1 {
 				return fmt.Errorf("Too many arguments provided, expected 0 or 1")
@@ -23,17 +29,60 @@ func NewHealthCmd() *cobra.Command {
 			if len(args) == 1 {
 				address = args[0]
 			}
-			healthAddress := address + "health"
+			if !strings.HasSuffix(address, "/") {
+				address += "/"
+			}
+			healthAddress := address + "health/ready"
+			if live, _ := cmd.Flags().GetBool("live"); live {
+				healthAddress = address + "health/live"
+			}
 			resp, err := http.Get(healthAddress)
 			if err != nil {
 				return err
 			}
 			defer resp.Body.Close()
-			if resp.StatusCode != 204 {
-				return fmt.Errorf("/health responded with status code %d, expected 204", resp.StatusCode)
+			var st chttp.HealthStatus
+			if err = json.NewDecoder(resp.Body).Decode(&st); err != nil {
+				return fmt.Errorf("%s responded with status code %d: %v", healthAddress, resp.StatusCode, err)
+			}
+			printHealth(st)
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("%s responded with status code %d, expected 200", healthAddress, resp.StatusCode)
 			}
 			log.Printf("%s ok", healthAddress)
 			return nil
 		},
 	}
+	cmd.Flags().Bool("live", false, "only check if the server is running, without checking the database")
+	return cmd
+}
+
+func printHealth(st chttp.HealthStatus) {
+	fmt.Printf("status:\t%s\n", st.Status)
+	if st.Error != "" {
+		fmt.Printf("error:\t%s\n", st.Error)
+	}
+	if st.Backend != "" {
+		fmt.Printf("backend:\t%s\n", st.Backend)
+	}
+	fmt.Printf("read-only:\t%v\n", st.ReadOnly)
+	if st.Stats != nil {
+		approx := ""
+		if !st.Stats.Exact {
+			approx = "~"
+		}
+		fmt.Printf("quads:\t%s%d\n", approx, st.Stats.Quads)
+		fmt.Printf("nodes:\t%s%d\n", approx, st.Stats.Nodes)
+	}
+	if st.ReplicationLag != "" {
+		fmt.Printf("replication lag:\t%s\n", st.ReplicationLag)
+	}
+	keys := make([]string, 0, len(st.Details))
+	for k := range st.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%s:\t%v\n", k, st.Details[k])
+	}
 }
@@ -1,6 +1,8 @@
 package command
 
 import (
+	"crypto/tls"
+	"errors"
 	"net"
 	"net/http"
 	"time"
@@ -8,10 +10,80 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/cayleygraph/cayley/audit"
 	"github.com/cayleygraph/cayley/clog"
 	chttp "github.com/cayleygraph/cayley/internal/http"
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
 )
 
+const (
+	keyHTTPAuth        = "http.auth"
+	keyHTTPCORSOrigins = "http.cors_origins"
+	keyHTTPLimits      = "http.limits"
+
+	keyHTTPTLSCert       = "http.tls.cert_file"
+	keyHTTPTLSKey        = "http.tls.key_file"
+	keyHTTPTLSMinVersion = "http.tls.min_version"
+	keyHTTPTLSClientCA   = "http.tls.client_ca"
+	keyHTTPTLSClientAuth = "http.tls.client_auth"
+)
+
+func httpAuth() (*cayleyhttp.Auth, error) {
+	var conf cayleyhttp.AuthConfig
+	if err := viper.UnmarshalKey(keyHTTPAuth, &conf); err != nil {
+		return nil, err
+	}
+	if !conf.Enabled() {
+		return nil, nil
+	}
+	return cayleyhttp.NewAuth(conf)
+}
+
+func httpLimits() (*cayleyhttp.Limiter, error) {
+	var conf cayleyhttp.LimitConfig
+	if err := viper.UnmarshalKey(keyHTTPLimits, &conf); err != nil {
+		return nil, err
+	}
+	if !conf.Enabled() {
+		return nil, nil
+	}
+	return cayleyhttp.NewLimiter(conf)
+}
+
+// httpTLS returns TLS config for servers, or nil if TLS is not configured.
+func httpTLS() (*tls.Config, error) {
+	// read keys one by one, since some of them can be set by flags
+	conf := cayleyhttp.TLSConfig{
+		CertFile:   viper.GetString(keyHTTPTLSCert),
+		KeyFile:    viper.GetString(keyHTTPTLSKey),
+		MinVersion: viper.GetString(keyHTTPTLSMinVersion),
+		ClientCA:   viper.GetString(keyHTTPTLSClientCA),
+		ClientAuth: viper.GetString(keyHTTPTLSClientAuth),
+	}
+	if !conf.Enabled() {
+		if conf.ClientCA != "" {
+			return nil, errors.New("TLS certificate must be set to verify client certificates")
+		}
+		return nil, nil
+	}
+	return cayleyhttp.NewTLSConfig(conf)
+}
+
+// registerTLSFlags registers flags for TLS config of servers.
+func registerTLSFlags(cmd *cobra.Command) {
+	cmd.Flags().String("tls-cert", "", "path to TLS certificate file; enables TLS")
+	cmd.Flags().String("tls-key", "", "path to TLS private key file")
+	cmd.Flags().String("tls-client-ca", "", "path to CA bundle to verify client certificates; enables mutual TLS")
+}
+
+// bindTLSFlags binds flags registered by registerTLSFlags to config keys.
+// It must be called when the command runs, since multiple commands define the same flags.
+func bindTLSFlags(cmd *cobra.Command) {
+	viper.BindPFlag(keyHTTPTLSCert, cmd.Flags().Lookup("tls-cert"))
+	viper.BindPFlag(keyHTTPTLSKey, cmd.Flags().Lookup("tls-key"))
+	viper.BindPFlag(keyHTTPTLSClientCA, cmd.Flags().Lookup("tls-client-ca"))
+}
+
 func NewHTTPCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "http",
@@ -27,26 +99,89 @@ func NewHTTPCmd() *cobra.Command {
 			}
 			defer h.Close()
 
-			err = chttp.SetupRoutes(h, &chttp.Config{
-				Timeout:  viper.GetDuration(keyQueryTimeout),
-				ReadOnly: viper.GetBool(KeyReadOnly),
-			})
+			auth, err := httpAuth()
 			if err != nil {
 				return err
+			} else if auth == nil {
+				clog.Warningf("authentication is not configured, all clients have full access")
+			}
+
+			limits, err := httpLimits()
+			if err != nil {
+				return err
+			}
+
+			bindTLSFlags(cmd)
+			tlsConf, err := httpTLS()
+			if err != nil {
+				return err
+			}
+
+			conf := &chttp.Config{
+				Backend:     viper.GetString(KeyBackend),
+				Timeout:     viper.GetDuration(keyQueryTimeout),
+				ReadOnly:    viper.GetBool(KeyReadOnly),
+				Auth:        auth,
+				Limits:      limits,
+				CORSOrigins: viper.GetStringSlice(keyHTTPCORSOrigins),
+
+				GizmoModules: viper.GetString(keyQueryGizmoModules),
+			}
+			conf.Writer, conf.WriterOptions = requestWriter(h)
+			if _, ok := h.QuadWriter.(*audit.Writer); ok {
+				clog.Infof("recording changes to %s", viper.GetString(keyAuditFile))
+			}
+			conf.DatabaseWriter = requestWriter
+			dbs := cayleyhttp.NewDatabases(databaseOpener(h))
+			dbs.SetDataDir(viper.GetString(keyDatabasesDir))
+			defer dbs.Close()
+			conf.Databases = dbs
+			err = chttp.SetupRoutes(h, conf)
+			if err != nil {
+				return err
+			}
+			if err = openDatabases(dbs); err != nil {
+				return err
+			}
+			errc := make(chan error, 2)
+			if addr, _ := cmd.Flags().GetString("grpc"); addr != "" {
+				serve, err := listenGRPC(h, auth, tlsConf, addr)
+				if err != nil {
+					return err
+				}
+				go func() {
+					errc <- serve()
+				}()
 			}
 			host, _ := cmd.Flags().GetString("host")
 			phost := host
 			if host, port, err := net.SplitHostPort(host); err == nil && host == "" {
 				phost = net.JoinHostPort("localhost", port)
 			}
-			clog.Infof("listening on %s, web interface at http://%s", host, phost)
-			return http.ListenAndServe(host, nil)
+			srv := &http.Server{Addr: host, TLSConfig: tlsConf}
+			go func() {
+				if tlsConf != nil {
+					errc <- srv.ListenAndServeTLS("", "")
+				} else {
+					errc <- srv.ListenAndServe()
+				}
+			}()
+			scheme := "http"
+			if tlsConf != nil {
+				scheme = "https"
+			}
+			clog.Infof("listening on %s, web interface at %s://%s", host, scheme, phost)
+			return <-errc
 		},
 	}
 	cmd.Flags().String("host", "127.0.0.1:64210", "host:port to listen on")
+	cmd.Flags().String("grpc", "", "host:port to serve gRPC API on (disabled by default)")
 	cmd.Flags().Bool("init", false, "initialize the database before using it")
 	cmd.Flags().DurationP("timeout", "t", 30*time.Second, "elapsed time until an individual query times out")
 	registerLoadFlags(cmd)
+	registerTLSFlags(cmd)
+	cmd.Flags().String("gizmo-modules", "", "directory with Gizmo modules available to queries and as stored procedures")
 	viper.BindPFlag(keyQueryTimeout, cmd.Flags().Lookup("timeout"))
+	viper.BindPFlag(keyQueryGizmoModules, cmd.Flags().Lookup("gizmo-modules"))
 	return cmd
 }
@@ -19,7 +19,8 @@ import (
 )
 
 const (
-	keyQueryTimeout = "query.timeout"
+	keyQueryTimeout      = "query.timeout"
+	keyQueryGizmoModules = "query.gizmo_modules"
 )
 
 func getContext() (context.Context, func()) {
@@ -66,7 +67,15 @@ func NewReplCmd() *cobra.Command {
 
 			timeout := viper.GetDuration("timeout")
 			lang, _ := cmd.Flags().GetString("lang")
-			return repl.Repl(ctx, h, lang, timeout)
+			confs, err := databaseConfigs()
+			if err != nil {
+				return err
+			}
+			var dbs repl.Databases
+			if len(confs) != 0 {
+				dbs = replDatabases{confs: confs, open: databaseOpener(h)}
+			}
+			return repl.Repl(ctx, h, lang, timeout, dbs)
 		},
 	}
 	registerQueryFlags(cmd)
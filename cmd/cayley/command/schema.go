@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/internal"
 	"github.com/cayleygraph/cayley/internal/linkedql/schema"
+	"github.com/cayleygraph/cayley/schema/gogen"
+	"github.com/cayleygraph/quad"
 )
 
 func NewSchemaCommand() *cobra.Command {
@@ -17,6 +22,7 @@ func NewSchemaCommand() *cobra.Command {
 	}
 	root.AddCommand(
 		NewLinkedQLSchemaCommand(),
+		NewGoSchemaCommand(),
 	)
 	return root
 }
@@ -40,3 +46,57 @@ func NewLinkedQLSchemaCommand() *cobra.Command {
 		},
 	}
 }
+
+func NewGoSchemaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen-go [file]",
+		Short: "Generate Go types for the schema package from RDFS/OWL vocabulary",
+		Long: "Generate Go structs with quad tags and schema.RegisterType calls for classes defined in a quad file,\n" +
+			"or in the database if the file is not specified.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("too many arguments provided, expected 0 or 1")
+			}
+			var quads []quad.Quad
+			if len(args) == 1 {
+				typ, _ := cmd.Flags().GetString(flagLoadFormat)
+				qr, err := internal.QuadReaderFor(args[0], typ)
+				if err != nil {
+					return err
+				}
+				quads, err = quad.ReadAll(qr)
+				qr.Close()
+				if err != nil {
+					return err
+				}
+			} else {
+				h, err := openDatabase()
+				if err != nil {
+					return err
+				}
+				qr := graph.NewQuadStoreReader(h.QuadStore)
+				quads, err = quad.ReadAll(qr)
+				qr.Close()
+				h.Close()
+				if err != nil {
+					return err
+				}
+			}
+			pkg, _ := cmd.Flags().GetString("package")
+			data, err := gogen.Generate(quads, gogen.Options{Package: pkg})
+			if err != nil {
+				return err
+			}
+			out, _ := cmd.Flags().GetString("out")
+			if out == "" || out == "-" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			return os.WriteFile(out, data, 0644)
+		},
+	}
+	cmd.Flags().String("package", "model", "name of the generated Go package")
+	cmd.Flags().StringP("out", "o", "", "file to write the generated code to (stdout by default)")
+	cmd.Flags().String(flagLoadFormat, "", "quad file format to use instead of auto-detection")
+	return cmd
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cayleygraph/cayley/clog"
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/validate"
+	"github.com/cayleygraph/quad"
+)
+
+const (
+	keyValidateOnWrite = "validate.on_write"
+	keyValidateShapes  = "validate.shapes"
+)
+
+// loadShapes loads SHACL shapes from a file, or from the database if path is empty.
+func loadShapes(ctx context.Context, qs graph.QuadStore, path string) (*validate.Shapes, error) {
+	if path != "" {
+		return validate.ReadShapesFile(path)
+	}
+	return validate.LoadShapesFrom(ctx, qs)
+}
+
+func NewValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the database against SHACL shapes.",
+		Long: "Validate all target nodes of SHACL shapes in the database and print the validation report.\n" +
+			"Shapes are loaded from a file (--shapes or validate.shapes config option), or from the database itself.\n" +
+			"The command fails if the data does not conform to the shapes.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("unexpected arguments: %q", args)
+			}
+			printBackendInfo()
+			h, err := openForQueries(cmd)
+			if err != nil {
+				return err
+			}
+			defer h.Close()
+
+			ctx := context.Background()
+			path, _ := cmd.Flags().GetString("shapes")
+			if path == "" {
+				path = viper.GetString(keyValidateShapes)
+			}
+			shapes, err := loadShapes(ctx, h.QuadStore, path)
+			if err != nil {
+				return err
+			} else if shapes.Len() == 0 {
+				return errors.New("no shapes found")
+			}
+			clog.Infof("validating with %d shapes", shapes.Len())
+			rep, err := shapes.Validate(ctx, h.QuadStore)
+			if err != nil {
+				return err
+			}
+			format, _ := cmd.Flags().GetString("format")
+			switch format {
+			case "text":
+				for _, r := range rep.Results {
+					fmt.Println(r.String())
+				}
+				fmt.Printf("conforms: %v, %d results\n", rep.Conforms, len(rep.Results))
+			case "jsonld":
+				if err := rep.WriteJSONLD(os.Stdout); err != nil {
+					return err
+				}
+			default:
+				f := quad.FormatByName(format)
+				if f == nil || f.Writer == nil {
+					return fmt.Errorf("unsupported format: %q", format)
+				}
+				w := f.Writer(os.Stdout)
+				if _, err := w.WriteQuads(rep.Quads()); err != nil {
+					w.Close()
+					return err
+				} else if err = w.Close(); err != nil {
+					return err
+				}
+			}
+			if !rep.Conforms {
+				return errors.New("data does not conform to shapes")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("init", false, "initialize the database before using it")
+	registerLoadFlags(cmd)
+	cmd.Flags().String("shapes", "", "file with SHACL shapes (validate.shapes config option, or shapes stored in the database are used by default)")
+	cmd.Flags().String("format", "text", `format of the report: "text", "jsonld" or any quad format, for example "nquads"`)
+	return cmd
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/cayleygraph/cayley/client"
 	"github.com/cayleygraph/cayley/clog"
 
 	// Load all supported quad formats.
@@ -21,6 +22,7 @@ const defaultFormat = "jsonld"
 // NewCmd creates the command
 func NewCmd() *cobra.Command {
 	var quiet bool
+	var tlsOpts client.TLSOptions
 	var uri, formatName, out string
 
 	var cmd = &cobra.Command{
@@ -60,8 +62,11 @@ func NewCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			client := &http.Client{}
-			resp, err := client.Do(req)
+			hc, err := client.NewHTTPClient(tlsOpts)
+			if err != nil {
+				return err
+			}
+			resp, err := hc.Do(req)
 			if err != nil {
 				return err
 			}
@@ -78,6 +83,7 @@ func NewCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&formatName, "format", "", "", "format of the provided data (if can not be detected defaults to JSON-LD)")
 	cmd.Flags().StringVarP(&out, "out", "o", "", "output file; if not specified, stdout is used")
 	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "hide all log output")
+	tlsOpts.RegisterFlags(cmd.Flags())
 
 	return cmd
 }
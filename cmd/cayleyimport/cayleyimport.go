@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/cayleygraph/cayley/client"
 	"github.com/cayleygraph/cayley/clog"
 
 	// Load all supported quad formats.
@@ -25,6 +26,7 @@ const defaultFormat = "jsonld"
 // NewCmd creates the command
 func NewCmd() *cobra.Command {
 	var quiet bool
+	var tlsOpts client.TLSOptions
 	var uri, formatName string
 
 	var cmd = &cobra.Command{
@@ -64,7 +66,11 @@ func NewCmd() *cobra.Command {
 			if format == nil {
 				format = quad.FormatByName(defaultFormat)
 			}
-			r, err := http.Post(uri+"/api/v2/write", format.Mime[0], reader)
+			hc, err := client.NewHTTPClient(tlsOpts)
+			if err != nil {
+				return err
+			}
+			r, err := hc.Post(uri+"/api/v2/write", format.Mime[0], reader)
 			if err != nil {
 				return err
 			}
@@ -96,6 +102,7 @@ func NewCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&uri, "uri", "", "http://127.0.0.1:64210", "Cayley URI connection string")
 	cmd.Flags().StringVarP(&formatName, "format", "", "", "format of the provided data (if can not be detected defaults to JSON-LD)")
 	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "hide all log output")
+	tlsOpts.RegisterFlags(cmd.Flags())
 	return cmd
 }
 
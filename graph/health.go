@@ -0,0 +1,81 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+	"time"
+)
+
+// Pinger is an optional interface for quad stores that can check if the backend is available.
+type Pinger interface {
+	// Ping checks the connection to the backend, or tries to access the storage.
+	Ping(ctx context.Context) error
+}
+
+// HealthReporter is an optional interface for quad stores that can report backend-specific health details.
+type HealthReporter interface {
+	// Health checks the backend and returns the details of its state.
+	Health(ctx context.Context) (map[string]interface{}, error)
+}
+
+// ReplicationLagger is an optional interface for quad writers that can report how far behind they are.
+// Writers that apply changes synchronously report zero lag.
+type ReplicationLagger interface {
+	// ReplicationLag returns the delay between accepting and applying the changes.
+	ReplicationLag(ctx context.Context) (time.Duration, error)
+}
+
+// ReplicationLag returns the replication lag of the quad writer. Writers that wrap other writers
+// and implement Unwrap() QuadWriter are unwrapped until a writer implementing ReplicationLagger is found.
+//
+// It returns false if no writer reports the lag.
+func ReplicationLag(ctx context.Context, qw QuadWriter) (time.Duration, bool, error) {
+	for qw != nil {
+		switch w := qw.(type) {
+		case ReplicationLagger:
+			lag, err := w.ReplicationLag(ctx)
+			return lag, true, err
+		case interface{ Unwrap() QuadWriter }:
+			qw = w.Unwrap()
+		default:
+			return 0, false, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// Ping checks if the quad store is available.
+//
+// If the quad store doesn't implement Pinger or HealthReporter, approximate stats are requested instead.
+func Ping(ctx context.Context, qs QuadStore) error {
+	switch qs := qs.(type) {
+	case Pinger:
+		return qs.Ping(ctx)
+	case HealthReporter:
+		_, err := qs.Health(ctx)
+		return err
+	}
+	_, err := qs.Stats(ctx, false)
+	return err
+}
+
+// Health checks if the quad store is available and returns backend-specific details, if any.
+func Health(ctx context.Context, qs QuadStore) (map[string]interface{}, error) {
+	if h, ok := qs.(HealthReporter); ok {
+		return h.Health(ctx)
+	}
+	return nil, Ping(ctx, qs)
+}
@@ -0,0 +1,751 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cayleygraph/quad"
+	"github.com/hidal-go/hidalgo/kv"
+
+	cproto "github.com/cayleygraph/cayley/graph/proto"
+	"github.com/cayleygraph/cayley/graph/refs"
+	"github.com/cayleygraph/cayley/internal/lru"
+)
+
+var ErrNotEmpty = errors.New("kv: bulk load requires an empty database")
+
+const (
+	// DefaultBulkRunSize is the default number of quads buffered in memory before
+	// a sorted run is written to disk.
+	DefaultBulkRunSize = 1 << 21
+	// DefaultBulkBatch is the default number of quads or index keys written in
+	// a single KV transaction.
+	DefaultBulkBatch = 1 << 16
+)
+
+const (
+	bulkStateFile = "state.json"
+	// bulkNodeCache is the number of recently used node IDs kept in memory.
+	bulkNodeCache = 1 << 16
+)
+
+// Phases of the bulk load, as reported by BulkProgress.
+const (
+	BulkPhaseRead  = "read"
+	BulkPhaseIndex = "index"
+	BulkPhaseDone  = "done"
+)
+
+// BulkOptions configures BulkLoad.
+type BulkOptions struct {
+	// Dir is a directory for sorted runs and checkpoints. An interrupted load
+	// can be resumed by calling BulkLoad with the same directory and input.
+	// If empty, a temporary directory is used and the load cannot be resumed.
+	Dir string
+	// RunSize is the number of quads to sort in memory before writing a run to disk.
+	RunSize int
+	// Batch is the number of quads or index keys written in a single transaction.
+	Batch int
+	// Progress is called after each transaction is committed.
+	Progress func(BulkProgress)
+}
+
+// BulkProgress describes the state of the bulk load.
+type BulkProgress struct {
+	Phase string
+	Quads int64 // quads read from the input
+	Nodes int64 // unique nodes found in the input
+	Index int   // index being written; the last one is node reference counts
+	Total int   // total number of indexes to write
+	Keys  int64 // keys written to the current index
+}
+
+// bulkState is a checkpoint of the bulk load. It's written to the disk each time
+// sorted runs are flushed, thus all the data it refers to is durable.
+type bulkState struct {
+	Indexes []QuadIndex `json:"indexes"`
+	Quads   int64       `json:"quads"`
+	Nodes   int64       `json:"nodes"`
+	Horizon uint64      `json:"horizon"`
+	Read    bool        `json:"read"`
+	// Runs is a list of sorted run files for each quad index. The last entry is
+	// for node reference counts.
+	Runs [][]string `json:"runs"`
+	Done []bool     `json:"done"`
+}
+
+// BulkLoad loads quads into an empty database, bypassing duplicate checks.
+//
+// It reads the input once, assigns IDs to nodes and quads and writes primitives
+// to the log. Node IDs are looked up in the KV, thus memory usage doesn't depend on the
+// number of nodes. Index entries are sorted externally in the directory specified in
+// options and are written directly to the KV after the input is consumed.
+//
+// The input must not contain duplicate quads, and it must return quads in the
+// same order if the load is resumed.
+func (qs *QuadStore) BulkLoad(ctx context.Context, r quad.Reader, opts BulkOptions) error {
+	if opts.RunSize <= 0 {
+		opts.RunSize = DefaultBulkRunSize
+	}
+	if opts.Batch <= 0 {
+		opts.Batch = DefaultBulkBatch
+	}
+	if opts.Dir == "" {
+		dir, err := os.MkdirTemp("", "cayley-bulk-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		opts.Dir = dir
+	} else if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return err
+	}
+	qs.writer.Lock()
+	defer qs.writer.Unlock()
+
+	qs.indexes.RLock()
+	indexes := qs.indexes.all
+	qs.indexes.RUnlock()
+
+	b := &bulkLoader{qs: qs, opts: opts}
+	if err := b.open(ctx, indexes); err != nil {
+		return err
+	}
+	if !b.st.Read {
+		if err := b.read(ctx, r); err != nil {
+			return err
+		}
+	}
+	for i := range b.st.Runs {
+		if b.st.Done[i] {
+			continue
+		}
+		if err := b.writeIndex(ctx, i); err != nil {
+			return err
+		}
+	}
+	if err := b.finish(ctx); err != nil {
+		return err
+	}
+	// the store is not empty anymore; rebuild filters to account for new quads
+	qs.mapBloom = nil
+	qs.mapNodes = nil
+	return qs.initBloomFilter(ctx)
+}
+
+type bulkLoader struct {
+	qs    *QuadStore
+	opts  BulkOptions
+	st    bulkState
+	nodes int64 // number of nodes, including the ones after the checkpoint
+
+	cache   *lru.Cache                // recently used node IDs
+	created map[refs.ValueHash]uint64 // nodes created in the current transaction
+}
+
+func (b *bulkLoader) path(name string) string {
+	return filepath.Join(b.opts.Dir, name)
+}
+
+func (b *bulkLoader) progress(p BulkProgress) {
+	if b.opts.Progress == nil {
+		return
+	}
+	p.Nodes = b.nodes
+	if p.Nodes == 0 {
+		p.Nodes = b.st.Nodes
+	}
+	p.Total = len(b.st.Runs)
+	b.opts.Progress(p)
+}
+
+// open loads the last checkpoint or starts a new bulk load if there is none.
+func (b *bulkLoader) open(ctx context.Context, indexes []QuadIndex) error {
+	horizon, err := b.qs.getMetaInt(ctx, "horizon")
+	if err == ErrNoBucket {
+		horizon = 0
+	} else if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(b.path(bulkStateFile))
+	if os.IsNotExist(err) {
+		if sz, err := b.qs.getSize(); err != nil {
+			return err
+		} else if sz != 0 || horizon != 0 {
+			return ErrNotEmpty
+		}
+		b.st = bulkState{
+			Indexes: indexes,
+			Runs:    make([][]string, len(indexes)+1),
+			Done:    make([]bool, len(indexes)+1),
+		}
+		return b.cleanup()
+	} else if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &b.st); err != nil {
+		return fmt.Errorf("cannot decode bulk load state: %v", err)
+	}
+	if !sameIndexes(b.st.Indexes, indexes) || len(b.st.Runs) != len(indexes)+1 || len(b.st.Done) != len(b.st.Runs) {
+		return fmt.Errorf("kv: bulk load state doesn't match the database indexes")
+	}
+	if uint64(horizon) < b.st.Horizon {
+		return fmt.Errorf("kv: bulk load state doesn't match the database")
+	}
+	return b.cleanup()
+}
+
+func sameIndexes(a, b []QuadIndex) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Unique != b[i].Unique || len(a[i].Dirs) != len(b[i].Dirs) {
+			return false
+		}
+		for j := range a[i].Dirs {
+			if a[i].Dirs[j] != b[i].Dirs[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cleanup removes runs that were written after the last checkpoint.
+func (b *bulkLoader) cleanup() error {
+	keep := map[string]struct{}{
+		bulkStateFile: {},
+	}
+	for _, runs := range b.st.Runs {
+		for _, name := range runs {
+			keep[name] = struct{}{}
+		}
+	}
+	files, err := filepath.Glob(b.path("run-*"))
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		if _, ok := keep[filepath.Base(name)]; ok {
+			continue
+		}
+		if err := os.Remove(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeID returns an ID assigned to the node, or zero if the node is new.
+//
+// IDs are stored in the KV and only a limited number of them is cached. IDs above the current
+// horizon were assigned after the checkpoint by an interrupted load, thus they are assigned again.
+func (b *bulkLoader) nodeID(ctx context.Context, tx kv.Tx, h refs.ValueHash, horizon uint64) (uint64, error) {
+	if id, ok := b.created[h]; ok {
+		return id, nil
+	} else if x, ok := b.cache.Get(string(h[:])); ok {
+		return x.(uint64), nil
+	}
+	val, err := tx.Get(ctx, bucketKeyForHash(h))
+	if err == kv.ErrNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	id, _ := binary.Uvarint(val)
+	if id > horizon {
+		return 0, nil
+	}
+	b.cache.Put(string(h[:]), id)
+	return id, nil
+}
+
+// saveState atomically replaces the checkpoint file.
+func (b *bulkLoader) saveState() error {
+	data, err := json.Marshal(b.st)
+	if err != nil {
+		return err
+	}
+	tmp := b.path(bulkStateFile + ".tmp")
+	if err = writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path(bulkStateFile))
+}
+
+func writeFileSync(name string, data []byte) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// read consumes the input, writes primitives to the log and spills
+// sorted index entries to the disk.
+func (b *bulkLoader) read(ctx context.Context, r quad.Reader) error {
+	// skip quads that were processed before the checkpoint
+	for i := int64(0); i < b.st.Quads; i++ {
+		if _, err := r.ReadQuad(); err == io.EOF {
+			return fmt.Errorf("kv: input is shorter than the bulk load checkpoint")
+		} else if err != nil {
+			return err
+		}
+	}
+	inds := b.st.Indexes
+	runs := make([]*runBuffer, len(inds)+1)
+	for i, ind := range inds {
+		runs[i] = newRunBuffer(8*len(ind.Dirs)+8, b.opts.RunSize)
+	}
+	runs[len(inds)] = newRunBuffer(len(refs.ValueHash{}), 2*b.opts.RunSize)
+
+	var (
+		tx      kv.Tx
+		n, inTx int
+		next    = b.st.Horizon
+		hash    refs.ValueHash
+	)
+	b.nodes = b.st.Nodes
+	b.cache = lru.New(bulkNodeCache)
+	b.created = make(map[refs.ValueHash]uint64)
+	defer func() {
+		if tx != nil {
+			tx.Close()
+		}
+	}()
+	commit := func(checkpoint bool) error {
+		if checkpoint {
+			// runs must be durable before the checkpoint refers to them
+			for i, rb := range runs {
+				name, err := rb.flush(b.opts.Dir, i, len(b.st.Runs[i]))
+				if err != nil {
+					return err
+				} else if name != "" {
+					b.st.Runs[i] = append(b.st.Runs[i], name)
+				}
+			}
+			if err := b.qs.setMetaInt(ctx, tx, "horizon", int64(next)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		tx = nil
+		for h, id := range b.created {
+			b.cache.Put(string(h[:]), id)
+		}
+		b.created = make(map[refs.ValueHash]uint64)
+		if checkpoint {
+			b.st.Quads += int64(n)
+			b.st.Nodes = b.nodes
+			b.st.Horizon = next
+			n = 0
+			if err := b.saveState(); err != nil {
+				return err
+			}
+		}
+		inTx = 0
+		b.progress(BulkProgress{Phase: BulkPhaseRead, Quads: b.st.Quads + int64(n)})
+		return nil
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q, err := r.ReadQuad()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if tx == nil {
+			tx, err = b.qs.db.Tx(ctx, true)
+			if err != nil {
+				return err
+			}
+			tx = wrapTx(tx)
+		}
+		link := &cproto.Primitive{}
+		for _, dir := range quad.Directions {
+			v := q.Get(dir)
+			if v == nil {
+				continue
+			}
+			hash = refs.HashOf(v)
+			id, err := b.nodeID(ctx, tx, hash, next)
+			if err != nil {
+				return err
+			} else if id == 0 {
+				next++
+				id = next
+				node, err := createNodePrimitive(v)
+				if err != nil {
+					return err
+				}
+				node.ID = id
+				if err = tx.Put(ctx, bucketKeyForHash(hash), uint64toBytes(id)); err != nil {
+					return err
+				}
+				if err = b.qs.addToLog(ctx, tx, node); err != nil {
+					return err
+				}
+				b.created[hash] = id
+				b.nodes++
+				mNodesNew.Inc()
+			}
+			link.SetDirection(dir, id)
+			runs[len(inds)].add(hash[:])
+		}
+		next++
+		link.ID = next
+		link.Timestamp = time.Now().UnixNano()
+		if err = b.qs.addToLog(ctx, tx, link); err != nil {
+			return err
+		}
+		for i, ind := range inds {
+			runs[i].addKey(ind.KeyFor(link)[1], link.ID)
+		}
+		n++
+		inTx++
+		if n >= b.opts.RunSize {
+			if err = commit(true); err != nil {
+				return err
+			}
+		} else if inTx >= b.opts.Batch {
+			if err = commit(false); err != nil {
+				return err
+			}
+		}
+	}
+	if tx == nil {
+		var err error
+		tx, err = b.qs.db.Tx(ctx, true)
+		if err != nil {
+			return err
+		}
+		tx = wrapTx(tx)
+	}
+	b.st.Read = true
+	return commit(true)
+}
+
+// writeIndex merges sorted runs of a given index and writes them to the KV.
+func (b *bulkLoader) writeIndex(ctx context.Context, i int) error {
+	var (
+		bucket  kv.Key
+		keySize int
+		recSize int
+	)
+	if i < len(b.st.Indexes) {
+		ind := b.st.Indexes[i]
+		bucket = ind.bucket()
+		keySize = 8 * len(ind.Dirs)
+		recSize = keySize + 8
+	} else {
+		keySize = len(refs.ValueHash{})
+		recSize = keySize
+	}
+	m, err := newRunMerger(b.opts.Dir, b.st.Runs[i], recSize)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	var (
+		tx      kv.Tx
+		keys    int64
+		inTx    int
+		lastKey []byte
+		ids     []uint64
+		cnt     uint64
+	)
+	defer func() {
+		if tx != nil {
+			tx.Close()
+		}
+	}()
+	put := func() error {
+		if lastKey == nil {
+			return nil
+		}
+		if tx == nil {
+			var err error
+			tx, err = b.qs.db.Tx(ctx, true)
+			if err != nil {
+				return err
+			}
+			tx = wrapTx(tx)
+		}
+		var (
+			k kv.Key
+			v []byte
+		)
+		if bucket != nil {
+			k, v = bucket.AppendBytes(lastKey), appendIndex(nil, ids)
+		} else {
+			var h refs.ValueHash
+			copy(h[:], lastKey)
+			k, v = bucketKeyForHashRefs(h), uint64toBytes(cnt)
+		}
+		if err := tx.Put(ctx, k, v); err != nil {
+			return err
+		}
+		keys++
+		inTx++
+		if inTx >= b.opts.Batch {
+			if err := tx.Commit(ctx); err != nil {
+				return err
+			}
+			tx, inTx = nil, 0
+			b.progress(BulkProgress{Phase: BulkPhaseIndex, Quads: b.st.Quads, Index: i, Keys: keys})
+		}
+		return nil
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rec, err := m.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if lastKey == nil || !bytes.Equal(lastKey, rec[:keySize]) {
+			if err = put(); err != nil {
+				return err
+			}
+			lastKey = append(lastKey[:0], rec[:keySize]...)
+			ids, cnt = ids[:0], 0
+		}
+		if bucket != nil {
+			ids = append(ids, quadKeyEnc.Uint64(rec[keySize:]))
+		}
+		cnt++
+	}
+	if err := put(); err != nil {
+		return err
+	}
+	if tx != nil {
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		tx = nil
+	}
+	b.progress(BulkProgress{Phase: BulkPhaseIndex, Quads: b.st.Quads, Index: i, Keys: keys})
+	runs := b.st.Runs[i]
+	b.st.Done[i] = true
+	b.st.Runs[i] = nil
+	if err := b.saveState(); err != nil {
+		return err
+	}
+	for _, name := range runs {
+		if err := os.Remove(b.path(name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish writes store metadata and removes the checkpoint.
+func (b *bulkLoader) finish(ctx context.Context) error {
+	err := kv.Update(ctx, b.qs.db, func(tx kv.Tx) error {
+		if err := b.qs.setMetaInt(ctx, tx, "horizon", int64(b.st.Horizon)); err != nil {
+			return err
+		}
+		return b.qs.setMetaInt(ctx, tx, "size", b.st.Quads)
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(b.path(bulkStateFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	b.progress(BulkProgress{Phase: BulkPhaseDone, Quads: b.st.Quads})
+	return nil
+}
+
+func (qs *QuadStore) setMetaInt(ctx context.Context, tx kv.Tx, key string, v int64) error {
+	buf := make([]byte, 8) // bolt needs all slices available on Commit
+	binary.LittleEndian.PutUint64(buf, uint64(v))
+	if err := tx.Put(ctx, metaBucket.AppendBytes([]byte(key)), buf); err != nil {
+		return fmt.Errorf("cannot set %s: %v", key, err)
+	}
+	return nil
+}
+
+// runBuffer accumulates fixed-size records in memory and writes them
+// to the disk as sorted runs.
+type runBuffer struct {
+	size int
+	buf  []byte
+	tmp  []byte
+}
+
+func newRunBuffer(size, n int) *runBuffer {
+	return &runBuffer{size: size, buf: make([]byte, 0, size*n), tmp: make([]byte, size)}
+}
+
+func (b *runBuffer) add(rec []byte) {
+	b.buf = append(b.buf, rec...)
+}
+
+func (b *runBuffer) addKey(key []byte, id uint64) {
+	b.buf = append(b.buf, key...)
+	var v [8]byte
+	quadKeyEnc.PutUint64(v[:], id)
+	b.buf = append(b.buf, v[:]...)
+}
+
+func (b *runBuffer) Len() int { return len(b.buf) / b.size }
+func (b *runBuffer) rec(i int) []byte {
+	return b.buf[i*b.size : (i+1)*b.size]
+}
+func (b *runBuffer) Less(i, j int) bool {
+	return bytes.Compare(b.rec(i), b.rec(j)) < 0
+}
+func (b *runBuffer) Swap(i, j int) {
+	copy(b.tmp, b.rec(i))
+	copy(b.rec(i), b.rec(j))
+	copy(b.rec(j), b.tmp)
+}
+
+// flush sorts buffered records and writes them to a new run file.
+// It returns an empty name if there was nothing to write.
+func (b *runBuffer) flush(dir string, index, n int) (string, error) {
+	if len(b.buf) == 0 {
+		return "", nil
+	}
+	sort.Sort(b)
+	name := fmt.Sprintf("run-%d-%06d", index, n)
+	if err := writeFileSync(filepath.Join(dir, name), b.buf); err != nil {
+		return "", err
+	}
+	b.buf = b.buf[:0]
+	return name, nil
+}
+
+// runMerger merges sorted runs of fixed-size records.
+type runMerger struct {
+	size  int
+	files []*os.File
+	h     runHeap
+	last  *runReader
+}
+
+type runReader struct {
+	r   *bufio.Reader
+	cur []byte
+}
+
+func newRunMerger(dir string, names []string, size int) (*runMerger, error) {
+	m := &runMerger{size: size}
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		m.files = append(m.files, f)
+		r := &runReader{r: bufio.NewReaderSize(f, 1<<16), cur: make([]byte, size)}
+		if ok, err := r.next(); err != nil {
+			m.Close()
+			return nil, err
+		} else if ok {
+			m.h = append(m.h, r)
+		}
+	}
+	heap.Init(&m.h)
+	return m, nil
+}
+
+func (r *runReader) next() (bool, error) {
+	_, err := io.ReadFull(r.r, r.cur)
+	if err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Next returns the next record in sorted order. The record is only valid until the next call.
+func (m *runMerger) Next() ([]byte, error) {
+	if m.last != nil {
+		// advance the reader that returned the previous record
+		if ok, err := m.last.next(); err != nil {
+			return nil, err
+		} else if ok {
+			heap.Fix(&m.h, 0)
+		} else {
+			heap.Pop(&m.h)
+		}
+		m.last = nil
+	}
+	if len(m.h) == 0 {
+		return nil, io.EOF
+	}
+	m.last = m.h[0]
+	return m.last.cur, nil
+}
+
+func (m *runMerger) Close() error {
+	var last error
+	for _, f := range m.files {
+		if err := f.Close(); err != nil {
+			last = err
+		}
+	}
+	m.files = nil
+	return last
+}
+
+type runHeap []*runReader
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return bytes.Compare(h[i].cur, h[j].cur) < 0 }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x interface{}) {
+	*h = append(*h, x.(*runReader))
+}
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
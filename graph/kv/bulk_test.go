@@ -0,0 +1,144 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/graphtest"
+	"github.com/cayleygraph/cayley/graph/kv"
+	"github.com/cayleygraph/cayley/graph/kv/btree"
+)
+
+func newBulkStore(t testing.TB) *kv.QuadStore {
+	db := btree.New()
+	require.NoError(t, kv.Init(db, nil))
+	qs, err := kv.New(db, nil)
+	require.NoError(t, err)
+	return qs.(*kv.QuadStore)
+}
+
+func readAllQuads(t testing.TB, qs graph.QuadStore) []quad.Quad {
+	r := graph.NewQuadStoreReader(qs)
+	defer r.Close()
+	var out []quad.Quad
+	for {
+		q, err := r.ReadQuad()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		out = append(out, q)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].NQuad() < out[j].NQuad()
+	})
+	return out
+}
+
+func sortedQuads(in []quad.Quad) []quad.Quad {
+	out := append([]quad.Quad{}, in...)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].NQuad() < out[j].NQuad()
+	})
+	return out
+}
+
+// failingReader returns an error after reading n quads.
+type failingReader struct {
+	quad.Reader
+	n int
+}
+
+var errReaderFailed = errors.New("reader failed")
+
+func (r *failingReader) ReadQuad() (quad.Quad, error) {
+	if r.n <= 0 {
+		return quad.Quad{}, errReaderFailed
+	}
+	r.n--
+	return r.Reader.ReadQuad()
+}
+
+func checkBulkStore(t *testing.T, qs *kv.QuadStore, quads []quad.Quad) {
+	ctx := context.TODO()
+	require.Equal(t, sortedQuads(quads), readAllQuads(t, qs))
+
+	st, err := qs.Stats(ctx, true)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(quads)), st.Quads.Value)
+
+	// removing quads must remove their nodes, thus reference counts are correct
+	deltas := make([]graph.Delta, 0, len(quads))
+	for _, q := range quads[1:] {
+		deltas = append(deltas, graph.Delta{Quad: q, Action: graph.Delete})
+	}
+	require.NoError(t, qs.ApplyDeltas(deltas, graph.IgnoreOpts{}))
+	st, err = qs.Stats(ctx, true)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), st.Quads.Value)
+	require.Equal(t, int64(3), st.Nodes.Value)
+
+	// duplicate checks must see quads written by the bulk loader
+	err = qs.ApplyDeltas([]graph.Delta{{Quad: quads[0], Action: graph.Add}}, graph.IgnoreOpts{})
+	require.True(t, errors.Is(err, graph.ErrQuadExists), "%v", err)
+}
+
+func TestBulkLoad(t *testing.T) {
+	quads := graphtest.MakeQuadSet()
+	qs := newBulkStore(t)
+	defer qs.Close()
+
+	var last kv.BulkProgress
+	err := qs.BulkLoad(context.TODO(), quad.NewReader(quads), kv.BulkOptions{
+		Dir:      t.TempDir(),
+		RunSize:  5,
+		Batch:    3,
+		Progress: func(p kv.BulkProgress) { last = p },
+	})
+	require.NoError(t, err)
+	require.Equal(t, kv.BulkPhaseDone, last.Phase)
+	require.Equal(t, int64(len(quads)), last.Quads)
+
+	checkBulkStore(t, qs, quads)
+
+	err = qs.BulkLoad(context.TODO(), quad.NewReader(quads), kv.BulkOptions{})
+	require.Equal(t, kv.ErrNotEmpty, err)
+}
+
+func TestBulkLoadResume(t *testing.T) {
+	quads := graphtest.MakeQuadSet()
+	qs := newBulkStore(t)
+	defer qs.Close()
+
+	dir := t.TempDir()
+	opts := kv.BulkOptions{Dir: dir, RunSize: 4, Batch: 3}
+	for _, n := range []int{6, 6, 9} {
+		err := qs.BulkLoad(context.TODO(), &failingReader{Reader: quad.NewReader(quads), n: n}, opts)
+		require.Equal(t, errReaderFailed, err)
+	}
+	err := qs.BulkLoad(context.TODO(), quad.NewReader(quads), opts)
+	require.NoError(t, err)
+
+	checkBulkStore(t, qs, quads)
+}
@@ -0,0 +1,274 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hidal-go/hidalgo/kv"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/cayleygraph/cayley/clog"
+	"github.com/cayleygraph/cayley/graph"
+	cproto "github.com/cayleygraph/cayley/graph/proto"
+)
+
+const (
+	// OptCompactInterval enables background compaction with a given interval, for example "1h".
+	OptCompactInterval = "compact_interval"
+
+	// DefaultCompactBatch is the default number of log entries processed in a single transaction.
+	DefaultCompactBatch = 1000
+)
+
+// CompactOptions configures Compact.
+type CompactOptions struct {
+	// Batch is the number of log entries processed in a single transaction.
+	// Writes are blocked only while a batch is processed.
+	Batch int
+	// Progress is called after each transaction is committed.
+	Progress func(CompactStats)
+}
+
+// CompactStats describes the work done by the compaction.
+type CompactStats struct {
+	Scanned   int64 // log entries scanned
+	Quads     int64 // dead quads removed from the log
+	Entries   int64 // references to dead quads removed from indexes
+	EmptyKeys int64 // index keys removed because no quads were left in them
+	// Bytes is the size of keys and values removed from the database.
+	// Backends may not return this space to the file system immediately.
+	Bytes int64
+}
+
+// Compact physically removes deleted quads from the log and the quad indexes and
+// rebuilds the bloom filter used for duplicate checks.
+//
+// The log is processed in batches, each in a separate write transaction, thus
+// compaction can run while the quad store is in use.
+func (qs *QuadStore) Compact(ctx context.Context, opts CompactOptions) (CompactStats, error) {
+	if opts.Batch <= 0 {
+		opts.Batch = DefaultCompactBatch
+	}
+	var (
+		st   CompactStats
+		from uint64
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return st, err
+		}
+		next, done, err := qs.compactBatch(ctx, from, opts.Batch, &st)
+		if err != nil {
+			return st, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(st)
+		}
+		if done {
+			break
+		}
+		from = next
+	}
+	if st.Quads == 0 {
+		return st, nil
+	}
+	return st, qs.rebuildBloomFilter(ctx)
+}
+
+func keySize(k kv.Key) int64 {
+	var n int64
+	for _, p := range k {
+		n += int64(len(p))
+	}
+	return n
+}
+
+// compactBatch removes dead quads from up to n log entries starting from a given ID.
+// It returns the ID to continue from, or true if the end of the log was reached.
+func (qs *QuadStore) compactBatch(ctx context.Context, from uint64, n int, st *CompactStats) (uint64, bool, error) {
+	qs.writer.Lock()
+	defer qs.writer.Unlock()
+	tx, err := qs.db.Tx(ctx, true)
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Close()
+	tx = wrapTx(tx)
+
+	var (
+		dead []*cproto.Primitive
+		next uint64
+		done = true
+	)
+	it := tx.Scan(ctx)
+	for ok := kv.Seek(ctx, it, logIndex.Append(uint64KeyBytes(from))); ok; ok = it.Next(ctx) {
+		k := it.Key()
+		if !k.HasPrefix(logIndex) {
+			break
+		} else if len(k) == 1 {
+			continue // bucket key
+		} else if len(k) != 2 || len(k[1]) != 8 {
+			it.Close()
+			return 0, false, fmt.Errorf("kv: unexpected log key: %v", k)
+		}
+		id := quadKeyEnc.Uint64(k[1])
+		if n <= 0 {
+			next, done = id, false
+			break
+		}
+		n--
+		st.Scanned++
+		p := new(cproto.Primitive)
+		if err := proto.Unmarshal(it.Val(), p); err != nil {
+			it.Close()
+			return 0, false, err
+		}
+		if p.IsNode() || !p.Deleted {
+			continue
+		}
+		st.Bytes += keySize(k) + int64(len(it.Val()))
+		dead = append(dead, p)
+	}
+	err = it.Err()
+	it.Close()
+	if err != nil {
+		return 0, false, err
+	}
+	if len(dead) != 0 {
+		if err = qs.removeDead(ctx, tx, dead, st); err != nil {
+			return 0, false, err
+		}
+	}
+	return next, done, tx.Commit(ctx)
+}
+
+// removeDead removes dead quads from the log and all quad indexes.
+func (qs *QuadStore) removeDead(ctx context.Context, tx kv.Tx, dead []*cproto.Primitive, st *CompactStats) error {
+	qs.indexes.RLock()
+	indexes := qs.indexes.all
+	qs.indexes.RUnlock()
+
+	// collect quad IDs to remove for each index key
+	rm := make(map[string]map[uint64]struct{})
+	var keys []kv.Key
+	for _, p := range dead {
+		for _, ind := range indexes {
+			k := ind.KeyFor(p)
+			sk := string(k[0]) + string(k[1])
+			ids, ok := rm[sk]
+			if !ok {
+				ids = make(map[uint64]struct{})
+				rm[sk] = ids
+				keys = append(keys, k)
+			}
+			ids[p.ID] = struct{}{}
+		}
+	}
+	vals, err := tx.GetBatch(ctx, keys)
+	if err != nil {
+		return err
+	}
+	for i, k := range keys {
+		if vals[i] == nil {
+			continue
+		}
+		list, err := decodeIndex(vals[i])
+		if err != nil {
+			return err
+		}
+		ids := rm[string(k[0])+string(k[1])]
+		out := list[:0]
+		for _, id := range list {
+			if _, ok := ids[id]; !ok {
+				out = append(out, id)
+			}
+		}
+		if len(out) == len(list) {
+			continue
+		}
+		st.Entries += int64(len(list) - len(out))
+		if len(out) == 0 {
+			if err = tx.Del(ctx, k); err != nil {
+				return err
+			}
+			st.EmptyKeys++
+			st.Bytes += keySize(k) + int64(len(vals[i]))
+			continue
+		}
+		buf := appendIndex(nil, out)
+		if err = tx.Put(ctx, k, buf); err != nil {
+			return err
+		}
+		st.Bytes += int64(len(vals[i]) - len(buf))
+	}
+	for _, p := range dead {
+		if err = qs.delLog(ctx, tx, p.ID); err != nil {
+			return err
+		}
+		st.Quads++
+	}
+	return nil
+}
+
+// startCompaction runs the compaction in background with a given interval until the quad store is closed.
+func (qs *QuadStore) startCompaction(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	qs.compact.cancel = cancel
+	qs.compact.done = make(chan struct{})
+	go func() {
+		defer close(qs.compact.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			st, err := qs.Compact(ctx, CompactOptions{})
+			if err == context.Canceled {
+				return
+			} else if err != nil {
+				clog.Errorf("kv: compaction failed: %v", err)
+			} else if st.Quads != 0 {
+				clog.Infof("kv: compaction removed %d dead quads, reclaimed %d bytes", st.Quads, st.Bytes)
+			}
+		}
+	}()
+}
+
+func (qs *QuadStore) stopCompaction() {
+	if qs.compact.cancel == nil {
+		return
+	}
+	qs.compact.cancel()
+	<-qs.compact.done
+	qs.compact.cancel = nil
+}
+
+func compactInterval(opt graph.Options) (time.Duration, error) {
+	s, err := opt.StringKey(OptCompactInterval, "")
+	if err != nil || s == "" {
+		return 0, err
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse %s: %v", OptCompactInterval, err)
+	}
+	return d, nil
+}
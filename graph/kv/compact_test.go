@@ -0,0 +1,119 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	hkv "github.com/hidal-go/hidalgo/kv"
+	"github.com/hidal-go/hidalgo/kv/options"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/graphtest"
+	"github.com/cayleygraph/cayley/graph/kv"
+	"github.com/cayleygraph/cayley/graph/kv/btree"
+)
+
+func countKeys(t testing.TB, db hkv.KV, pref hkv.Key) int {
+	var n int
+	err := hkv.View(context.TODO(), db, func(tx hkv.Tx) error {
+		it := tx.Scan(context.TODO(), options.WithPrefixKV(pref))
+		defer it.Close()
+		for it.Next(context.TODO()) {
+			if len(it.Key()) > len(pref) { // skip bucket keys
+				n++
+			}
+		}
+		return it.Err()
+	})
+	require.NoError(t, err)
+	return n
+}
+
+func TestCompact(t *testing.T) {
+	ctx := context.TODO()
+	db := btree.New()
+	require.NoError(t, kv.Init(db, nil))
+	hqs, err := kv.New(db, nil)
+	require.NoError(t, err)
+	qs := hqs.(*kv.QuadStore)
+	defer qs.Close()
+
+	quads := graphtest.MakeQuadSet()
+	var deltas []graph.Delta
+	for _, q := range quads {
+		deltas = append(deltas, graph.Delta{Quad: q, Action: graph.Add})
+	}
+	require.NoError(t, qs.ApplyDeltas(deltas, graph.IgnoreOpts{}))
+
+	logKey := hkv.Key{[]byte("log")}
+	indexKeys := func() int {
+		var n int
+		for _, ind := range kv.DefaultQuadIndexes {
+			pref := make([]byte, 0, len(ind.Dirs))
+			for _, d := range ind.Dirs {
+				pref = append(pref, d.Prefix())
+			}
+			n += countKeys(t, db, hkv.Key{pref})
+		}
+		return n
+	}
+	logs, keys := countKeys(t, db, logKey), indexKeys()
+
+	// delete quads, but keep all nodes alive
+	deleted := quads[2:5]
+	deltas = deltas[:0]
+	for _, q := range deleted {
+		deltas = append(deltas, graph.Delta{Quad: q, Action: graph.Delete})
+	}
+	require.NoError(t, qs.ApplyDeltas(deltas, graph.IgnoreOpts{}))
+	require.Equal(t, logs, countKeys(t, db, logKey))
+
+	var calls int
+	st, err := qs.Compact(ctx, kv.CompactOptions{
+		Batch:    4,
+		Progress: func(kv.CompactStats) { calls++ },
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(logs), st.Scanned)
+	require.Equal(t, int64(len(deleted)), st.Quads)
+	require.Equal(t, int64(len(kv.DefaultQuadIndexes)*len(deleted)), st.Entries)
+	require.True(t, st.EmptyKeys > 0)
+	require.True(t, st.Bytes > 0)
+	require.True(t, calls > 1)
+
+	require.Equal(t, logs-len(deleted), countKeys(t, db, logKey))
+	require.Equal(t, keys-int(st.EmptyKeys), indexKeys())
+	exp := append([]quad.Quad{quads[0], quads[1]}, quads[5:]...)
+	require.Equal(t, sortedQuads(exp), readAllQuads(t, qs))
+
+	// nothing left to compact
+	st, err = qs.Compact(ctx, kv.CompactOptions{})
+	require.NoError(t, err)
+	require.Equal(t, kv.CompactStats{Scanned: int64(logs - len(deleted))}, st)
+
+	// the store is still consistent
+	err = qs.ApplyDeltas([]graph.Delta{{Quad: quads[1], Action: graph.Add}}, graph.IgnoreOpts{})
+	require.Error(t, err)
+	require.NoError(t, qs.ApplyDeltas([]graph.Delta{
+		{Quad: quads[2], Action: graph.Add},
+		{Quad: quads[1], Action: graph.Delete},
+	}, graph.IgnoreOpts{}))
+	exp = append([]quad.Quad{quads[0], quads[2]}, quads[5:]...)
+	require.Equal(t, sortedQuads(exp), readAllQuads(t, qs))
+}
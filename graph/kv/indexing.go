@@ -1183,30 +1183,74 @@ func (qs *QuadStore) initBloomFilter(ctx context.Context) error {
 		return nil
 	}
 	qs.exists.buf = make([]byte, 3*8)
-	qs.exists.DeletableBloomFilter = boom.NewDeletableBloomFilter(100*1000*1000, 120, 0.05)
+	qs.exists.DeletableBloomFilter = newBloomFilter()
 	return kv.View(ctx, qs.db, func(tx kv.Tx) error {
-		p := cproto.Primitive{}
-		it := tx.Scan(ctx, options.WithPrefixKV(logIndex))
-		defer it.Close()
-		for it.Next(ctx) {
-			v := it.Val()
-			p = cproto.Primitive{}
-			err := proto.Unmarshal(v, &p)
-			if err != nil {
-				return err
-			}
-			if p.IsNode() {
-				continue
-			} else if p.Deleted {
-				continue
-			}
-			writePrimToBuf(&p, qs.exists.buf)
-			qs.exists.Add(qs.exists.buf)
-		}
-		return it.Err()
+		return qs.fillBloomFilter(ctx, tx, qs.exists.DeletableBloomFilter)
 	})
 }
 
+func newBloomFilter() *boom.DeletableBloomFilter {
+	return boom.NewDeletableBloomFilter(100*1000*1000, 120, 0.05)
+}
+
+// fillBloomFilter adds all quads from the log to the filter.
+func (qs *QuadStore) fillBloomFilter(ctx context.Context, tx kv.Tx, f *boom.DeletableBloomFilter) error {
+	buf := make([]byte, 3*8)
+	p := cproto.Primitive{}
+	it := tx.Scan(ctx, options.WithPrefixKV(logIndex))
+	defer it.Close()
+	for it.Next(ctx) {
+		v := it.Val()
+		p = cproto.Primitive{}
+		err := proto.Unmarshal(v, &p)
+		if err != nil {
+			return err
+		}
+		if p.IsNode() {
+			continue
+		} else if p.Deleted {
+			continue
+		}
+		writePrimToBuf(&p, buf)
+		qs.exists.Lock()
+		f.Add(buf)
+		qs.exists.Unlock()
+	}
+	return it.Err()
+}
+
+// rebuildBloomFilter replaces the bloom filter with a new one to remove false positives
+// left by deleted quads. Writes are only blocked while the read transaction is started.
+func (qs *QuadStore) rebuildBloomFilter(ctx context.Context) error {
+	if qs.exists.disabled {
+		return nil
+	}
+	f := newBloomFilter()
+	qs.writer.Lock()
+	tx, err := qs.db.Tx(ctx, false)
+	if err != nil {
+		qs.writer.Unlock()
+		return err
+	}
+	defer tx.Close()
+	// quads written after this point will be added to both filters
+	qs.exists.Lock()
+	qs.exists.next = f
+	qs.exists.Unlock()
+	qs.writer.Unlock()
+
+	err = qs.fillBloomFilter(ctx, wrapTx(tx), f)
+
+	qs.exists.Lock()
+	defer qs.exists.Unlock()
+	qs.exists.next = nil
+	if err != nil {
+		return err
+	}
+	qs.exists.DeletableBloomFilter = f
+	return nil
+}
+
 func (qs *QuadStore) testBloom(p *cproto.Primitive) bool {
 	if qs.exists.disabled {
 		return true // false positives are expected
@@ -1235,6 +1279,9 @@ func (qs *QuadStore) bloomAdd(p *cproto.Primitive) {
 	defer qs.exists.Unlock()
 	writePrimToBuf(p, qs.exists.buf)
 	qs.exists.Add(qs.exists.buf)
+	if qs.exists.next != nil {
+		qs.exists.next.Add(qs.exists.buf)
+	}
 }
 
 func writePrimToBuf(p *cproto.Primitive, buf []byte) {
@@ -114,6 +114,12 @@ type QuadStore struct {
 		sync.Mutex
 		buf []byte
 		*boom.DeletableBloomFilter
+		next *boom.DeletableBloomFilter // filter being rebuilt
+	}
+
+	compact struct {
+		cancel func()
+		done   chan struct{}
 	}
 }
 
@@ -186,6 +192,11 @@ func New(kv kv.KV, opt graph.Options) (graph.QuadStore, error) {
 			qs.mapNodes = boom.NewBloomFilter(100*1000*1000, 0.05)
 		}
 	}
+	if d, err := compactInterval(opt); err != nil {
+		return nil, err
+	} else if d > 0 {
+		qs.startCompaction(d)
+	}
 	return qs, nil
 }
 
@@ -262,7 +273,14 @@ func (qs *QuadStore) Stats(ctx context.Context, exact bool) (graph.Stats, error)
 	return st, nil
 }
 
+// Ping implements graph.Pinger. It reads the database version in a read-only transaction.
+func (qs *QuadStore) Ping(ctx context.Context) error {
+	_, err := qs.getMetadata(ctx)
+	return err
+}
+
 func (qs *QuadStore) Close() error {
+	qs.stopCompaction()
 	return qs.db.Close()
 }
 
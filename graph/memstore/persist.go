@@ -0,0 +1,924 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/pquads"
+
+	"github.com/cayleygraph/cayley/clog"
+	"github.com/cayleygraph/cayley/graph"
+)
+
+const (
+	// OptPersistPath is a directory to store snapshots and the journal in.
+	// If set, the quad store is loaded from it on start and all changes are written to it.
+	OptPersistPath = "persist_path"
+	// OptSnapshotInterval is a minimal interval between snapshots, for example "10m".
+	// Snapshots are only written on Close if set to "0".
+	OptSnapshotInterval = "snapshot_interval"
+	// OptFsync is a policy of flushing the journal to the disk:
+	// "always" (after each write), "second" (at most once per second) or "never".
+	OptFsync = "fsync"
+)
+
+const (
+	FsyncAlways = "always"
+	FsyncSecond = "second"
+	FsyncNever  = "never"
+
+	defaultSnapshotInterval = 5 * time.Minute
+)
+
+var ErrCorruptSnapshot = errors.New("memstore: corrupt snapshot")
+
+const (
+	snapshotFile    = "snapshot"
+	snapshotMagic   = "CAYLEYMS"
+	snapshotVersion = 1
+	journalPrefix   = "journal."
+
+	maxJournalRecord = 1 << 30
+)
+
+// persister writes changes of the quad store to a journal and periodically
+// replaces the journal with a snapshot of the whole store.
+//
+// Periodic snapshots are written in the background from a Snapshot of the store,
+// while following changes go to the next journal.
+type persister struct {
+	dir      string
+	interval time.Duration
+	fsync    string
+
+	gen      uint64 // generation of the current journal
+	journal  *os.File
+	buf      bytes.Buffer
+	dirty    bool // changes since the last snapshot
+	unsynced bool // changes since the last fsync
+	lastSync time.Time
+	lastSnap time.Time
+	pending  chan error // result of the snapshot written in the background; nil if there is none
+	err      error
+}
+
+// Open loads an in-memory quad store from a snapshot and a journal in a given
+// directory and persists all further changes to it.
+func Open(path string, opts graph.Options) (*QuadStore, error) {
+	p := &persister{dir: path, interval: defaultSnapshotInterval}
+	if s, err := opts.StringKey(OptSnapshotInterval, ""); err != nil {
+		return nil, err
+	} else if s != "" {
+		if p.interval, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("couldn't parse %s: %v", OptSnapshotInterval, err)
+		}
+	}
+	var err error
+	p.fsync, err = opts.StringKey(OptFsync, FsyncSecond)
+	if err != nil {
+		return nil, err
+	}
+	switch p.fsync {
+	case FsyncAlways, FsyncSecond, FsyncNever:
+	default:
+		return nil, fmt.Errorf("unsupported %s policy: %q", OptFsync, p.fsync)
+	}
+	if err = os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	qs := newQuadStore()
+	if err = p.load(qs); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	p.lastSync, p.lastSnap = now, now
+	qs.persist = p
+	return qs, nil
+}
+
+func (p *persister) path(name string) string {
+	return filepath.Join(p.dir, name)
+}
+
+func journalName(gen uint64) string {
+	return journalPrefix + strconv.FormatUint(gen, 10)
+}
+
+// journals returns generations of all journal files in the directory, in ascending order.
+func (p *persister) journals() ([]uint64, error) {
+	files, err := filepath.Glob(p.path(journalPrefix + "*"))
+	if err != nil {
+		return nil, err
+	}
+	var gens []uint64
+	for _, name := range files {
+		gen, err := strconv.ParseUint(strings.TrimPrefix(filepath.Base(name), journalPrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		gens = append(gens, gen)
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i] < gens[j] })
+	return gens, nil
+}
+
+// load reads the last snapshot, replays journals written after it and opens the last journal for writing.
+func (p *persister) load(qs *QuadStore) error {
+	p.gen = 1
+	f, err := os.Open(p.path(snapshotFile))
+	if err == nil {
+		p.gen, err = qs.readSnapshot(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("cannot read snapshot: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	gens, err := p.journals()
+	if err != nil {
+		return err
+	}
+	var size int64
+	for i, gen := range gens {
+		if gen < p.gen {
+			// already included into the snapshot
+			if err = os.Remove(p.path(journalName(gen))); err != nil {
+				return err
+			}
+			continue
+		}
+		last := i == len(gens)-1
+		size, err = qs.replayJournal(p.path(journalName(gen)), last)
+		if err != nil {
+			return err
+		}
+		p.gen = gen
+	}
+	p.journal, err = os.OpenFile(p.path(journalName(p.gen)), os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	// drop incomplete records written before the crash
+	if err = p.journal.Truncate(size); err != nil {
+		p.journal.Close()
+		return err
+	}
+	if _, err = p.journal.Seek(size, io.SeekStart); err != nil {
+		p.journal.Close()
+		return err
+	}
+	return nil
+}
+
+// replayJournal applies all complete records from the journal and returns the size of valid data.
+// An incomplete record is only allowed at the end of the last journal.
+func (qs *QuadStore) replayJournal(path string, last bool) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var (
+		off  int64
+		data []byte
+		sum  [4]byte
+		n    int
+	)
+	for {
+		sz, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			return off, nil
+		} else if err == nil && sz > maxJournalRecord {
+			err = fmt.Errorf("record is too large: %d", sz)
+		}
+		if err == nil {
+			data = append(data[:0], make([]byte, sz)...)
+			if _, err = io.ReadFull(r, sum[:]); err == nil {
+				_, err = io.ReadFull(r, data)
+			}
+		}
+		if err == nil && crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(sum[:]) {
+			err = fmt.Errorf("checksum mismatch")
+		}
+		var op interface{}
+		if err == nil {
+			op, err = decodeOp(data)
+		}
+		if err != nil {
+			if last {
+				clog.Warningf("memstore: dropping incomplete journal record %d: %v", n, err)
+				return off, nil
+			}
+			return 0, fmt.Errorf("cannot read journal %q: %w", path, err)
+		}
+		qs.applyOp(op)
+		n++
+		off += int64(uvarintSize(sz)) + int64(len(sum)) + int64(sz)
+	}
+}
+
+func uvarintSize(v uint64) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], v)
+}
+
+// Checkpoint writes a snapshot of the quad store to the disk and removes the journal.
+// It waits for the snapshot written in the background, if any.
+func (qs *QuadStore) Checkpoint() error {
+	if qs.persist == nil {
+		return nil
+	}
+	return qs.persist.checkpoint(qs)
+}
+
+func (p *persister) checkpoint(qs *QuadStore) error {
+	// changes of a failed background snapshot are included into this one
+	p.wait()
+	snap, err := p.rotate(qs)
+	if err != nil {
+		return err
+	}
+	if err = p.writeSnapshot(snap, p.gen); err != nil {
+		p.dirty = true
+	}
+	return err
+}
+
+// checkpointAsync is the same as checkpoint, but it writes the snapshot in the background.
+func (p *persister) checkpointAsync(qs *QuadStore) error {
+	snap, err := p.rotate(qs)
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func(gen uint64) {
+		done <- p.writeSnapshot(snap, gen)
+	}(p.gen)
+	p.pending = done
+	return nil
+}
+
+// wait waits for the snapshot written in the background. If it failed, the changes are written by the next one.
+func (p *persister) wait() error {
+	if p.pending == nil {
+		return nil
+	}
+	return p.finish(<-p.pending)
+}
+
+// finish records a result of the snapshot written in the background.
+func (p *persister) finish(err error) error {
+	p.pending = nil
+	if err != nil {
+		p.dirty = true
+		clog.Errorf("memstore: cannot write snapshot: %v", err)
+	}
+	return err
+}
+
+// rotate switches to the next journal and returns a snapshot of the store state before it.
+func (p *persister) rotate(qs *QuadStore) (*Snapshot, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if err := p.journal.Sync(); err != nil {
+		return nil, p.fail(err)
+	}
+	if err := p.journal.Close(); err != nil {
+		return nil, p.fail(err)
+	}
+	// changes after the snapshot will go to the next journal
+	p.gen++
+	j, err := os.OpenFile(p.path(journalName(p.gen)), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, p.fail(err)
+	}
+	p.journal = j
+	now := time.Now()
+	p.dirty, p.unsynced = false, false
+	p.lastSnap, p.lastSync = now, now
+	return qs.Snapshot(), nil
+}
+
+// writeSnapshot writes the snapshot to the disk and removes journals that are included in it.
+// It doesn't modify the persister, thus it can run concurrently with writes to the current journal.
+func (p *persister) writeSnapshot(snap *Snapshot, gen uint64) error {
+	defer snap.Close()
+	tmp := p.path(snapshotFile + ".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = snap.writeSnapshot(f, gen)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(tmp, p.path(snapshotFile))
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	syncDir(p.dir)
+	gens, err := p.journals()
+	if err != nil {
+		return err
+	}
+	for _, g := range gens {
+		if g < gen {
+			if err = os.Remove(p.path(journalName(g))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncDir flushes directory entries to the disk. Not all platforms support it, so errors are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+}
+
+// fail records an error that makes the journal unusable. All following writes will return it.
+func (p *persister) fail(err error) error {
+	if p.err == nil {
+		p.err = err
+		clog.Errorf("memstore: persistence failed: %v", err)
+	}
+	return err
+}
+
+// logOp appends the operation to the journal before it's applied.
+func (qs *QuadStore) logOp(op interface{}) error {
+	p := qs.persist
+	if p == nil {
+		return nil
+	} else if p.err != nil {
+		return p.err
+	}
+	p.buf.Reset()
+	if err := encodeOp(&p.buf, op); err != nil {
+		return err
+	}
+	data := p.buf.Bytes()
+	rec := make([]byte, binary.MaxVarintLen64+4, binary.MaxVarintLen64+4+len(data))
+	n := binary.PutUvarint(rec, uint64(len(data)))
+	binary.LittleEndian.PutUint32(rec[n:], crc32.ChecksumIEEE(data))
+	rec = append(rec[:n+4], data...)
+	if _, err := p.journal.Write(rec); err != nil {
+		return p.fail(err)
+	}
+	p.dirty, p.unsynced = true, true
+	return nil
+}
+
+// logWrite appends the operation to the journal for write methods that cannot return an error.
+// Any error makes the persistence fail, thus it's returned by all following writes, Checkpoint, Close and Err.
+// It returns false if the operation must not be applied.
+func (qs *QuadStore) logWrite(op interface{}) bool {
+	if err := qs.logOp(op); err != nil {
+		qs.persist.fail(err)
+		return false
+	}
+	return true
+}
+
+// afterLogWrite is an equivalent of afterWrite for methods that cannot return an error.
+func (qs *QuadStore) afterLogWrite() {
+	if err := qs.afterWrite(); err != nil {
+		qs.persist.fail(err)
+	}
+}
+
+// Err returns an error that made the persistence of the quad store fail.
+// Such store rejects all writes, including the ones made with methods that cannot return an error, like AddQuad.
+func (qs *QuadStore) Err() error {
+	if qs.persist == nil {
+		return nil
+	}
+	return qs.persist.err
+}
+
+// afterWrite flushes the journal and writes a snapshot according to the persistence options.
+func (qs *QuadStore) afterWrite() error {
+	p := qs.persist
+	if p == nil {
+		return nil
+	} else if p.err != nil {
+		return p.err
+	}
+	if p.pending != nil {
+		select {
+		case err := <-p.pending:
+			p.finish(err)
+		default:
+		}
+	}
+	now := time.Now()
+	if p.pending == nil && p.interval > 0 && now.Sub(p.lastSnap) >= p.interval {
+		return p.checkpointAsync(qs)
+	}
+	if p.unsynced && (p.fsync == FsyncAlways || (p.fsync == FsyncSecond && now.Sub(p.lastSync) >= time.Second)) {
+		if err := p.journal.Sync(); err != nil {
+			return p.fail(err)
+		}
+		p.unsynced, p.lastSync = false, now
+	}
+	return nil
+}
+
+func (qs *QuadStore) closePersist() error {
+	p := qs.persist
+	p.wait()
+	var err error
+	if p.dirty && p.err == nil {
+		err = p.checkpoint(qs)
+	} else if p.unsynced && p.err == nil {
+		err = p.journal.Sync()
+	}
+	if err2 := p.journal.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = p.err
+	}
+	qs.persist = nil
+	return err
+}
+
+// Journal operations. Each public write method of the quad store is recorded as a single operation.
+type (
+	opAddQuads    []quad.Quad
+	opAddValue    struct{ Value quad.Value }
+	opAddBNode    struct{}
+	opDelete      int64
+	opApplyDeltas struct {
+		Deltas []graph.Delta
+		Opts   graph.IgnoreOpts
+	}
+)
+
+const (
+	opTypeAddQuads = iota + 1
+	opTypeAddValue
+	opTypeAddBNode
+	opTypeDelete
+	opTypeApplyDeltas
+)
+
+func (qs *QuadStore) applyOp(op interface{}) {
+	switch op := op.(type) {
+	case opAddQuads:
+		for _, q := range op {
+			qs.addQuad(q)
+		}
+	case opAddValue:
+		qs.addValue(op.Value)
+	case opAddBNode:
+		qs.addBNode()
+	case opDelete:
+		qs.delete(int64(op))
+	case opApplyDeltas:
+		// the operation is replayed exactly as it was executed, including failures
+		_ = qs.applyDeltas(op.Deltas, op.Opts)
+	}
+}
+
+func encodeOp(w io.Writer, op interface{}) error {
+	e := newEncoder(w)
+	switch op := op.(type) {
+	case opAddQuads:
+		e.byte(opTypeAddQuads)
+		e.uvarint(uint64(len(op)))
+		for _, q := range op {
+			e.quad(q)
+		}
+	case opAddValue:
+		e.byte(opTypeAddValue)
+		e.value(op.Value)
+	case opAddBNode:
+		e.byte(opTypeAddBNode)
+	case opDelete:
+		e.byte(opTypeDelete)
+		e.varint(int64(op))
+	case opApplyDeltas:
+		e.byte(opTypeApplyDeltas)
+		e.bool(op.Opts.IgnoreDup)
+		e.bool(op.Opts.IgnoreMissing)
+		e.uvarint(uint64(len(op.Deltas)))
+		for _, d := range op.Deltas {
+			e.varint(int64(d.Action))
+			e.quad(d.Quad)
+		}
+	default:
+		return fmt.Errorf("unsupported journal operation: %T", op)
+	}
+	return e.flush()
+}
+
+func decodeOp(data []byte) (interface{}, error) {
+	d := newDecoder(bytes.NewReader(data))
+	var op interface{}
+	switch typ := d.byte(); typ {
+	case opTypeAddQuads:
+		n := d.uvarint()
+		var quads opAddQuads
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			quads = append(quads, d.quad())
+		}
+		op = quads
+	case opTypeAddValue:
+		op = opAddValue{Value: d.value()}
+	case opTypeAddBNode:
+		op = opAddBNode{}
+	case opTypeDelete:
+		op = opDelete(d.varint())
+	case opTypeApplyDeltas:
+		var o opApplyDeltas
+		o.Opts.IgnoreDup = d.bool()
+		o.Opts.IgnoreMissing = d.bool()
+		n := d.uvarint()
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			act := graph.Procedure(d.varint())
+			o.Deltas = append(o.Deltas, graph.Delta{Action: act, Quad: d.quad()})
+		}
+		op = o
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("unknown journal operation: %d", typ)
+		}
+	}
+	return op, d.err
+}
+
+// Snapshot layout: magic, version, journal generation, ID counters, primitives
+// ordered by id, quad direction index trees and a CRC32 of all preceding bytes.
+const (
+	primBNode = iota
+	primValue
+	primQuad
+)
+
+func (s *pstate) writeSnapshot(w io.Writer, gen uint64) error {
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(w)
+	e := newEncoder(io.MultiWriter(bw, crc))
+	e.bytes([]byte(snapshotMagic))
+	e.uvarint(snapshotVersion)
+	e.uvarint(gen)
+	e.varint(s.last)
+	e.varint(s.horizon)
+	e.uvarint(uint64(s.prim.Len()))
+	for it := s.prim.SeekFirst(); ; {
+		id, p, ok := it.Next()
+		if !ok {
+			break
+		}
+		refs, _ := s.refs.Get(id)
+		e.varint(id)
+		e.uvarint(uint64(refs))
+		switch {
+		case !p.Quad.Zero():
+			e.byte(primQuad)
+			for dir := quad.Subject; dir <= quad.Label; dir++ {
+				e.varint(p.Quad.Dir(dir))
+			}
+		case p.Value != nil:
+			e.byte(primValue)
+			e.value(p.Value)
+		default:
+			e.byte(primBNode)
+		}
+	}
+	for i := range s.index {
+		e.uvarint(uint64(s.index[i].Len()))
+		for it := s.index[i].SeekFirst(); ; {
+			id, t, ok := it.Next()
+			if !ok {
+				break
+			}
+			e.varint(id)
+			e.uvarint(uint64(t.Len()))
+			var prev int64
+			for qit := t.SeekFirst(); ; {
+				k, _, ok := qit.Next()
+				if !ok {
+					break
+				}
+				e.uvarint(uint64(k - prev))
+				prev = k
+			}
+		}
+	}
+	if err := e.flush(); err != nil {
+		return err
+	}
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], crc.Sum32())
+	if _, err := bw.Write(sum[:]); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// readSnapshot loads an empty quad store from a snapshot and returns a journal generation stored in it.
+func (qs *QuadStore) readSnapshot(r io.Reader) (uint64, error) {
+	br := bufio.NewReader(r)
+	hr := &hashReader{r: br, h: crc32.NewIEEE()}
+	d := newDecoder(hr)
+	if magic := d.bytes(); d.err == nil && string(magic) != snapshotMagic {
+		return 0, ErrCorruptSnapshot
+	}
+	if vers := d.uvarint(); d.err == nil && vers != snapshotVersion {
+		return 0, fmt.Errorf("memstore: unsupported snapshot version: %d", vers)
+	}
+	gen := d.uvarint()
+	qs.last = d.varint()
+	qs.horizon = d.varint()
+	n := d.uvarint()
+	if d.err != nil {
+		return 0, d.err
+	}
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		p := &Primitive{ID: d.varint()}
+		p.refs = int(d.uvarint())
+		switch d.byte() {
+		case primQuad:
+			for dir := quad.Subject; dir <= quad.Label; dir++ {
+				p.Quad.SetDir(dir, d.varint())
+			}
+			qs.quads[p.Quad] = p.ID
+		case primValue:
+			p.Value = d.value()
+			if p.Value != nil {
+				qs.vals[p.Value.String()] = p.ID
+			}
+		case primBNode:
+		default:
+			if d.err == nil {
+				d.err = ErrCorruptSnapshot
+			}
+		}
+		qs.appendPrimitive(p)
+	}
+	for dir := quad.Subject; dir <= quad.Label; dir++ {
+		trees := d.uvarint()
+		for j := uint64(0); j < trees && d.err == nil; j++ {
+			id := d.varint()
+			cnt := d.uvarint()
+			var k int64
+			for l := uint64(0); l < cnt && d.err == nil; l++ {
+				k += int64(d.uvarint())
+				p, ok := qs.prim[k]
+				if !ok {
+					d.err = ErrCorruptSnapshot
+					break
+				}
+				qs.index.Tree(dir, id).Set(k, p)
+			}
+		}
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	sum := hr.h.Sum32()
+	var exp [4]byte
+	if _, err := io.ReadFull(br, exp[:]); err != nil {
+		return 0, err
+	} else if binary.LittleEndian.Uint32(exp[:]) != sum {
+		return 0, ErrCorruptSnapshot
+	}
+	return gen, nil
+}
+
+// hashReader computes a checksum of all bytes consumed from the reader.
+type hashReader struct {
+	r *bufio.Reader
+	h hash.Hash32
+	b [1]byte
+}
+
+func (r *hashReader) ReadByte() (byte, error) {
+	c, err := r.r.ReadByte()
+	if err == nil {
+		r.b[0] = c
+		r.h.Write(r.b[:])
+	}
+	return c, err
+}
+
+func (r *hashReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.h.Write(p[:n])
+	return n, err
+}
+
+// encoder writes primitive types used in snapshots and the journal. It records the first error.
+type encoder struct {
+	w   io.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func newEncoder(w io.Writer) *encoder {
+	return &encoder{w: w}
+}
+
+func (e *encoder) write(p []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(p)
+	}
+}
+
+func (e *encoder) byte(v byte) {
+	e.buf[0] = v
+	e.write(e.buf[:1])
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.byte(1)
+	} else {
+		e.byte(0)
+	}
+}
+
+func (e *encoder) uvarint(v uint64) {
+	n := binary.PutUvarint(e.buf[:], v)
+	e.write(e.buf[:n])
+}
+
+func (e *encoder) varint(v int64) {
+	n := binary.PutVarint(e.buf[:], v)
+	e.write(e.buf[:n])
+}
+
+func (e *encoder) bytes(p []byte) {
+	e.uvarint(uint64(len(p)))
+	e.write(p)
+}
+
+// value writes a length-prefixed value; zero length is reserved for nil values.
+func (e *encoder) value(v quad.Value) {
+	if v == nil {
+		e.uvarint(0)
+		return
+	}
+	data, err := pquads.MarshalValue(v)
+	if err != nil {
+		if e.err == nil {
+			e.err = err
+		}
+		return
+	}
+	e.uvarint(uint64(len(data)) + 1)
+	e.write(data)
+}
+
+func (e *encoder) quad(q quad.Quad) {
+	for dir := quad.Subject; dir <= quad.Label; dir++ {
+		e.value(q.Get(dir))
+	}
+}
+
+func (e *encoder) flush() error {
+	return e.err
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// decoder reads values written by encoder. It records the first error and returns zero values after it.
+type decoder struct {
+	r   byteReader
+	err error
+}
+
+func newDecoder(r byteReader) *decoder {
+	return &decoder{r: r}
+}
+
+func (d *decoder) setErr(err error) {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	v, err := d.r.ReadByte()
+	if err != nil {
+		d.setErr(err)
+	}
+	return v
+}
+
+func (d *decoder) bool() bool {
+	return d.byte() != 0
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		d.setErr(err)
+	}
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	if err != nil {
+		d.setErr(err)
+	}
+	return v
+}
+
+func (d *decoder) read(n uint64) []byte {
+	if d.err != nil {
+		return nil
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(d.r, p); err != nil {
+		d.setErr(err)
+		return nil
+	}
+	return p
+}
+
+func (d *decoder) bytes() []byte {
+	return d.read(d.uvarint())
+}
+
+func (d *decoder) value() quad.Value {
+	n := d.uvarint()
+	if n == 0 || d.err != nil {
+		return nil
+	}
+	data := d.read(n - 1)
+	if d.err != nil {
+		return nil
+	}
+	v, err := pquads.UnmarshalValue(data)
+	if err != nil {
+		d.setErr(err)
+		return nil
+	}
+	return v
+}
+
+func (d *decoder) quad() quad.Quad {
+	var q quad.Quad
+	for dir := quad.Subject; dir <= quad.Label; dir++ {
+		q.Set(dir, d.value())
+	}
+	return q
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memstore
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/quad"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/graphtest"
+)
+
+func TestPersistent(t *testing.T) {
+	graphtest.TestAll(t, func(t testing.TB) (graph.QuadStore, graph.Options) {
+		qs, err := Open(t.TempDir(), nil)
+		require.NoError(t, err)
+		return qs, nil
+	}, &graphtest.Config{
+		AlwaysRunIntegration: true,
+	})
+}
+
+type storeState struct {
+	Last  int64
+	Prims map[int64]Primitive
+	Index [4]map[int64][]int64
+}
+
+func dumpState(t testing.TB, qs *QuadStore) storeState {
+	st := storeState{Last: qs.last, Prims: make(map[int64]Primitive)}
+	for id, p := range qs.prim {
+		st.Prims[id] = *p
+	}
+	for i, m := range qs.index.index {
+		st.Index[i] = make(map[int64][]int64)
+		for id, tree := range m {
+			it, err := tree.SeekFirst()
+			if err == io.EOF {
+				continue
+			}
+			require.NoError(t, err)
+			for {
+				k, _, err := it.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				st.Index[i][id] = append(st.Index[i][id], k)
+			}
+		}
+	}
+	return st
+}
+
+// crash closes the journal without writing a snapshot.
+func crash(qs *QuadStore) {
+	qs.persist.journal.Close()
+	qs.persist = nil
+}
+
+func writePersistData(t testing.TB, qs *QuadStore) {
+	quads := graphtest.MakeQuadSet()
+	var deltas []graph.Delta
+	for _, q := range quads {
+		deltas = append(deltas, graph.Delta{Quad: q, Action: graph.Add})
+	}
+	require.NoError(t, qs.ApplyDeltas(deltas, graph.IgnoreOpts{}))
+	require.NoError(t, qs.ApplyDeltas([]graph.Delta{
+		{Quad: quads[0], Action: graph.Delete},
+		{Quad: quad.MakeIRI("x", "y", "z", ""), Action: graph.Add},
+	}, graph.IgnoreOpts{}))
+	// failed transactions are journaled as well
+	require.Error(t, qs.ApplyDeltas([]graph.Delta{{Quad: quads[1], Action: graph.Add}}, graph.IgnoreOpts{}))
+
+	id := qs.AddBNode()
+	qs.AddQuad(quad.Quad{
+		Subject:   quad.BNode(internalBNodePrefix + "1000"),
+		Predicate: quad.IRI("bnode"),
+		Object:    qs.lookupVal(id),
+	})
+	qs.AddValue(quad.String("lonely"))
+	_, err := qs.WriteQuads([]quad.Quad{quad.MakeIRI("a", "b", "c", "d")})
+	require.NoError(t, err)
+}
+
+func TestPersistJournal(t *testing.T) {
+	dir := t.TempDir()
+	qs, err := Open(dir, graph.Options{OptFsync: FsyncAlways})
+	require.NoError(t, err)
+	writePersistData(t, qs)
+	exp := dumpState(t, qs)
+	crash(qs)
+
+	qs, err = Open(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, exp, dumpState(t, qs))
+
+	// checkpoint in the middle, and some writes after it
+	require.NoError(t, qs.Checkpoint())
+	id := qs.vals[quad.String("lonely").String()]
+	qs.Delete(id)
+	exp = dumpState(t, qs)
+	crash(qs)
+
+	qs, err = Open(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, exp, dumpState(t, qs))
+	qs.AddValue(quad.String("more"))
+	exp = dumpState(t, qs)
+	require.NoError(t, qs.Close())
+
+	// close writes the snapshot and removes the journal
+	files, err := filepath.Glob(filepath.Join(dir, journalPrefix+"*"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	fi, err := os.Stat(files[0])
+	require.NoError(t, err)
+	require.Equal(t, int64(0), fi.Size())
+
+	qs, err = Open(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, exp, dumpState(t, qs))
+	require.NoError(t, qs.Close())
+}
+
+func TestPersistIncompleteJournal(t *testing.T) {
+	dir := t.TempDir()
+	qs, err := Open(dir, nil)
+	require.NoError(t, err)
+	writePersistData(t, qs)
+	exp := dumpState(t, qs)
+	name := qs.persist.journal.Name()
+	crash(qs)
+
+	// simulate a partial write of the next record
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{20, 1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	qs, err = Open(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, exp, dumpState(t, qs))
+
+	qs.AddQuad(quad.MakeIRI("e", "f", "g", ""))
+	exp = dumpState(t, qs)
+	crash(qs)
+
+	qs, err = Open(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, exp, dumpState(t, qs))
+	require.NoError(t, qs.Close())
+}
+
+func TestPersistCorruptSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	qs, err := Open(dir, nil)
+	require.NoError(t, err)
+	writePersistData(t, qs)
+	require.NoError(t, qs.Close())
+
+	name := filepath.Join(dir, snapshotFile)
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	data[len(data)/2] ^= 0xff
+	require.NoError(t, os.WriteFile(name, data, 0644))
+
+	_, err = Open(dir, nil)
+	require.Error(t, err)
+}
+
+func TestPersistFailedJournal(t *testing.T) {
+	qs, err := Open(t.TempDir(), nil)
+	require.NoError(t, err)
+	qs.AddQuad(quad.MakeIRI("a", "b", "c", ""))
+	require.NoError(t, qs.Err())
+
+	// writes to the journal will fail from now on
+	require.NoError(t, qs.persist.journal.Close())
+
+	id, added := qs.AddQuad(quad.MakeIRI("e", "f", "g", ""))
+	require.Zero(t, id)
+	require.False(t, added)
+	require.Error(t, qs.Err())
+	v, err := qs.ValueOf(quad.IRI("e"))
+	require.NoError(t, err)
+	require.Nil(t, v, "change that is not journaled must not be applied")
+
+	_, err = qs.WriteQuads([]quad.Quad{quad.MakeIRI("x", "y", "z", "")})
+	require.Equal(t, qs.Err(), err)
+	require.Error(t, qs.Close())
+}
+
+func TestPersistBackground(t *testing.T) {
+	dir := t.TempDir()
+	// every write starts a snapshot in the background, unless one is running already
+	qs, err := Open(dir, graph.Options{OptSnapshotInterval: "1ns"})
+	require.NoError(t, err)
+	writePersistData(t, qs)
+	qs.AddQuad(quad.MakeIRI("e", "f", "g", ""))
+	exp := dumpState(t, qs)
+	require.NoError(t, qs.persist.wait())
+	crash(qs)
+
+	qs, err = Open(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, exp, dumpState(t, qs))
+	require.NoError(t, qs.Close())
+
+	// snapshots written one after another
+	qs, err = Open(dir, graph.Options{OptSnapshotInterval: "1ns"})
+	require.NoError(t, err)
+	qs.AddQuad(quad.MakeIRI("x", "y", "z", ""))
+	require.NoError(t, qs.persist.wait())
+	qs.AddQuad(quad.MakeIRI("z", "y", "x", ""))
+	require.NoError(t, qs.persist.wait())
+	exp = dumpState(t, qs)
+	require.NoError(t, qs.Close())
+
+	qs, err = Open(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, exp, dumpState(t, qs))
+	require.NoError(t, qs.Close())
+}
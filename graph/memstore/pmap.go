@@ -0,0 +1,256 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memstore
+
+// owner is a token that allows modifying tree nodes in place.
+//
+// Nodes created with one token are copied before being modified with a different one,
+// thus a tree can be shared between stores by giving each of them a new token.
+type owner struct {
+	_ byte // pointers to distinct zero-size values may be equal
+}
+
+type pnode[K, V any] struct {
+	key         K
+	val         V
+	left, right *pnode[K, V]
+	height      int32
+	own         *owner
+}
+
+func (n *pnode[K, V]) mutable(o *owner) *pnode[K, V] {
+	if n.own == o {
+		return n
+	}
+	c := *n
+	c.own = o
+	return &c
+}
+
+func nodeHeight[K, V any](n *pnode[K, V]) int32 {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *pnode[K, V]) fix() {
+	n.height = max(nodeHeight(n.left), nodeHeight(n.right)) + 1
+}
+
+// pmap is a persistent ordered map based on an AVL tree.
+//
+// Copies of the map share all nodes. Nodes are modified in place only if they were
+// created with the same owner token, thus each copy must be modified with a distinct token.
+type pmap[K, V any] struct {
+	cmp  func(a, b K) int
+	root *pnode[K, V]
+	size int
+}
+
+func newPMap[K, V any](cmp func(a, b K) int) pmap[K, V] {
+	return pmap[K, V]{cmp: cmp}
+}
+
+// Len returns the number of elements in the map.
+func (m *pmap[K, V]) Len() int {
+	return m.size
+}
+
+// Get returns a value for the key.
+func (m *pmap[K, V]) Get(k K) (V, bool) {
+	for n := m.root; n != nil; {
+		switch c := m.cmp(k, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.val, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Set sets a value for the key. It returns false if the key existed already.
+func (m *pmap[K, V]) Set(o *owner, k K, v V) bool {
+	var added bool
+	m.root, added = m.insert(o, m.root, k, v)
+	if added {
+		m.size++
+	}
+	return added
+}
+
+// Delete removes the key from the map. It returns false if the key does not exist.
+func (m *pmap[K, V]) Delete(o *owner, k K) bool {
+	var ok bool
+	m.root, ok = m.remove(o, m.root, k)
+	if ok {
+		m.size--
+	}
+	return ok
+}
+
+func (m *pmap[K, V]) insert(o *owner, n *pnode[K, V], k K, v V) (*pnode[K, V], bool) {
+	if n == nil {
+		return &pnode[K, V]{key: k, val: v, height: 1, own: o}, true
+	}
+	c := m.cmp(k, n.key)
+	n = n.mutable(o)
+	var added bool
+	switch {
+	case c < 0:
+		n.left, added = m.insert(o, n.left, k, v)
+	case c > 0:
+		n.right, added = m.insert(o, n.right, k, v)
+	default:
+		n.val = v
+		return n, false
+	}
+	return rebalance(o, n), added
+}
+
+func (m *pmap[K, V]) remove(o *owner, n *pnode[K, V], k K) (*pnode[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+	c := m.cmp(k, n.key)
+	switch {
+	case c < 0:
+		l, ok := m.remove(o, n.left, k)
+		if !ok {
+			return n, false
+		}
+		n = n.mutable(o)
+		n.left = l
+	case c > 0:
+		r, ok := m.remove(o, n.right, k)
+		if !ok {
+			return n, false
+		}
+		n = n.mutable(o)
+		n.right = r
+	case n.left == nil:
+		return n.right, true
+	case n.right == nil:
+		return n.left, true
+	default:
+		r, first := removeFirst(o, n.right)
+		n = n.mutable(o)
+		n.key, n.val = first.key, first.val
+		n.right = r
+	}
+	return rebalance(o, n), true
+}
+
+// removeFirst removes the leftmost node from the subtree. It returns the new subtree and the removed node.
+func removeFirst[K, V any](o *owner, n *pnode[K, V]) (*pnode[K, V], *pnode[K, V]) {
+	if n.left == nil {
+		return n.right, n
+	}
+	n = n.mutable(o)
+	l, first := removeFirst(o, n.left)
+	n.left = l
+	return rebalance(o, n), first
+}
+
+// rebalance restores the balance of the node. The node must be owned by o.
+func rebalance[K, V any](o *owner, n *pnode[K, V]) *pnode[K, V] {
+	n.fix()
+	switch b := nodeHeight(n.left) - nodeHeight(n.right); {
+	case b > 1:
+		if nodeHeight(n.left.left) < nodeHeight(n.left.right) {
+			n.left = rotateLeft(o, n.left.mutable(o))
+		}
+		return rotateRight(o, n)
+	case b < -1:
+		if nodeHeight(n.right.right) < nodeHeight(n.right.left) {
+			n.right = rotateRight(o, n.right.mutable(o))
+		}
+		return rotateLeft(o, n)
+	}
+	return n
+}
+
+func rotateRight[K, V any](o *owner, n *pnode[K, V]) *pnode[K, V] {
+	l := n.left.mutable(o)
+	n.left = l.right
+	n.fix()
+	l.right = n
+	l.fix()
+	return l
+}
+
+func rotateLeft[K, V any](o *owner, n *pnode[K, V]) *pnode[K, V] {
+	r := n.right.mutable(o)
+	n.right = r.left
+	n.fix()
+	r.left = n
+	r.fix()
+	return r
+}
+
+// SeekFirst returns an iterator positioned before the first element of the map.
+//
+// The map must not be modified in place while the iterator is in use.
+func (m *pmap[K, V]) SeekFirst() *pmapIter[K, V] {
+	it := &pmapIter[K, V]{}
+	it.pushLeft(m.root)
+	return it
+}
+
+// Seek returns an iterator positioned before the first element that is greater or equal to k.
+//
+// The map must not be modified in place while the iterator is in use.
+func (m *pmap[K, V]) Seek(k K) *pmapIter[K, V] {
+	it := &pmapIter[K, V]{}
+	for n := m.root; n != nil; {
+		if m.cmp(k, n.key) <= 0 {
+			it.stack = append(it.stack, n)
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return it
+}
+
+// pmapIter iterates over elements of pmap in ascending key order.
+type pmapIter[K, V any] struct {
+	stack []*pnode[K, V]
+}
+
+func (it *pmapIter[K, V]) pushLeft(n *pnode[K, V]) {
+	for ; n != nil; n = n.left {
+		it.stack = append(it.stack, n)
+	}
+}
+
+// Next returns the next element of the map. It returns false if there are no more elements.
+func (it *pmapIter[K, V]) Next() (K, V, bool) {
+	if len(it.stack) == 0 {
+		var (
+			k K
+			v V
+		)
+		return k, v, false
+	}
+	n := it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	it.pushLeft(n.right)
+	return n.key, n.val, true
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memstore
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func pmapContent(m pmap[int64, int]) map[int64]int {
+	out := make(map[int64]int)
+	for it := m.SeekFirst(); ; {
+		k, v, ok := it.Next()
+		if !ok {
+			break
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func checkPMap(t testing.TB, m pmap[int64, int], exp map[int64]int) {
+	keys := make([]int64, 0, len(exp))
+	for k := range exp {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	require.Equal(t, len(exp), m.Len())
+	var got []int64
+	for it := m.SeekFirst(); ; {
+		k, v, ok := it.Next()
+		if !ok {
+			break
+		}
+		require.Equal(t, exp[k], v)
+		got = append(got, k)
+	}
+	if len(keys) == 0 {
+		keys = nil
+	}
+	require.Equal(t, keys, got)
+
+	var check func(n *pnode[int64, int]) int32
+	check = func(n *pnode[int64, int]) int32 {
+		if n == nil {
+			return 0
+		}
+		l, r := check(n.left), check(n.right)
+		require.True(t, l-r <= 1 && r-l <= 1, "unbalanced tree")
+		require.Equal(t, max(l, r)+1, n.height)
+		return n.height
+	}
+	check(m.root)
+}
+
+func TestPMap(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	m := newPMap[int64, int](cmpID)
+	exp := make(map[int64]int)
+	o := &owner{}
+
+	type version struct {
+		m   pmap[int64, int]
+		exp map[int64]int
+	}
+	var versions []version
+	for i := 0; i < 5000; i++ {
+		k := rnd.Int63n(500)
+		if rnd.Intn(3) == 0 {
+			_, ok := exp[k]
+			require.Equal(t, ok, m.Delete(o, k))
+			delete(exp, k)
+		} else {
+			_, ok := exp[k]
+			require.Equal(t, !ok, m.Set(o, k, i))
+			exp[k] = i
+		}
+		if i%500 == 0 {
+			checkPMap(t, m, exp)
+			// keep the version and continue with a new owner
+			versions = append(versions, version{m: m, exp: pmapContent(m)})
+			o = &owner{}
+		}
+	}
+	checkPMap(t, m, exp)
+	for _, v := range versions {
+		checkPMap(t, v.m, v.exp)
+	}
+
+	for i := 0; i < 20; i++ {
+		k := rnd.Int63n(520)
+		it := m.Seek(k)
+		nk, _, ok := it.Next()
+		var (
+			ek    int64
+			found bool
+		)
+		for j := k; j < 520; j++ {
+			if _, ok := exp[j]; ok {
+				ek, found = j, true
+				break
+			}
+		}
+		require.Equal(t, found, ok)
+		if found {
+			require.Equal(t, ek, nk)
+		}
+	}
+}
+
+func BenchmarkPMapSet(b *testing.B) {
+	m := newPMap[int64, int](cmpID)
+	o := &owner{}
+	for i := 0; i < b.N; i++ {
+		m.Set(o, int64(i), i)
+	}
+}
+
+func BenchmarkPMapSetShared(b *testing.B) {
+	m := newPMap[int64, int](cmpID)
+	for i := 0; i < b.N; i++ {
+		// each write copies the path to the root
+		m.Set(&owner{}, int64(i), i)
+	}
+}
+
+func BenchmarkPMapGet(b *testing.B) {
+	m := newPMap[int64, int](cmpID)
+	o := &owner{}
+	for i := 0; i < 1e5; i++ {
+		m.Set(o, int64(i), i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(int64(i % 1e5))
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/cayleygraph/cayley/graph"
 	"github.com/cayleygraph/cayley/graph/iterator"
@@ -30,7 +31,13 @@ const QuadStoreType = "memstore"
 
 func init() {
 	graph.RegisterQuadStore(QuadStoreType, graph.QuadStoreRegistration{
-		NewFunc: func(string, graph.Options) (graph.QuadStore, error) {
+		NewFunc: func(_ string, opts graph.Options) (graph.QuadStore, error) {
+			path, err := opts.StringKey(OptPersistPath, "")
+			if err != nil {
+				return nil, err
+			} else if path != "" {
+				return Open(path, opts)
+			}
 			return newQuadStore(), nil
 		},
 		UpgradeFunc:  nil,
@@ -141,7 +148,10 @@ type QuadStore struct {
 	all     []*Primitive // might not be sorted by id
 	reading bool         // someone else might be reading "all" slice - next insert/delete should clone it
 	index   QuadDirectionIndex
-	horizon int64 // used only to assign ids to tx
+	horizon int64        // used only to assign ids to tx
+	shared  *pstate      // persistent copy of indexes for snapshots and forks; nil if none of them is alive
+	live    atomic.Int64 // number of snapshots and forks of the store that are alive
+	persist *persister
 	// vip_index map[string]map[int64]map[string]map[int64]*b.Tree
 }
 
@@ -168,6 +178,14 @@ func (qs *QuadStore) cloneAll() []*Primitive {
 	return qs.all
 }
 
+// addRef increments the reference counter of the primitive.
+func (qs *QuadStore) addRef(p *Primitive) {
+	p.refs++
+	if s := qs.persistent(); s != nil {
+		s.setRefs(p.ID, p.refs)
+	}
+}
+
 func (qs *QuadStore) addPrimitive(p *Primitive) int64 {
 	qs.last++
 	id := qs.last
@@ -186,6 +204,9 @@ func (qs *QuadStore) appendPrimitive(p *Primitive) {
 		qs.all = append(qs.all[:n:n], p) // reallocate slice
 		qs.reading = false               // this is a new slice
 	}
+	if s := qs.persistent(); s != nil {
+		s.putPrim(p, p.refs)
+	}
 }
 
 const internalBNodePrefix = "memnode"
@@ -200,7 +221,7 @@ func (qs *QuadStore) resolveVal(v quad.Value, add bool) (int64, bool) {
 		if err == nil && id != 0 {
 			if p, ok := qs.prim[id]; ok || !add {
 				if add {
-					p.refs++
+					qs.addRef(p)
 				}
 				return id, ok
 			}
@@ -211,12 +232,15 @@ func (qs *QuadStore) resolveVal(v quad.Value, add bool) (int64, bool) {
 	vs := v.String()
 	if id, exists := qs.vals[vs]; exists || !add {
 		if exists && add {
-			qs.prim[id].refs++
+			qs.addRef(qs.prim[id])
 		}
 		return id, exists
 	}
 	id := qs.addPrimitive(&Primitive{Value: v})
 	qs.vals[vs] = id
+	if s := qs.persistent(); s != nil {
+		s.putValue(vs, id)
+	}
 	return id, true
 }
 
@@ -258,13 +282,35 @@ func (qs *QuadStore) lookupQuadDirs(p internalQuad) quad.Quad {
 }
 
 // AddNode adds a blank node (with no value) to quad store. It returns an id of the node.
+//
+// If the store is persistent and the change cannot be written to the journal, it's not applied
+// and zero is returned. See Err.
 func (qs *QuadStore) AddBNode() int64 {
+	if !qs.logWrite(opAddBNode{}) {
+		return 0
+	}
+	defer qs.afterLogWrite()
+	return qs.addBNode()
+}
+
+func (qs *QuadStore) addBNode() int64 {
 	return qs.addPrimitive(&Primitive{})
 }
 
 // AddNode adds a value to quad store. It returns an id of the value.
 // False is returned as a second parameter if value exists already.
+//
+// If the store is persistent and the change cannot be written to the journal, it's not applied
+// and zero id is returned. See Err.
 func (qs *QuadStore) AddValue(v quad.Value) (int64, bool) {
+	if !qs.logWrite(opAddValue{Value: v}) {
+		return 0, false
+	}
+	defer qs.afterLogWrite()
+	return qs.addValue(v)
+}
+
+func (qs *QuadStore) addValue(v quad.Value) (int64, bool) {
 	id, exists := qs.resolveVal(v, true)
 	return id, !exists
 }
@@ -283,7 +329,18 @@ func (qs *QuadStore) indexesForQuad(q internalQuad) []*Tree {
 
 // AddQuad adds a quad to quad store. It returns an id of the quad.
 // False is returned as a second parameter if quad exists already.
+//
+// If the store is persistent and the change cannot be written to the journal, it's not applied
+// and zero id is returned. See Err.
 func (qs *QuadStore) AddQuad(q quad.Quad) (int64, bool) {
+	if !qs.logWrite(opAddQuads{q}) {
+		return 0, false
+	}
+	defer qs.afterLogWrite()
+	return qs.addQuad(q)
+}
+
+func (qs *QuadStore) addQuad(q quad.Quad) (int64, bool) {
 	p, _ := qs.resolveQuad(q, false)
 	if id := qs.quads[p]; id != 0 {
 		return id, false
@@ -295,6 +352,9 @@ func (qs *QuadStore) AddQuad(q quad.Quad) (int64, bool) {
 	for _, t := range qs.indexesForQuad(p) {
 		t.Set(id, pr)
 	}
+	if s := qs.persistent(); s != nil {
+		s.putQuad(pr)
+	}
 	// TODO(barakmich): Add VIP indexing
 	return id, true
 }
@@ -303,16 +363,19 @@ func (qs *QuadStore) AddQuad(q quad.Quad) (int64, bool) {
 //
 // Deprecated: use AddQuad instead.
 func (qs *QuadStore) WriteQuad(q quad.Quad) error {
-	qs.AddQuad(q)
-	return nil
+	_, err := qs.WriteQuads([]quad.Quad{q})
+	return err
 }
 
 // WriteQuads implements quad.Writer.
 func (qs *QuadStore) WriteQuads(buf []quad.Quad) (int, error) {
+	if err := qs.logOp(opAddQuads(buf)); err != nil {
+		return 0, err
+	}
 	for _, q := range buf {
-		qs.AddQuad(q)
+		qs.addQuad(q)
 	}
-	return len(buf), nil
+	return len(buf), qs.afterWrite()
 }
 
 func (qs *QuadStore) NewQuadWriter() (quad.WriteCloser, error) {
@@ -324,15 +387,12 @@ type quadWriter struct {
 }
 
 func (w *quadWriter) WriteQuad(q quad.Quad) error {
-	w.qs.AddQuad(q)
-	return nil
+	_, err := w.qs.WriteQuads([]quad.Quad{q})
+	return err
 }
 
 func (w *quadWriter) WriteQuads(buf []quad.Quad) (int, error) {
-	for _, q := range buf {
-		w.qs.AddQuad(q)
-	}
-	return len(buf), nil
+	return w.qs.WriteQuads(buf)
 }
 
 func (w *quadWriter) Close() error {
@@ -350,12 +410,27 @@ func (qs *QuadStore) deleteQuadNodes(q internalQuad) {
 			if p.refs < 0 {
 				panic("remove of deleted node")
 			} else if p.refs == 0 {
-				qs.Delete(id)
+				qs.delete(id)
+			} else if s := qs.persistent(); s != nil {
+				s.setRefs(id, p.refs)
 			}
 		}
 	}
 }
+
+// Delete removes a primitive with a given id from the quad store.
+//
+// If the store is persistent and the change cannot be written to the journal, it's not applied
+// and false is returned. See Err.
 func (qs *QuadStore) Delete(id int64) bool {
+	if !qs.logWrite(opDelete(id)) {
+		return false
+	}
+	defer qs.afterLogWrite()
+	return qs.delete(id)
+}
+
+func (qs *QuadStore) delete(id int64) bool {
 	p := qs.prim[id]
 	if p == nil {
 		return false
@@ -389,6 +464,9 @@ func (qs *QuadStore) Delete(id int64) bool {
 			qs.reading = false // this is a new slice
 		}
 	}
+	if s := qs.persistent(); s != nil {
+		s.deletePrim(p)
+	}
 	qs.deleteQuadNodes(p.Quad)
 	return true
 }
@@ -402,36 +480,54 @@ func (qs *QuadStore) findQuad(q quad.Quad) (int64, internalQuad, bool) {
 	return id, p, id != 0
 }
 
-func (qs *QuadStore) ApplyDeltas(deltas []graph.Delta, ignoreOpts graph.IgnoreOpts) error {
-	// Precheck the whole transaction (if required)
-	if !ignoreOpts.IgnoreDup || !ignoreOpts.IgnoreMissing {
-		for _, d := range deltas {
-			switch d.Action {
-			case graph.Add:
-				if !ignoreOpts.IgnoreDup {
-					if _, _, ok := qs.findQuad(d.Quad); ok {
-						return &graph.DeltaError{Delta: d, Err: graph.ErrQuadExists}
-					}
-				}
-			case graph.Delete:
-				if !ignoreOpts.IgnoreMissing {
-					if _, _, ok := qs.findQuad(d.Quad); !ok {
-						return &graph.DeltaError{Delta: d, Err: graph.ErrQuadNotExist}
-					}
-				}
-			default:
-				return &graph.DeltaError{Delta: d, Err: graph.ErrInvalidAction}
+func (qs *QuadStore) hasQuad(q quad.Quad) bool {
+	_, _, ok := qs.findQuad(q)
+	return ok
+}
+
+// checkDeltas prechecks the whole transaction (if required).
+func checkDeltas(deltas []graph.Delta, ignoreOpts graph.IgnoreOpts, exists func(q quad.Quad) bool) error {
+	if ignoreOpts.IgnoreDup && ignoreOpts.IgnoreMissing {
+		return nil
+	}
+	for _, d := range deltas {
+		switch d.Action {
+		case graph.Add:
+			if !ignoreOpts.IgnoreDup && exists(d.Quad) {
+				return &graph.DeltaError{Delta: d, Err: graph.ErrQuadExists}
 			}
+		case graph.Delete:
+			if !ignoreOpts.IgnoreMissing && !exists(d.Quad) {
+				return &graph.DeltaError{Delta: d, Err: graph.ErrQuadNotExist}
+			}
+		default:
+			return &graph.DeltaError{Delta: d, Err: graph.ErrInvalidAction}
 		}
 	}
+	return nil
+}
+
+func (qs *QuadStore) ApplyDeltas(deltas []graph.Delta, ignoreOpts graph.IgnoreOpts) error {
+	if err := qs.logOp(opApplyDeltas{Deltas: deltas, Opts: ignoreOpts}); err != nil {
+		return err
+	}
+	if err := qs.applyDeltas(deltas, ignoreOpts); err != nil {
+		return err
+	}
+	return qs.afterWrite()
+}
 
+func (qs *QuadStore) applyDeltas(deltas []graph.Delta, ignoreOpts graph.IgnoreOpts) error {
+	if err := checkDeltas(deltas, ignoreOpts, qs.hasQuad); err != nil {
+		return err
+	}
 	for _, d := range deltas {
 		switch d.Action {
 		case graph.Add:
-			qs.AddQuad(d.Quad)
+			qs.addQuad(d.Quad)
 		case graph.Delete:
 			if id, _, ok := qs.findQuad(d.Quad); ok {
-				qs.Delete(id)
+				qs.delete(id)
 			}
 		default:
 			// TODO: ideally we should rollback it
@@ -561,4 +657,9 @@ func (qs *QuadStore) NodesAllIterator() iterator.Shape {
 	return qs.newAllIterator(true, qs.last)
 }
 
-func (qs *QuadStore) Close() error { return nil }
+func (qs *QuadStore) Close() error {
+	if qs.persist == nil {
+		return nil
+	}
+	return qs.closePersist()
+}
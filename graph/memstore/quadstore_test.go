@@ -264,3 +264,121 @@ func TestTransaction(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, st, st2, "Appended a new quad in a failed transaction")
 }
+
+func TestMemstoreShared(t *testing.T) {
+	graphtest.TestAll(t, func(t testing.TB) (graph.QuadStore, graph.Options) {
+		qs := New()
+		// writes must keep the persistent indexes in sync while the snapshot is alive
+		snap := qs.Snapshot()
+		t.Cleanup(func() { snap.Close() })
+		return qs, nil
+	}, &graphtest.Config{
+		AlwaysRunIntegration: true,
+	})
+}
+
+func TestMemstoreFork(t *testing.T) {
+	graphtest.TestAll(t, func(t testing.TB) (graph.QuadStore, graph.Options) {
+		qs := New()
+		snap := qs.Snapshot()
+		t.Cleanup(func() { snap.Close() })
+		return qs.Fork(), nil
+	}, &graphtest.Config{
+		AlwaysRunIntegration: true,
+	})
+}
+
+func readQuads(t testing.TB, qs graph.QuadStore) []quad.Quad {
+	r := graph.NewQuadStoreReader(qs)
+	defer r.Close()
+	quads, err := quad.ReadAll(r)
+	require.NoError(t, err)
+	sort.Slice(quads, func(i, j int) bool {
+		return quads[i].NQuad() < quads[j].NQuad()
+	})
+	return quads
+}
+
+func TestForkDeltas(t *testing.T) {
+	qs := New(simpleGraph...)
+	exp := readQuads(t, qs)
+
+	snap := qs.Snapshot()
+	fork := qs.Fork()
+	err := fork.ApplyDeltas([]graph.Delta{
+		{Quad: quad.MakeRaw("E", "follows", "F", ""), Action: graph.Delete},
+		{Quad: quad.MakeRaw("A", "follows", "B", ""), Action: graph.Delete},
+		{Quad: quad.MakeRaw("E", "follows", "G", ""), Action: graph.Add},
+		{Quad: quad.MakeRaw("X", "follows", "Y", ""), Action: graph.Add},
+	}, graph.IgnoreOpts{})
+	require.NoError(t, err)
+	// revert some of the changes
+	err = fork.ApplyDeltas([]graph.Delta{
+		{Quad: quad.MakeRaw("X", "follows", "Y", ""), Action: graph.Delete},
+		{Quad: quad.MakeRaw("A", "follows", "B", ""), Action: graph.Add},
+	}, graph.IgnoreOpts{})
+	require.NoError(t, err)
+	forked := readQuads(t, fork)
+
+	require.Equal(t, exp, readQuads(t, qs), "fork changed the parent")
+	require.Equal(t, []graph.Delta{
+		{Quad: quad.MakeRaw("E", "follows", "F", ""), Action: graph.Delete},
+		{Quad: quad.MakeRaw("E", "follows", "G", ""), Action: graph.Add},
+	}, fork.Deltas())
+
+	// changes of the parent are not visible in the fork or the snapshot
+	qs.AddQuad(quad.MakeRaw("Z", "follows", "A", ""))
+	id, _, ok := qs.findQuad(quad.MakeRaw("B", "follows", "F", ""))
+	require.True(t, ok)
+	qs.Delete(id)
+	require.Equal(t, forked, readQuads(t, fork))
+	require.Equal(t, exp, readQuads(t, snap))
+	// but they are visible in new snapshots
+	require.Equal(t, readQuads(t, qs), readQuads(t, qs.Snapshot()))
+
+	// deltas produce the same state when applied to the parent
+	qs2 := snap.Fork()
+	require.NoError(t, qs2.ApplyDeltas(fork.Deltas(), graph.IgnoreOpts{}))
+	require.Equal(t, forked, readQuads(t, qs2))
+
+	st, err := fork.Stats(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(forked)), st.Quads.Value)
+}
+
+func TestSnapshotReadOnly(t *testing.T) {
+	qs := New(simpleGraph...)
+	snap := qs.Snapshot()
+	err := snap.ApplyDeltas([]graph.Delta{
+		{Quad: quad.MakeRaw("X", "follows", "Y", ""), Action: graph.Add},
+	}, graph.IgnoreOpts{})
+	require.Equal(t, ErrReadOnly, err)
+	_, err = snap.WriteQuads([]quad.Quad{quad.MakeRaw("X", "follows", "Y", "")})
+	require.Equal(t, ErrReadOnly, err)
+	_, err = snap.NewQuadWriter()
+	require.Equal(t, ErrReadOnly, err)
+	require.Equal(t, readQuads(t, qs), readQuads(t, snap))
+}
+
+func TestSnapshotRelease(t *testing.T) {
+	qs := New(simpleGraph...)
+	snap := qs.Snapshot()
+	fork := qs.Fork()
+	exp := readQuads(t, snap)
+
+	qs.AddQuad(quad.MakeRaw("X", "follows", "Y", ""))
+	require.NotNil(t, qs.shared, "persistent indexes must be updated while snapshots are alive")
+
+	require.NoError(t, snap.Close())
+	require.NoError(t, snap.Close())
+	qs.AddQuad(quad.MakeRaw("Y", "follows", "Z", ""))
+	require.NotNil(t, qs.shared)
+
+	require.NoError(t, fork.Close())
+	qs.AddQuad(quad.MakeRaw("Z", "follows", "X", ""))
+	require.Nil(t, qs.shared, "persistent indexes must be dropped when no snapshots are alive")
+
+	// closed snapshots are still readable, and new ones see all changes
+	require.Equal(t, exp, readQuads(t, snap))
+	require.Equal(t, readQuads(t, qs), readQuads(t, qs.Snapshot()))
+}
@@ -0,0 +1,653 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memstore
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/refs"
+	"github.com/cayleygraph/quad"
+)
+
+// ErrReadOnly is returned when trying to modify a snapshot of the quad store.
+var ErrReadOnly = errors.New("memstore: snapshot is read-only")
+
+var (
+	_ graph.QuadStore = (*Snapshot)(nil)
+	_ graph.QuadStore = (*Fork)(nil)
+)
+
+func cmpID(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return +1
+	}
+	return 0
+}
+
+func cmpQuad(a, b internalQuad) int {
+	if c := cmpID(a.S, b.S); c != 0 {
+		return c
+	} else if c = cmpID(a.P, b.P); c != 0 {
+		return c
+	} else if c = cmpID(a.O, b.O); c != 0 {
+		return c
+	}
+	return cmpID(a.L, b.L)
+}
+
+// quadTree is a set of quad primitives ordered by id.
+type quadTree = pmap[int64, *Primitive]
+
+// pstate is a persistent (structurally shared) version of the quad store indexes, used by snapshots and forks.
+//
+// Primitives are shared with the QuadStore, but their reference counters are stored separately,
+// since the quad store modifies them in place.
+type pstate struct {
+	last    int64
+	horizon int64
+	vals    pmap[string, int64]
+	quads   pmap[internalQuad, int64]
+	prim    pmap[int64, *Primitive]
+	refs    pmap[int64, int]
+	index   [4]pmap[int64, quadTree]
+
+	// own allows modifying trees in place; nil means that trees might be shared
+	// with snapshots, forks or iterators and must be copied on write
+	own *owner
+}
+
+func newPState() *pstate {
+	s := &pstate{
+		vals:  newPMap[string, int64](strings.Compare),
+		quads: newPMap[internalQuad, int64](cmpQuad),
+		prim:  newPMap[int64, *Primitive](cmpID),
+		refs:  newPMap[int64, int](cmpID),
+	}
+	for i := range s.index {
+		s.index[i] = newPMap[int64, quadTree](cmpID)
+	}
+	return s
+}
+
+// token returns an owner token for modifying trees of the state.
+func (s *pstate) token() *owner {
+	if s.own == nil {
+		s.own = &owner{}
+	}
+	return s.own
+}
+
+// freeze makes current trees immutable. Next write will copy modified tree nodes.
+func (s *pstate) freeze() {
+	s.own = nil
+}
+
+// clone returns a copy of the state that shares all the trees with it.
+func (s *pstate) clone() pstate {
+	s.freeze()
+	return *s
+}
+
+func (s *pstate) putPrim(p *Primitive, refs int) {
+	o := s.token()
+	s.prim.Set(o, p.ID, p)
+	s.refs.Set(o, p.ID, refs)
+}
+
+func (s *pstate) setRefs(id int64, n int) {
+	s.refs.Set(s.token(), id, n)
+}
+
+func (s *pstate) putValue(key string, id int64) {
+	s.vals.Set(s.token(), key, id)
+}
+
+// putQuad adds the quad primitive to the quad and direction indexes.
+func (s *pstate) putQuad(p *Primitive) {
+	s.quads.Set(s.token(), p.Quad, p.ID)
+	s.updateIndex(p, true)
+}
+
+// deletePrim removes the primitive from all indexes.
+func (s *pstate) deletePrim(p *Primitive) {
+	o := s.token()
+	if p.Value != nil {
+		s.vals.Delete(o, p.Value.String())
+	}
+	if !p.Quad.Zero() {
+		s.updateIndex(p, false)
+		s.quads.Delete(o, p.Quad)
+	}
+	s.prim.Delete(o, p.ID)
+	s.refs.Delete(o, p.ID)
+}
+
+// updateIndex adds or removes the quad primitive from all direction indexes.
+func (s *pstate) updateIndex(p *Primitive, add bool) {
+	o := s.token()
+	for dir := quad.Subject; dir <= quad.Label; dir++ {
+		v := p.Quad.Dir(dir)
+		if v == 0 {
+			continue
+		}
+		m := &s.index[dir-1]
+		t, ok := m.Get(v)
+		if !ok && !add {
+			continue
+		} else if !ok {
+			t = newPMap[int64, *Primitive](cmpID)
+		}
+		if add {
+			t.Set(o, p.ID, p)
+		} else {
+			t.Delete(o, p.ID)
+		}
+		if t.Len() == 0 {
+			m.Delete(o, v)
+		} else {
+			m.Set(o, v, t)
+		}
+	}
+}
+
+func (s *pstate) tree(d quad.Direction, id int64) (quadTree, bool) {
+	if d < quad.Subject || d > quad.Label {
+		panic("illegal direction")
+	}
+	return s.index[d-1].Get(id)
+}
+
+func (s *pstate) lookupVal(id int64) quad.Value {
+	pv, _ := s.prim.Get(id)
+	if pv == nil || pv.Value == nil {
+		return quad.BNode(internalBNodePrefix + strconv.FormatInt(id, 10))
+	}
+	return pv.Value
+}
+
+func (s *pstate) lookupQuadDirs(p internalQuad) quad.Quad {
+	var q quad.Quad
+	for dir := quad.Subject; dir <= quad.Label; dir++ {
+		vid := p.Dir(dir)
+		if vid == 0 {
+			continue
+		}
+		q.Set(dir, s.lookupVal(vid))
+	}
+	return q
+}
+
+func (s *pstate) quad(v graph.Ref) (q internalQuad, ok bool) {
+	switch v := v.(type) {
+	case bnode:
+		p, _ := s.prim.Get(int64(v))
+		if p == nil {
+			return
+		}
+		q = p.Quad
+	case qprim:
+		q = v.p.Quad
+	default:
+		return internalQuad{}, false
+	}
+	return q, !q.Zero()
+}
+
+func (s *pstate) Quad(index graph.Ref) (quad.Quad, error) {
+	q, ok := s.quad(index)
+	if !ok {
+		return quad.Quad{}, nil
+	}
+	return s.lookupQuadDirs(q), nil
+}
+
+func (s *pstate) QuadIterator(d quad.Direction, value graph.Ref) iterator.Shape {
+	id, ok := asID(value)
+	if !ok {
+		return iterator.NewNull()
+	}
+	index, ok := s.tree(d, id)
+	if ok && index.Len() != 0 {
+		return s.newIterator(d, id)
+	}
+	return iterator.NewNull()
+}
+
+func (s *pstate) QuadIteratorSize(ctx context.Context, d quad.Direction, v graph.Ref) (refs.Size, error) {
+	id, ok := asID(v)
+	if !ok {
+		return refs.Size{Value: 0, Exact: true}, nil
+	}
+	index, ok := s.tree(d, id)
+	if !ok {
+		return refs.Size{Value: 0, Exact: true}, nil
+	}
+	return refs.Size{Value: int64(index.Len()), Exact: true}, nil
+}
+
+func (s *pstate) Stats(ctx context.Context, exact bool) (graph.Stats, error) {
+	return graph.Stats{
+		Nodes: refs.Size{
+			Value: int64(s.vals.Len()),
+			Exact: true,
+		},
+		Quads: refs.Size{
+			Value: int64(s.quads.Len()),
+			Exact: true,
+		},
+	}, nil
+}
+
+func (s *pstate) ValueOf(name quad.Value) (graph.Ref, error) {
+	if name == nil {
+		return nil, nil
+	}
+	id, _ := s.vals.Get(name.String())
+	if id == 0 {
+		return nil, nil
+	}
+	return bnode(id), nil
+}
+
+func (s *pstate) NameOf(v graph.Ref) (quad.Value, error) {
+	if v == nil {
+		return nil, nil
+	} else if v, ok := v.(refs.PreFetchedValue); ok {
+		return v.NameOf(), nil
+	}
+	n, ok := asID(v)
+	if !ok {
+		return nil, nil
+	}
+	if _, ok = s.prim.Get(n); !ok {
+		return nil, nil
+	}
+	return s.lookupVal(n), nil
+}
+
+func (s *pstate) QuadsAllIterator() iterator.Shape {
+	return s.newAllIterator(false, s.last)
+}
+
+func (s *pstate) QuadDirection(val graph.Ref, d quad.Direction) (graph.Ref, error) {
+	q, ok := s.quad(val)
+	if !ok {
+		return nil, nil
+	}
+	id := q.Dir(d)
+	if id == 0 {
+		return nil, nil
+	}
+	return bnode(id), nil
+}
+
+func (s *pstate) NodesAllIterator() iterator.Shape {
+	return s.newAllIterator(true, s.last)
+}
+
+func (s *pstate) Close() error { return nil }
+
+// persistent returns the persistent version of the store indexes, which must be updated by writes.
+//
+// It returns nil if no snapshot or fork of the store is alive. The persistent version is dropped in this case.
+func (qs *QuadStore) persistent() *pstate {
+	if qs.shared != nil && qs.live.Load() == 0 {
+		qs.shared = nil
+	}
+	return qs.shared
+}
+
+// sharedState returns a copy of the persistent version of the store indexes.
+//
+// The persistent version is built if it doesn't exist and is kept up to date by following writes,
+// while the returned state (or any other one) is in use. See storeRef.
+func (qs *QuadStore) sharedState() pstate {
+	if qs.persistent() == nil {
+		s := newPState()
+		for _, p := range qs.prim {
+			s.putPrim(p, p.refs)
+			if p.Value != nil {
+				s.putValue(p.Value.String(), p.ID)
+			}
+			if !p.Quad.Zero() {
+				s.putQuad(p)
+			}
+		}
+		qs.shared = s
+	}
+	qs.shared.last, qs.shared.horizon = qs.last, qs.horizon
+	return qs.shared.clone()
+}
+
+// storeRef marks a snapshot or a fork of the quad store as alive until it's closed or garbage collected.
+type storeRef struct {
+	live *atomic.Int64
+	done atomic.Bool
+}
+
+func (qs *QuadStore) newRef() *storeRef {
+	qs.live.Add(1)
+	return &storeRef{live: &qs.live}
+}
+
+func (r *storeRef) release() {
+	if r != nil && r.done.CompareAndSwap(false, true) {
+		r.live.Add(-1)
+	}
+}
+
+// Snapshot returns a read-only view of the current state of the quad store.
+// Further changes of the store are not visible in the snapshot.
+//
+// The first call takes a linear time to build a persistent (structurally shared) copy of the store indexes.
+// While any snapshot or fork of the store is alive, the store keeps the copy up to date and next snapshots and forks
+// are created in a constant time. The copy is dropped when all of them are closed or garbage collected.
+func (qs *QuadStore) Snapshot() *Snapshot {
+	s := &Snapshot{pstate: qs.sharedState(), ref: qs.newRef()}
+	runtime.SetFinalizer(s, (*Snapshot).Close)
+	return s
+}
+
+// Fork returns a copy of the quad store that can be modified independently from it.
+// Both stores share all the data until it's modified. See Snapshot for the time complexity.
+//
+// Fork is not persistent, even if the parent store is.
+func (qs *QuadStore) Fork() *Fork {
+	f := newFork(qs.sharedState())
+	f.ref = qs.newRef()
+	runtime.SetFinalizer(f, (*Fork).Close)
+	return f
+}
+
+// Snapshot is a read-only view of the quad store at a specific point in time.
+// Methods that modify the snapshot return ErrReadOnly.
+type Snapshot struct {
+	pstate
+	ref *storeRef // set if the snapshot was created from a QuadStore
+}
+
+// Close releases the snapshot.
+func (s *Snapshot) Close() error {
+	s.ref.release()
+	return nil
+}
+
+// Fork returns a copy of the snapshot that can be modified independently from it.
+// It takes a constant time.
+func (s *Snapshot) Fork() *Fork {
+	return newFork(s.clone())
+}
+
+// ApplyDeltas implements graph.QuadStore. It always returns ErrReadOnly.
+func (s *Snapshot) ApplyDeltas(deltas []graph.Delta, ignoreOpts graph.IgnoreOpts) error {
+	return ErrReadOnly
+}
+
+// NewQuadWriter implements graph.QuadStore. It always returns ErrReadOnly.
+func (s *Snapshot) NewQuadWriter() (quad.WriteCloser, error) {
+	return nil, ErrReadOnly
+}
+
+// WriteQuads implements quad.Writer. It always returns ErrReadOnly.
+func (s *Snapshot) WriteQuads(buf []quad.Quad) (int, error) {
+	return 0, ErrReadOnly
+}
+
+// Fork is a copy of the quad store that can be modified independently from its parent.
+// Quads added to or deleted from the fork are tracked and can be listed with Deltas.
+type Fork struct {
+	pstate
+	changes map[quadKey]graph.Delta // changes against the parent store
+	ref     *storeRef               // set if the fork was created from a QuadStore
+}
+
+// Close releases the fork.
+func (f *Fork) Close() error {
+	f.ref.release()
+	return nil
+}
+
+func newFork(s pstate) *Fork {
+	return &Fork{pstate: s, changes: make(map[quadKey]graph.Delta)}
+}
+
+// Snapshot returns a read-only view of the current state of the fork. It takes a constant time.
+func (f *Fork) Snapshot() *Snapshot {
+	return &Snapshot{pstate: f.clone()}
+}
+
+// Fork returns a copy of the fork that can be modified independently from it. It takes a constant time.
+// Deltas of the new fork are tracked against this fork.
+func (f *Fork) Fork() *Fork {
+	return newFork(f.clone())
+}
+
+// quadKey is a string representation of quad values, used to compare quads between stores.
+type quadKey [4]string
+
+func keyOf(q quad.Quad) quadKey {
+	var k quadKey
+	for dir := quad.Subject; dir <= quad.Label; dir++ {
+		if v := q.Get(dir); v != nil {
+			k[dir-1] = v.String()
+		}
+	}
+	return k
+}
+
+func (f *Fork) recordChange(q quad.Quad, act graph.Procedure) {
+	k := keyOf(q)
+	if d, ok := f.changes[k]; ok && d.Action != act {
+		// change reverted
+		delete(f.changes, k)
+		return
+	}
+	f.changes[k] = graph.Delta{Quad: q, Action: act}
+}
+
+// Deltas returns a list of changes made to the fork since it was created.
+// Applying them to the parent store in its state at the time of the fork will
+// produce the same set of quads as in the fork.
+//
+// Deletions are listed first.
+func (f *Fork) Deltas() []graph.Delta {
+	keys := make([]quadKey, 0, len(f.changes))
+	for k := range f.changes {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := f.changes[keys[i]], f.changes[keys[j]]
+		if a.Action != b.Action {
+			return a.Action == graph.Delete
+		}
+		for d := range keys[i] {
+			if keys[i][d] != keys[j][d] {
+				return keys[i][d] < keys[j][d]
+			}
+		}
+		return false
+	})
+	out := make([]graph.Delta, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, f.changes[k])
+	}
+	return out
+}
+
+func (f *Fork) addRef(id int64) {
+	n, _ := f.refs.Get(id)
+	f.setRefs(id, n+1)
+}
+
+func (f *Fork) resolveVal(v quad.Value, add bool) (int64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	if n, ok := v.(quad.BNode); ok && strings.HasPrefix(string(n), internalBNodePrefix) {
+		n = n[len(internalBNodePrefix):]
+		id, err := strconv.ParseInt(string(n), 10, 64)
+		if err == nil && id != 0 {
+			if _, ok := f.prim.Get(id); ok || !add {
+				if add {
+					f.addRef(id)
+				}
+				return id, ok
+			}
+			f.putPrim(&Primitive{ID: id}, 1)
+			return id, true
+		}
+	}
+	vs := v.String()
+	if id, exists := f.vals.Get(vs); exists || !add {
+		if exists && add {
+			f.addRef(id)
+		}
+		return id, exists
+	}
+	f.last++
+	p := &Primitive{ID: f.last, Value: v}
+	f.putPrim(p, 1)
+	f.putValue(vs, p.ID)
+	return p.ID, true
+}
+
+func (f *Fork) resolveQuad(q quad.Quad, add bool) (internalQuad, bool) {
+	var p internalQuad
+	for dir := quad.Subject; dir <= quad.Label; dir++ {
+		v := q.Get(dir)
+		if v == nil {
+			continue
+		}
+		if vid, _ := f.resolveVal(v, add); vid != 0 {
+			p.SetDir(dir, vid)
+		} else if !add {
+			return internalQuad{}, false
+		}
+	}
+	return p, true
+}
+
+func (f *Fork) findQuad(q quad.Quad) (int64, bool) {
+	p, ok := f.resolveQuad(q, false)
+	if !ok {
+		return 0, false
+	}
+	id, _ := f.quads.Get(p)
+	return id, id != 0
+}
+
+func (f *Fork) hasQuad(q quad.Quad) bool {
+	_, ok := f.findQuad(q)
+	return ok
+}
+
+func (f *Fork) addQuad(q quad.Quad) {
+	if f.hasQuad(q) {
+		return
+	}
+	p, _ := f.resolveQuad(q, true)
+	f.last++
+	pr := &Primitive{ID: f.last, Quad: p}
+	f.putPrim(pr, 1)
+	f.putQuad(pr)
+	f.recordChange(f.lookupQuadDirs(p), graph.Add)
+}
+
+func (f *Fork) delete(id int64) {
+	p, ok := f.prim.Get(id)
+	if !ok {
+		return
+	}
+	if !p.Quad.Zero() {
+		f.recordChange(f.lookupQuadDirs(p.Quad), graph.Delete)
+	}
+	f.deletePrim(p)
+	for dir := quad.Subject; dir <= quad.Label; dir++ {
+		id := p.Quad.Dir(dir)
+		if id == 0 {
+			continue
+		}
+		if n, ok := f.refs.Get(id); ok {
+			n--
+			if n < 0 {
+				panic("remove of deleted node")
+			} else if n == 0 {
+				f.delete(id)
+			} else {
+				f.setRefs(id, n)
+			}
+		}
+	}
+}
+
+func (f *Fork) ApplyDeltas(deltas []graph.Delta, ignoreOpts graph.IgnoreOpts) error {
+	if err := checkDeltas(deltas, ignoreOpts, f.hasQuad); err != nil {
+		return err
+	}
+	for _, d := range deltas {
+		switch d.Action {
+		case graph.Add:
+			f.addQuad(d.Quad)
+		case graph.Delete:
+			if id, ok := f.findQuad(d.Quad); ok {
+				f.delete(id)
+			}
+		default:
+			return &graph.DeltaError{Delta: d, Err: graph.ErrInvalidAction}
+		}
+	}
+	f.horizon++
+	return nil
+}
+
+// WriteQuads implements quad.Writer.
+func (f *Fork) WriteQuads(buf []quad.Quad) (int, error) {
+	for _, q := range buf {
+		f.addQuad(q)
+	}
+	return len(buf), nil
+}
+
+func (f *Fork) NewQuadWriter() (quad.WriteCloser, error) {
+	return &forkWriter{f: f}, nil
+}
+
+type forkWriter struct {
+	f *Fork
+}
+
+func (w *forkWriter) WriteQuad(q quad.Quad) error {
+	w.f.addQuad(q)
+	return nil
+}
+
+func (w *forkWriter) WriteQuads(buf []quad.Quad) (int, error) {
+	return w.f.WriteQuads(buf)
+}
+
+func (w *forkWriter) Close() error {
+	return nil
+}
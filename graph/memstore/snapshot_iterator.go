@@ -0,0 +1,380 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/refs"
+	"github.com/cayleygraph/quad"
+)
+
+// Iterators over snapshots and forks.
+
+type snapIterator struct {
+	s     *pstate
+	d     quad.Direction
+	value int64
+}
+
+func (s *pstate) newIterator(d quad.Direction, value int64) *snapIterator {
+	return &snapIterator{
+		s:     s,
+		d:     d,
+		value: value,
+	}
+}
+
+// tree returns an immutable version of the current index tree.
+func (it *snapIterator) tree() quadTree {
+	it.s.freeze()
+	tree, _ := it.s.tree(it.d, it.value)
+	return tree
+}
+
+func (it *snapIterator) Iterate() iterator.Scanner {
+	// TODO(dennwc): it doesn't check the direction and value, while Contains does; is it expected?
+	return it.s.newIteratorNext(it.tree(), it.d)
+}
+
+func (it *snapIterator) Lookup() iterator.Index {
+	return it.s.newIteratorContains(it.tree(), it.d, it.value)
+}
+
+func (it *snapIterator) SubIterators() []iterator.Shape {
+	return nil
+}
+
+func (it *snapIterator) String() string {
+	return fmt.Sprintf("MemStore(%v)", it.d)
+}
+
+func (it *snapIterator) Sorted() bool { return true }
+
+func (it *snapIterator) Optimize(ctx context.Context) (iterator.Shape, bool) {
+	return it, false
+}
+
+func (it *snapIterator) Stats(ctx context.Context) (iterator.Costs, error) {
+	tree, _ := it.s.tree(it.d, it.value)
+	return iterator.Costs{
+		ContainsCost: int64(math.Log(float64(tree.Len()))) + 1,
+		NextCost:     1,
+		Size: refs.Size{
+			Value: int64(tree.Len()),
+			Exact: true,
+		},
+	}, nil
+}
+
+type snapIteratorNext struct {
+	nodes bool
+	s     *pstate
+	tree  quadTree
+	d     quad.Direction
+
+	iter *pmapIter[int64, *Primitive]
+	cur  *Primitive
+}
+
+func (s *pstate) newIteratorNext(tree quadTree, d quad.Direction) *snapIteratorNext {
+	return &snapIteratorNext{
+		nodes: d == 0,
+		d:     d,
+		s:     s,
+		tree:  tree,
+	}
+}
+
+func (it *snapIteratorNext) TagResults(dst map[string]graph.Ref) {}
+
+func (it *snapIteratorNext) Close() error {
+	return nil
+}
+
+func (it *snapIteratorNext) Next(ctx context.Context) bool {
+	if it.iter == nil {
+		it.iter = it.tree.SeekFirst()
+	}
+	_, p, ok := it.iter.Next()
+	if !ok {
+		it.cur = nil
+		return false
+	}
+	it.cur = p
+	return true
+}
+
+func (it *snapIteratorNext) Err() error {
+	return nil
+}
+
+func (it *snapIteratorNext) Result() graph.Ref {
+	if it.cur == nil {
+		return nil
+	}
+	return qprim{p: it.cur}
+}
+
+func (it *snapIteratorNext) NextPath(ctx context.Context) bool {
+	return false
+}
+
+func (it *snapIteratorNext) String() string {
+	return fmt.Sprintf("MemStoreNext(%v)", it.d)
+}
+
+func (it *snapIteratorNext) Sorted() bool { return true }
+
+type snapIteratorContains struct {
+	nodes bool
+	s     *pstate
+	tree  quadTree
+
+	cur *Primitive
+
+	d     quad.Direction
+	value int64
+}
+
+func (s *pstate) newIteratorContains(tree quadTree, d quad.Direction, value int64) *snapIteratorContains {
+	return &snapIteratorContains{
+		nodes: d == 0,
+		s:     s,
+		tree:  tree,
+		d:     d,
+		value: value,
+	}
+}
+
+func (it *snapIteratorContains) TagResults(dst map[string]graph.Ref) {}
+
+func (it *snapIteratorContains) Close() error {
+	return nil
+}
+
+func (it *snapIteratorContains) Err() error {
+	return nil
+}
+
+func (it *snapIteratorContains) Result() graph.Ref {
+	if it.cur == nil {
+		return nil
+	}
+	return qprim{p: it.cur}
+}
+
+func (it *snapIteratorContains) NextPath(ctx context.Context) bool {
+	return false
+}
+
+func (it *snapIteratorContains) Contains(ctx context.Context, v graph.Ref) bool {
+	if v == nil {
+		return false
+	}
+	switch v := v.(type) {
+	case bnode:
+		if p, ok := it.tree.Get(int64(v)); ok {
+			it.cur = p
+			return true
+		}
+	case qprim:
+		if v.p.Quad.Dir(it.d) == it.value {
+			it.cur = v.p
+			return true
+		}
+	}
+	return false
+}
+
+func (it *snapIteratorContains) String() string {
+	return fmt.Sprintf("MemStoreContains(%v)", it.d)
+}
+
+func (it *snapIteratorContains) Sorted() bool { return true }
+
+type snapAllIterator struct {
+	s     *pstate
+	all   pmap[int64, *Primitive]
+	maxid int64 // id of last observed insert (prim id)
+	nodes bool
+}
+
+func (s *pstate) newAllIterator(nodes bool, maxid int64) *snapAllIterator {
+	s.freeze()
+	return &snapAllIterator{
+		s: s, all: s.prim, nodes: nodes,
+		maxid: maxid,
+	}
+}
+
+func (it *snapAllIterator) Iterate() iterator.Scanner {
+	return it.s.newAllIteratorNext(it.nodes, it.maxid, it.all)
+}
+
+func (it *snapAllIterator) Lookup() iterator.Index {
+	return it.s.newAllIteratorContains(it.nodes, it.maxid)
+}
+
+func (it *snapAllIterator) SubIterators() []iterator.Shape { return nil }
+func (it *snapAllIterator) Optimize(ctx context.Context) (iterator.Shape, bool) {
+	return it, false
+}
+
+func (it *snapAllIterator) String() string {
+	return "MemStoreAll"
+}
+
+func (it *snapAllIterator) Stats(ctx context.Context) (iterator.Costs, error) {
+	return iterator.Costs{
+		NextCost:     1,
+		ContainsCost: 1,
+		Size: refs.Size{
+			// TODO(dennwc): use maxid?
+			Value: int64(it.all.Len()),
+			Exact: true,
+		},
+	}, nil
+}
+
+type snapAllIteratorNext struct {
+	s     *pstate
+	iter  *pmapIter[int64, *Primitive]
+	maxid int64 // id of last observed insert (prim id)
+	nodes bool
+
+	cur  *Primitive
+	done bool
+}
+
+func (s *pstate) newAllIteratorNext(nodes bool, maxid int64, all pmap[int64, *Primitive]) *snapAllIteratorNext {
+	return &snapAllIteratorNext{
+		s: s, iter: all.SeekFirst(), nodes: nodes,
+		maxid: maxid,
+	}
+}
+
+func (it *snapAllIteratorNext) ok(p *Primitive) bool {
+	return p.filter(it.nodes, it.maxid)
+}
+
+func (it *snapAllIteratorNext) Next(ctx context.Context) bool {
+	it.cur = nil
+	if it.done {
+		return false
+	}
+	for {
+		_, p, ok := it.iter.Next()
+		if !ok || p.ID > it.maxid {
+			break
+		}
+		if it.ok(p) {
+			it.cur = p
+			return true
+		}
+	}
+	it.done = true
+	return false
+}
+
+func (it *snapAllIteratorNext) Result() graph.Ref {
+	if it.cur == nil {
+		return nil
+	}
+	if !it.cur.Quad.Zero() {
+		return qprim{p: it.cur}
+	}
+	return bnode(it.cur.ID)
+}
+
+func (it *snapAllIteratorNext) Err() error { return nil }
+func (it *snapAllIteratorNext) Close() error {
+	it.done = true
+	it.iter = nil
+	return nil
+}
+
+func (it *snapAllIteratorNext) TagResults(dst map[string]graph.Ref) {}
+
+func (it *snapAllIteratorNext) String() string {
+	return "MemStoreAllNext"
+}
+func (it *snapAllIteratorNext) NextPath(ctx context.Context) bool { return false }
+
+type snapAllIteratorContains struct {
+	s     *pstate
+	maxid int64 // id of last observed insert (prim id)
+	nodes bool
+
+	cur  *Primitive
+	done bool
+}
+
+func (s *pstate) newAllIteratorContains(nodes bool, maxid int64) *snapAllIteratorContains {
+	return &snapAllIteratorContains{
+		s: s, nodes: nodes,
+		maxid: maxid,
+	}
+}
+
+func (it *snapAllIteratorContains) ok(p *Primitive) bool {
+	return p.filter(it.nodes, it.maxid)
+}
+
+func (it *snapAllIteratorContains) Contains(ctx context.Context, v graph.Ref) bool {
+	it.cur = nil
+	if it.done {
+		return false
+	}
+	id, ok := asID(v)
+	if !ok {
+		return false
+	}
+	p, ok := it.s.prim.Get(id)
+	if !ok || p.ID > it.maxid {
+		return false
+	}
+	if !it.ok(p) {
+		return false
+	}
+	it.cur = p
+	return true
+}
+func (it *snapAllIteratorContains) Result() graph.Ref {
+	if it.cur == nil {
+		return nil
+	}
+	if !it.cur.Quad.Zero() {
+		return qprim{p: it.cur}
+	}
+	return bnode(it.cur.ID)
+}
+
+func (it *snapAllIteratorContains) Err() error { return nil }
+func (it *snapAllIteratorContains) Close() error {
+	it.done = true
+	return nil
+}
+
+func (it *snapAllIteratorContains) TagResults(dst map[string]graph.Ref) {}
+
+func (it *snapAllIteratorContains) String() string {
+	return "MemStoreAllContains"
+}
+func (it *snapAllIteratorContains) NextPath(ctx context.Context) bool { return false }
@@ -737,6 +737,15 @@ func (qs *QuadStore) Size() int64 {
 	return count
 }
 
+// Ping implements graph.Pinger. It reads a single document from the log collection.
+func (qs *QuadStore) Ping(ctx context.Context) error {
+	_, err := qs.db.Query(colLog).Limit(1).One(ctx)
+	if err == nosql.ErrNotFound {
+		err = nil
+	}
+	return err
+}
+
 func (qs *QuadStore) Close() error {
 	return qs.db.Close()
 }
@@ -0,0 +1,112 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"io"
+
+	"github.com/cayleygraph/quad"
+
+	"github.com/cayleygraph/cayley/graph/iterator"
+)
+
+// HasQuad checks if the exact quad, including the label, is stored in the quad store.
+func HasQuad(qs QuadStore, q quad.Quad) (bool, error) {
+	var its []iterator.Shape
+	for _, d := range quad.Directions {
+		v := q.Get(d)
+		if v == nil {
+			continue
+		}
+		ref, err := qs.ValueOf(v)
+		if err != nil || ref == nil {
+			return false, err
+		}
+		its = append(its, qs.QuadIterator(d, ref))
+	}
+	if len(its) == 0 {
+		return false, nil
+	}
+	r := NewResultReader(qs, iterator.NewAnd(its...).Iterate())
+	defer r.Close()
+	for {
+		got, err := r.ReadQuad()
+		if err == io.EOF {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		} else if got.Label == q.Label {
+			return true, nil
+		}
+	}
+}
+
+// Changes returns deltas that would change the quad store: additions of quads that are not stored yet,
+// and deletions of stored quads. Other deltas are either ignored by quad writers, or make them fail.
+// Duplicate deltas are only returned once.
+func Changes(qs QuadStore, deltas []Delta) ([]Delta, error) {
+	var (
+		out  []Delta
+		seen = make(map[Delta]struct{}, len(deltas))
+	)
+	for _, d := range deltas {
+		if _, ok := seen[d]; ok {
+			continue
+		}
+		seen[d] = struct{}{}
+		ok, err := HasQuad(qs, d.Quad)
+		if err != nil {
+			return nil, err
+		}
+		if (d.Action == Add && !ok) || (d.Action == Delete && ok) {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+// NodeQuads returns all quads that have the node as a subject, predicate, object or label.
+// Each quad is returned once. It returns ErrNodeNotExists if there are no such quads.
+func NodeQuads(qs QuadStore, v quad.Value) ([]quad.Quad, error) {
+	ref, err := qs.ValueOf(v)
+	if err != nil {
+		return nil, err
+	} else if ref == nil {
+		return nil, ErrNodeNotExists
+	}
+	var (
+		out  []quad.Quad
+		seen = make(map[quad.Quad]struct{})
+	)
+	for _, d := range quad.Directions {
+		r := NewResultReader(qs, qs.QuadIterator(d, ref).Iterate())
+		quads, err := quad.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range quads {
+			if _, ok := seen[q]; ok {
+				continue
+			}
+			seen[q] = struct{}{}
+			out = append(out, q)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrNodeNotExists
+	}
+	return out, nil
+}
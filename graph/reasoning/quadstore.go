@@ -0,0 +1,425 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reasoning implements a QuadStore wrapper that infers quads at query time.
+//
+// The wrapper keeps the RDFS schema of the underlying store (rdfs:subClassOf, rdfs:subPropertyOf,
+// rdfs:domain and rdfs:range quads) in an inference.Store and rewrites queries, so that lookups of
+// rdf:type values include instances of subclasses, and lookups of predicates include sub-properties.
+// No quads are written to the underlying store.
+package reasoning
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/refs"
+	"github.com/cayleygraph/cayley/inference"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/shape"
+)
+
+const (
+	// OptionName is the name of the quad store option that enables reasoning.
+	OptionName = "reasoning"
+	// RDFS is the value of the option that enables RDFS reasoning.
+	RDFS = "rdfs"
+)
+
+var (
+	_ graph.QuadStore = (*QuadStore)(nil)
+	_ shape.Rewriter  = (*QuadStore)(nil)
+	_ query.Reasoner  = (*QuadStore)(nil)
+)
+
+// schemaPredicates are predicates of quads that are tracked by the inference store.
+var schemaPredicates = []quad.Value{
+	quad.IRI(rdfs.SubClassOf),
+	quad.IRI(rdfs.SubPropertyOf),
+	quad.IRI(rdfs.Domain),
+	quad.IRI(rdfs.Range),
+}
+
+func isSchema(q quad.Quad) bool {
+	p, ok := q.Predicate.(quad.IRI)
+	if !ok {
+		return false
+	}
+	p = p.Short()
+	for _, sp := range schemaPredicates {
+		if quad.Value(p) == sp {
+			return true
+		}
+	}
+	return false
+}
+
+// short converts IRIs to a short form. The inference store only recognizes short forms of RDFS IRIs,
+// while quads might use either of them.
+func short(v quad.Value) quad.Value {
+	if iri, ok := v.(quad.IRI); ok {
+		return iri.Short()
+	}
+	return v
+}
+
+func shortQuad(q quad.Quad) quad.Quad {
+	return quad.Quad{
+		Subject:   short(q.Subject),
+		Predicate: short(q.Predicate),
+		Object:    short(q.Object),
+		Label:     q.Label,
+	}
+}
+
+// withFullIRIs returns values together with full forms of IRIs.
+func withFullIRIs(vals []quad.Value) []quad.Value {
+	out := make([]quad.Value, 0, len(vals))
+	for _, v := range vals {
+		out = append(out, v)
+		if iri, ok := v.(quad.IRI); ok {
+			if full := iri.Full(); full != iri {
+				out = append(out, full)
+			}
+		}
+	}
+	return out
+}
+
+// schema is the inference state shared by all views of the quad store.
+type schema struct {
+	mu    sync.RWMutex
+	store inference.Store
+}
+
+// QuadStore infers quads of the underlying quad store at query time.
+type QuadStore struct {
+	qs  graph.QuadStore
+	off bool
+	s   *schema
+}
+
+// New creates a reasoning wrapper for the quad store and loads the schema from it.
+func New(ctx context.Context, qs graph.QuadStore) (*QuadStore, error) {
+	s := &schema{store: inference.NewStore()}
+	for _, p := range withFullIRIs(schemaPredicates) {
+		ref, err := qs.ValueOf(p)
+		if err != nil {
+			return nil, err
+		} else if ref == nil {
+			continue
+		}
+		err = iterator.Iterate(ctx, qs.QuadIterator(quad.Predicate, ref)).Each(func(r refs.Ref) error {
+			q, err := qs.Quad(r)
+			if err != nil {
+				return err
+			}
+			s.store.ProcessQuads(shortQuad(q))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot load schema: %w", err)
+		}
+	}
+	return &QuadStore{qs: qs, s: s}, nil
+}
+
+// FromOptions wraps the quad store if reasoning is enabled in quad store options.
+func FromOptions(ctx context.Context, qs graph.QuadStore, opts graph.Options) (graph.QuadStore, error) {
+	mode, err := opts.StringKey(OptionName, "")
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case "":
+		return qs, nil
+	case RDFS:
+		return New(ctx, qs)
+	}
+	return nil, fmt.Errorf("unsupported reasoning: %q", mode)
+}
+
+// WithReasoning returns a view of the quad store with inference enabled or disabled.
+// Views share the schema and the underlying quad store.
+func (qs *QuadStore) WithReasoning(on bool) graph.QuadStore {
+	if qs.off == !on {
+		return qs
+	}
+	return &QuadStore{qs: qs.qs, off: !on, s: qs.s}
+}
+
+// RewriteShape implements shape.Rewriter.
+func (qs *QuadStore) RewriteShape(ctx context.Context, s shape.Shape) (shape.Shape, graph.QuadStore) {
+	if qs.off {
+		return s, qs.qs
+	}
+	qs.s.mu.RLock()
+	defer qs.s.mu.RUnlock()
+	ns, _ := s.Optimize(ctx, rewriter{qs: qs.qs, store: &qs.s.store})
+	if ns == nil {
+		ns = shape.Null{}
+	}
+	return ns, qs.qs
+}
+
+// rewriter expands values in quad filters according to the schema.
+type rewriter struct {
+	qs    graph.QuadStore
+	store *inference.Store
+}
+
+// values returns a fixed set of values that a shape represents. IRIs are converted to a short form.
+func (r rewriter) values(s shape.Shape) ([]quad.Value, bool) {
+	switch s := s.(type) {
+	case shape.Lookup:
+		out := make([]quad.Value, 0, len(s))
+		for _, v := range s {
+			out = append(out, short(v))
+		}
+		return out, true
+	case shape.Fixed:
+		out := make([]quad.Value, 0, len(s))
+		for _, ref := range s {
+			v, err := r.qs.NameOf(ref)
+			if err != nil || v == nil {
+				return nil, false
+			}
+			out = append(out, short(v))
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// subClasses returns given classes with all their subclasses.
+func (r rewriter) subClasses(vals []quad.Value) []quad.Value {
+	seen := make(map[quad.Value]struct{}, len(vals))
+	out := make([]quad.Value, 0, len(vals))
+	var add func(v quad.Value)
+	add = func(v quad.Value) {
+		if _, ok := seen[v]; ok {
+			return
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+		if c := r.store.GetClass(v); c != nil {
+			for _, sub := range c.SubClasses() {
+				add(sub.Name())
+			}
+		}
+	}
+	for _, v := range vals {
+		add(v)
+	}
+	return out
+}
+
+// subProperties returns given properties with all their sub-properties.
+func (r rewriter) subProperties(vals []quad.Value) []quad.Value {
+	seen := make(map[quad.Value]struct{}, len(vals))
+	out := make([]quad.Value, 0, len(vals))
+	var add func(v quad.Value)
+	add = func(v quad.Value) {
+		if _, ok := seen[v]; ok {
+			return
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+		if p := r.store.GetProperty(v); p != nil {
+			for _, sub := range p.SubProperties() {
+				add(sub.Name())
+			}
+		}
+	}
+	for _, v := range vals {
+		add(v)
+	}
+	return out
+}
+
+func (r rewriter) OptimizeShape(ctx context.Context, s shape.Shape) (shape.Shape, bool) {
+	q, ok := s.(shape.Quads)
+	if !ok {
+		return s, false
+	}
+	var (
+		out     shape.Quads
+		isType  bool
+		objects = -1
+	)
+	replace := func(i int, vals []quad.Value) {
+		if out == nil {
+			out = make(shape.Quads, len(q))
+			copy(out, q)
+		}
+		out[i].Values = shape.Lookup(withFullIRIs(vals))
+	}
+	for i, f := range q {
+		switch f.Dir {
+		case quad.Predicate:
+			preds, ok := r.values(f.Values)
+			if !ok {
+				continue
+			}
+			if len(preds) == 1 && preds[0] == quad.IRI(rdf.Type) {
+				isType = true
+			}
+			if exp := r.subProperties(preds); len(exp) != len(preds) {
+				replace(i, exp)
+			}
+		case quad.Object:
+			objects = i
+		}
+	}
+	if isType && objects >= 0 {
+		if classes, ok := r.values(q[objects].Values); ok {
+			if exp := r.subClasses(classes); len(exp) != len(classes) {
+				replace(objects, exp)
+			}
+		}
+	}
+	if out == nil {
+		return s, false
+	}
+	return out, true
+}
+
+// apply updates the schema with quads that were added or removed from the underlying store.
+func (s *schema) apply(in []graph.Delta) {
+	for _, d := range in {
+		if !isSchema(d.Quad) {
+			continue
+		}
+		switch d.Action {
+		case graph.Add:
+			s.store.ProcessQuads(shortQuad(d.Quad))
+		case graph.Delete:
+			s.store.UnprocessQuads(shortQuad(d.Quad))
+		}
+	}
+}
+
+func hasSchema(in []graph.Delta) bool {
+	for _, d := range in {
+		if isSchema(d.Quad) {
+			return true
+		}
+	}
+	return false
+}
+
+func (qs *QuadStore) ApplyDeltas(in []graph.Delta, opts graph.IgnoreOpts) error {
+	if !hasSchema(in) {
+		return qs.qs.ApplyDeltas(in, opts)
+	}
+	// keep the schema consistent with the order of changes in the store
+	qs.s.mu.Lock()
+	defer qs.s.mu.Unlock()
+	if err := qs.qs.ApplyDeltas(in, opts); err != nil {
+		return err
+	}
+	qs.s.apply(in)
+	return nil
+}
+
+func (qs *QuadStore) NewQuadWriter() (quad.WriteCloser, error) {
+	w, err := qs.qs.NewQuadWriter()
+	if err != nil {
+		return nil, err
+	}
+	return &quadWriter{s: qs.s, w: w}, nil
+}
+
+func (qs *QuadStore) ValueOf(v quad.Value) (graph.Ref, error) {
+	return qs.qs.ValueOf(v)
+}
+
+func (qs *QuadStore) NameOf(v graph.Ref) (quad.Value, error) {
+	return qs.qs.NameOf(v)
+}
+
+func (qs *QuadStore) Quad(v graph.Ref) (quad.Quad, error) {
+	return qs.qs.Quad(v)
+}
+
+func (qs *QuadStore) QuadDirection(v graph.Ref, d quad.Direction) (graph.Ref, error) {
+	return qs.qs.QuadDirection(v, d)
+}
+
+func (qs *QuadStore) QuadIterator(d quad.Direction, v graph.Ref) iterator.Shape {
+	return qs.qs.QuadIterator(d, v)
+}
+
+func (qs *QuadStore) QuadIteratorSize(ctx context.Context, d quad.Direction, v graph.Ref) (refs.Size, error) {
+	return qs.qs.QuadIteratorSize(ctx, d, v)
+}
+
+func (qs *QuadStore) Stats(ctx context.Context, exact bool) (graph.Stats, error) {
+	return qs.qs.Stats(ctx, exact)
+}
+
+func (qs *QuadStore) NodesAllIterator() iterator.Shape {
+	return qs.qs.NodesAllIterator()
+}
+
+func (qs *QuadStore) QuadsAllIterator() iterator.Shape {
+	return qs.qs.QuadsAllIterator()
+}
+
+// Close closes the underlying quad store.
+func (qs *QuadStore) Close() error {
+	return qs.qs.Close()
+}
+
+// Ping implements graph.Pinger.
+func (qs *QuadStore) Ping(ctx context.Context) error {
+	return graph.Ping(ctx, qs.qs)
+}
+
+type quadWriter struct {
+	s *schema
+	w quad.WriteCloser
+}
+
+func (w *quadWriter) WriteQuad(q quad.Quad) error {
+	_, err := w.WriteQuads([]quad.Quad{q})
+	return err
+}
+
+func (w *quadWriter) WriteQuads(buf []quad.Quad) (int, error) {
+	n, err := w.w.WriteQuads(buf)
+	var in []graph.Delta
+	for _, q := range buf[:n] {
+		if isSchema(q) {
+			in = append(in, graph.Delta{Quad: q, Action: graph.Add})
+		}
+	}
+	if len(in) != 0 {
+		w.s.mu.Lock()
+		w.s.apply(in)
+		w.s.mu.Unlock()
+	}
+	return n, err
+}
+
+func (w *quadWriter) Close() error {
+	return w.w.Close()
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reasoning_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/graph/reasoning"
+	"github.com/cayleygraph/cayley/query"
+	_ "github.com/cayleygraph/cayley/query/gizmo"
+	"github.com/cayleygraph/cayley/query/path"
+	_ "github.com/cayleygraph/cayley/writer"
+)
+
+var quads = []quad.Quad{
+	quad.MakeIRI("Employee", rdfs.SubClassOf, "Person", ""),
+	quad.MakeIRI("Manager", rdfs.SubClassOf, "Employee", ""),
+	quad.MakeIRI("worksWith", rdfs.SubPropertyOf, "knows", ""),
+	quad.MakeIRI("alice", rdf.Type, "Employee", ""),
+	quad.MakeIRI("bob", rdf.Type, "Manager", ""),
+	quad.MakeIRI("carol", rdf.Type, "Person", ""),
+	quad.MakeIRI("alice", "knows", "bob", ""),
+	quad.MakeIRI("alice", "worksWith", "carol", ""),
+}
+
+func names(t testing.TB, qs graph.QuadStore, p *path.Path) []string {
+	ctx := context.TODO()
+	vals, err := iterator.Iterate(ctx, p.BuildIteratorOn(ctx, qs)).AllValues(qs)
+	require.NoError(t, err)
+	out := make([]string, 0, len(vals))
+	for _, v := range vals {
+		out = append(out, quad.StringOf(v))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestReasoning(t *testing.T) {
+	ctx := context.TODO()
+	mem := memstore.New(quads...)
+	qs, err := reasoning.New(ctx, mem)
+	require.NoError(t, err)
+
+	persons := path.NewPath(nil).Has(quad.IRI(rdf.Type), quad.IRI("Person"))
+	known := path.StartMorphism(quad.IRI("alice")).Out(quad.IRI("knows"))
+
+	require.Equal(t, []string{"<alice>", "<bob>", "<carol>"}, names(t, qs, persons))
+	require.Equal(t, []string{"<bob>", "<carol>"}, names(t, qs, known))
+	// other predicates don't match instances of subclasses
+	require.Equal(t, []string{"<bob>"}, names(t, qs, path.StartMorphism(quad.IRI("alice")).Out(quad.IRI("knows")).Has(quad.IRI(rdf.Type), quad.IRI("Manager"))))
+
+	off := qs.WithReasoning(false)
+	require.Equal(t, []string{"<carol>"}, names(t, off, persons))
+	require.Equal(t, []string{"<bob>"}, names(t, off, known))
+
+	// schema changes are applied to all views
+	qw, err := graph.NewQuadWriter("single", qs, nil)
+	require.NoError(t, err)
+	require.NoError(t, qw.AddQuadSet([]quad.Quad{
+		quad.MakeIRI("Intern", rdfs.SubClassOf, "Employee", ""),
+		quad.MakeIRI("dave", rdf.Type, "Intern", ""),
+	}))
+	require.Equal(t, []string{"<alice>", "<bob>", "<carol>", "<dave>"}, names(t, qs, persons))
+	require.NoError(t, qw.RemoveQuad(quad.MakeIRI("Employee", rdfs.SubClassOf, "Person", "")))
+	require.Equal(t, []string{"<carol>"}, names(t, qs, persons))
+	require.Equal(t, []string{"<alice>", "<bob>", "<dave>"}, names(t, qs.WithReasoning(true), path.NewPath(nil).Has(quad.IRI(rdf.Type), quad.IRI("Employee"))))
+
+	// schema is loaded from the existing quads
+	qs2, err := reasoning.New(ctx, mem)
+	require.NoError(t, err)
+	require.Equal(t, []string{"<bob>", "<carol>"}, names(t, qs2, known))
+	require.Equal(t, []string{"<alice>", "<bob>", "<dave>"}, names(t, qs2, path.NewPath(nil).Has(quad.IRI(rdf.Type), quad.IRI("Employee"))))
+}
+
+func TestQueryOptions(t *testing.T) {
+	ctx := context.TODO()
+	qs, err := reasoning.FromOptions(ctx, memstore.New(quads...), graph.Options{reasoning.OptionName: reasoning.RDFS})
+	require.NoError(t, err)
+
+	const qu = `g.V().Has("<rdf:type>", "<Person>").All()`
+	run := func(opt query.Options) int {
+		opt.Collation = query.JSON
+		it, err := query.Execute(ctx, qs, "gizmo", qu, opt)
+		require.NoError(t, err)
+		defer it.Close()
+		n := 0
+		for it.Next(ctx) {
+			n++
+		}
+		require.NoError(t, it.Err())
+		return n
+	}
+	require.Equal(t, 3, run(query.Options{}))
+	require.Equal(t, 1, run(query.Options{NoReasoning: true}))
+
+	mem := memstore.New()
+	qs, err = reasoning.FromOptions(ctx, mem, nil)
+	require.NoError(t, err)
+	require.Equal(t, graph.QuadStore(mem), qs)
+	_, err = reasoning.FromOptions(ctx, mem, graph.Options{reasoning.OptionName: "owl"})
+	require.Error(t, err)
+}
+
+func TestFullIRIs(t *testing.T) {
+	ctx := context.TODO()
+	typ, sub := quad.IRI(rdf.Type).Full(), quad.IRI(rdfs.SubClassOf).Full()
+	qs, err := reasoning.New(ctx, memstore.New(
+		quad.Make(quad.IRI("Employee"), sub, quad.IRI("Person"), nil),
+		quad.Make(quad.IRI("alice"), typ, quad.IRI("Employee"), nil),
+	))
+	require.NoError(t, err)
+	require.Equal(t, []string{"<alice>"}, names(t, qs, path.NewPath(nil).Has(typ, quad.IRI("Person"))))
+}
@@ -0,0 +1,331 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scope implements a QuadStore wrapper that restricts access to a set of quad labels (named graphs).
+//
+// Only quads with one of the allowed labels are visible through the wrapper, and only nodes
+// used by those quads can be resolved. Quads without a label are not visible. Writes of quads
+// with other labels are rejected.
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cayleygraph/quad"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/refs"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/shape"
+)
+
+// ErrLabelNotAllowed is returned when writing a quad with a label outside of the scope.
+var ErrLabelNotAllowed = errors.New("scope: label is not allowed")
+
+var (
+	_ graph.QuadStore = (*QuadStore)(nil)
+	_ shape.Rewriter  = (*QuadStore)(nil)
+	_ query.Reasoner  = (*QuadStore)(nil)
+)
+
+// QuadStore restricts the underlying quad store to a set of labels.
+type QuadStore struct {
+	qs     graph.QuadStore
+	labels []quad.Value
+	hashes map[refs.ValueHash]struct{}
+}
+
+// New creates a view of the quad store that contains only quads with given labels.
+// Nil labels are ignored.
+func New(qs graph.QuadStore, labels ...quad.Value) *QuadStore {
+	s := &QuadStore{qs: qs, hashes: make(map[refs.ValueHash]struct{}, len(labels))}
+	for _, l := range labels {
+		if l == nil {
+			continue
+		}
+		h := refs.HashOf(l)
+		if _, ok := s.hashes[h]; ok {
+			continue
+		}
+		s.hashes[h] = struct{}{}
+		s.labels = append(s.labels, l)
+	}
+	return s
+}
+
+// wrap returns a scope with the same labels for another quad store.
+func (qs *QuadStore) wrap(base graph.QuadStore) *QuadStore {
+	return &QuadStore{qs: base, labels: qs.labels, hashes: qs.hashes}
+}
+
+// Labels returns the list of allowed labels.
+func (qs *QuadStore) Labels() []quad.Value {
+	return append([]quad.Value{}, qs.labels...)
+}
+
+// Allowed checks if quads with a given label are visible through the scope.
+func (qs *QuadStore) Allowed(label quad.Value) bool {
+	if label == nil {
+		return false
+	}
+	_, ok := qs.hashes[refs.HashOf(label)]
+	return ok
+}
+
+// labelRefs resolves allowed labels in the underlying store. Labels that are not present in the store are skipped.
+// Labels are resolved each time, because they might be added after the scope was created.
+func (qs *QuadStore) labelRefs() ([]graph.Ref, error) {
+	out := make([]graph.Ref, 0, len(qs.labels))
+	for _, l := range qs.labels {
+		r, err := qs.qs.ValueOf(l)
+		if err != nil {
+			return nil, err
+		} else if r != nil {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (qs *QuadStore) isLabel(v graph.Ref) (bool, error) {
+	val, err := qs.qs.NameOf(v)
+	if err != nil {
+		return false, err
+	}
+	return qs.Allowed(val), nil
+}
+
+// allQuads returns an iterator of all quads with allowed labels.
+func (qs *QuadStore) allQuads() iterator.Shape {
+	labels, err := qs.labelRefs()
+	if err != nil {
+		return iterator.NewError(err)
+	}
+	switch len(labels) {
+	case 0:
+		return iterator.NewNull()
+	case 1:
+		return qs.qs.QuadIterator(quad.Label, labels[0])
+	}
+	subs := make([]iterator.Shape, 0, len(labels))
+	for _, l := range labels {
+		subs = append(subs, qs.qs.QuadIterator(quad.Label, l))
+	}
+	return iterator.NewOr(subs...)
+}
+
+func (qs *QuadStore) ValueOf(v quad.Value) (graph.Ref, error) {
+	r, err := qs.qs.ValueOf(v)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	if qs.Allowed(v) {
+		return r, nil
+	}
+	ctx := context.TODO()
+	for _, d := range []quad.Direction{quad.Subject, quad.Predicate, quad.Object} {
+		it := iterator.NewAnd(qs.qs.QuadIterator(d, r), qs.allQuads()).Iterate()
+		ok := it.Next(ctx)
+		err = it.Err()
+		it.Close()
+		if err != nil {
+			return nil, err
+		} else if ok {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (qs *QuadStore) NameOf(v graph.Ref) (quad.Value, error) {
+	return qs.qs.NameOf(v)
+}
+
+func (qs *QuadStore) Quad(v graph.Ref) (quad.Quad, error) {
+	q, err := qs.qs.Quad(v)
+	if err != nil || !qs.Allowed(q.Label) {
+		return quad.Quad{}, err
+	}
+	return q, nil
+}
+
+func (qs *QuadStore) QuadDirection(v graph.Ref, d quad.Direction) (graph.Ref, error) {
+	return qs.qs.QuadDirection(v, d)
+}
+
+func (qs *QuadStore) QuadIterator(d quad.Direction, v graph.Ref) iterator.Shape {
+	if d == quad.Label {
+		ok, err := qs.isLabel(v)
+		if err != nil {
+			return iterator.NewError(err)
+		} else if !ok {
+			return iterator.NewNull()
+		}
+		return qs.qs.QuadIterator(d, v)
+	}
+	return iterator.NewAnd(qs.qs.QuadIterator(d, v), qs.allQuads())
+}
+
+func (qs *QuadStore) QuadIteratorSize(ctx context.Context, d quad.Direction, v graph.Ref) (refs.Size, error) {
+	if d == quad.Label {
+		ok, err := qs.isLabel(v)
+		if err != nil || !ok {
+			return refs.Size{Exact: true}, err
+		}
+		return qs.qs.QuadIteratorSize(ctx, d, v)
+	}
+	sz, err := qs.qs.QuadIteratorSize(ctx, d, v)
+	sz.Exact = false // only an upper bound
+	return sz, err
+}
+
+func (qs *QuadStore) Stats(ctx context.Context, exact bool) (graph.Stats, error) {
+	labels, err := qs.labelRefs()
+	if err != nil {
+		return graph.Stats{}, err
+	}
+	st := graph.Stats{Quads: refs.Size{Exact: true}}
+	for _, l := range labels {
+		sz, err := qs.qs.QuadIteratorSize(ctx, quad.Label, l)
+		if err != nil {
+			return st, err
+		}
+		st.Quads.Value += sz.Value
+		st.Quads.Exact = st.Quads.Exact && sz.Exact
+	}
+	if exact {
+		if !st.Quads.Exact {
+			n, err := iterator.Iterate(ctx, qs.QuadsAllIterator()).Count()
+			if err != nil {
+				return st, err
+			}
+			st.Quads = refs.Size{Value: n, Exact: true}
+		}
+		n, err := iterator.Iterate(ctx, qs.NodesAllIterator()).Count()
+		if err != nil {
+			return st, err
+		}
+		st.Nodes = refs.Size{Value: n, Exact: true}
+		return st, nil
+	}
+	sst, err := qs.qs.Stats(ctx, false)
+	if err != nil {
+		return st, err
+	}
+	// there are at most 3 new nodes per quad, plus labels
+	st.Nodes.Value = min(sst.Nodes.Value, 3*st.Quads.Value+int64(len(labels)))
+	return st, nil
+}
+
+func (qs *QuadStore) NodesAllIterator() iterator.Shape {
+	labels, err := qs.labelRefs()
+	if err != nil {
+		return iterator.NewError(err)
+	} else if len(labels) == 0 {
+		return iterator.NewNull()
+	}
+	quads := qs.allQuads()
+	return iterator.NewUnique(iterator.NewOr(
+		graph.NewHasA(qs.qs, quads, quad.Subject),
+		graph.NewHasA(qs.qs, quads, quad.Predicate),
+		graph.NewHasA(qs.qs, quads, quad.Object),
+		iterator.NewFixed(labels...),
+	))
+}
+
+func (qs *QuadStore) QuadsAllIterator() iterator.Shape {
+	return qs.allQuads()
+}
+
+func (qs *QuadStore) checkQuad(q quad.Quad) error {
+	if !qs.Allowed(q.Label) {
+		return fmt.Errorf("%w: %v", ErrLabelNotAllowed, q.Label)
+	}
+	return nil
+}
+
+func (qs *QuadStore) ApplyDeltas(in []graph.Delta, opts graph.IgnoreOpts) error {
+	for _, d := range in {
+		if err := qs.checkQuad(d.Quad); err != nil {
+			return err
+		}
+	}
+	return qs.qs.ApplyDeltas(in, opts)
+}
+
+func (qs *QuadStore) NewQuadWriter() (quad.WriteCloser, error) {
+	w, err := qs.qs.NewQuadWriter()
+	if err != nil {
+		return nil, err
+	}
+	return &quadWriter{qs: qs, w: w}, nil
+}
+
+// Close does nothing. The underlying quad store is not closed, thus multiple scopes can share it.
+func (qs *QuadStore) Close() error {
+	return nil
+}
+
+type quadWriter struct {
+	qs *QuadStore
+	w  quad.WriteCloser
+}
+
+func (w *quadWriter) WriteQuad(q quad.Quad) error {
+	if err := w.qs.checkQuad(q); err != nil {
+		return err
+	}
+	return w.w.WriteQuad(q)
+}
+
+func (w *quadWriter) WriteQuads(buf []quad.Quad) (int, error) {
+	for _, q := range buf {
+		if err := w.qs.checkQuad(q); err != nil {
+			return 0, err
+		}
+	}
+	return w.w.WriteQuads(buf)
+}
+
+func (w *quadWriter) Close() error {
+	return w.w.Close()
+}
+
+// RewriteShape implements shape.Rewriter. Shapes are rewritten by the underlying quad store, if it supports it,
+// and iterators are still restricted by the scope.
+func (qs *QuadStore) RewriteShape(ctx context.Context, s shape.Shape) (shape.Shape, graph.QuadStore) {
+	rw, ok := qs.qs.(shape.Rewriter)
+	if !ok {
+		return s, qs
+	}
+	s, base := rw.RewriteShape(ctx, s)
+	return s, qs.wrap(base)
+}
+
+// WithReasoning implements query.Reasoner. It returns the scope itself if the underlying quad store doesn't infer quads.
+func (qs *QuadStore) WithReasoning(on bool) graph.QuadStore {
+	r, ok := qs.qs.(query.Reasoner)
+	if !ok {
+		return qs
+	}
+	return qs.wrap(r.WithReasoning(on))
+}
+
+// Ping implements graph.Pinger.
+func (qs *QuadStore) Ping(ctx context.Context) error {
+	return graph.Ping(ctx, qs.qs)
+}
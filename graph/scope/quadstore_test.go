@@ -0,0 +1,155 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scope_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/graph/reasoning"
+	"github.com/cayleygraph/cayley/graph/refs"
+	"github.com/cayleygraph/cayley/graph/scope"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/path"
+)
+
+var quads = []quad.Quad{
+	quad.MakeIRI("alice", "follows", "bob", "acme"),
+	quad.MakeIRI("bob", "follows", "carol", "acme"),
+	quad.MakeIRI("alice", "follows", "dave", "globex"),
+	quad.MakeIRI("dave", "likes", "erin", "globex"),
+	quad.MakeIRI("erin", "type", "Person", ""),
+}
+
+func names(t testing.TB, qs graph.QuadStore, it iterator.Shape) []string {
+	ctx := context.TODO()
+	var out []string
+	err := iterator.Iterate(ctx, it).EachValue(qs, func(v quad.Value) error {
+		out = append(out, quad.StringOf(v))
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(out)
+	return out
+}
+
+func allQuads(t testing.TB, qs graph.QuadStore) []quad.Quad {
+	out, err := quad.ReadAll(graph.NewQuadStoreReader(qs))
+	require.NoError(t, err)
+	sort.Sort(quad.ByQuadString(out))
+	return out
+}
+
+func TestScope(t *testing.T) {
+	ctx := context.TODO()
+	base := memstore.New(quads...)
+	qs := scope.New(base, quad.IRI("acme"), quad.IRI("missing"))
+
+	require.Equal(t, []quad.Quad{quads[0], quads[1]}, allQuads(t, qs))
+	require.Equal(t, []string{"<acme>", "<alice>", "<bob>", "<carol>", "<follows>"}, names(t, qs, qs.NodesAllIterator()))
+
+	st, err := qs.Stats(ctx, true)
+	require.NoError(t, err)
+	require.Equal(t, graph.Stats{
+		Nodes: refs.Size{Value: 5, Exact: true},
+		Quads: refs.Size{Value: 2, Exact: true},
+	}, st)
+
+	for _, v := range []string{"dave", "erin", "globex", "likes", "Person"} {
+		r, err := qs.ValueOf(quad.IRI(v))
+		require.NoError(t, err)
+		require.Nil(t, r, v)
+	}
+	globex, err := base.ValueOf(quad.IRI("globex"))
+	require.NoError(t, err)
+	require.Equal(t, []string(nil), names(t, qs, qs.QuadIterator(quad.Label, globex)))
+
+	// alice follows dave in another label
+	out, err := path.StartPath(qs, quad.IRI("alice")).Out(quad.IRI("follows")).Iterate(ctx).AllValues(qs)
+	require.NoError(t, err)
+	require.Equal(t, []quad.Value{quad.IRI("bob")}, out)
+
+	// quads outside of the scope are hidden even when referenced directly
+	it := base.QuadIterator(quad.Label, globex).Iterate()
+	require.True(t, it.Next(ctx))
+	q, err := qs.Quad(it.Result())
+	require.NoError(t, err)
+	require.Equal(t, quad.Quad{}, q)
+	it.Close()
+}
+
+func TestScopeWrite(t *testing.T) {
+	base := memstore.New(quads...)
+	qs := scope.New(base, quad.IRI("acme"))
+
+	add := quad.MakeIRI("carol", "follows", "alice", "acme")
+	err := qs.ApplyDeltas([]graph.Delta{
+		{Quad: add, Action: graph.Add},
+		{Quad: quads[2], Action: graph.Delete},
+	}, graph.IgnoreOpts{})
+	require.True(t, errors.Is(err, scope.ErrLabelNotAllowed), "%v", err)
+	require.Len(t, allQuads(t, base), len(quads))
+
+	for _, q := range []quad.Quad{quads[4], quad.MakeIRI("a", "b", "c", "globex")} {
+		err = qs.ApplyDeltas([]graph.Delta{{Quad: q, Action: graph.Add}}, graph.IgnoreOpts{})
+		require.True(t, errors.Is(err, scope.ErrLabelNotAllowed), "%v", err)
+	}
+
+	// a new label added to the store after the scope was created
+	qs = scope.New(base, quad.IRI("acme"), quad.IRI("initech"))
+	w, err := qs.NewQuadWriter()
+	require.NoError(t, err)
+	added := quad.MakeIRI("frank", "follows", "alice", "initech")
+	require.NoError(t, w.WriteQuad(added))
+	_, err = w.WriteQuads([]quad.Quad{quads[3]})
+	require.True(t, errors.Is(err, scope.ErrLabelNotAllowed), "%v", err)
+	require.NoError(t, w.Close())
+
+	exp := []quad.Quad{quads[0], quads[1], added}
+	sort.Sort(quad.ByQuadString(exp))
+	require.Equal(t, exp, allQuads(t, qs))
+}
+
+func TestScopeReasoning(t *testing.T) {
+	ctx := context.TODO()
+	base := memstore.New(
+		quad.MakeIRI("Employee", rdfs.SubClassOf, "Person", ""),
+		quad.MakeIRI("alice", rdf.Type, "Employee", "acme"),
+		quad.MakeIRI("bob", rdf.Type, "Employee", "globex"),
+	)
+	rs, err := reasoning.New(ctx, base)
+	require.NoError(t, err)
+	qs := scope.New(rs, quad.IRI("acme"))
+
+	people := func(qs graph.QuadStore) []quad.Value {
+		out, err := path.StartPath(qs).Has(quad.IRI(rdf.Type), quad.IRI("Person")).Iterate(ctx).AllValues(qs)
+		require.NoError(t, err)
+		return out
+	}
+	// inferred quads are restricted by the scope as well
+	require.Equal(t, []quad.Value{quad.IRI("alice")}, people(qs))
+	require.Empty(t, people(query.QuadStoreFor(qs, query.Options{NoReasoning: true})))
+	require.Equal(t, []quad.Value{quad.IRI("alice")}, people(query.QuadStoreFor(qs, query.Options{})))
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/cayleygraph/cayley/graph/iterator"
 	"github.com/cayleygraph/cayley/graph/refs"
 	"github.com/cayleygraph/cayley/query/shape"
+	"github.com/cayleygraph/cayley/trace"
 	"github.com/cayleygraph/quad"
 )
 
@@ -46,13 +47,25 @@ func (qs *QuadStore) prepareQuery(s Shape) (string, []interface{}) {
 
 func (qs *QuadStore) QueryRow(ctx context.Context, s Shape) *sql.Row {
 	qu, vals := qs.prepareQuery(s)
-	return qs.db.QueryRowContext(ctx, qu, vals...)
+	ctx, span := trace.Start(ctx, "sql.query")
+	span.SetAttr("query", qu)
+	defer span.End()
+	done := qs.observe(opQuery)
+	row := qs.db.QueryRowContext(ctx, qu, vals...)
+	done(row.Err())
+	return row
 }
 
 func (qs *QuadStore) Query(ctx context.Context, s Shape) (*sql.Rows, error) {
 	qu, vals := qs.prepareQuery(s)
+	ctx, span := trace.Start(ctx, "sql.query")
+	span.SetAttr("query", qu)
+	defer span.End()
+	done := qs.observe(opQuery)
 	rows, err := qs.db.QueryContext(ctx, qu, vals...)
+	done(err)
 	if err != nil {
+		span.SetError(err)
 		return nil, fmt.Errorf("sql query failed: %v\nquery: %v", err, qu)
 	}
 	return rows, nil
@@ -0,0 +1,35 @@
+package sql
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Operations reported in metrics.
+const (
+	opQuery       = "query"
+	opApplyDeltas = "apply_deltas"
+	opStats       = "stats"
+)
+
+var (
+	mQuerySeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "cayley_sql_query_seconds",
+		Help: "Time to execute an SQL query or a write transaction.",
+	}, []string{"driver", "op"})
+	mQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cayley_sql_query_errors",
+		Help: "Number of failed SQL queries and write transactions.",
+	}, []string{"driver", "op"})
+)
+
+// observe starts a timer for an SQL operation. The returned function must be called with the result of the operation.
+func (qs *QuadStore) observe(op string) func(err error) {
+	t := prometheus.NewTimer(mQuerySeconds.WithLabelValues(qs.flavor.Driver, op))
+	return func(err error) {
+		t.ObserveDuration()
+		if err != nil {
+			mQueryErrors.WithLabelValues(qs.flavor.Driver, op).Inc()
+		}
+	}
+}
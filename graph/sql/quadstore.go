@@ -396,6 +396,13 @@ func (w *quadWriter) Close() error {
 }
 
 func (qs *QuadStore) ApplyDeltas(in []graph.Delta, opts graph.IgnoreOpts) error {
+	done := qs.observe(opApplyDeltas)
+	err := qs.applyDeltas(in, opts)
+	done(err)
+	return err
+}
+
+func (qs *QuadStore) applyDeltas(in []graph.Delta, opts graph.IgnoreOpts) error {
 	// first calculate values ref deltas
 	deltas := graphlog.SplitDeltas(in)
 
@@ -753,11 +760,12 @@ func (qs *QuadStore) Stats(ctx context.Context, exact bool) (graph.Stats, error)
 		st.Quads.Exact = false
 		st.Nodes.Exact = false
 	}
-	err := qs.db.QueryRow(query("quads")).Scan(&st.Quads.Value)
-	if err != nil {
-		return graph.Stats{}, err
+	done := qs.observe(opStats)
+	err := qs.db.QueryRowContext(ctx, query("quads")).Scan(&st.Quads.Value)
+	if err == nil {
+		err = qs.db.QueryRowContext(ctx, query("nodes")).Scan(&st.Nodes.Value)
 	}
-	err = qs.db.QueryRow(query("nodes")).Scan(&st.Nodes.Value)
+	done(err)
 	if err != nil {
 		return graph.Stats{}, err
 	}
@@ -770,6 +778,26 @@ func (qs *QuadStore) Stats(ctx context.Context, exact bool) (graph.Stats, error)
 	return st, nil
 }
 
+// Ping implements graph.Pinger.
+func (qs *QuadStore) Ping(ctx context.Context) error {
+	return qs.db.PingContext(ctx)
+}
+
+// Health implements graph.HealthReporter. It returns the state of the connection pool.
+func (qs *QuadStore) Health(ctx context.Context) (map[string]interface{}, error) {
+	if err := qs.db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+	st := qs.db.Stats()
+	return map[string]interface{}{
+		"open_connections": st.OpenConnections,
+		"in_use":           st.InUse,
+		"idle":             st.Idle,
+		"wait_count":       st.WaitCount,
+		"wait_duration":    st.WaitDuration.String(),
+	}, nil
+}
+
 func (qs *QuadStore) Close() error {
 	return qs.db.Close()
 }
@@ -0,0 +1,75 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"context"
+
+	"github.com/cayleygraph/quad"
+
+	"github.com/cayleygraph/cayley/trace"
+)
+
+// TraceWriter returns a writer that records a span for each change applied to qw, as a child of the span in ctx.
+// If tracing is disabled, qw is returned as-is.
+func TraceWriter(ctx context.Context, qw QuadWriter) QuadWriter {
+	if qw == nil || !trace.Enabled() {
+		return qw
+	}
+	return &traceWriter{QuadWriter: qw, ctx: ctx}
+}
+
+type traceWriter struct {
+	QuadWriter
+	ctx context.Context
+}
+
+// Unwrap returns the underlying writer.
+func (w *traceWriter) Unwrap() QuadWriter {
+	return w.QuadWriter
+}
+
+func (w *traceWriter) apply(deltas int, fnc func() error) error {
+	_, span := trace.Start(w.ctx, "graph.apply_deltas")
+	defer span.End()
+	span.SetAttr("deltas", deltas)
+	err := fnc()
+	span.SetError(err)
+	return err
+}
+
+func (w *traceWriter) AddQuad(q quad.Quad) error {
+	return w.apply(1, func() error { return w.QuadWriter.AddQuad(q) })
+}
+
+func (w *traceWriter) AddQuadSet(quads []quad.Quad) error {
+	return w.apply(len(quads), func() error { return w.QuadWriter.AddQuadSet(quads) })
+}
+
+func (w *traceWriter) RemoveQuad(q quad.Quad) error {
+	return w.apply(1, func() error { return w.QuadWriter.RemoveQuad(q) })
+}
+
+func (w *traceWriter) ApplyTransaction(tx *Transaction) error {
+	return w.apply(len(tx.Deltas), func() error { return w.QuadWriter.ApplyTransaction(tx) })
+}
+
+func (w *traceWriter) RemoveNode(v quad.Value) error {
+	_, span := trace.Start(w.ctx, "graph.remove_node")
+	defer span.End()
+	err := w.QuadWriter.RemoveNode(v)
+	span.SetError(err)
+	return err
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/trace"
+	"github.com/cayleygraph/cayley/writer"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+}
+
+func TestTraceWriter(t *testing.T) {
+	qw, err := writer.NewSingle(memstore.New(), graph.IgnoreOpts{})
+	require.NoError(t, err)
+	require.Equal(t, qw, graph.TraceWriter(context.Background(), qw))
+
+	rec := &spanRecorder{}
+	trace.SetExporter(rec)
+	defer trace.SetExporter(nil)
+
+	ctx, parent := trace.Start(context.Background(), "parent")
+	w := graph.TraceWriter(ctx, qw)
+	require.NoError(t, w.AddQuadSet([]quad.Quad{
+		quad.MakeIRI("a", "b", "c", ""),
+		quad.MakeIRI("a", "b", "d", ""),
+	}))
+	require.Error(t, w.RemoveNode(quad.IRI("e")))
+	parent.End()
+
+	require.Len(t, rec.spans, 3)
+	apply, remove := rec.spans[0], rec.spans[1]
+	require.Equal(t, "graph.apply_deltas", apply.Name)
+	require.Equal(t, parent.Context().SpanID.String(), apply.ParentID)
+	require.EqualValues(t, 2, apply.Attrs["deltas"])
+	require.Equal(t, "graph.remove_node", remove.Name)
+	require.Equal(t, graph.ErrNodeNotExists.Error(), remove.Error)
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package union
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/refs"
+)
+
+var _ iterator.Shape = (*storeIterator)(nil)
+
+// storeIterator wraps an iterator of a member store and converts
+// references returned by it to the union references.
+type storeIterator struct {
+	qs    *QuadStore
+	store int
+	nodes bool
+	sub   iterator.Shape
+}
+
+func (qs *QuadStore) newNodes(i int, sub iterator.Shape) *storeIterator {
+	return &storeIterator{qs: qs, store: i, nodes: true, sub: sub}
+}
+
+func (qs *QuadStore) newQuads(i int, sub iterator.Shape) *storeIterator {
+	return &storeIterator{qs: qs, store: i, nodes: false, sub: sub}
+}
+
+func (it *storeIterator) Iterate() iterator.Scanner {
+	return &storeNext{storeIterator: it, sub: it.sub.Iterate()}
+}
+
+func (it *storeIterator) Lookup() iterator.Index {
+	return &storeContains{storeIterator: it, sub: it.sub.Lookup()}
+}
+
+func (it *storeIterator) String() string {
+	return fmt.Sprintf("Union(%d)", it.store)
+}
+
+func (it *storeIterator) SubIterators() []iterator.Shape {
+	return []iterator.Shape{it.sub}
+}
+
+func (it *storeIterator) Optimize(ctx context.Context) (iterator.Shape, bool) {
+	sub, changed := it.sub.Optimize(ctx)
+	if !changed {
+		return it, false
+	}
+	if iterator.IsNull(sub) {
+		return sub, true
+	}
+	it.sub = sub
+	return it, true
+}
+
+func (it *storeIterator) Stats(ctx context.Context) (iterator.Costs, error) {
+	st, err := it.sub.Stats(ctx)
+	if it.nodes {
+		// converting a reference requires a value lookup in the member store
+		st.NextCost++
+		st.ContainsCost++
+	}
+	return st, err
+}
+
+func (it *storeIterator) lift(r refs.Ref) (refs.Ref, error) {
+	if r == nil {
+		return nil, nil
+	} else if !it.nodes {
+		return quadRef{store: it.store, ref: r}, nil
+	}
+	n, err := it.qs.liftNode(it.store, r)
+	if err != nil || n == nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (it *storeIterator) liftTags(src map[string]refs.Ref, dst map[string]refs.Ref) {
+	for k, v := range src {
+		// tags of the member iterators can only point to nodes
+		if n, err := it.qs.liftNode(it.store, v); err == nil && n != nil {
+			dst[k] = n
+		}
+	}
+}
+
+type storeNext struct {
+	*storeIterator
+	sub iterator.Scanner
+	cur refs.Ref
+	err error
+}
+
+func (it *storeNext) Next(ctx context.Context) bool {
+	it.cur = nil
+	for it.sub.Next(ctx) {
+		r, err := it.lift(it.sub.Result())
+		if err != nil {
+			it.err = err
+			return false
+		} else if r == nil {
+			continue
+		}
+		it.cur = r
+		return true
+	}
+	it.err = it.sub.Err()
+	return false
+}
+
+func (it *storeNext) NextPath(ctx context.Context) bool {
+	return it.sub.NextPath(ctx)
+}
+
+func (it *storeNext) TagResults(dst map[string]refs.Ref) {
+	tags := make(map[string]refs.Ref)
+	it.sub.TagResults(tags)
+	it.liftTags(tags, dst)
+}
+
+func (it *storeNext) Result() refs.Ref {
+	return it.cur
+}
+
+func (it *storeNext) Err() error {
+	return it.err
+}
+
+func (it *storeNext) Close() error {
+	return it.sub.Close()
+}
+
+func (it *storeNext) String() string {
+	return fmt.Sprintf("UnionNext(%d)", it.store)
+}
+
+type storeContains struct {
+	*storeIterator
+	sub iterator.Index
+	cur refs.Ref
+	err error
+}
+
+func (it *storeContains) Contains(ctx context.Context, v refs.Ref) bool {
+	it.cur = nil
+	var r refs.Ref
+	if it.nodes {
+		n := it.qs.asNode(v)
+		if n == nil {
+			return false
+		}
+		var err error
+		r, err = it.qs.memberRef(it.store, n)
+		if err != nil {
+			it.err = err
+			return false
+		}
+	} else if q, ok := v.(quadRef); ok && q.store == it.store {
+		r = q.ref
+	}
+	if r == nil || !it.sub.Contains(ctx, r) {
+		return false
+	}
+	it.cur = v
+	return true
+}
+
+func (it *storeContains) NextPath(ctx context.Context) bool {
+	return it.sub.NextPath(ctx)
+}
+
+func (it *storeContains) TagResults(dst map[string]refs.Ref) {
+	tags := make(map[string]refs.Ref)
+	it.sub.TagResults(tags)
+	it.liftTags(tags, dst)
+}
+
+func (it *storeContains) Result() refs.Ref {
+	return it.cur
+}
+
+func (it *storeContains) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.sub.Err()
+}
+
+func (it *storeContains) Close() error {
+	return it.sub.Close()
+}
+
+func (it *storeContains) String() string {
+	return fmt.Sprintf("UnionContains(%d)", it.store)
+}
+
+var _ iterator.Shape = (*allIterator)(nil)
+
+// allIterator combines all iterators of member stores. Member iterators
+// are already optimal, so it's never changed by the optimizer.
+type allIterator struct {
+	nodes bool
+	sub   iterator.Shape
+}
+
+func (it *allIterator) Iterate() iterator.Scanner {
+	return it.sub.Iterate()
+}
+
+func (it *allIterator) Lookup() iterator.Index {
+	return it.sub.Lookup()
+}
+
+func (it *allIterator) String() string {
+	if it.nodes {
+		return "UnionNodesAll"
+	}
+	return "UnionQuadsAll"
+}
+
+func (it *allIterator) SubIterators() []iterator.Shape {
+	return []iterator.Shape{it.sub}
+}
+
+func (it *allIterator) Optimize(ctx context.Context) (iterator.Shape, bool) {
+	return it, false
+}
+
+func (it *allIterator) Stats(ctx context.Context) (iterator.Costs, error) {
+	return it.sub.Stats(ctx)
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package union
+
+import (
+	"fmt"
+
+	"github.com/cayleygraph/quad"
+
+	"github.com/cayleygraph/cayley/graph"
+)
+
+const (
+	// OptStores is a list of member stores. Each entry is an object with
+	// backend, address, options, label and primary keys.
+	OptStores = "stores"
+)
+
+func newFromOptions(opts graph.Options) (*QuadStore, error) {
+	raw, ok := opts[OptStores]
+	if !ok {
+		return nil, ErrNoStores
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Invalid %s parameter type from config: %T", OptStores, raw)
+	}
+	var stores []Store
+	closeAll := func() {
+		for _, s := range stores {
+			s.QuadStore.Close()
+		}
+	}
+	for i, v := range list {
+		s, err := storeFromOptions(asOptions(v))
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("union: store %d: %w", i, err)
+		}
+		stores = append(stores, s)
+	}
+	qs, err := New(stores...)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return qs, nil
+}
+
+// asOptions converts a configuration object to graph.Options.
+// Config decoders may produce maps with non-string keys for nested objects.
+func asOptions(v interface{}) graph.Options {
+	switch v := v.(type) {
+	case graph.Options:
+		return v
+	case map[string]interface{}:
+		return graph.Options(v)
+	case map[interface{}]interface{}:
+		o := make(graph.Options, len(v))
+		for k, val := range v {
+			o[fmt.Sprint(k)] = val
+		}
+		return o
+	}
+	return nil
+}
+
+func storeFromOptions(opts graph.Options) (Store, error) {
+	if opts == nil {
+		return Store{}, fmt.Errorf("expected an object")
+	}
+	name, err := opts.StringKey("backend", "")
+	if err != nil {
+		return Store{}, err
+	} else if name == "" {
+		return Store{}, fmt.Errorf("backend is not specified")
+	} else if name == QuadStoreType {
+		return Store{}, fmt.Errorf("nested unions are not supported")
+	}
+	addr, err := opts.StringKey("address", "")
+	if err != nil {
+		return Store{}, err
+	}
+	label, err := opts.StringKey("label", "")
+	if err != nil {
+		return Store{}, err
+	}
+	primary, err := opts.BoolKey("primary", false)
+	if err != nil {
+		return Store{}, err
+	}
+	qs, err := graph.NewQuadStore(name, addr, asOptions(opts["options"]))
+	if err != nil {
+		return Store{}, err
+	}
+	s := Store{QuadStore: qs, Primary: primary}
+	if label != "" {
+		s.Label = quad.StringToValue(label)
+	}
+	return s, nil
+}
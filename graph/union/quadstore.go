@@ -0,0 +1,471 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package union implements a federated QuadStore that presents several
+// quad stores as a single graph.
+//
+// Nodes are merged across member stores by their quad.Value, so a path
+// that starts in one store can continue in another. Quads are not merged:
+// an identical quad stored in two members is returned twice.
+package union
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cayleygraph/quad"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/refs"
+)
+
+const QuadStoreType = "union"
+
+func init() {
+	graph.RegisterQuadStore(QuadStoreType, graph.QuadStoreRegistration{
+		NewFunc: func(_ string, opts graph.Options) (graph.QuadStore, error) {
+			return newFromOptions(opts)
+		},
+		UpgradeFunc:  nil,
+		InitFunc:     nil,
+		IsPersistent: false,
+	})
+}
+
+var (
+	ErrNoStores      = errors.New("union: at least one store must be specified")
+	ErrManyPrimaries = errors.New("union: only one store can be a primary")
+	ErrReadOnly      = errors.New("union: no primary store to write to")
+	ErrLabelMismatch = errors.New("union: quad label does not match the label of the primary store")
+)
+
+// Store is a member of the union.
+type Store struct {
+	// QuadStore is the underlying graph.
+	QuadStore graph.QuadStore
+	// Label is an optional label to tag quads of the member with.
+	// If set, all quads of the store are seen with this label, and
+	// labels stored in the member itself are hidden.
+	Label quad.Value
+	// Primary marks the store that will receive all writes.
+	Primary bool
+}
+
+var (
+	_ graph.QuadStore      = (*QuadStore)(nil)
+	_ refs.PreFetchedValue = (*nodeRef)(nil)
+)
+
+// QuadStore is a union of multiple quad stores.
+type QuadStore struct {
+	stores  []Store
+	labels  []refs.ValueHash
+	primary int
+}
+
+// New creates a union of given quad stores. Stores are not copied and will be
+// closed when the union is closed.
+func New(stores ...Store) (*QuadStore, error) {
+	if len(stores) == 0 {
+		return nil, ErrNoStores
+	}
+	qs := &QuadStore{
+		stores:  append([]Store{}, stores...),
+		labels:  make([]refs.ValueHash, len(stores)),
+		primary: -1,
+	}
+	for i, s := range stores {
+		if s.QuadStore == nil {
+			return nil, fmt.Errorf("union: store %d is nil", i)
+		}
+		qs.labels[i] = refs.HashOf(s.Label)
+		if !s.Primary {
+			continue
+		}
+		if qs.primary >= 0 {
+			return nil, ErrManyPrimaries
+		}
+		qs.primary = i
+	}
+	return qs, nil
+}
+
+// Stores returns all member stores of the union.
+func (qs *QuadStore) Stores() []Store {
+	return append([]Store{}, qs.stores...)
+}
+
+// nodeRef is a node reference shared by all member stores.
+type nodeRef struct {
+	val  quad.Value
+	hash refs.ValueHash
+	// refs for each member store; only valid if resolved is set,
+	// or for a single member the node was received from
+	refs     []graph.Ref
+	resolved bool
+}
+
+func (n *nodeRef) Key() interface{}   { return n.hash }
+func (n *nodeRef) NameOf() quad.Value { return n.val }
+
+// quadRef is a reference to a quad in one of the member stores.
+type quadRef struct {
+	store int
+	ref   graph.Ref
+}
+
+type quadKey struct {
+	store int
+	key   interface{}
+}
+
+func (q quadRef) Key() interface{} {
+	return quadKey{store: q.store, key: refs.ToKey(q.ref)}
+}
+
+func (qs *QuadStore) tagged(i int) bool {
+	return qs.stores[i].Label != nil
+}
+
+// asNode converts any node reference to a union node.
+func (qs *QuadStore) asNode(v graph.Ref) *nodeRef {
+	switch v := v.(type) {
+	case *nodeRef:
+		return v
+	case refs.PreFetchedValue:
+		val := v.NameOf()
+		if val == nil {
+			return nil
+		}
+		return &nodeRef{val: val, hash: refs.HashOf(val)}
+	}
+	return nil
+}
+
+// liftNode converts a node reference of a given member store to a union node.
+func (qs *QuadStore) liftNode(i int, ref graph.Ref) (*nodeRef, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	val, err := qs.stores[i].QuadStore.NameOf(ref)
+	if err != nil {
+		return nil, err
+	} else if val == nil {
+		return nil, nil
+	}
+	n := &nodeRef{val: val, hash: refs.HashOf(val), refs: make([]graph.Ref, len(qs.stores))}
+	n.refs[i] = ref
+	return n, nil
+}
+
+// memberRef resolves a node reference in a given member store.
+// It returns nil if the node is not present in the store.
+func (qs *QuadStore) memberRef(i int, n *nodeRef) (graph.Ref, error) {
+	if n.refs != nil {
+		if r := n.refs[i]; r != nil || n.resolved {
+			return r, nil
+		}
+	}
+	return qs.stores[i].QuadStore.ValueOf(n.val)
+}
+
+func (qs *QuadStore) ValueOf(v quad.Value) (graph.Ref, error) {
+	if v == nil {
+		return nil, nil
+	}
+	n := &nodeRef{
+		val: v, hash: refs.HashOf(v),
+		refs:     make([]graph.Ref, len(qs.stores)),
+		resolved: true,
+	}
+	found := false
+	for i, s := range qs.stores {
+		if qs.tagged(i) && qs.labels[i] == n.hash {
+			found = true
+		}
+		r, err := s.QuadStore.ValueOf(v)
+		if err != nil {
+			return nil, err
+		} else if r != nil {
+			n.refs[i] = r
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	return n, nil
+}
+
+func (qs *QuadStore) NameOf(v graph.Ref) (quad.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if n := qs.asNode(v); n != nil {
+		return n.val, nil
+	}
+	return nil, nil
+}
+
+func (qs *QuadStore) Quad(v graph.Ref) (quad.Quad, error) {
+	r, ok := v.(quadRef)
+	if !ok {
+		return quad.Quad{}, nil
+	}
+	q, err := qs.stores[r.store].QuadStore.Quad(r.ref)
+	if err != nil {
+		return q, err
+	}
+	if qs.tagged(r.store) && q.IsValid() {
+		q.Label = qs.stores[r.store].Label
+	}
+	return q, nil
+}
+
+func (qs *QuadStore) QuadDirection(v graph.Ref, d quad.Direction) (graph.Ref, error) {
+	r, ok := v.(quadRef)
+	if !ok {
+		return nil, nil
+	}
+	if d == quad.Label && qs.tagged(r.store) {
+		l := qs.stores[r.store].Label
+		return &nodeRef{val: l, hash: qs.labels[r.store]}, nil
+	}
+	ref, err := qs.stores[r.store].QuadStore.QuadDirection(r.ref, d)
+	if err != nil || ref == nil {
+		return nil, err
+	}
+	n, err := qs.liftNode(r.store, ref)
+	if err != nil || n == nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (qs *QuadStore) QuadIterator(d quad.Direction, v graph.Ref) iterator.Shape {
+	n := qs.asNode(v)
+	if n == nil {
+		return iterator.NewNull()
+	}
+	var subs []iterator.Shape
+	for i, s := range qs.stores {
+		if d == quad.Label && qs.tagged(i) {
+			if qs.labels[i] == n.hash {
+				subs = append(subs, qs.newQuads(i, s.QuadStore.QuadsAllIterator()))
+			}
+			continue
+		}
+		r, err := qs.memberRef(i, n)
+		if err != nil {
+			return iterator.NewError(err)
+		} else if r == nil {
+			continue
+		}
+		subs = append(subs, qs.newQuads(i, s.QuadStore.QuadIterator(d, r)))
+	}
+	return qs.or(subs, false)
+}
+
+func (qs *QuadStore) QuadIteratorSize(ctx context.Context, d quad.Direction, v graph.Ref) (refs.Size, error) {
+	n := qs.asNode(v)
+	sz := refs.Size{Exact: true}
+	if n == nil {
+		return sz, nil
+	}
+	for i, s := range qs.stores {
+		var (
+			ssz refs.Size
+			err error
+		)
+		if d == quad.Label && qs.tagged(i) {
+			if qs.labels[i] != n.hash {
+				continue
+			}
+			var st graph.Stats
+			st, err = s.QuadStore.Stats(ctx, false)
+			ssz = st.Quads
+		} else {
+			var r graph.Ref
+			r, err = qs.memberRef(i, n)
+			if err != nil {
+				return sz, err
+			} else if r == nil {
+				continue
+			}
+			ssz, err = s.QuadStore.QuadIteratorSize(ctx, d, r)
+		}
+		if err != nil {
+			return sz, err
+		}
+		sz.Value += ssz.Value
+		sz.Exact = sz.Exact && ssz.Exact
+	}
+	return sz, nil
+}
+
+func (qs *QuadStore) Stats(ctx context.Context, exact bool) (graph.Stats, error) {
+	var st graph.Stats
+	st.Nodes.Exact = len(qs.stores) == 1
+	st.Quads.Exact = true
+	for _, s := range qs.stores {
+		sst, err := s.QuadStore.Stats(ctx, exact)
+		if err != nil {
+			return st, err
+		}
+		// nodes might be shared between stores, so the sum is only an upper bound
+		st.Nodes.Value += sst.Nodes.Value
+		st.Nodes.Exact = st.Nodes.Exact && sst.Nodes.Exact
+		st.Quads.Value += sst.Quads.Value
+		st.Quads.Exact = st.Quads.Exact && sst.Quads.Exact
+	}
+	if exact && !st.Nodes.Exact {
+		// calculate the exact number of unique nodes
+		n, err := iterator.Iterate(ctx, qs.NodesAllIterator()).Count()
+		if err != nil {
+			return st, err
+		}
+		st.Nodes = refs.Size{Value: n, Exact: true}
+	}
+	return st, nil
+}
+
+func (qs *QuadStore) NodesAllIterator() iterator.Shape {
+	subs := make([]iterator.Shape, 0, len(qs.stores)+1)
+	for i, s := range qs.stores {
+		subs = append(subs, qs.newNodes(i, s.QuadStore.NodesAllIterator()))
+	}
+	var labels []graph.Ref
+	for i, s := range qs.stores {
+		if qs.tagged(i) {
+			labels = append(labels, &nodeRef{val: s.Label, hash: qs.labels[i]})
+		}
+	}
+	if len(labels) != 0 {
+		subs = append(subs, iterator.NewFixed(labels...))
+	}
+	return &allIterator{nodes: true, sub: qs.or(subs, true)}
+}
+
+func (qs *QuadStore) QuadsAllIterator() iterator.Shape {
+	subs := make([]iterator.Shape, 0, len(qs.stores))
+	for i, s := range qs.stores {
+		subs = append(subs, qs.newQuads(i, s.QuadStore.QuadsAllIterator()))
+	}
+	return &allIterator{nodes: false, sub: qs.or(subs, false)}
+}
+
+func (qs *QuadStore) or(subs []iterator.Shape, unique bool) iterator.Shape {
+	switch len(subs) {
+	case 0:
+		return iterator.NewNull()
+	case 1:
+		return subs[0]
+	}
+	var it iterator.Shape = iterator.NewOr(subs...)
+	if unique {
+		it = iterator.NewUnique(it)
+	}
+	return it
+}
+
+func (qs *QuadStore) primaryStore() (Store, error) {
+	if qs.primary < 0 {
+		return Store{}, ErrReadOnly
+	}
+	return qs.stores[qs.primary], nil
+}
+
+// untag removes the label of the primary store from quads before writing them.
+func untag(q quad.Quad, label quad.Value) (quad.Quad, error) {
+	if label == nil || q.Label == nil {
+		return q, nil
+	} else if refs.HashOf(q.Label) != refs.HashOf(label) {
+		return q, ErrLabelMismatch
+	}
+	q.Label = nil
+	return q, nil
+}
+
+func (qs *QuadStore) ApplyDeltas(in []graph.Delta, opts graph.IgnoreOpts) error {
+	s, err := qs.primaryStore()
+	if err != nil {
+		return err
+	}
+	if s.Label != nil {
+		out := make([]graph.Delta, 0, len(in))
+		for _, d := range in {
+			q, err := untag(d.Quad, s.Label)
+			if err != nil {
+				return &graph.DeltaError{Delta: d, Err: err}
+			}
+			out = append(out, graph.Delta{Quad: q, Action: d.Action})
+		}
+		in = out
+	}
+	return s.QuadStore.ApplyDeltas(in, opts)
+}
+
+func (qs *QuadStore) NewQuadWriter() (quad.WriteCloser, error) {
+	s, err := qs.primaryStore()
+	if err != nil {
+		return nil, err
+	}
+	w, err := s.QuadStore.NewQuadWriter()
+	if err != nil || s.Label == nil {
+		return w, err
+	}
+	return &untagWriter{w: w, label: s.Label}, nil
+}
+
+type untagWriter struct {
+	w     quad.WriteCloser
+	label quad.Value
+	buf   []quad.Quad
+}
+
+func (w *untagWriter) WriteQuad(q quad.Quad) error {
+	q, err := untag(q, w.label)
+	if err != nil {
+		return err
+	}
+	return w.w.WriteQuad(q)
+}
+
+func (w *untagWriter) WriteQuads(buf []quad.Quad) (int, error) {
+	w.buf = w.buf[:0]
+	for i, q := range buf {
+		q, err := untag(q, w.label)
+		if err != nil {
+			return i, err
+		}
+		w.buf = append(w.buf, q)
+	}
+	return w.w.WriteQuads(w.buf)
+}
+
+func (w *untagWriter) Close() error {
+	return w.w.Close()
+}
+
+func (qs *QuadStore) Close() error {
+	var last error
+	for _, s := range qs.stores {
+		if err := s.QuadStore.Close(); err != nil {
+			last = err
+		}
+	}
+	return last
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package union_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/graphtest"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/graph/union"
+	"github.com/cayleygraph/cayley/query/path"
+)
+
+func TestUnion(t *testing.T) {
+	graphtest.TestAll(t, func(t testing.TB) (graph.QuadStore, graph.Options) {
+		qs, err := union.New(
+			union.Store{QuadStore: memstore.New(), Primary: true},
+			union.Store{QuadStore: memstore.New()},
+		)
+		require.NoError(t, err)
+		return qs, nil
+	}, &graphtest.Config{
+		AlwaysRunIntegration: true,
+	})
+}
+
+func newTestUnion(t testing.TB) *union.QuadStore {
+	ontology := memstore.New(
+		quad.MakeIRI("Person", "subClassOf", "Agent", ""),
+	)
+	tenant := memstore.New(
+		quad.MakeIRI("alice", "type", "Person", ""),
+		quad.MakeIRI("bob", "type", "Person", ""),
+		quad.MakeIRI("alice", "follows", "bob", ""),
+	)
+	qs, err := union.New(
+		union.Store{QuadStore: ontology, Label: quad.IRI("ontology")},
+		union.Store{QuadStore: tenant, Primary: true},
+	)
+	require.NoError(t, err)
+	return qs
+}
+
+func runPath(t testing.TB, qs graph.QuadStore, p *path.Path) []quad.Value {
+	var out []quad.Value
+	err := p.Iterate(context.TODO()).EachValue(qs, func(v quad.Value) error {
+		out = append(out, v)
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
+
+func TestUnionTraversal(t *testing.T) {
+	qs := newTestUnion(t)
+	defer qs.Close()
+
+	p := path.StartPath(qs, quad.IRI("alice")).Out(quad.IRI("type")).Out(quad.IRI("subClassOf"))
+	require.Equal(t, []quad.Value{quad.IRI("Agent")}, runPath(t, qs, p))
+
+	p = path.StartPath(qs, quad.IRI("Agent")).In(quad.IRI("subClassOf")).In(quad.IRI("type"))
+	require.Equal(t, []quad.Value{quad.IRI("alice"), quad.IRI("bob")}, runPath(t, qs, p))
+
+	p = path.StartPath(qs).Has(quad.IRI("type"), quad.IRI("Person"))
+	require.Equal(t, []quad.Value{quad.IRI("alice"), quad.IRI("bob")}, runPath(t, qs, p))
+
+	st, err := qs.Stats(context.TODO(), true)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), st.Quads.Value)
+}
+
+func TestUnionLabels(t *testing.T) {
+	qs := newTestUnion(t)
+	defer qs.Close()
+
+	p := path.StartPath(qs).LabelContext(quad.IRI("ontology")).Out(quad.IRI("subClassOf"))
+	require.Equal(t, []quad.Value{quad.IRI("Agent")}, runPath(t, qs, p))
+
+	var labels []quad.Value
+	r := graph.NewQuadStoreReader(qs)
+	defer r.Close()
+	for {
+		q, err := r.ReadQuad()
+		if err != nil {
+			break
+		}
+		labels = append(labels, q.Label)
+	}
+	require.ElementsMatch(t, []quad.Value{quad.IRI("ontology"), nil, nil, nil}, labels)
+}
+
+func TestUnionWrite(t *testing.T) {
+	qs := newTestUnion(t)
+	defer qs.Close()
+
+	w := graph.NewWriter(mustWriter(t, qs))
+	_, err := w.WriteQuads([]quad.Quad{quad.MakeIRI("bob", "follows", "alice", "")})
+	require.NoError(t, err)
+
+	p := path.StartPath(qs, quad.IRI("bob")).Out(quad.IRI("follows"))
+	require.Equal(t, []quad.Value{quad.IRI("alice")}, runPath(t, qs, p))
+
+	ro, err := union.New(union.Store{QuadStore: memstore.New()})
+	require.NoError(t, err)
+	err = ro.ApplyDeltas([]graph.Delta{{Quad: quad.MakeIRI("a", "b", "c", ""), Action: graph.Add}}, graph.IgnoreOpts{})
+	require.Equal(t, union.ErrReadOnly, err)
+}
+
+func mustWriter(t testing.TB, qs graph.QuadStore) graph.QuadWriter {
+	w, err := graph.NewQuadWriter("single", qs, nil)
+	require.NoError(t, err)
+	return w
+}
@@ -0,0 +1,142 @@
+package inference
+
+import (
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+)
+
+// isNode checks whether value can be a subject of a quad
+func isNode(v quad.Value) bool {
+	switch v.(type) {
+	case quad.IRI, quad.BNode:
+		return true
+	}
+	return false
+}
+
+// Entailments returns all quads that are inferred from a given quad and the schema in the store.
+// Returned quads don't have a label and don't include the quad itself.
+// Rules that join multiple data quads (owl:TransitiveProperty) are not applied.
+func (s *Store) Entailments(q quad.Quad) []quad.Quad {
+	q = normalizeQuad(q)
+	q.Label = nil
+	seen := map[quad.Quad]struct{}{q: {}}
+	var out []quad.Quad
+	add := func(sub quad.Value, pred quad.Value, obj quad.Value) {
+		t := quad.Quad{Subject: sub, Predicate: pred, Object: obj}
+		if _, ok := seen[t]; ok {
+			return
+		}
+		seen[t] = struct{}{}
+		out = append(out, t)
+	}
+	queue := []quad.Quad{q}
+	for len(queue) > 0 {
+		n := len(out)
+		s.entail(queue[0], add)
+		queue = append(queue[1:], out[n:]...)
+	}
+	return out
+}
+
+// entail applies the rules to a single quad
+func (s *Store) entail(q quad.Quad, add func(sub, pred, obj quad.Value)) {
+	var (
+		typ      = quad.IRI(rdf.Type)
+		resource = quad.IRI(rdfs.Resource)
+	)
+	sub, obj := q.Subject, q.Object
+	pred, ok := q.Predicate.(quad.IRI)
+	if !ok {
+		return
+	}
+	// 1, 4a, 4b
+	add(pred, typ, quad.IRI(rdf.Property))
+	add(sub, typ, resource)
+	if isNode(obj) {
+		add(obj, typ, resource)
+	}
+	if p := s.GetProperty(pred); p != nil {
+		// 2, 3
+		for c := range p.domains {
+			add(sub, typ, c.name)
+		}
+		if isNode(obj) {
+			for c := range p.ranges {
+				add(obj, typ, c.name)
+			}
+		}
+		// 7
+		for super := range p.super {
+			add(sub, super.name, obj)
+		}
+		if isNode(obj) {
+			// prp-inv
+			for inv := range p.inverse {
+				add(obj, inv.name, sub)
+			}
+			// prp-symp
+			if p.symmetric {
+				add(obj, pred, sub)
+			}
+		}
+	}
+	switch pred {
+	case rdf.Type:
+		switch obj {
+		case quad.IRI(rdfs.Class):
+			// 8, 10
+			add(sub, quad.IRI(rdfs.SubClassOf), resource)
+			add(sub, quad.IRI(rdfs.SubClassOf), sub)
+		case quad.IRI(rdf.Property):
+			// 6
+			add(sub, quad.IRI(rdfs.SubPropertyOf), sub)
+		case quad.IRI(rdfs.ContainerMembershipProperty):
+			// 12
+			add(sub, quad.IRI(rdfs.SubPropertyOf), quad.IRI(rdfs.Member))
+		case quad.IRI(rdfs.Datatype):
+			// 13
+			add(sub, quad.IRI(rdfs.SubClassOf), quad.IRI(rdfs.Literal))
+		}
+		// 9, cax-eqc
+		if c := s.GetClass(obj); c != nil {
+			for _, super := range c.SuperClasses() {
+				add(sub, typ, super.name)
+			}
+		}
+	case rdfs.SubClassOf:
+		// 11
+		if c := s.GetClass(obj); c != nil {
+			for _, super := range c.SuperClasses() {
+				add(sub, pred, super.name)
+			}
+		}
+	case rdfs.SubPropertyOf:
+		// 5
+		if p := s.GetProperty(obj); p != nil {
+			for super := range p.super {
+				add(sub, pred, super.name)
+			}
+		}
+	case owlEquivalentClass:
+		// scm-eqc
+		add(sub, quad.IRI(rdfs.SubClassOf), obj)
+		add(obj, quad.IRI(rdfs.SubClassOf), sub)
+	case owlSameAs:
+		// eq-sym
+		add(obj, pred, sub)
+	}
+	// eq-rep-s, eq-rep-p, eq-rep-o, eq-trans
+	for _, v := range s.SameAs(sub) {
+		add(v, pred, obj)
+	}
+	for _, v := range s.SameAs(pred) {
+		add(sub, v, obj)
+	}
+	if isNode(obj) {
+		for _, v := range s.SameAs(obj) {
+			add(sub, pred, v)
+		}
+	}
+}
@@ -2,38 +2,112 @@
 //
 // RDFS Rules:
 //
-//		1. (x p y) -> (p rdf:type rdf:Property)
-//		2. (p rdfs:domain c), (x p y) -> (x rdf:type c)
-//		3. (p rdfs:range c), (x p y) -> (y rdf:type c)
-//		4a. (x p y) -> (x rdf:type rdfs:Resource)
-//		4b. (x p y) -> (y rdf:type rdfs:Resource)
-//		5. (p rdfs:subPropertyOf q), (q rdfs:subPropertyOf r) -> (p rdfs:subPropertyOf r)
-//		6. (p rdf:type Property) -> (p rdfs:subPropertyOf p)
-//		7. (p rdf:subPropertyOf q), (x p y) -> (x q y)
-//		8. (c rdf:type rdfs:Class) -> (c rdfs:subClassOf rdfs:Resource)
-//		9. (c rdfs:subClassOf d), (x rdf:type c) -> (x rdf:type d)
-//		10. (c rdf:type rdfs:Class) -> (c rdfs:subClassOf c)
-//		11. (c rdfs:subClassOf d), (d rdfs:subClassOf e) -> (c rdfs:subClassOf e)
-//		12. (p rdf:type rdfs:ContainerMembershipProperty) -> (p rdfs:subPropertyOf rdfs:member)
-//		13. (x rdf:type rdfs:Datatype) -> (x rdfs:subClassOf rdfs:Literal)
+//  1. (x p y) -> (p rdf:type rdf:Property)
+//  2. (p rdfs:domain c), (x p y) -> (x rdf:type c)
+//  3. (p rdfs:range c), (x p y) -> (y rdf:type c)
+//     4a. (x p y) -> (x rdf:type rdfs:Resource)
+//     4b. (x p y) -> (y rdf:type rdfs:Resource)
+//  5. (p rdfs:subPropertyOf q), (q rdfs:subPropertyOf r) -> (p rdfs:subPropertyOf r)
+//  6. (p rdf:type Property) -> (p rdfs:subPropertyOf p)
+//  7. (p rdf:subPropertyOf q), (x p y) -> (x q y)
+//  8. (c rdf:type rdfs:Class) -> (c rdfs:subClassOf rdfs:Resource)
+//  9. (c rdfs:subClassOf d), (x rdf:type c) -> (x rdf:type d)
+//  10. (c rdf:type rdfs:Class) -> (c rdfs:subClassOf c)
+//  11. (c rdfs:subClassOf d), (d rdfs:subClassOf e) -> (c rdfs:subClassOf e)
+//  12. (p rdf:type rdfs:ContainerMembershipProperty) -> (p rdfs:subPropertyOf rdfs:member)
+//  13. (x rdf:type rdfs:Datatype) -> (x rdfs:subClassOf rdfs:Literal)
 //
 // Exported from: https://www.researchgate.net/figure/RDF-RDFS-entailment-rules_tbl1_268419911
 //
-// Implemented here: 1 2 3 5 6 8 10 11
+// All the rules are implemented. Rules 4b and 3 are not applied to literals.
+//
+// OWL 2 RL Rules:
+//
+//	prp-inv. (p owl:inverseOf q), (x p y) -> (y q x), and the reverse
+//	prp-trp. (p rdf:type owl:TransitiveProperty), (x p y), (y p z) -> (x p z)
+//	prp-symp. (p rdf:type owl:SymmetricProperty), (x p y) -> (y p x)
+//	eq-sym, eq-trans. owl:sameAs is symmetric and transitive
+//	eq-rep-s, eq-rep-p, eq-rep-o. (x owl:sameAs y), (x p z) -> (y p z), and the same for predicates and objects
+//	cax-eqc, scm-eqc. (c owl:equivalentClass d) -> (c rdfs:subClassOf d), (d rdfs:subClassOf c)
+//
+// The Store only keeps the schema, thus rules that join multiple data quads (prp-trp) are only
+// applied by Materialize.
+//
+// The store recognizes IRIs of RDF, RDFS and OWL vocabularies both in the short (rdf:type) and the full form.
+// Short forms are used by the store and in inferred quads.
 package inference
 
 import (
+	"strings"
+
 	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/owl"
 	"github.com/cayleygraph/quad/voc/rdf"
 	"github.com/cayleygraph/quad/voc/rdfs"
 )
 
+// OWL vocabulary that is not defined in the owl package.
+const (
+	owlInverseOf          = owl.Prefix + "inverseOf"
+	owlTransitiveProperty = owl.Prefix + "TransitiveProperty"
+	owlSymmetricProperty  = owl.Prefix + "SymmetricProperty"
+	owlSameAs             = owl.Prefix + "sameAs"
+	owlEquivalentClass    = owl.Prefix + "equivalentClass"
+)
+
+// vocabularies are namespaces of IRIs that are normalized by the store.
+var vocabularies = []string{rdf.NS, rdfs.NS, owl.NS}
+
+// isVocabulary checks whether IRI belongs to one of the known vocabularies.
+func isVocabulary(iri quad.IRI) bool {
+	full := string(iri.Full())
+	for _, ns := range vocabularies {
+		if strings.HasPrefix(full, ns) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize converts IRIs of known vocabularies to the short form.
+func normalize(v quad.Value) quad.Value {
+	if iri, ok := v.(quad.IRI); ok && isVocabulary(iri) {
+		return iri.Short()
+	}
+	return v
+}
+
+func normalizeQuad(q quad.Quad) quad.Quad {
+	return quad.Quad{
+		Subject:   normalize(q.Subject),
+		Predicate: normalize(q.Predicate),
+		Object:    normalize(q.Object),
+		Label:     q.Label,
+	}
+}
+
 // classSet is a set of RDF Classes
 type classSet map[*Class]struct{}
 
+func (s classSet) list() []*Class {
+	out := make([]*Class, 0, len(s))
+	for c := range s {
+		out = append(out, c)
+	}
+	return out
+}
+
 // propertySet is a set of RDF Properties
 type propertySet map[*Property]struct{}
 
+func (s propertySet) list() []*Property {
+	out := make([]*Property, 0, len(s))
+	for p := range s {
+		out = append(out, p)
+	}
+	return out
+}
+
 // Class represents a RDF Class with the links to classes and other properties
 type Class struct {
 	store      *Store
@@ -42,19 +116,21 @@ type Class struct {
 	references int
 	super      classSet
 	sub        classSet
+	equivalent classSet
 	ownProp    propertySet
 	inProp     propertySet
 }
 
 func (s *Store) newClass(name quad.Value, explicit bool) *Class {
 	c := &Class{
-		store:    s,
-		name:     name,
-		explicit: explicit,
-		super:    make(classSet),
-		sub:      make(classSet),
-		ownProp:  make(propertySet),
-		inProp:   make(propertySet),
+		store:      s,
+		name:       name,
+		explicit:   explicit,
+		super:      make(classSet),
+		sub:        make(classSet),
+		equivalent: make(classSet),
+		ownProp:    make(propertySet),
+		inProp:     make(propertySet),
 	}
 	s.classes[name] = c
 	return c
@@ -67,26 +143,60 @@ func (c *Class) Name() quad.Value {
 
 // IsSubClassOf recursively checks whether class is a superClass
 func (c *Class) IsSubClassOf(super *Class) bool {
-	if c == super {
-		return true
-	}
 	if super.name == quad.IRI(rdfs.Resource) {
 		return true
 	}
-	if _, ok := c.super[super]; ok {
-		return true
-	}
-	for s := range c.super {
-		if s.IsSubClassOf(super) {
+	for _, s := range c.allSuperClasses() {
+		if s == super {
 			return true
 		}
 	}
 	return false
 }
 
+// SubClasses returns direct subclasses of the class, including equivalent classes
+func (c *Class) SubClasses() []*Class {
+	out := make([]*Class, 0, len(c.sub)+len(c.equivalent))
+	for s := range c.sub {
+		out = append(out, s)
+	}
+	for s := range c.equivalent {
+		out = append(out, s)
+	}
+	return out
+}
+
+// SuperClasses returns direct super classes of the class, including equivalent classes
+func (c *Class) SuperClasses() []*Class {
+	out := make([]*Class, 0, len(c.super)+len(c.equivalent))
+	for s := range c.super {
+		out = append(out, s)
+	}
+	for s := range c.equivalent {
+		out = append(out, s)
+	}
+	return out
+}
+
+// allSuperClasses returns the class with all its super classes
+func (c *Class) allSuperClasses() []*Class {
+	seen := classSet{c: {}}
+	out := []*Class{c}
+	for i := 0; i < len(out); i++ {
+		for _, s := range out[i].SuperClasses() {
+			if _, ok := seen[s]; !ok {
+				seen[s] = struct{}{}
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
 func (c *Class) isReferenced() bool {
 	return c.explicit || len(c.super) > 0 ||
 		len(c.sub) > 0 ||
+		len(c.equivalent) > 0 ||
 		len(c.ownProp) > 0 ||
 		len(c.inProp) > 0 ||
 		c.references > 0
@@ -103,10 +213,13 @@ type Property struct {
 	name       quad.Value
 	explicit   bool
 	references int
-	domain     *Class
-	prange     *Class
+	domains    classSet
+	ranges     classSet
 	super      propertySet
 	sub        propertySet
+	inverse    propertySet
+	transitive bool
+	symmetric  bool
 	store      *Store
 }
 
@@ -114,8 +227,11 @@ func newProperty(name quad.Value, explicit bool, store *Store) *Property {
 	return &Property{
 		name:     name,
 		explicit: explicit,
+		domains:  make(classSet),
+		ranges:   make(classSet),
 		super:    make(propertySet),
 		sub:      make(propertySet),
+		inverse:  make(propertySet),
 		store:    store,
 	}
 }
@@ -125,38 +241,94 @@ func (p *Property) Name() quad.Value {
 	return p.name
 }
 
-// Domain returns the domain of the property
+// Domain returns the domain of the property, or one of the domains if there are multiple of them
+//
+// Deprecated: use Domains
 func (p *Property) Domain() *Class {
-	return p.domain
+	for c := range p.domains {
+		return c
+	}
+	return nil
 }
 
-// Range returns the range of the property
+// Range returns the range of the property, or one of the ranges if there are multiple of them
+//
+// Deprecated: use Ranges
 func (p *Property) Range() *Class {
-	return p.prange
+	for c := range p.ranges {
+		return c
+	}
+	return nil
+}
+
+// Domains returns the domains of the property
+func (p *Property) Domains() []*Class {
+	return p.domains.list()
+}
+
+// Ranges returns the ranges of the property
+func (p *Property) Ranges() []*Class {
+	return p.ranges.list()
 }
 
 // IsSubPropertyOf recursively checks whether property is a superProperty
 func (p *Property) IsSubPropertyOf(super *Property) bool {
-	if p == super {
-		return true
-	}
-	if _, ok := p.super[super]; ok {
-		return true
-	}
-	for s := range p.super {
-		if s.IsSubPropertyOf(super) {
+	for _, s := range p.allSuperProperties() {
+		if s == super {
 			return true
 		}
 	}
 	return false
 }
 
+// SubProperties returns direct sub-properties of the property
+func (p *Property) SubProperties() []*Property {
+	return p.sub.list()
+}
+
+// SuperProperties returns direct super properties of the property
+func (p *Property) SuperProperties() []*Property {
+	return p.super.list()
+}
+
+// Inverses returns properties that are declared as inverse of the property
+func (p *Property) Inverses() []*Property {
+	return p.inverse.list()
+}
+
+// IsTransitive checks whether property is declared as owl:TransitiveProperty
+func (p *Property) IsTransitive() bool {
+	return p.transitive
+}
+
+// IsSymmetric checks whether property is declared as owl:SymmetricProperty
+func (p *Property) IsSymmetric() bool {
+	return p.symmetric
+}
+
+// allSuperProperties returns the property with all its super properties
+func (p *Property) allSuperProperties() []*Property {
+	seen := propertySet{p: {}}
+	out := []*Property{p}
+	for i := 0; i < len(out); i++ {
+		for s := range out[i].super {
+			if _, ok := seen[s]; !ok {
+				seen[s] = struct{}{}
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
 func (p *Property) isReferenced() bool {
 	return p.explicit || p.references > 0 ||
 		len(p.super) > 0 ||
 		len(p.sub) > 0 ||
-		p.domain != nil ||
-		p.prange != nil
+		len(p.domains) > 0 ||
+		len(p.ranges) > 0 ||
+		len(p.inverse) > 0 ||
+		p.transitive || p.symmetric
 }
 
 func (p *Property) deleteIfUnreferenced() {
@@ -169,6 +341,8 @@ func (p *Property) deleteIfUnreferenced() {
 type Store struct {
 	classes    map[quad.Value]*Class
 	properties map[quad.Value]*Property
+	// same is a graph of owl:sameAs links between values
+	same map[quad.Value]map[quad.Value]int
 }
 
 // NewStore creates a new Store
@@ -176,6 +350,7 @@ func NewStore() Store {
 	s := Store{
 		classes:    make(map[quad.Value]*Class),
 		properties: make(map[quad.Value]*Property),
+		same:       make(map[quad.Value]map[quad.Value]int),
 	}
 	s.ensureClass(quad.IRI(rdfs.Resource))
 	return s
@@ -183,12 +358,36 @@ func NewStore() Store {
 
 // GetClass returns a class struct for class name, if it doesn't exist in the store then it returns nil
 func (s *Store) GetClass(name quad.Value) *Class {
-	return s.classes[name]
+	return s.classes[normalize(name)]
 }
 
 // GetProperty returns a class struct for property name, if it doesn't exist in the store then it returns nil
 func (s *Store) GetProperty(name quad.Value) *Property {
-	return s.properties[name]
+	return s.properties[normalize(name)]
+}
+
+// SameAs returns all values that are declared to be the same as a given value, directly or transitively
+// It doesn't include the value itself
+func (s *Store) SameAs(v quad.Value) []quad.Value {
+	v = normalize(v)
+	if len(s.same[v]) == 0 {
+		return nil
+	}
+	seen := map[quad.Value]struct{}{v: {}}
+	queue := []quad.Value{v}
+	var out []quad.Value
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for o := range s.same[cur] {
+			if _, ok := seen[o]; !ok {
+				seen[o] = struct{}{}
+				queue = append(queue, o)
+				out = append(out, o)
+			}
+		}
+	}
+	return out
 }
 
 func (s *Store) ensureClass(name quad.Value) {
@@ -245,19 +444,47 @@ func (s *Store) addPropertyRelationship(child quad.Value, parent quad.Value) {
 func (s *Store) setPropertyDomain(property quad.Value, domain quad.Value) {
 	p := s.getOrCreateImplicitProperty(property)
 	c := s.getOrCreateImplicitClass(domain)
-	// FIXME(iddan): Currently doesn't support multiple domains as they are very rare
-	p.domain = c
+	p.domains[c] = struct{}{}
 	c.ownProp[p] = struct{}{}
 }
 
 func (s *Store) setPropertyRange(property quad.Value, prange quad.Value) {
 	p := s.getOrCreateImplicitProperty(property)
 	c := s.getOrCreateImplicitClass(prange)
-	p.prange = c
-	// FIXME(iddan): Currently doesn't support multiple ranges as they are very rare
+	p.ranges[c] = struct{}{}
 	c.inProp[p] = struct{}{}
 }
 
+func (s *Store) addEquivalentClass(a quad.Value, b quad.Value) {
+	ca := s.getOrCreateImplicitClass(a)
+	cb := s.getOrCreateImplicitClass(b)
+	if ca != cb {
+		ca.equivalent[cb] = struct{}{}
+		cb.equivalent[ca] = struct{}{}
+	}
+}
+
+func (s *Store) addInverseProperty(a quad.Value, b quad.Value) {
+	pa := s.getOrCreateImplicitProperty(a)
+	pb := s.getOrCreateImplicitProperty(b)
+	pa.inverse[pb] = struct{}{}
+	pb.inverse[pa] = struct{}{}
+}
+
+func (s *Store) addSameAs(a quad.Value, b quad.Value) {
+	if a == b {
+		return
+	}
+	for _, l := range [][2]quad.Value{{a, b}, {b, a}} {
+		m := s.same[l[0]]
+		if m == nil {
+			m = make(map[quad.Value]int)
+			s.same[l[0]] = m
+		}
+		m[l[1]]++
+	}
+}
+
 func (s *Store) addClassInstance(name quad.Value) {
 	c := s.GetClass(name)
 	if c == nil {
@@ -284,6 +511,7 @@ func (s *Store) ProcessQuads(quads ...quad.Quad) {
 
 // processQuad is used to update the store with a new quad
 func (s *Store) processQuad(q quad.Quad) {
+	q = normalizeQuad(q)
 	pred, ok := q.Predicate.(quad.IRI)
 	if !ok {
 		return
@@ -300,6 +528,14 @@ func (s *Store) processQuad(q quad.Quad) {
 				s.ensureClass(sub)
 			case rdf.Property:
 				s.createProperty(sub)
+			case rdfs.ContainerMembershipProperty:
+				s.addPropertyRelationship(sub, quad.IRI(rdfs.Member))
+			case rdfs.Datatype:
+				s.addClassRelationship(sub, quad.IRI(rdfs.Literal))
+			case owlTransitiveProperty:
+				s.getOrCreateImplicitProperty(sub).transitive = true
+			case owlSymmetricProperty:
+				s.getOrCreateImplicitProperty(sub).symmetric = true
 			default:
 				s.addClassInstance(obj)
 			}
@@ -312,15 +548,19 @@ func (s *Store) processQuad(q quad.Quad) {
 		s.setPropertyDomain(sub, obj)
 	case rdfs.Range:
 		s.setPropertyRange(sub, obj)
+	case owlEquivalentClass:
+		s.addEquivalentClass(sub, obj)
+	case owlInverseOf:
+		s.addInverseProperty(sub, obj)
+	case owlSameAs:
+		s.addSameAs(sub, obj)
 	default:
 		p := s.addPropertyInstance(pred)
-		domain := p.Domain()
-		if domain != nil {
-			domain.references++
+		for c := range p.domains {
+			c.references++
 		}
-		prange := p.Range()
-		if prange != nil {
-			prange.references++
+		for c := range p.ranges {
+			c.references++
 		}
 	}
 }
@@ -336,6 +576,9 @@ func (s *Store) deleteClass(name quad.Value) {
 	for super := range c.super {
 		delete(super.sub, c)
 	}
+	for eq := range c.equivalent {
+		delete(eq.equivalent, c)
+	}
 	delete(s.classes, name)
 }
 
@@ -350,12 +593,18 @@ func (s *Store) deleteProperty(name quad.Value) {
 	for sub := range p.sub {
 		delete(sub.super, p)
 	}
+	for inv := range p.inverse {
+		delete(inv.inverse, p)
+	}
 	delete(s.properties, name)
 }
 
 func (s *Store) deleteClassRel(child quad.Value, parent quad.Value) {
 	p := s.GetClass(parent)
 	c := s.GetClass(child)
+	if p == nil || c == nil {
+		return
+	}
 	if _, ok := p.sub[c]; ok {
 		delete(p.sub, c)
 		delete(c.super, p)
@@ -367,6 +616,9 @@ func (s *Store) deleteClassRel(child quad.Value, parent quad.Value) {
 func (s *Store) deletePropertyRel(child quad.Value, parent quad.Value) {
 	p := s.GetProperty(parent)
 	c := s.GetProperty(child)
+	if p == nil || c == nil {
+		return
+	}
 	if _, ok := p.sub[c]; ok {
 		delete(p.sub, c)
 		delete(c.super, p)
@@ -378,8 +630,10 @@ func (s *Store) deletePropertyRel(child quad.Value, parent quad.Value) {
 func (s *Store) unsetPropertyDomain(property quad.Value, domain quad.Value) {
 	p := s.GetProperty(property)
 	c := s.GetClass(domain)
-	// FIXME(iddan): Currently doesn't support multiple domains as they are very rare
-	p.domain = nil
+	if p == nil || c == nil {
+		return
+	}
+	delete(p.domains, c)
 	delete(c.ownProp, p)
 	p.deleteIfUnreferenced()
 	c.deleteIfUnreferenced()
@@ -388,13 +642,62 @@ func (s *Store) unsetPropertyDomain(property quad.Value, domain quad.Value) {
 func (s *Store) unsetPropertyRange(property quad.Value, prange quad.Value) {
 	p := s.GetProperty(property)
 	c := s.GetClass(prange)
-	p.prange = nil
-	// FIXME(iddan): Currently doesn't support multiple ranges as they are very rare
+	if p == nil || c == nil {
+		return
+	}
+	delete(p.ranges, c)
 	delete(c.inProp, p)
 	p.deleteIfUnreferenced()
 	c.deleteIfUnreferenced()
 }
 
+func (s *Store) deleteEquivalentClass(a quad.Value, b quad.Value) {
+	ca := s.GetClass(a)
+	cb := s.GetClass(b)
+	if ca == nil || cb == nil {
+		return
+	}
+	delete(ca.equivalent, cb)
+	delete(cb.equivalent, ca)
+	ca.deleteIfUnreferenced()
+	cb.deleteIfUnreferenced()
+}
+
+func (s *Store) deleteInverseProperty(a quad.Value, b quad.Value) {
+	pa := s.GetProperty(a)
+	pb := s.GetProperty(b)
+	if pa == nil || pb == nil {
+		return
+	}
+	delete(pa.inverse, pb)
+	delete(pb.inverse, pa)
+	pa.deleteIfUnreferenced()
+	pb.deleteIfUnreferenced()
+}
+
+func (s *Store) deleteSameAs(a quad.Value, b quad.Value) {
+	for _, l := range [][2]quad.Value{{a, b}, {b, a}} {
+		m := s.same[l[0]]
+		if m[l[1]] <= 1 {
+			delete(m, l[1])
+		} else {
+			m[l[1]]--
+		}
+		if len(m) == 0 {
+			delete(s.same, l[0])
+		}
+	}
+}
+
+func (s *Store) unsetPropertyFlag(name quad.Value, flag *bool) {
+	p := s.GetProperty(name)
+	if p == nil {
+		return
+	}
+	*flag = false
+	p.deleteIfUnreferenced()
+}
+
 func (s *Store) deleteClassInstance(name quad.Value) {
 	c := s.GetClass(name)
 	if c == nil {
@@ -423,6 +726,7 @@ func (s *Store) UnprocessQuads(quads ...quad.Quad) {
 
 // unprocessQuad is used to delete a quad from the store
 func (s *Store) unprocessQuad(q quad.Quad) {
+	q = normalizeQuad(q)
 	pred, ok := q.Predicate.(quad.IRI)
 	if !ok {
 		return
@@ -430,17 +734,30 @@ func (s *Store) unprocessQuad(q quad.Quad) {
 	sub, obj := q.Subject, q.Object
 	switch pred {
 	case rdf.Type:
-		obj, ok := obj.(quad.IRI)
-		if !ok {
-			return
-		}
-		switch obj {
-		case rdfs.Class:
-			s.deleteClass(sub)
-		case rdf.Property:
-			s.deleteProperty(sub)
-		default:
+		switch obj := obj.(type) {
+		case quad.BNode:
 			s.deleteClassInstance(obj)
+		case quad.IRI:
+			switch obj {
+			case rdfs.Class:
+				s.deleteClass(sub)
+			case rdf.Property:
+				s.deleteProperty(sub)
+			case rdfs.ContainerMembershipProperty:
+				s.deletePropertyRel(sub, quad.IRI(rdfs.Member))
+			case rdfs.Datatype:
+				s.deleteClassRel(sub, quad.IRI(rdfs.Literal))
+			case owlTransitiveProperty:
+				if p := s.GetProperty(sub); p != nil {
+					s.unsetPropertyFlag(sub, &p.transitive)
+				}
+			case owlSymmetricProperty:
+				if p := s.GetProperty(sub); p != nil {
+					s.unsetPropertyFlag(sub, &p.symmetric)
+				}
+			default:
+				s.deleteClassInstance(obj)
+			}
 		}
 	case rdfs.SubPropertyOf:
 		s.deletePropertyRel(sub, obj)
@@ -450,14 +767,20 @@ func (s *Store) unprocessQuad(q quad.Quad) {
 		s.unsetPropertyDomain(sub, obj)
 	case rdfs.Range:
 		s.unsetPropertyRange(sub, obj)
+	case owlEquivalentClass:
+		s.deleteEquivalentClass(sub, obj)
+	case owlInverseOf:
+		s.deleteInverseProperty(sub, obj)
+	case owlSameAs:
+		s.deleteSameAs(sub, obj)
 	default:
 		p := s.deletePropertyInstance(pred)
 		if p != nil {
-			if domain := p.Domain(); domain != nil {
-				s.deleteClassInstance(domain.Name())
+			for c := range p.domains {
+				s.deleteClassInstance(c.Name())
 			}
-			if prange := p.Range(); prange != nil {
-				s.deleteClassInstance(prange.Name())
+			for c := range p.ranges {
+				s.deleteClassInstance(c.Name())
 			}
 		}
 	}
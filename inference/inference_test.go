@@ -594,3 +594,148 @@ func TestDeleteNonExistingUsedProperty(t *testing.T) {
 	store := NewStore()
 	store.UnprocessQuads(aliceNameAlice)
 }
+
+func TestDeleteNonExistingSubClass(t *testing.T) {
+	store := NewStore()
+	store.UnprocessQuads(engineerSubClass, nameSubPropertyOfPersonal, nameDomainPerson, likesRangePerson)
+}
+
+func TestSubClasses(t *testing.T) {
+	store := NewStore()
+	store.ProcessQuads(engineerAndSoftwareEngineerSubClasses...)
+	subs := store.GetClass(engineer).SubClasses()
+	require.Equal(t, []*Class{store.GetClass(softwareEngineer)}, subs)
+	require.Empty(t, store.GetClass(softwareEngineer).SubClasses())
+}
+
+func TestSubProperties(t *testing.T) {
+	store := NewStore()
+	store.ProcessQuads(nameSubPropertyOfPersonal, personalSubPropertyOfInformation)
+	subs := store.GetProperty(information).SubProperties()
+	require.Equal(t, []*Property{store.GetProperty(personal)}, subs)
+	require.Empty(t, store.GetProperty(name).SubProperties())
+}
+
+func TestMultipleDomainsAndRanges(t *testing.T) {
+	store := NewStore()
+	store.ProcessQuads(
+		nameDomainPerson,
+		triple(name, domain, engineer),
+		likesRangePerson,
+		triple(likes, prange, engineer),
+	)
+	require.ElementsMatch(t, []*Class{store.GetClass(person), store.GetClass(engineer)}, store.GetProperty(name).Domains())
+	require.ElementsMatch(t, []*Class{store.GetClass(person), store.GetClass(engineer)}, store.GetProperty(likes).Ranges())
+	store.ProcessQuads(aliceLikesBob)
+	require.Equal(t, 1, store.GetClass(engineer).references)
+	require.Equal(t, 1, store.GetClass(person).references)
+	store.UnprocessQuads(triple(name, domain, engineer))
+	require.Equal(t, []*Class{store.GetClass(person)}, store.GetProperty(name).Domains())
+}
+
+func TestFullVocabularyIRIs(t *testing.T) {
+	store := NewStore()
+	store.ProcessQuads(triple(engineer, subClassOf.Full(), person))
+	require.True(t, store.GetClass(engineer).IsSubClassOf(store.GetClass(person)))
+	require.NotNil(t, store.GetClass(quad.IRI(rdfs.Resource).Full()))
+	store.UnprocessQuads(triple(engineer, subClassOf.Full(), person))
+	require.Nil(t, store.GetClass(engineer))
+}
+
+func TestOWLSchema(t *testing.T) {
+	var (
+		knows    = quad.IRI("knows")
+		parentOf = quad.IRI("parentOf")
+		childOf  = quad.IRI("childOf")
+		human    = quad.IRI("Human")
+	)
+	quads := []quad.Quad{
+		triple(knows, ptype, quad.IRI(owlSymmetricProperty)),
+		triple(parentOf, quad.IRI(owlInverseOf), childOf),
+		triple(human, quad.IRI(owlEquivalentClass), person),
+		triple(alice, quad.IRI(owlSameAs), bob),
+	}
+	store := NewStore()
+	store.ProcessQuads(quads...)
+	require.True(t, store.GetProperty(knows).IsSymmetric())
+	require.False(t, store.GetProperty(knows).IsTransitive())
+	require.Equal(t, []*Property{store.GetProperty(childOf)}, store.GetProperty(parentOf).Inverses())
+	require.True(t, store.GetClass(human).IsSubClassOf(store.GetClass(person)))
+	require.True(t, store.GetClass(person).IsSubClassOf(store.GetClass(human)))
+	require.Equal(t, []quad.Value{alice}, store.SameAs(bob))
+
+	store.UnprocessQuads(quads...)
+	require.Nil(t, store.GetProperty(knows))
+	require.Nil(t, store.GetProperty(parentOf))
+	require.Nil(t, store.GetClass(human))
+	require.Empty(t, store.SameAs(bob))
+}
+
+func TestEntailments(t *testing.T) {
+	resource := quad.IRI(rdfs.Resource)
+	store := NewStore()
+	store.ProcessQuads(engineerAndSoftwareEngineerSubClasses...)
+	store.ProcessQuads(nameDomainPerson, nameSubPropertyOfPersonal, personalSubPropertyOfInformation)
+
+	got := store.Entailments(triple(alice, ptype, softwareEngineer))
+	require.Subset(t, got, []quad.Quad{
+		triple(alice, ptype, engineer),
+		triple(alice, ptype, person),
+		triple(alice, ptype, resource),
+		triple(softwareEngineer, ptype, resource),
+		triple(ptype, ptype, property),
+	})
+
+	got = store.Entailments(aliceNameAlice)
+	require.Subset(t, got, []quad.Quad{
+		triple(alice, personal, aliceName),
+		triple(alice, information, aliceName),
+		triple(alice, ptype, person),
+		triple(name, ptype, property),
+	})
+	require.NotContains(t, got, triple(aliceName, ptype, resource))
+	require.NotContains(t, got, aliceNameAlice)
+
+	got = store.Entailments(triple(softwareEngineer, subClassOf, engineer))
+	require.Contains(t, got, triple(softwareEngineer, subClassOf, person))
+
+	got = store.Entailments(triple(likes, ptype, quad.IRI(rdfs.ContainerMembershipProperty)))
+	require.Contains(t, got, triple(likes, subPropertyOf, quad.IRI(rdfs.Member)))
+	got = store.Entailments(triple(name, ptype, quad.IRI(rdfs.Datatype)))
+	require.Contains(t, got, triple(name, subClassOf, literal))
+	got = store.Entailments(personClass)
+	require.Subset(t, got, []quad.Quad{
+		triple(person, subClassOf, resource),
+		triple(person, subClassOf, person),
+	})
+}
+
+func TestOWLEntailments(t *testing.T) {
+	var (
+		knows    = quad.IRI("knows")
+		parentOf = quad.IRI("parentOf")
+		childOf  = quad.IRI("childOf")
+		human    = quad.IRI("Human")
+		carol    = quad.IRI("carol")
+	)
+	store := NewStore()
+	store.ProcessQuads(
+		triple(knows, ptype, quad.IRI(owlSymmetricProperty)),
+		triple(parentOf, quad.IRI(owlInverseOf), childOf),
+		triple(human, quad.IRI(owlEquivalentClass), person),
+		triple(alice, quad.IRI(owlSameAs), bob),
+		triple(bob, quad.IRI(owlSameAs), carol),
+	)
+	require.Contains(t, store.Entailments(triple(alice, knows, engineer)), triple(engineer, knows, alice))
+	require.Contains(t, store.Entailments(triple(engineer, parentOf, person)), triple(person, childOf, engineer))
+	require.Contains(t, store.Entailments(triple(engineer, childOf, person)), triple(person, parentOf, engineer))
+	require.Contains(t, store.Entailments(triple(engineer, ptype, human)), triple(engineer, ptype, person))
+	require.Subset(t, store.Entailments(triple(alice, likes, engineer)), []quad.Quad{
+		triple(bob, likes, engineer),
+		triple(carol, likes, engineer),
+	})
+	require.Subset(t, store.Entailments(triple(alice, quad.IRI(owlSameAs), bob)), []quad.Quad{
+		triple(bob, quad.IRI(owlSameAs), alice),
+		triple(carol, quad.IRI(owlSameAs), bob),
+	})
+}
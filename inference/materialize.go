@@ -0,0 +1,191 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/cayleygraph/quad"
+
+	"github.com/cayleygraph/cayley/graph"
+)
+
+// Materialize computes all quads inferred from the quad store and writes them to a dedicated label.
+//
+// Quads in the label are ignored by the inference and are replaced by inferred quads:
+// quads that are not inferred anymore are removed, and quads that are stated explicitly are not written.
+// IRIs of RDF, RDFS and OWL vocabularies are written in the full form.
+// It returns the number of added and removed quads.
+//
+// The store is read a few times instead of being loaded into memory: only the schema, links by transitive
+// properties and inferred quads are kept in memory.
+func Materialize(ctx context.Context, qs graph.QuadStore, qw graph.QuadWriter, label quad.Value) (added, removed int, err error) {
+	if label == nil {
+		return 0, 0, errors.New("inference: label is required for materialization")
+	}
+	var (
+		existing []quad.Quad
+		store    = NewStore()
+		edges    = make(transitiveEdges)
+		known    = make(map[quad.Quad]struct{})
+		inferred []quad.Quad
+	)
+	infer := func(quads []quad.Quad) {
+		for _, q := range quads {
+			if _, ok := known[q]; !ok {
+				known[q] = struct{}{}
+				inferred = append(inferred, q)
+			}
+		}
+	}
+	// the schema must be complete before the entailments are computed
+	err = forEachQuad(ctx, qs, func(q quad.Quad) {
+		if q.Label == label {
+			existing = append(existing, q)
+		} else {
+			store.ProcessQuads(q)
+		}
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	err = forEachQuad(ctx, qs, func(q quad.Quad) {
+		if q.Label == label {
+			return
+		}
+		q = normalizeQuad(q)
+		q.Label = nil
+		edges.add(&store, q)
+		infer(store.Entailments(q))
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := 0; ; {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		infer(edges.closure())
+		if i == len(inferred) {
+			break
+		}
+		next := inferred[i:]
+		i = len(inferred)
+		// inferred quads may change the schema
+		store.ProcessQuads(next...)
+		for _, q := range next {
+			edges.add(&store, q)
+			infer(store.Entailments(q))
+		}
+	}
+	// quads that are stated explicitly are not written
+	err = forEachQuad(ctx, qs, func(q quad.Quad) {
+		if q.Label == label {
+			return
+		}
+		q = normalizeQuad(q)
+		q.Label = nil
+		delete(known, q)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	target := known
+
+	tx := graph.NewTransaction()
+	for _, q := range existing {
+		t := normalizeQuad(q)
+		t.Label = nil
+		if _, ok := target[t]; ok {
+			delete(target, t)
+			continue
+		}
+		tx.RemoveQuad(q)
+		removed++
+	}
+	for _, q := range inferred {
+		if _, ok := target[q]; !ok {
+			continue
+		}
+		tx.AddQuad(quad.Quad{
+			Subject:   fullIRI(q.Subject),
+			Predicate: fullIRI(q.Predicate),
+			Object:    fullIRI(q.Object),
+			Label:     label,
+		})
+		added++
+	}
+	if added+removed == 0 {
+		return 0, 0, nil
+	}
+	if err := qw.ApplyTransaction(tx); err != nil {
+		return 0, 0, err
+	}
+	return added, removed, nil
+}
+
+// transitiveEdges are links between nodes by transitive properties, by the property and the subject.
+type transitiveEdges map[quad.Value]map[quad.Value][]quad.Value
+
+// add records the quad if its predicate is a transitive property.
+func (t transitiveEdges) add(s *Store, q quad.Quad) {
+	p := s.GetProperty(q.Predicate)
+	if p == nil || !p.transitive || !isNode(q.Object) {
+		return
+	}
+	m := t[p.name]
+	if m == nil {
+		m = make(map[quad.Value][]quad.Value)
+		t[p.name] = m
+	}
+	m[q.Subject] = append(m[q.Subject], q.Object)
+}
+
+// closure returns quads that are inferred by transitive properties.
+// Nodes in a cycle are linked to themselves.
+func (t transitiveEdges) closure() []quad.Quad {
+	var out []quad.Quad
+	for pred, m := range t {
+		for sub := range m {
+			seen := make(map[quad.Value]struct{})
+			stack := append([]quad.Value{}, m[sub]...)
+			for len(stack) > 0 {
+				obj := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if _, ok := seen[obj]; ok {
+					continue
+				}
+				seen[obj] = struct{}{}
+				out = append(out, quad.Quad{Subject: sub, Predicate: pred, Object: obj})
+				stack = append(stack, m[obj]...)
+			}
+		}
+	}
+	return out
+}
+
+// fullIRI converts IRIs of known vocabularies to the full form
+func fullIRI(v quad.Value) quad.Value {
+	if iri, ok := v.(quad.IRI); ok && isVocabulary(iri) {
+		return iri.Full()
+	}
+	return v
+}
+
+// forEachQuad calls the function for each quad in the store.
+func forEachQuad(ctx context.Context, qs graph.QuadStore, fn func(q quad.Quad)) error {
+	r := graph.NewQuadStoreReader(qs)
+	defer r.Close()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q, err := r.ReadQuad()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fn(q)
+	}
+}
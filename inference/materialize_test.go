@@ -0,0 +1,95 @@
+package inference_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/inference"
+	_ "github.com/cayleygraph/cayley/writer"
+)
+
+func TestMaterialize(t *testing.T) {
+	ctx := context.TODO()
+	label := quad.IRI("inferred")
+	typ := quad.IRI(rdf.Type).Full()
+	qs := memstore.New(
+		quad.Make(quad.IRI("Employee"), quad.IRI(rdfs.SubClassOf).Full(), quad.IRI("Person"), nil),
+		quad.Make(quad.IRI("ancestorOf"), typ, quad.IRI("http://www.w3.org/2002/07/owl#TransitiveProperty"), nil),
+		quad.Make(quad.IRI("alice"), typ, quad.IRI("Employee"), nil),
+		quad.Make(quad.IRI("alice"), quad.IRI("ancestorOf"), quad.IRI("bob"), nil),
+		quad.Make(quad.IRI("bob"), quad.IRI("ancestorOf"), quad.IRI("carol"), nil),
+	)
+	qw, err := graph.NewQuadWriter("single", qs, nil)
+	require.NoError(t, err)
+
+	added, removed, err := inference.Materialize(ctx, qs, qw, label)
+	require.NoError(t, err)
+	require.NotZero(t, added)
+	require.Zero(t, removed)
+
+	has := func(q quad.Quad) bool {
+		r := graph.NewQuadStoreReader(qs)
+		defer r.Close()
+		all, err := quad.ReadAll(r)
+		require.NoError(t, err)
+		for _, q2 := range all {
+			if q2 == q {
+				return true
+			}
+		}
+		return false
+	}
+	require.True(t, has(quad.Make(quad.IRI("alice"), typ, quad.IRI("Person"), label)))
+	require.True(t, has(quad.Make(quad.IRI("alice"), quad.IRI("ancestorOf"), quad.IRI("carol"), label)))
+	// explicit quads are not duplicated
+	require.False(t, has(quad.Make(quad.IRI("alice"), typ, quad.IRI("Employee"), label)))
+
+	// materialization is idempotent
+	added, removed, err = inference.Materialize(ctx, qs, qw, label)
+	require.NoError(t, err)
+	require.Zero(t, added)
+	require.Zero(t, removed)
+
+	// quads that are not inferred anymore are removed
+	require.NoError(t, qw.RemoveQuad(quad.Make(quad.IRI("bob"), quad.IRI("ancestorOf"), quad.IRI("carol"), nil)))
+	_, removed, err = inference.Materialize(ctx, qs, qw, label)
+	require.NoError(t, err)
+	require.NotZero(t, removed)
+	require.False(t, has(quad.Make(quad.IRI("alice"), quad.IRI("ancestorOf"), quad.IRI("carol"), label)))
+	require.True(t, has(quad.Make(quad.IRI("alice"), typ, quad.IRI("Person"), label)))
+
+	_, _, err = inference.Materialize(ctx, qs, qw, nil)
+	require.Error(t, err)
+}
+
+func TestMaterializeCycle(t *testing.T) {
+	ctx := context.TODO()
+	label := quad.IRI("inferred")
+	typ := quad.IRI(rdf.Type).Full()
+	qs := memstore.New(
+		quad.Make(quad.IRI("sameTeam"), typ, quad.IRI("http://www.w3.org/2002/07/owl#TransitiveProperty"), nil),
+		quad.Make(quad.IRI("alice"), quad.IRI("sameTeam"), quad.IRI("bob"), nil),
+		quad.Make(quad.IRI("bob"), quad.IRI("sameTeam"), quad.IRI("alice"), nil),
+	)
+	qw, err := graph.NewQuadWriter("single", qs, nil)
+	require.NoError(t, err)
+
+	_, _, err = inference.Materialize(ctx, qs, qw, label)
+	require.NoError(t, err)
+
+	r := graph.NewQuadStoreReader(qs)
+	defer r.Close()
+	all, err := quad.ReadAll(r)
+	require.NoError(t, err)
+	require.Subset(t, all, []quad.Quad{
+		quad.Make(quad.IRI("alice"), quad.IRI("sameTeam"), quad.IRI("alice"), label),
+		quad.Make(quad.IRI("bob"), quad.IRI("sameTeam"), quad.IRI("bob"), label),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/schema"
 	cayleyhttp "github.com/cayleygraph/cayley/server/http"
 	"github.com/julienschmidt/httprouter"
 )
@@ -11,9 +12,13 @@ import (
 type API struct {
 	config *Config
 	handle *graph.Handle
+	ns     *schema.NamespaceRegistry
 }
 
 func (api *API) GetHandleForRequest(r *http.Request) (*graph.Handle, error) {
+	if api.config.Writer != "" {
+		return cayleyhttp.HandleForRequest(api.handle, api.config.Writer, api.config.WriterOptions, r)
+	}
 	return cayleyhttp.HandleForRequest(api.handle, "single", nil, r)
 }
 
@@ -26,10 +31,13 @@ func (api *API) RWOnly(handler httprouter.Handle) httprouter.Handle {
 	return handler
 }
 
-func (api *API) APIv1(r *httprouter.Router) {
-	r.POST("/query/:query_lang", api.ServeV1Query)
-	r.POST("/shape/:query_lang", api.ServeV1Shape)
-	r.POST("/write", api.RWOnly(api.ServeV1Write))
-	r.POST("/write/file/nquad", api.RWOnly(api.ServeV1WriteNQuad))
-	r.POST("/delete", api.RWOnly(api.ServeV1Delete))
+func (api *API) APIv1(router *httprouter.Router) {
+	r := cayleyhttp.Routes{Router: router}
+	read := cayleyhttp.RequirePermission(cayleyhttp.PermRead)
+	write := cayleyhttp.RequirePermission(cayleyhttp.PermWrite)
+	r.POST("/query/:query_lang", cayleyhttp.WrapHandle(read, api.ServeV1Query))
+	r.POST("/shape/:query_lang", cayleyhttp.WrapHandle(read, api.ServeV1Shape))
+	r.POST("/write", cayleyhttp.WrapHandle(write, api.RWOnly(api.ServeV1Write)))
+	r.POST("/write/file/nquad", cayleyhttp.WrapHandle(write, api.RWOnly(api.ServeV1WriteNQuad)))
+	r.POST("/delete", cayleyhttp.WrapHandle(write, api.RWOnly(api.ServeV1Delete)))
 }
@@ -6,12 +6,26 @@ import (
 
 // CORS adds CORS related headers to responses
 func CORS(h http.Handler) http.Handler {
+	return CORSFor(nil, h)
+}
+
+// CORSFor adds CORS related headers to responses to requests from given origins.
+// All origins are allowed if the list is empty.
+func CORSFor(origins []string, h http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(origins))
+	for _, o := range origins {
+		allowed[o] = struct{}{}
+	}
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		if origin := req.Header.Get("Origin"); origin != "" {
+		origin := req.Header.Get("Origin")
+		if _, ok := allowed[origin]; origin != "" && (ok || len(allowed) == 0) {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
 			w.Header().Set("Access-Control-Allow-Headers",
-				"Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+				"Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key")
+			if len(allowed) != 0 {
+				w.Header().Add("Vary", "Origin")
+			}
 		}
 		h.ServeHTTP(w, req)
 	})
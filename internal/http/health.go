@@ -1,9 +1,119 @@
 package http
 
-import "net/http"
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cayleygraph/cayley/graph"
+)
 
 // HandleHealth is a route for handling health checks to the server
 func HandleHealth(w http.ResponseWriter, r *http.Request) {
 	// Adjust status code to 204
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// healthTimeout limits the time of a readiness check.
+const healthTimeout = 5 * time.Second
+
+// Statuses reported by health checks.
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+)
+
+// HealthStats is a number of nodes and quads in the database.
+type HealthStats struct {
+	Nodes int64 `json:"nodes"`
+	Quads int64 `json:"quads"`
+	// Exact is set if the numbers are exact, and not estimated.
+	Exact bool `json:"exact"`
+}
+
+// HealthStatus is returned by health check endpoints.
+type HealthStatus struct {
+	Status   string `json:"status"`
+	Backend  string `json:"backend,omitempty"`
+	ReadOnly bool   `json:"read_only"`
+	Error    string `json:"error,omitempty"`
+
+	Stats *HealthStats `json:"stats,omitempty"`
+	// ReplicationLag is set only for quad writers that report it, see graph.ReplicationLagger.
+	ReplicationLag string `json:"replication_lag,omitempty"`
+	// Details are backend-specific, see graph.HealthReporter.
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Health serves liveness and readiness checks for a database.
+type Health struct {
+	h   *graph.Handle
+	cfg *Config
+}
+
+// NewHealth creates health check handlers for a database.
+func NewHealth(h *graph.Handle, cfg *Config) *Health {
+	return &Health{h: h, cfg: cfg}
+}
+
+// Check runs the readiness check and returns the database state.
+func (s *Health) Check(ctx context.Context) HealthStatus {
+	st := HealthStatus{
+		Status:   HealthOK,
+		Backend:  s.cfg.Backend,
+		ReadOnly: s.cfg.ReadOnly,
+	}
+	fail := func(err error) HealthStatus {
+		st.Status = HealthUnavailable
+		st.Error = err.Error()
+		return st
+	}
+	details, err := graph.Health(ctx, s.h.QuadStore)
+	if err != nil {
+		return fail(err)
+	}
+	st.Details = details
+	stats, err := s.h.QuadStore.Stats(ctx, false)
+	if err != nil {
+		return fail(err)
+	}
+	st.Stats = &HealthStats{
+		Nodes: stats.Nodes.Value,
+		Quads: stats.Quads.Value,
+		Exact: stats.Nodes.Exact && stats.Quads.Exact,
+	}
+	if lag, ok, err := graph.ReplicationLag(ctx, s.h.QuadWriter); err != nil {
+		return fail(err)
+	} else if ok {
+		st.ReplicationLag = lag.String()
+	}
+	return st
+}
+
+func writeHealth(w http.ResponseWriter, st HealthStatus) {
+	code := http.StatusOK
+	if st.Status != HealthOK {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(st)
+}
+
+// ServeLive responds if the server process is running. It never checks the database.
+func (s *Health) ServeLive(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, HealthStatus{
+		Status:   HealthOK,
+		Backend:  s.cfg.Backend,
+		ReadOnly: s.cfg.ReadOnly,
+	})
+}
+
+// ServeReady responds with the database state. It returns 503 if the database is not available.
+func (s *Health) ServeReady(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthTimeout)
+	defer cancel()
+	writeHealth(w, s.Check(ctx))
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cayleygraph/cayley/audit"
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/writer"
+	"github.com/cayleygraph/quad"
+)
+
+type downStore struct {
+	graph.QuadStore
+}
+
+func (downStore) Ping(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestHealth(t *testing.T) {
+	qs := memstore.New(quad.MakeIRI("a", "b", "c", ""))
+	cfg := &Config{Backend: "memstore", ReadOnly: true}
+
+	check := func(h *Health, path string, handler http.HandlerFunc, code int) HealthStatus {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != code {
+			t.Fatalf("%s: unexpected code: %d (%s)", path, rr.Code, rr.Body.String())
+		}
+		var st HealthStatus
+		if err := json.Unmarshal(rr.Body.Bytes(), &st); err != nil {
+			t.Fatal(err)
+		}
+		return st
+	}
+
+	h := NewHealth(&graph.Handle{QuadStore: qs}, cfg)
+	st := check(h, "/health/ready", h.ServeReady, http.StatusOK)
+	if st.Status != HealthOK || st.Backend != "memstore" || !st.ReadOnly {
+		t.Fatalf("unexpected status: %+v", st)
+	} else if st.Stats == nil || st.Stats.Quads != 1 || st.Stats.Nodes != 3 {
+		t.Fatalf("unexpected stats: %+v", st.Stats)
+	}
+	if st.ReplicationLag != "" {
+		t.Fatalf("unexpected replication lag: %q", st.ReplicationLag)
+	}
+
+	// the lag is reported by wrapped writers as well
+	qw, err := writer.NewSingle(qs, graph.IgnoreOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h = NewHealth(&graph.Handle{QuadStore: qs, QuadWriter: audit.NewWriter(qs, qw, nil)}, cfg)
+	st = check(h, "/health/ready", h.ServeReady, http.StatusOK)
+	if st.ReplicationLag != "0s" {
+		t.Fatalf("unexpected replication lag: %q", st.ReplicationLag)
+	}
+
+	h = NewHealth(&graph.Handle{QuadStore: downStore{qs}}, cfg)
+	check(h, "/health/live", h.ServeLive, http.StatusOK)
+	st = check(h, "/health/ready", h.ServeReady, http.StatusServiceUnavailable)
+	if st.Status != HealthUnavailable || st.Error != "connection refused" {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+}
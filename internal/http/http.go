@@ -15,6 +15,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/fs"
@@ -40,42 +41,140 @@ func jsonResponse(w http.ResponseWriter, code int, err interface{}) {
 
 // Config holds the HTTP server configuration
 type Config struct {
+	// Backend is the name of the database backend, reported by health checks.
+	Backend  string
 	ReadOnly bool
 	Timeout  time.Duration
 	Batch    int
+	// Auth authenticates requests. All requests are allowed if it is nil.
+	Auth *cayleyhttp.Auth
+	// Writer and WriterOptions set the type and options of quad writers created for each request,
+	// if requests are restricted to a subset of the graph. The writer of the handle is used by default.
+	Writer        string
+	WriterOptions graph.Options
+	// DatabaseWriter returns the type and options of quad writers created for each request to a named database
+	// with a given handle, the same way as Writer and WriterOptions. The writer of the handle is used if it's nil,
+	// or if it returns an empty type.
+	DatabaseWriter func(h *graph.Handle) (string, graph.Options)
+	// Limits enforces rate and concurrency limits for queries and writes of each client. No limits if it is nil.
+	Limits *cayleyhttp.Limiter
+	// CORSOrigins is a list of origins allowed for cross-origin requests. All origins are allowed if it is empty.
+	CORSOrigins []string
+	// Databases serves named databases in addition to the default one. Only the default database is served if it is nil.
+	Databases *cayleyhttp.Databases
+	// GizmoModules is a directory with Gizmo modules available to queries and as stored procedures of all databases.
+	GizmoModules string
+}
+
+// loadProcedures loads stored procedures of the database and modules from the configured directory.
+func (cfg *Config) loadProcedures(api *cayleyhttp.APIv2) error {
+	ctx := context.Background()
+	if err := api.Procedures().Load(ctx); err != nil {
+		return fmt.Errorf("cannot load stored procedures: %w", err)
+	}
+	if cfg.GizmoModules == "" {
+		return nil
+	}
+	return api.Procedures().LoadDir(ctx, cfg.GizmoModules)
+}
+
+// ServeGephi streams the graph visible for the request to Gephi.
+func (api *API) ServeGephi(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	h, err := api.GetHandleForRequest(r)
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	gs := &gephi.GraphStreamHandler{QS: h.QuadStore}
+	gs.ServeHTTP(w, r, params)
 }
 
 func SetupRoutes(handle *graph.Handle, cfg *Config) error {
-	ui, err := fs.Sub(ui.FS, "web")
+	web, err := fs.Sub(ui.FS, "web")
+	if err != nil {
+		return err
+	}
+	ui, err := newUIHandler(web)
 	if err != nil {
 		return err
 	}
-	r := httprouter.New()
+	router := httprouter.New()
+	r := cayleyhttp.Routes{Router: router}
 
 	// Health check
 	r.HandlerFunc("GET", "/health", HandleHealth)
+	health := NewHealth(handle, cfg)
+	r.HandlerFunc("GET", "/health/live", health.ServeLive)
+	r.HandlerFunc("GET", "/health/ready", health.ServeReady)
 
 	// Handle CORS preflight request
 	r.HandlerFunc("OPTIONS", "/*path", HandlePreflight)
 
+	if cfg.Auth.Scoped() {
+		// restrict principals to their labels
+		handle = &graph.Handle{
+			QuadStore:  cayleyhttp.ScopedQuadStore{QuadStore: handle.QuadStore},
+			QuadWriter: handle.QuadWriter,
+		}
+	}
+
 	// Register API V1
 	api := &API{config: cfg, handle: handle}
-	api.APIv1(r)
+	api.APIv1(router)
 
 	// Register Gephi API
-	gs := &gephi.GraphStreamHandler{QS: handle.QuadStore}
-	r.GET("/gephi/gs", gs.ServeHTTP)
+	r.GET("/gephi/gs", cayleyhttp.WrapHandle(cayleyhttp.RequirePermission(cayleyhttp.PermRead), api.ServeGephi))
 
 	// Register API V2
-	api2 := cayleyhttp.NewBoundAPIv2(handle, r)
+	api2 := cayleyhttp.NewBoundAPIv2(handle, router)
 	api2.SetReadOnly(cfg.ReadOnly)
 	api2.SetBatchSize(cfg.Batch)
 	api2.SetQueryTimeout(cfg.Timeout)
+	if cfg.Writer != "" {
+		api2.SetWriter(cfg.Writer, cfg.WriterOptions)
+	}
+	if err := api2.Namespaces().Load(context.Background()); err != nil {
+		return fmt.Errorf("cannot load namespace rules: %w", err)
+	}
+	api.ns = api2.Namespaces()
+	if err := cfg.loadProcedures(api2); err != nil {
+		return err
+	}
+
+	// Register named databases
+	if dbs := cfg.Databases; dbs != nil {
+		// only authenticated admins can create and drop databases
+		dbs.SetReadOnly(cfg.ReadOnly || cfg.Auth == nil)
+		dbs.SetScoped(cfg.Auth.Scoped())
+		dbs.SetNotFound(ui)
+		dbs.SetSetup(func(api *cayleyhttp.APIv2, h *graph.Handle) error {
+			api.SetReadOnly(cfg.ReadOnly)
+			api.SetBatchSize(cfg.Batch)
+			api.SetQueryTimeout(cfg.Timeout)
+			if cfg.DatabaseWriter != nil {
+				if wtyp, wopts := cfg.DatabaseWriter(h); wtyp != "" {
+					api.SetWriter(wtyp, wopts)
+				}
+			}
+			if err := api.Namespaces().Load(context.Background()); err != nil {
+				return fmt.Errorf("cannot load namespace rules: %w", err)
+			}
+			return cfg.loadProcedures(api)
+		})
+		dbs.RegisterOn(router)
+	}
 
 	// For non API requests serve the UI
-	r.NotFound = http.FileServer(http.FS(ui))
+	r.NotFound = ui
 
-	http.Handle("/", CORS(LogRequest(r)))
+	var h http.Handler = router
+	if cfg.Limits != nil {
+		h = limitRoutes(cfg.Limits).Wrap(h)
+	}
+	if cfg.Auth != nil {
+		h = cfg.Auth.Wrap(h)
+	}
+	http.Handle("/", CORSFor(cfg.CORSOrigins, Instrument(LogRequest(h))))
 
 	return nil
 }
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
+)
+
+// limitClasses maps methods and routes to classes of requests subject to limits.
+var limitClasses = map[string]string{
+	"GET /api/v2/query":             cayleyhttp.LimitQuery,
+	"POST /api/v2/query":            cayleyhttp.LimitQuery,
+	"POST /query/:query_lang":       cayleyhttp.LimitQuery,
+	"POST /api/v2/procedures/*name": cayleyhttp.LimitQuery,
+
+	"POST /api/v2/write":       cayleyhttp.LimitWrite,
+	"POST /api/v2/delete":      cayleyhttp.LimitWrite,
+	"POST /api/v2/node/delete": cayleyhttp.LimitWrite,
+	"POST /write":              cayleyhttp.LimitWrite,
+	"POST /write/file/nquad":   cayleyhttp.LimitWrite,
+	"POST /delete":             cayleyhttp.LimitWrite,
+}
+
+// limitRoutes returns a route hook that enforces the limits for requests, based on the class of their route.
+//
+// Routes of named databases are the same as for the default one, and the hook is called for them as well.
+// Changes made by queries and procedures are limited as writes by cayleyhttp.LimitWriter.
+func limitRoutes(l *cayleyhttp.Limiter) cayleyhttp.RouteHook {
+	return func(route string, h httprouter.Handle) httprouter.Handle {
+		class := func(r *http.Request) string {
+			return limitClasses[r.Method+" "+route]
+		}
+		return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+			l.Wrap(class, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				h(w, r, params)
+			})).ServeHTTP(w, r)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package http
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
 	"time"
 
+	"github.com/cayleygraph/cayley/audit"
 	"github.com/cayleygraph/cayley/clog"
 )
 
@@ -36,11 +39,27 @@ func getAddress(req *http.Request) string {
 	return addr
 }
 
-// LogRequest wraps a http.Handler and emits logs about the request and the response
+// newRequestID generates a random request ID.
+func newRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// LogRequest wraps a http.Handler and emits logs about the request and the response.
+//
+// It also assigns an ID to the request, if the client has not sent one, and stores the ID and the address
+// of the client in the request context for audit records.
 func LogRequest(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		start := time.Now()
 		addr := getAddress(req)
+		id := req.Header.Get(audit.RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(audit.RequestIDHeader, id)
+		req = req.WithContext(audit.NewContext(req.Context(), audit.Meta{Remote: addr, RequestID: id}))
 		sw := newStatusWriter(w)
 		clog.Infof("started %s %s for %s", req.Method, req.URL.Path, addr)
 		handler.ServeHTTP(sw, req)
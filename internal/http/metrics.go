@@ -0,0 +1,111 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/cayleygraph/cayley/query"
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
+	"github.com/cayleygraph/cayley/trace"
+)
+
+var (
+	mRequestSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "cayley_http_request_seconds",
+		Help: "Time to serve an HTTP request.",
+	}, []string{"route", "lang", "method", "code"})
+	mRequestSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cayley_http_request_size_bytes",
+		Help:    "Size of HTTP request bodies.",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 10),
+	}, []string{"route", "lang"})
+	mResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cayley_http_response_size_bytes",
+		Help:    "Size of HTTP response bodies.",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 10),
+	}, []string{"route", "lang"})
+)
+
+// routeOther is reported for requests that do not match any route, for example UI files.
+const routeOther = "other"
+
+// langOf returns the query language of the request to a given route, if any.
+// Only registered languages are returned, to be used as metric labels.
+func langOf(route string, req *http.Request, params httprouter.Params) string {
+	lang := params.ByName("query_lang")
+	if lang == "" && route == "/api/v2/query" {
+		lang = req.URL.Query().Get("lang")
+	}
+	if lang != "" && query.GetLanguage(lang) == nil {
+		lang = ""
+	}
+	return lang
+}
+
+// sizeWriter wraps http.ResponseWriter and captures the status code and the size of the response.
+type sizeWriter struct {
+	statusWriter
+	size int
+}
+
+func (w *sizeWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.size += n
+	return n, err
+}
+
+// Instrument wraps a http.Handler to record request metrics and traces.
+//
+// Requests are reported by the route pattern of the matched handler, if it's registered with cayleyhttp.Routes.
+//
+// Trace context is read from the traceparent header, and the context of the request span
+// is returned in the same header.
+func Instrument(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+
+		ctx := req.Context()
+		if sc, err := trace.ParseTraceparent(req.Header.Get(trace.Header)); err == nil {
+			ctx = trace.ContextWithRemoteParent(ctx, sc)
+		}
+		ctx, span := trace.Start(ctx, "http.request")
+		span.SetAttr("method", req.Method)
+		if span != nil {
+			w.Header().Set(trace.Header, span.Context().Traceparent())
+		}
+
+		// the route is set by the first router that matches the request; both values are metric labels
+		route, lang := routeOther, ""
+		ctx = cayleyhttp.WithRouteHook(ctx, func(r string, h httprouter.Handle) httprouter.Handle {
+			if route != routeOther {
+				return h
+			}
+			route = r
+			return func(w http.ResponseWriter, req *http.Request, params httprouter.Params) {
+				lang = langOf(r, req, params)
+				h(w, req, params)
+			}
+		})
+
+		sw := &sizeWriter{statusWriter: statusWriter{ResponseWriter: w, code: http.StatusOK}}
+		handler.ServeHTTP(sw, req.WithContext(ctx))
+
+		span.SetAttr("route", route)
+		if lang != "" {
+			span.SetAttr("lang", lang)
+		}
+		span.SetAttr("status", sw.code)
+		span.End()
+		code := strconv.Itoa(sw.code)
+		mRequestSeconds.WithLabelValues(route, lang, req.Method, code).Observe(time.Since(start).Seconds())
+		if req.ContentLength >= 0 {
+			mRequestSize.WithLabelValues(route, lang).Observe(float64(req.ContentLength))
+		}
+		mResponseSize.WithLabelValues(route, lang).Observe(float64(sw.size))
+	})
+}
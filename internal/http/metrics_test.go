@@ -0,0 +1,165 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	_ "github.com/cayleygraph/cayley/query/gizmo"
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
+	"github.com/cayleygraph/cayley/trace"
+	"github.com/cayleygraph/cayley/writer"
+)
+
+func TestInstrumentRoutes(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	trace.SetExporter(trace.NewWriterExporter(buf))
+	defer trace.SetExporter(nil)
+
+	open := func(cayleyhttp.DatabaseConfig) (*graph.Handle, error) {
+		qs := memstore.New()
+		qw, err := writer.NewSingle(qs, graph.IgnoreOpts{})
+		if err != nil {
+			return nil, err
+		}
+		return &graph.Handle{QuadStore: qs, QuadWriter: qw}, nil
+	}
+	def, err := open(cayleyhttp.DatabaseConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := httprouter.New()
+	r := cayleyhttp.Routes{Router: router}
+	noop := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {}
+	r.POST("/query/:query_lang", noop)
+	r.OPTIONS("/*path", noop)
+	cayleyhttp.NewBoundAPIv2(def, router)
+	dbs := cayleyhttp.NewDatabases(open)
+	dbs.RegisterOn(router)
+	// names of databases are also parts of their routes
+	names := []string{"a", "p", "at", "path", "api"}
+	for _, name := range names {
+		if err := dbs.Open(cayleyhttp.DatabaseConfig{Name: name, Backend: memstore.QuadStoreType}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l, err := cayleyhttp.NewLimiter(cayleyhttp.LimitConfig{ClassLimits: cayleyhttp.ClassLimits{
+		Query: cayleyhttp.Limit{Rate: 1},
+		Write: cayleyhttp.Limit{Rate: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Instrument(limitRoutes(l).Wrap(router))
+
+	client := 0
+	type result struct {
+		code        int
+		route, lang string
+	}
+	do := func(method, path, body string) (first, second result) {
+		client++
+		for _, res := range []*result{&first, &second} {
+			buf.Reset()
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.RemoteAddr = fmt.Sprintf("10.0.0.%d:1234", client)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			// the request span ends last
+			var span trace.SpanData
+			for dec := json.NewDecoder(buf); dec.More(); {
+				span = trace.SpanData{}
+				if err := dec.Decode(&span); err != nil {
+					t.Fatal(err)
+				}
+			}
+			res.code = rr.Code
+			res.route, _ = span.Attrs["route"].(string)
+			res.lang, _ = span.Attrs["lang"].(string)
+		}
+		return first, second
+	}
+	for _, c := range []struct {
+		method, path, body string
+		route, lang        string
+		limited            bool
+	}{
+		{"POST", "/query/gizmo", "", "/query/:query_lang", "gizmo", true},
+		{"POST", "/query/unknown", "", "/query/:query_lang", "", true},
+		{"POST", "/api/v2/query?lang=gizmo", "g.V().all()", "/api/v2/query", "gizmo", true},
+		{"POST", "/api/v2/read", "", "/api/v2/read", "", false},
+		{"OPTIONS", "/api/v2/query", "", "/*path", "", false},
+		{"GET", "/index.html", "", routeOther, "", false},
+	} {
+		first, second := do(c.method, c.path, c.body)
+		if first.code != http.StatusOK && first.code != http.StatusNotFound {
+			t.Errorf("%s %s: unexpected code: %d", c.method, c.path, first.code)
+		} else if first.route != c.route || first.lang != c.lang {
+			t.Errorf("%s %s: got %q, %q; expected %q, %q", c.method, c.path, first.route, first.lang, c.route, c.lang)
+		} else if limited := second.code == http.StatusTooManyRequests; limited != c.limited {
+			t.Errorf("%s %s: unexpected limits: %d", c.method, c.path, second.code)
+		}
+	}
+	for _, name := range names {
+		for _, c := range []struct {
+			path    string
+			limited bool
+		}{
+			{"/api/v2/query?lang=gizmo", true},
+			{"/api/v2/write", true},
+			{"/api/v2/read", false},
+			{"/api/v2/procedures/count", true},
+		} {
+			path := "/db/" + name + c.path
+			first, second := do("POST", path, "")
+			if first.route != cayleyhttp.DatabaseRoute || first.lang != "" {
+				t.Errorf("%s: got %q, %q", path, first.route, first.lang)
+			} else if limited := second.code == http.StatusTooManyRequests; limited != c.limited {
+				t.Errorf("%s: unexpected limits: %d", path, second.code)
+			}
+		}
+	}
+}
+
+func TestInstrumentTrace(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	trace.SetExporter(trace.NewWriterExporter(buf))
+	defer trace.SetExporter(nil)
+
+	r := cayleyhttp.Routes{Router: httprouter.New()}
+	var inner trace.SpanContext
+	r.GET("/api/v2/read", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		inner = trace.SpanContextFromContext(r.Context())
+		w.Write([]byte("ok"))
+	})
+	h := Instrument(r)
+
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest("GET", "/api/v2/read", nil)
+	req.Header.Set(trace.Header, parent)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	sc, err := trace.ParseTraceparent(rr.Header().Get(trace.Header))
+	if err != nil {
+		t.Fatal(err)
+	} else if sc != inner {
+		t.Fatalf("unexpected span context: %v vs %v", sc, inner)
+	} else if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace ID is not propagated: %v", sc.TraceID)
+	}
+	var span trace.SpanData
+	if err = json.Unmarshal(buf.Bytes(), &span); err != nil {
+		t.Fatal(err)
+	} else if span.ParentID != "00f067aa0ba902b7" || span.Attrs["route"] != "/api/v2/read" || span.Attrs["status"] != 200.0 {
+		t.Fatalf("unexpected span: %+v", span)
+	}
+}
@@ -114,10 +114,18 @@ func (api *API) ServeV1Query(w http.ResponseWriter, r *http.Request, params http
 		errFunc(w, err)
 		return
 	}
-	it, err := ses.Execute(ctx, string(bodyBytes), query.Options{
+	opt := query.Options{
 		Collation: query.JSON,
 		Limit:     limit,
-	})
+	}
+	if api.ns != nil {
+		opt.Namespaces, err = api.ns.Namespaces(ctx)
+		if err != nil {
+			errFunc(w, err)
+			return
+		}
+	}
+	it, err := ses.Execute(ctx, string(bodyBytes), opt)
 	if err != nil {
 		errFunc(w, err)
 		return
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
+)
+
+// uiServerURL is the address of the API compiled into the web UI.
+const uiServerURL = `"http://localhost:64210"`
+
+// uiSwitcher is injected into the UI index page. It lists databases served by the server and allows switching
+// between them. The UI served under /db/{name}/ sends API requests to that database.
+const uiSwitcher = `<script>window.CAYLEY_DATABASE=%DB%;if(window.CAYLEY_DATABASE)window.CAYLEY_SERVER_URL=location.origin+"/db/"+window.CAYLEY_DATABASE;
+window.addEventListener("load",function(){fetch("/api/v2/databases").then(function(r){return r.ok?r.json():[]}).then(function(dbs){
+if(!dbs||!dbs.length)return;var s=document.createElement("select");s.style.cssText="position:fixed;top:8px;right:8px;z-index:1000";
+["default"].concat(dbs.map(function(d){return d.name})).forEach(function(n){var o=document.createElement("option");o.value=o.text=n;s.appendChild(o)});
+s.value=window.CAYLEY_DATABASE||"default";s.onchange=function(){location.href=s.value==="default"?"/":"/db/"+s.value+"/"};document.body.appendChild(s)})});</script>`
+
+// uiHandler serves the web UI at the root and under /db/{name}/ for named databases.
+type uiHandler struct {
+	files http.Handler
+	index []byte
+	// main is the path and the content of the script that contains the API address.
+	main     string
+	mainData []byte
+}
+
+func newUIHandler(ui fs.FS) (*uiHandler, error) {
+	h := &uiHandler{files: http.FileServer(http.FS(ui))}
+	var err error
+	h.index, err = fs.ReadFile(ui, "index.html")
+	if err != nil {
+		return nil, err
+	}
+	var manifest struct {
+		Files map[string]string `json:"files"`
+	}
+	data, err := fs.ReadFile(ui, "asset-manifest.json")
+	if err != nil {
+		return nil, err
+	} else if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	if main := manifest.Files["main.js"]; main != "" {
+		data, err = fs.ReadFile(ui, strings.TrimPrefix(main, "/"))
+		if err != nil {
+			return nil, err
+		}
+		// let the page choose the API address
+		h.main = main
+		h.mainData = bytes.Replace(data, []byte(uiServerURL), []byte(`(window.CAYLEY_SERVER_URL||`+uiServerURL+`)`), 1)
+	}
+	return h, nil
+}
+
+func (h *uiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := path.Clean("/" + r.URL.Path)
+	db, _ := cayleyhttp.DatabaseFromContext(r.Context())
+	switch {
+	case p == "/" || p == "/index.html":
+		name, _ := json.Marshal(db)
+		script := strings.Replace(uiSwitcher, "%DB%", string(name), 1)
+		page := bytes.Replace(h.index, []byte("<script>"), []byte(script+"<script>"), 1)
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeContent(w, r, "index.html", time.Time{}, bytes.NewReader(page))
+	case p == h.main && db == "":
+		http.ServeContent(w, r, path.Base(p), time.Time{}, bytes.NewReader(h.mainData))
+	default:
+		h.files.ServeHTTP(w, r)
+	}
+}
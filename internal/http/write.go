@@ -28,6 +28,7 @@ import (
 	"github.com/cayleygraph/cayley/clog"
 	"github.com/cayleygraph/cayley/graph"
 	"github.com/cayleygraph/cayley/internal/decompressor"
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
 	"github.com/cayleygraph/quad"
 	"github.com/cayleygraph/quad/nquads"
 )
@@ -90,7 +91,7 @@ func (api *API) ServeV1Write(w http.ResponseWriter, r *http.Request, _ httproute
 		jsonResponse(w, 400, err)
 		return
 	}
-	if err = h.QuadWriter.AddQuadSet(quads); err != nil {
+	if err = cayleyhttp.RequestWriter(r, h.QuadWriter).AddQuadSet(quads); err != nil {
 		jsonResponse(w, 400, err)
 		return
 	}
@@ -125,7 +126,7 @@ func (api *API) ServeV1WriteNQuad(w http.ResponseWriter, r *http.Request, params
 		jsonResponse(w, 400, err)
 		return
 	}
-	qw := graph.NewWriter(h.QuadWriter)
+	qw := graph.NewWriter(cayleyhttp.RequestWriter(r, h.QuadWriter))
 	n, err := quad.CopyBatch(qw, dec, blockSize)
 	if err != nil {
 		jsonResponse(w, 400, err)
@@ -159,8 +160,9 @@ func (api *API) ServeV1Delete(w http.ResponseWriter, r *http.Request, params htt
 		jsonResponse(w, 400, err)
 		return
 	}
+	qw := cayleyhttp.RequestWriter(r, h.QuadWriter)
 	for _, q := range quads {
-		err = h.QuadWriter.RemoveQuad(q)
+		err = qw.RemoveQuad(q)
 		if err != nil && !graph.IsQuadNotExist(err) {
 			jsonResponse(w, 400, err)
 			return
@@ -87,7 +87,19 @@ const (
 	history = ".cayley_history"
 )
 
-func Repl(ctx context.Context, h *graph.Handle, queryLanguage string, timeout time.Duration) error {
+// Databases opens named databases for the :db command.
+type Databases interface {
+	// Names returns the names of databases that can be opened.
+	Names() []string
+	// Open opens a database with a given name.
+	Open(name string) (*graph.Handle, error)
+}
+
+// defaultDatabase is the name of the database passed to Repl.
+const defaultDatabase = "default"
+
+// Repl runs an interactive session for the database. If dbs is not nil, the :db command switches to other databases.
+func Repl(ctx context.Context, h *graph.Handle, queryLanguage string, timeout time.Duration, dbs Databases) error {
 	if queryLanguage == "" {
 		queryLanguage = defaultLanguage
 	}
@@ -97,6 +109,31 @@ func Repl(ctx context.Context, h *graph.Handle, queryLanguage string, timeout ti
 	}
 	ses := l.Session(h.QuadStore)
 
+	var (
+		def = h
+		cur = defaultDatabase
+	)
+	defer func() {
+		if h != def {
+			h.Close()
+		}
+	}()
+	useDatabase := func(name string) error {
+		next := def
+		if name != defaultDatabase {
+			var err error
+			if next, err = dbs.Open(name); err != nil {
+				return err
+			}
+		}
+		if h != def {
+			h.Close()
+		}
+		h, cur = next, name
+		ses = l.Session(h.QuadStore)
+		return nil
+	}
+
 	term, err := terminal(history)
 	if os.IsNotExist(err) {
 		fmt.Printf("creating new history file: %q\n", history)
@@ -191,8 +228,31 @@ func Repl(ctx context.Context, h *graph.Handle, queryLanguage string, timeout ti
 				}
 				continue
 
+			case ":db":
+				if dbs == nil {
+					fmt.Println("Error: named databases are not configured")
+					continue
+				}
+				name := strings.TrimSpace(args)
+				if name == "" {
+					for _, n := range append([]string{defaultDatabase}, dbs.Names()...) {
+						mark := " "
+						if n == cur {
+							mark = "*"
+						}
+						fmt.Printf("%s %s\n", mark, n)
+					}
+					continue
+				}
+				if err := useDatabase(name); err != nil {
+					fmt.Printf("Error: cannot open database %q: %v\n", name, err)
+					continue
+				}
+				fmt.Printf("Using database %q\n", name)
+				continue
+
 			case "help":
-				fmt.Printf("Help\n\texit // Exit\n\thelp // this help\n\td: <quad> // delete quad\n\ta: <quad> // add quad\n\t:debug [t|f]\n")
+				fmt.Printf("Help\n\texit // Exit\n\thelp // this help\n\td: <quad> // delete quad\n\ta: <quad> // add quad\n\t:debug [t|f]\n\t:db [name] // list databases or switch to one\n")
 				continue
 
 			case "exit":
@@ -23,7 +23,9 @@ import (
 
 	"github.com/dop251/goja"
 
+	"github.com/cayleygraph/cayley/graph"
 	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/query"
 	"github.com/cayleygraph/cayley/query/path"
 	"github.com/cayleygraph/cayley/query/shape"
 	"github.com/cayleygraph/quad"
@@ -75,7 +77,7 @@ func (g *graphObject) NewV(call goja.FunctionCall) goja.Value {
 //
 // Returns: Path object
 func (g *graphObject) NewVertex(call goja.FunctionCall) goja.Value {
-	qv, err := toQuadValues(exportArgs(call.Arguments))
+	qv, err := toQuadValues(g.s.dbns, exportArgs(call.Arguments))
 	if err != nil {
 		return throwErr(g.s.vm, err)
 	}
@@ -120,6 +122,70 @@ func (g *graphObject) Emit(call goja.FunctionCall) goja.Value {
 	return goja.Null()
 }
 
+// AddQuad adds a quad to the graph.
+// Signature: (subject, predicate, object, [label])
+//
+// Changes are not visible to the query, they are applied in a single transaction when the query finishes,
+// and only if it succeeds. Writes are only allowed if the session has a quad writer, see NewSession.
+//
+// Arguments:
+//
+// * `subject`, `predicate`, `object`: Values of the quad, in the same format as in `graph.V`.
+// * `label` (Optional): A label of the quad.
+//
+//	// javascript
+//	g.V("<alice>").out("<follows>").forEach(function(d) {
+//		g.addQuad(d.id, "<followed_by>", "<alice>")
+//	})
+func (g *graphObject) AddQuad(call goja.FunctionCall) goja.Value {
+	return g.s.changeQuad(call, graph.Add)
+}
+
+// RemoveQuad removes a quad from the graph. See AddQuad for details.
+// Signature: (subject, predicate, object, [label])
+func (g *graphObject) RemoveQuad(call goja.FunctionCall) goja.Value {
+	return g.s.changeQuad(call, graph.Delete)
+}
+
+// Transaction calls a function and groups the changes made by it. If the function throws an exception,
+// its changes are discarded and the exception is re-thrown. Returns the value returned by the function.
+// Signature: (fn)
+//
+//	// javascript
+//	g.transaction(function() {
+//		g.removeQuad("<bob>", "<status>", "cool_person")
+//		g.addQuad("<bob>", "<status>", "smart_person")
+//	})
+func (g *graphObject) Transaction(call goja.FunctionCall) goja.Value {
+	fnc, ok := goja.AssertFunction(call.Argument(0))
+	if !ok {
+		return throwErr(g.s.vm, fmt.Errorf("expected js callback function"))
+	} else if g.s.writer() == nil {
+		return throwErr(g.s.vm, errReadOnly)
+	}
+	var saved []graph.Delta
+	if g.s.tx != nil {
+		saved = append(saved, g.s.tx.Deltas...)
+	}
+	v, err := fnc(call.This)
+	if err != nil {
+		// roll back the changes made by the function
+		g.s.tx = graph.NewTransactionN(len(saved))
+		for _, d := range saved {
+			if d.Action == graph.Add {
+				g.s.tx.AddQuad(d.Quad)
+			} else {
+				g.s.tx.RemoveQuad(d.Quad)
+			}
+		}
+		if e, ok := err.(*goja.Exception); ok {
+			panic(e.Value())
+		}
+		return throwErr(g.s.vm, err)
+	}
+	return v
+}
+
 // Backwards compatibility
 func (g *graphObject) CapitalizedUri(s string) quad.IRI {
 	return g.NewIRI(s)
@@ -163,7 +229,7 @@ func cmpOpType(op iterator.Operator) func(vm *goja.Runtime, call goja.FunctionCa
 		if len(args) != 1 {
 			return throwErr(vm, errArgCount2{Expected: 1, Got: len(args)})
 		}
-		qv, err := toQuadValue(args[0])
+		qv, err := toQuadValue(nil, args[0])
 		if err != nil {
 			return throwErr(vm, err)
 		}
@@ -188,7 +254,7 @@ func cmpRegexp(vm *goja.Runtime, call goja.FunctionCall) goja.Value {
 	if len(args) != 1 && len(args) != 2 {
 		return throwErr(vm, errArgCount2{Expected: 1, Got: len(args)})
 	}
-	v, err := toQuadValue(args[0])
+	v, err := toQuadValue(nil, args[0])
 	if err != nil {
 		return throwErr(vm, err)
 	}
@@ -303,7 +369,8 @@ func toInt(o interface{}) (int, bool) {
 	}
 }
 
-func toQuadValue(o interface{}) (quad.Value, error) {
+// toQuadValue converts a JS value to a quad value. Prefixed IRIs are expanded with given namespaces.
+func toQuadValue(ns *voc.Namespaces, o interface{}) (quad.Value, error) {
 	var qv quad.Value
 	switch v := o.(type) {
 	case quad.Value:
@@ -327,16 +394,16 @@ func toQuadValue(o interface{}) (quad.Value, error) {
 	default:
 		return nil, errNotQuadValue{Val: o}
 	}
-	return qv, nil
+	return query.ExpandValue(qv, ns), nil
 }
 
-func toQuadValues(objs []interface{}) ([]quad.Value, error) {
+func toQuadValues(ns *voc.Namespaces, objs []interface{}) ([]quad.Value, error) {
 	if len(objs) == 0 {
 		return nil, nil
 	}
 	vals := make([]quad.Value, 0, len(objs))
 	for _, o := range objs {
-		qv, err := toQuadValue(o)
+		qv, err := toQuadValue(ns, o)
 		if err != nil {
 			return nil, err
 		}
@@ -367,20 +434,20 @@ func toStrings(objs []interface{}) []string {
 	return out
 }
 
-func toVia(via []interface{}) []interface{} {
+func toVia(ns *voc.Namespaces, via []interface{}) []interface{} {
 	if len(via) == 0 {
 		return nil
 	} else if len(via) == 1 {
 		if via[0] == nil {
 			return nil
 		} else if v, ok := via[0].([]interface{}); ok {
-			return toVia(v)
+			return toVia(ns, v)
 		} else if v, ok := via[0].([]string); ok {
 			arr := make([]interface{}, 0, len(v))
 			for _, s := range v {
 				arr = append(arr, s)
 			}
-			return toVia(arr)
+			return toVia(ns, arr)
 		}
 	}
 	for i := range via {
@@ -388,7 +455,7 @@ func toVia(via []interface{}) []interface{} {
 			// bypass
 		} else if vp, ok := via[i].(*pathObject); ok {
 			via[i] = vp.path
-		} else if qv, err := toQuadValue(via[i]); err == nil {
+		} else if qv, err := toQuadValue(ns, via[i]); err == nil {
 			via[i] = qv
 		} else {
 			panic(fmt.Errorf("unsupported type: %T", via[i]))
@@ -397,9 +464,9 @@ func toVia(via []interface{}) []interface{} {
 	return via
 }
 
-func toViaData(objs []interface{}) (predicates []interface{}, tags []string, ok bool) {
+func toViaData(ns *voc.Namespaces, objs []interface{}) (predicates []interface{}, tags []string, ok bool) {
 	if len(objs) != 0 {
-		predicates = toVia([]interface{}{objs[0]})
+		predicates = toVia(ns, []interface{}{objs[0]})
 	}
 	if len(objs) > 1 {
 		tags = toStrings(objs[1:])
@@ -408,9 +475,9 @@ func toViaData(objs []interface{}) (predicates []interface{}, tags []string, ok
 	return
 }
 
-func toViaDepthData(objs []interface{}) (predicates []interface{}, maxDepth int, tags []string, ok bool) {
+func toViaDepthData(ns *voc.Namespaces, objs []interface{}) (predicates []interface{}, maxDepth int, tags []string, ok bool) {
 	if len(objs) != 0 {
-		predicates = toVia([]interface{}{objs[0]})
+		predicates = toVia(ns, []interface{}{objs[0]})
 	}
 	if len(objs) > 1 {
 		maxDepth, ok = toInt(objs[1])
@@ -0,0 +1,76 @@
+package gizmo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/path"
+	"github.com/cayleygraph/quad"
+)
+
+func runGizmo(qs graph.QuadStore, qu string) ([]interface{}, error) {
+	ctx := context.TODO()
+	it, err := NewSession(qs).Execute(ctx, qu, query.Options{Collation: query.JSON})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var out []interface{}
+	for it.Next(ctx) {
+		out = append(out, it.Result())
+	}
+	return out, it.Err()
+}
+
+func TestGizmoWrite(t *testing.T) {
+	h := modulesHandle(t)
+	statuses := func(id string) []quad.Value {
+		vals, err := path.StartPath(h, quad.IRI(id)).Out(quad.IRI("status")).Iterate(context.TODO()).AllValues(h)
+		require.NoError(t, err)
+		return vals
+	}
+
+	out, err := runGizmo(h, `
+g.V("<charlie>", "<dani>").forEach(function(d) {
+	g.addQuad(d.id, "<status>", "migrated")
+})
+g.transaction(function() {
+	g.removeQuad("<bob>", "<status>", "cool_person")
+	g.addQuad("<bob>", "<status>", "smart_person")
+})
+try {
+	g.transaction(function() {
+		g.addQuad("<alice>", "<status>", "lost")
+		g.removeQuad("<charlie>", "<status>", "migrated")
+		throw new Error("rollback")
+	})
+} catch (e) {}
+// changes are applied when the query finishes
+g.V("<charlie>").out("<status>").all()
+`)
+	require.NoError(t, err)
+	require.Empty(t, out)
+	require.Equal(t, []quad.Value{quad.String("migrated")}, statuses("charlie"))
+	require.ElementsMatch(t, []quad.Value{quad.String("cool_person"), quad.String("migrated")}, statuses("dani"))
+	require.Equal(t, []quad.Value{quad.String("smart_person")}, statuses("bob"))
+	require.Empty(t, statuses("alice"))
+
+	// failed queries don't change the graph
+	_, err = runGizmo(h, `
+g.addQuad("<alice>", "<status>", "lost")
+throw new Error("fail")
+`)
+	require.Error(t, err)
+	require.Empty(t, statuses("alice"))
+
+	// sessions without a writer are read-only
+	_, err = runGizmo(h.QuadStore, `g.addQuad("<alice>", "<status>", "lost")`)
+	require.Error(t, err)
+	_, err = runGizmo(&graph.Handle{QuadStore: h.QuadStore}, `g.transaction(function() {})`)
+	require.Error(t, err)
+	require.Empty(t, statuses("alice"))
+}
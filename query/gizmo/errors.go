@@ -19,6 +19,7 @@ import "fmt"
 var (
 	errNoVia       = fmt.Errorf("expected predicate list")
 	errRegexpOnIRI = fmt.Errorf("regexps are not allowed on IRIs")
+	errReadOnly    = fmt.Errorf("writes are not allowed: database is read-only or the session has no write permission")
 )
 
 type errArgCount2 struct {
@@ -46,6 +46,9 @@ func init() {
 	})
 }
 
+// NewSession creates a new Gizmo session.
+//
+// Queries of the session can change the graph only if qs is a *graph.Handle with a quad writer.
 func NewSession(qs graph.QuadStore) *Session {
 	s := &Session{
 		ctx: context.Background(),
@@ -87,11 +90,18 @@ type Session struct {
 	vm  *goja.Runtime
 	ns  voc.Namespaces
 	sch *schema.Config
-	col query.Collation
+	// namespaces of the database, see query.Options
+	dbns *voc.Namespaces
+	col  query.Collation
 
 	last string
 	p    *goja.Program
 
+	mods    *Modules
+	exports map[*Module]goja.Value // exports of imported modules, nil while loading
+
+	tx *graph.Transaction // changes made by the query, applied when it finishes
+
 	out   chan *Result
 	ctx   context.Context
 	limit int
@@ -112,6 +122,7 @@ func (s *Session) buildEnv() error {
 	s.vm.SetFieldNameMapper(fieldNameMapper{})
 	s.vm.Set("graph", &graphObject{s: s})
 	s.vm.Set("g", s.vm.Get("graph"))
+	s.vm.Set("require", s.require)
 	for name, val := range defaultEnv {
 		fnc := val
 		s.vm.Set(name, func(call goja.FunctionCall) goja.Value {
@@ -125,6 +136,7 @@ func (s *Session) quadValueToNative(v quad.Value) interface{} {
 	if v == nil {
 		return nil
 	}
+	v = query.CompactValue(v, s.dbns)
 	if s.col == query.JSONLD {
 		return jsonld.FromValue(v)
 	}
@@ -277,12 +289,7 @@ func (s *Session) compile(qu string) error {
 
 func (s *Session) run() (goja.Value, error) {
 	v, err := s.vm.RunProgram(s.p)
-	if e, ok := err.(*goja.Exception); ok && e.Value() != nil {
-		if er, ok := e.Value().Export().(error); ok {
-			err = er
-		}
-	}
-	return v, err
+	return v, jsError(err)
 }
 func (s *Session) Execute(ctx context.Context, qu string, opt query.Options) (query.Iterator, error) {
 	switch opt.Collation {
@@ -290,24 +297,192 @@ func (s *Session) Execute(ctx context.Context, qu string, opt query.Options) (qu
 	default:
 		return nil, &query.ErrUnsupportedCollation{Collation: opt.Collation}
 	}
+	if m := modulesFromContext(ctx); m != nil {
+		s.mods = m
+	}
 	if err := s.compile(qu); err != nil {
 		return nil, err
 	}
+	return s.start(opt, s.run), nil
+}
+
+// SetModules sets modules that can be imported by queries with require. See also WithModules.
+func (s *Session) SetModules(m *Modules) {
+	s.mods = m
+}
+
+// start prepares the session to run a given function with query options.
+func (s *Session) start(opt query.Options, run func() (goja.Value, error)) *results {
+	s.qs = query.QuadStoreFor(s.qs, opt)
 	s.limit = opt.Limit
 	s.count = 0
+	s.tx = nil
+	s.dbns = opt.Namespaces
+	if s.dbns != nil {
+		s.dbns.CloneTo(&s.ns)
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	s.ctx = ctx
 	s.col = opt.Collation
 	return &results{
 		col: opt.Collation,
 		s:   s,
+		run: run,
 		ctx: ctx, cancel: cancel,
-	}, nil
+	}
+}
+
+// writer returns a quad writer of the session, or nil if the session is read-only.
+func (s *Session) writer() graph.QuadWriter {
+	if h, ok := s.qs.(*graph.Handle); ok {
+		return h.QuadWriter
+	}
+	return nil
+}
+
+// changeQuad records a quad change in the transaction of the query.
+func (s *Session) changeQuad(call goja.FunctionCall, act graph.Procedure) goja.Value {
+	if s.writer() == nil {
+		return throwErr(s.vm, errReadOnly)
+	}
+	args := exportArgs(call.Arguments)
+	if len(args) != 3 && len(args) != 4 {
+		return throwErr(s.vm, errArgCount{Got: len(args)})
+	}
+	vals, err := toQuadValues(s.dbns, args)
+	if err != nil {
+		return throwErr(s.vm, err)
+	}
+	q := quad.Quad{Subject: vals[0], Predicate: vals[1], Object: vals[2]}
+	if len(vals) == 4 {
+		q.Label = vals[3]
+	}
+	if !q.IsValid() {
+		return throwErr(s.vm, fmt.Errorf("invalid quad: %v", q))
+	}
+	if s.tx == nil {
+		s.tx = graph.NewTransaction()
+	}
+	if act == graph.Add {
+		s.tx.AddQuad(q)
+	} else {
+		s.tx.RemoveQuad(q)
+	}
+	return goja.Undefined()
+}
+
+// commit applies the changes made by the query.
+func (s *Session) commit() error {
+	tx := s.tx
+	s.tx = nil
+	if tx == nil || len(tx.Deltas) == 0 {
+		return nil
+	}
+	qw := s.writer()
+	if qw == nil {
+		return errReadOnly
+	}
+	return qw.ApplyTransaction(tx)
+}
+
+// jsError converts JS exceptions that wrap Go errors back to errors.
+func jsError(err error) error {
+	if e, ok := err.(*goja.Exception); ok && e.Value() != nil {
+		if er, ok := e.Value().Export().(error); ok {
+			err = er
+		}
+	}
+	return err
+}
+
+// require implements the require function of the environment.
+//
+// Signature: (name)
+//
+// Arguments:
+//
+// * `name`: A name of the module, optionally followed by "@" and a version number.
+//
+// Returns: Exports of the module.
+func (s *Session) require(call goja.FunctionCall) goja.Value {
+	ref, ok := call.Argument(0).Export().(string)
+	if !ok {
+		return throwErr(s.vm, errors.New("require: module name must be a string"))
+	} else if s.mods == nil {
+		return throwErr(s.vm, fmt.Errorf("require: modules are not available: %q", ref))
+	}
+	name, vers, err := ParseModuleRef(ref)
+	if err != nil {
+		return throwErr(s.vm, err)
+	}
+	mod, err := s.mods.Get(s.context(), name, vers)
+	if err != nil {
+		return throwErr(s.vm, fmt.Errorf("require %q: %w", ref, err))
+	}
+	v, err := s.load(mod)
+	if err != nil {
+		return throwErr(s.vm, err)
+	}
+	return v
+}
+
+// load runs the module once per session and returns its exports.
+func (s *Session) load(mod *Module) (goja.Value, error) {
+	if v, ok := s.exports[mod]; ok {
+		if v == nil {
+			return nil, fmt.Errorf("cyclic import of module %q", mod.Name)
+		}
+		return v, nil
+	}
+	if s.exports == nil {
+		s.exports = make(map[*Module]goja.Value)
+	}
+	s.exports[mod] = nil
+	v, err := s.vm.RunProgram(mod.prog)
+	if err != nil {
+		delete(s.exports, mod)
+		return nil, jsError(err)
+	}
+	fnc, ok := goja.AssertFunction(v)
+	if !ok {
+		delete(s.exports, mod)
+		return nil, fmt.Errorf("cannot load module %q", mod.Name)
+	}
+	exports := s.vm.NewObject()
+	module := s.vm.NewObject()
+	module.Set("exports", exports)
+	if _, err = fnc(goja.Undefined(), exports, module, s.vm.Get("require")); err != nil {
+		delete(s.exports, mod)
+		return nil, jsError(err)
+	}
+	v = module.Get("exports")
+	s.exports[mod] = v
+	return v, nil
+}
+
+// call prepares the session to call a function exported by the module.
+//
+// The module is loaded when the results are iterated, thus loading is also interrupted if the context is canceled.
+func (s *Session) call(mod *Module, params interface{}, opt query.Options) *results {
+	s.ns = voc.Namespaces{}
+	return s.start(opt, func() (goja.Value, error) {
+		exports, err := s.load(mod)
+		if err != nil {
+			return nil, err
+		}
+		fnc, ok := goja.AssertFunction(exports)
+		if !ok {
+			return nil, fmt.Errorf("module %q does not export a function", mod.Name)
+		}
+		v, err := fnc(goja.Undefined(), s.vm.ToValue(params))
+		return v, jsError(err)
+	})
 }
 
 type results struct {
 	s      *Session
 	col    query.Collation
+	run    func() (goja.Value, error)
 	ctx    context.Context
 	cancel func()
 
@@ -334,7 +509,10 @@ func (it *results) Next(ctx context.Context) bool {
 		it.running = true
 		go func() {
 			defer close(it.errc)
-			v, err := it.s.run()
+			v, err := it.run()
+			if err == nil && it.ctx.Err() == nil {
+				err = it.s.commit()
+			}
 			if err != nil {
 				it.errc <- err
 				return
@@ -349,6 +527,7 @@ func (it *results) Next(ctx context.Context) bool {
 		it.cur = r
 		return true
 	case err := <-it.errc:
+		it.running = false
 		if err != nil {
 			it.err = err
 		}
@@ -0,0 +1,422 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gizmo
+
+// Modules and stored procedures.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dop251/goja"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/schema"
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc"
+)
+
+// ErrModuleNotFound is returned if a module with a given name or version does not exist.
+var ErrModuleNotFound = errors.New("module not found")
+
+// ModuleExt is an extension of module files loaded by LoadDir.
+const ModuleExt = ".js"
+
+var reModuleName = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*(/[a-zA-Z0-9_][a-zA-Z0-9_.-]*)*$`)
+
+// Module is a named Gizmo script that can be imported by other scripts with require,
+// or called as a stored procedure.
+//
+// The script is executed as a function with exports, module and require arguments, similar to CommonJS modules.
+// Values assigned to the exports object, or to module.exports, are returned by require:
+//
+//	// javascript
+//	exports.friends = g.M().out("<follows>")
+//
+// Modules that export a single function can be called as stored procedures, see Modules.Call.
+type Module struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Source  string `json:"source,omitempty"`
+
+	stored bool
+	prog   *goja.Program
+}
+
+// storedModule is a module as written to the graph.
+type storedModule struct {
+	_       struct{} `quad:"@type > cayley:gizmo_module"`
+	ID      quad.IRI `quad:"@id"`
+	Name    string   `quad:"cayley:name"`
+	Version int      `quad:"cayley:version"`
+	Source  string   `quad:"cayley:source"`
+}
+
+func moduleIRI(name string, version int) quad.IRI {
+	return quad.IRI("cayley:gizmo_module/" + name + "@" + strconv.Itoa(version))
+}
+
+// compileModule compiles the module source wrapped into a function.
+func compileModule(name, src string) (*goja.Program, error) {
+	if !reModuleName.MatchString(name) {
+		return nil, fmt.Errorf("invalid module name: %q", name)
+	}
+	return goja.Compile(name, "(function(exports, module, require) {"+src+"\n})", false)
+}
+
+// ParseModuleRef splits a module reference in the "name@version" form.
+// Version is zero if it's not set, which means the latest version.
+func ParseModuleRef(ref string) (string, int, error) {
+	i := strings.LastIndexByte(ref, '@')
+	if i < 0 {
+		return ref, 0, nil
+	}
+	vers, err := strconv.Atoi(ref[i+1:])
+	if err != nil || vers <= 0 {
+		return "", 0, fmt.Errorf("invalid module version: %q", ref)
+	}
+	return ref[:i], vers, nil
+}
+
+// Modules is a set of Gizmo modules, loaded from a directory or persisted in a graph.
+// Each change of the module creates a new version; old versions stay available until the module is deleted.
+//
+// It is safe for concurrent use.
+type Modules struct {
+	c *schema.Config
+	h *graph.Handle
+
+	mu     sync.RWMutex
+	loaded bool
+	mods   map[string][]*Module // versions in ascending order
+
+	pools sync.Map // scope -> *sync.Pool of *Session
+}
+
+// NewModules creates a registry of modules stored in a given graph.
+// Modules are loaded from the graph on the first access, or by calling Load.
+//
+// If the handle is nil, the modules are not persisted and can only be added with Add or LoadDir.
+func NewModules(h *graph.Handle) *Modules {
+	return &Modules{c: schema.NewConfig(), h: h}
+}
+
+// Load reloads the list of modules from the graph. Modules that were not stored in the graph are kept.
+func (m *Modules) Load(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.load(ctx)
+}
+
+func (m *Modules) load(ctx context.Context) error {
+	mods := make(map[string][]*Module, len(m.mods))
+	for name, list := range m.mods {
+		for _, mod := range list {
+			if !mod.stored {
+				mods[name] = append(mods[name], mod)
+			}
+		}
+	}
+	if m.h != nil {
+		var list []storedModule
+		if err := m.c.LoadTo(ctx, m.h.QuadStore, &list); err != nil {
+			return err
+		}
+		for _, sm := range list {
+			p, err := compileModule(sm.Name, sm.Source)
+			if err != nil {
+				return fmt.Errorf("cannot compile module %s@%d: %w", sm.Name, sm.Version, err)
+			}
+			mods[sm.Name] = append(mods[sm.Name], &Module{
+				Name: sm.Name, Version: sm.Version, Source: sm.Source,
+				stored: true, prog: p,
+			})
+		}
+	}
+	for _, list := range mods {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Version < list[j].Version
+		})
+	}
+	m.mods = mods
+	m.loaded = true
+	return nil
+}
+
+func (m *Modules) ensureLoaded(ctx context.Context) error {
+	m.mu.RLock()
+	loaded := m.loaded
+	m.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.loaded {
+		return nil
+	}
+	return m.load(ctx)
+}
+
+// nextVersion returns a version number for a new version of the module.
+func (m *Modules) nextVersion(name string) int {
+	list := m.mods[name]
+	if len(list) == 0 {
+		return 1
+	}
+	return list[len(list)-1].Version + 1
+}
+
+// Add registers a new version of the module without storing it in the graph.
+func (m *Modules) Add(ctx context.Context, name, src string) (*Module, error) {
+	p, err := compileModule(name, src)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mod := &Module{Name: name, Version: m.nextVersion(name), Source: src, prog: p}
+	m.mods[name] = append(m.mods[name], mod)
+	return mod, nil
+}
+
+// LoadDir registers all modules from files with ModuleExt extension in a given directory and its sub-directories.
+// The name of the module is a path to the file relative to the directory, without an extension.
+func (m *Modules) LoadDir(ctx context.Context, dir string) error {
+	return filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if fi.IsDir() || filepath.Ext(path) != ModuleExt {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(strings.TrimSuffix(rel, ModuleExt))
+		if _, err = m.Add(ctx, name, string(data)); err != nil {
+			return fmt.Errorf("cannot load module %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// Save stores a new version of the module in the graph.
+func (m *Modules) Save(ctx context.Context, name, src string) (*Module, error) {
+	if m.h == nil || m.h.QuadWriter == nil {
+		return nil, errors.New("modules are not persisted")
+	}
+	p, err := compileModule(name, src)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	vers := m.nextVersion(name)
+	tx := graph.NewTransaction()
+	_, err = m.c.WriteAsQuads(graph.NewTxWriter(tx, graph.Add), storedModule{
+		ID: moduleIRI(name, vers), Name: name, Version: vers, Source: src,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = m.h.QuadWriter.ApplyTransaction(tx); err != nil {
+		return nil, err
+	}
+	mod := &Module{Name: name, Version: vers, Source: src, stored: true, prog: p}
+	m.mods[name] = append(m.mods[name], mod)
+	return mod, nil
+}
+
+// Delete removes all versions of the module, including the ones stored in the graph.
+// It returns false if the module does not exist.
+func (m *Modules) Delete(ctx context.Context, name string) (bool, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list, ok := m.mods[name]
+	if !ok {
+		return false, nil
+	}
+	tx := graph.NewTransaction()
+	w := graph.NewTxWriter(tx, graph.Delete)
+	for _, mod := range list {
+		if !mod.stored {
+			continue
+		}
+		_, err := m.c.WriteAsQuads(w, storedModule{
+			ID: moduleIRI(mod.Name, mod.Version), Name: mod.Name, Version: mod.Version, Source: mod.Source,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	if len(tx.Deltas) != 0 {
+		if m.h == nil || m.h.QuadWriter == nil {
+			return false, errors.New("modules are not persisted")
+		}
+		if err := m.h.QuadWriter.ApplyTransaction(tx); err != nil {
+			return false, err
+		}
+	}
+	delete(m.mods, name)
+	return true, nil
+}
+
+// Get returns a given version of the module. Zero version means the latest one.
+func (m *Modules) Get(ctx context.Context, name string, version int) (*Module, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list := m.mods[name]
+	if len(list) == 0 {
+		return nil, ErrModuleNotFound
+	} else if version == 0 {
+		return list[len(list)-1], nil
+	}
+	i := sort.Search(len(list), func(i int) bool {
+		return list[i].Version >= version
+	})
+	if i >= len(list) || list[i].Version != version {
+		return nil, ErrModuleNotFound
+	}
+	return list[i], nil
+}
+
+// List returns the latest versions of all modules, sorted by name. Sources are not included.
+func (m *Modules) List(ctx context.Context) ([]Module, error) {
+	if err := m.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Module, 0, len(m.mods))
+	for name, list := range m.mods {
+		out = append(out, Module{Name: name, Version: list[len(list)-1].Version})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+type modulesKey struct{}
+
+// WithModules returns a new context with a given set of modules. Gizmo sessions executed
+// with this context can import these modules with require.
+func WithModules(ctx context.Context, m *Modules) context.Context {
+	return context.WithValue(ctx, modulesKey{}, m)
+}
+
+func modulesFromContext(ctx context.Context) *Modules {
+	m, _ := ctx.Value(modulesKey{}).(*Modules)
+	return m
+}
+
+type scopeKey struct{}
+
+// WithScope returns a new context with a given scope of procedure calls, for example the name of the user.
+// Calls with different scopes never share sessions, thus exports and module-level variables of one scope
+// are not visible to calls in another one.
+func WithScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// pool returns a pool of sessions for a scope of the context.
+func (m *Modules) pool(ctx context.Context) *sync.Pool {
+	scope, _ := ctx.Value(scopeKey{}).(string)
+	if p, ok := m.pools.Load(scope); ok {
+		return p.(*sync.Pool)
+	}
+	p, _ := m.pools.LoadOrStore(scope, new(sync.Pool))
+	return p.(*sync.Pool)
+}
+
+// session returns an idle session from the pool, or creates a new one.
+func (m *Modules) session(pool *sync.Pool, qs graph.QuadStore) *Session {
+	s, _ := pool.Get().(*Session)
+	if s == nil {
+		s = NewSession(qs)
+		s.mods = m
+		return s
+	}
+	s.qs = qs
+	s.ns = voc.Namespaces{}
+	return s
+}
+
+// Call runs the function exported by a given version of the module (zero means the latest one),
+// and returns values emitted by the function, followed by its return value.
+//
+// Parameters are passed to the function as a single argument. Calls reuse the sessions and cache
+// the modules imported by them, thus module-level variables may persist between calls with the same scope.
+// See WithScope.
+func (m *Modules) Call(ctx context.Context, qs graph.QuadStore, name string, version int, params interface{}, opt query.Options) ([]interface{}, error) {
+	switch opt.Collation {
+	case query.JSON, query.JSONLD:
+	default:
+		return nil, &query.ErrUnsupportedCollation{Collation: opt.Collation}
+	}
+	mod, err := m.Get(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	pool := m.pool(ctx)
+	s := m.session(pool, qs)
+	it := s.call(mod, params, opt)
+	var out []interface{}
+	for it.Next(ctx) {
+		if r := it.cur; r.Meta {
+			switch r.Val.(type) {
+			case *pathObject, *graphObject:
+			default:
+				out = append(out, r.Val)
+			}
+		} else {
+			out = append(out, it.Result())
+		}
+	}
+	it.Close()
+	if err = it.Err(); err != nil {
+		// the session may still be running, don't reuse it
+		return nil, err
+	}
+	s.qs, s.dbns = nil, nil
+	pool.Put(s)
+	return out, nil
+}
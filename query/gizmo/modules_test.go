@@ -0,0 +1,177 @@
+package gizmo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/graphtest/testutil"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/query"
+)
+
+const followersModule = `
+var followers = function(id) { return g.V(id).in("<follows>") }
+exports.followers = followers
+`
+
+func modulesHandle(t testing.TB) *graph.Handle {
+	qs := memstore.New(testutil.LoadGraph(t, "../../data/testdata.nq")...)
+	qw, err := graph.NewQuadWriter("single", qs, nil)
+	require.NoError(t, err)
+	return &graph.Handle{QuadStore: qs, QuadWriter: qw}
+}
+
+func TestModulesCall(t *testing.T) {
+	ctx := context.TODO()
+	h := modulesHandle(t)
+	m := NewModules(h)
+
+	_, err := m.Save(ctx, "social/follows", followersModule)
+	require.NoError(t, err)
+	mod, err := m.Save(ctx, "followers", `
+var social = require("social/follows")
+module.exports = function(p) { social.followers(p.id).all() }
+`)
+	require.NoError(t, err)
+	require.Equal(t, 1, mod.Version)
+
+	opt := query.Options{Collation: query.JSON}
+	params := map[string]interface{}{"id": "<bob>"}
+	expect := []interface{}{
+		map[string]interface{}{"id": "<alice>"},
+		map[string]interface{}{"id": "<charlie>"},
+		map[string]interface{}{"id": "<dani>"},
+	}
+	// second call reuses the session
+	for i := 0; i < 2; i++ {
+		out, err := m.Call(ctx, h.QuadStore, "followers", 0, params, opt)
+		require.NoError(t, err)
+		require.ElementsMatch(t, expect, out)
+	}
+
+	mod, err = m.Save(ctx, "followers", `
+module.exports = function(p) { return require("social/follows").followers(p.id).count() }
+`)
+	require.NoError(t, err)
+	require.Equal(t, 2, mod.Version)
+
+	out, err := m.Call(ctx, h.QuadStore, "followers", 0, params, opt)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(3)}, out)
+
+	out, err = m.Call(ctx, h.QuadStore, "followers", 1, params, opt)
+	require.NoError(t, err)
+	require.ElementsMatch(t, expect, out)
+
+	_, err = m.Call(ctx, h.QuadStore, "followers", 3, params, opt)
+	require.ErrorIs(t, err, ErrModuleNotFound)
+	_, err = m.Call(ctx, h.QuadStore, "social/follows", 0, params, opt)
+	require.Error(t, err)
+
+	// modules are loaded from the graph by a new registry
+	m = NewModules(h)
+	list, err := m.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []Module{
+		{Name: "followers", Version: 2},
+		{Name: "social/follows", Version: 1},
+	}, list)
+
+	ok, err := m.Delete(ctx, "followers")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, m.Load(ctx))
+	_, err = m.Get(ctx, "followers", 0)
+	require.ErrorIs(t, err, ErrModuleNotFound)
+}
+
+func TestModulesRequire(t *testing.T) {
+	ctx := context.TODO()
+	h := modulesHandle(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "social"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "social", "follows.js"), []byte(followersModule), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.js"), []byte(`exports.b = require("b")`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.js"), []byte(`exports.a = require("a")`), 0644))
+
+	m := NewModules(nil)
+	require.NoError(t, m.LoadDir(ctx, dir))
+	_, err := m.Save(ctx, "c", `exports.c = 1`)
+	require.Error(t, err)
+	_, err = m.Add(ctx, "../c", `exports.c = 1`)
+	require.Error(t, err)
+	_, err = m.Add(ctx, "c", `exports.c = (`)
+	require.Error(t, err)
+
+	run := func(qu string) ([]interface{}, error) {
+		ses := NewSession(h.QuadStore)
+		it, err := ses.Execute(WithModules(ctx, m), qu, query.Options{Collation: query.JSON})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+		var out []interface{}
+		for it.Next(ctx) {
+			out = append(out, it.Result())
+		}
+		return out, it.Err()
+	}
+	out, err := run(`require("social/follows@1").followers("<fred>").all()`)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []interface{}{
+		map[string]interface{}{"id": "<bob>"},
+		map[string]interface{}{"id": "<emily>"},
+	}, out)
+
+	_, err = run(`require("a")`)
+	require.Error(t, err)
+	_, err = run(`require("social/follows@2")`)
+	require.Error(t, err)
+}
+
+func TestModulesCallTimeout(t *testing.T) {
+	h := modulesHandle(t)
+	m := NewModules(nil)
+	_, err := m.Add(context.TODO(), "load", `while(true) {}`)
+	require.NoError(t, err)
+	_, err = m.Add(context.TODO(), "call", `module.exports = function() { while(true) {} }`)
+	require.NoError(t, err)
+
+	for _, name := range []string{"load", "call"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		start := time.Now()
+		_, err = m.Call(ctx, h.QuadStore, name, 0, nil, query.Options{Collation: query.JSON})
+		cancel()
+		require.ErrorIs(t, err, context.DeadlineExceeded, name)
+		require.Less(t, int64(time.Since(start)), int64(5*time.Second), name)
+	}
+}
+
+func TestModulesCallScope(t *testing.T) {
+	ctx := context.TODO()
+	h := modulesHandle(t)
+	m := NewModules(nil)
+	_, err := m.Add(ctx, "counter", `
+var calls = 0
+module.exports = function() { calls++; return calls }
+`)
+	require.NoError(t, err)
+
+	call := func(ctx context.Context) []interface{} {
+		out, err := m.Call(ctx, h.QuadStore, "counter", 0, nil, query.Options{Collation: query.JSON})
+		require.NoError(t, err)
+		return out
+	}
+	alice, bob := WithScope(ctx, "alice"), WithScope(ctx, "bob")
+	require.Equal(t, []interface{}{int64(1)}, call(alice))
+	// module state is not shared between scopes
+	require.Equal(t, []interface{}{int64(1)}, call(bob))
+	require.Equal(t, []interface{}{int64(1)}, call(ctx))
+}
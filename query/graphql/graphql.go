@@ -31,8 +31,8 @@ const Name = "graphql"
 // (https://www.w3.org/TR/turtle/#grammar-production-IRIREF)
 
 func allowedNameRune(r rune) bool {
-	// will include <> in the IRI value
-	return r > 0x20 && !strings.ContainsRune("\"{}()|^`", r) && !unicode.IsSpace(r)
+	// will include <> in the IRI value; []! are excluded for type references like [ID!]
+	return r > 0x20 && !strings.ContainsRune("\"{}()[]!|^`", r) && !unicode.IsSpace(r)
 }
 
 func init() {
@@ -62,31 +62,47 @@ func (s *Session) Execute(ctx context.Context, qu string, opt query.Options) (qu
 	default:
 		return nil, &query.ErrUnsupportedCollation{Collation: opt.Collation}
 	}
+	s.qs = query.QuadStoreFor(s.qs, opt)
+	res, typed, err := executeTyped(ctx, s.qs, request{Query: qu})
+	if err != nil {
+		return nil, err
+	} else if typed {
+		if err = resultError(res); err != nil {
+			return nil, err
+		}
+		data, _ := res.Data.(map[string]interface{})
+		return &results{
+			run: func(ctx context.Context) (map[string]interface{}, error) {
+				return data, nil
+			},
+			col: opt.Collation,
+		}, nil
+	}
 	q, err := Parse(strings.NewReader(qu))
 	if err != nil {
 		return nil, err
 	}
 	return &results{
-		s:   s,
-		q:   q,
+		run: func(ctx context.Context) (map[string]interface{}, error) {
+			return q.Execute(ctx, s.qs)
+		},
 		col: opt.Collation,
 	}, nil
 }
 
 type results struct {
-	s   *Session
-	q   *Query
+	run func(ctx context.Context) (map[string]interface{}, error)
 	col query.Collation
 	res map[string]interface{}
 	err error
 }
 
 func (it *results) Next(ctx context.Context) bool {
-	if it.q == nil {
+	if it.run == nil {
 		return false
 	}
-	it.res, it.err = it.q.Execute(ctx, it.s.qs)
-	it.q = nil
+	it.res, it.err = it.run(ctx)
+	it.run = nil
 	return it.err == nil && len(it.res) != 0
 }
 
@@ -106,7 +122,7 @@ func (it *results) Err() error {
 }
 
 func (it *results) Close() error {
-	it.q = nil
+	it.run = nil
 	return nil
 }
 
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"io/ioutil"
+	"strings"
 
 	"github.com/dennwc/graphql/gqlerrors"
 
@@ -25,7 +27,25 @@ func httpError(w query.ResponseWriter, err error) {
 }
 
 func httpQuery(ctx context.Context, qs graph.QuadStore, w query.ResponseWriter, r io.Reader) {
-	q, err := Parse(r)
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	req, err := parseRequest(data)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	res, typed, err := executeTyped(ctx, qs, req)
+	if err != nil {
+		httpError(w, err)
+		return
+	} else if typed {
+		json.NewEncoder(w).Encode(httpResult{Data: res.Data, Errors: res.Errors})
+		return
+	}
+	q, err := Parse(strings.NewReader(req.Query))
 	if err != nil {
 		httpError(w, err)
 		return
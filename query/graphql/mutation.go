@@ -0,0 +1,317 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	gql "github.com/dennwc/graphql"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/shape"
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/rdf"
+)
+
+// Names of the mutation fields, their arguments and input types.
+const (
+	propertyInput = "PropertyInput"
+
+	predicateKey = "predicate"
+	valuesKey    = "values"
+	nodesKey     = "nodes"
+	typeKey      = "type"
+	setKey       = "set"
+)
+
+// errReadOnly is returned by mutations if the quad store has no writer.
+var errReadOnly = errors.New("database is read-only")
+
+// writerOf returns a quad writer for a given quad store, or nil if the store is read-only.
+//
+// Mutations are only allowed for handles, thus the caller decides if the writes are permitted.
+func writerOf(qs graph.QuadStore) graph.QuadWriter {
+	if h, ok := qs.(*graph.Handle); ok {
+		return h.QuadWriter
+	}
+	return nil
+}
+
+// fromNative converts a value of the Value scalar to a quad value.
+// Numbers without a fractional part are stored as integers.
+func fromNative(v interface{}) (quad.Value, error) {
+	switch v := v.(type) {
+	case string:
+		return quad.String(v), nil
+	case bool:
+		return quad.Bool(v), nil
+	case int:
+		return quad.Int(v), nil
+	case int64:
+		return quad.Int(v), nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return quad.Int(v), nil
+		}
+		return quad.Float(v), nil
+	}
+	return nil, fmt.Errorf("unsupported value: %v (%T)", v, v)
+}
+
+// mutation accumulates changes made by mutation fields of a single request.
+type mutation struct {
+	qs graph.QuadStore
+	qw graph.QuadWriter
+	tx *graph.Transaction
+}
+
+// pending checks if the transaction already has a given delta.
+func (m *mutation) pending(q quad.Quad, act graph.Procedure) bool {
+	for _, d := range m.tx.Deltas {
+		if d.Action == act && d.Quad == q {
+			return true
+		}
+	}
+	return false
+}
+
+// exists checks if the exact quad, including the label, is stored in the quad store.
+// Quads inferred by the store are not considered, since they cannot be added or removed.
+func (m *mutation) exists(ctx context.Context, q quad.Quad) (bool, error) {
+	qs := query.QuadStoreFor(m.qs, query.Options{NoReasoning: true})
+	filter := shape.Quads{
+		{Dir: quad.Subject, Values: shape.Lookup{q.Subject}},
+		{Dir: quad.Predicate, Values: shape.Lookup{q.Predicate}},
+		{Dir: quad.Object, Values: shape.Lookup{q.Object}},
+	}
+	if q.Label != nil {
+		filter = append(filter, shape.QuadFilter{Dir: quad.Label, Values: shape.Lookup{q.Label}})
+	}
+	r := graph.NewResultReader(qs, shape.BuildIterator(ctx, qs, filter).Iterate())
+	defer r.Close()
+	for {
+		got, err := r.ReadQuad()
+		if err == io.EOF {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		} else if got.Label == q.Label {
+			return true, nil
+		}
+	}
+}
+
+// add adds the quad to the transaction, unless it's already in the store.
+func (m *mutation) add(ctx context.Context, q quad.Quad) error {
+	ok, err := m.exists(ctx, q)
+	if err != nil {
+		return err
+	} else if !ok || m.pending(q, graph.Delete) {
+		m.tx.AddQuad(q)
+	}
+	return nil
+}
+
+// remove removes the quad in the transaction, if it's in the store or was added by a previous mutation.
+func (m *mutation) remove(ctx context.Context, q quad.Quad) error {
+	ok, err := m.exists(ctx, q)
+	if err != nil {
+		return err
+	} else if ok || m.pending(q, graph.Add) {
+		m.tx.RemoveQuad(q)
+	}
+	return nil
+}
+
+// properties converts the list of PropertyInput values to quads with a given subject.
+func properties(id quad.Value, arg interface{}) ([]quad.Quad, error) {
+	list, _ := arg.([]interface{})
+	var out []quad.Quad
+	for _, p := range list {
+		p, _ := p.(map[string]interface{})
+		pred, _ := p[predicateKey].(string)
+		if pred == "" {
+			return nil, errors.New("predicate is not set")
+		}
+		pv := parseID(pred)
+		vals, _ := p[valuesKey].([]interface{})
+		for _, v := range vals {
+			qv, err := fromNative(v)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, quad.Quad{Subject: id, Predicate: pv, Object: qv})
+		}
+		nodes, _ := p[nodesKey].([]interface{})
+		for _, n := range nodes {
+			if n, ok := n.(string); ok {
+				out = append(out, quad.Quad{Subject: id, Predicate: pv, Object: parseID(n)})
+			}
+		}
+	}
+	return out, nil
+}
+
+// deleteNode removes all quads which have the given node as subject, predicate, object, or label.
+// It works the same way as the RemoveNode method of the quad writer, but records changes in the transaction.
+// Quads of the node added by previous mutations of the request are removed from the transaction as well.
+func (m *mutation) deleteNode(ctx context.Context, v quad.Value) error {
+	quads, err := graph.NodeQuads(m.qs, v)
+	if err != nil && err != graph.ErrNodeNotExists {
+		return err
+	}
+	for _, q := range quads {
+		if !m.pending(q, graph.Delete) {
+			m.tx.RemoveQuad(q)
+		}
+	}
+	found := len(quads)
+	for _, d := range append([]graph.Delta{}, m.tx.Deltas...) {
+		if d.Action == graph.Add && mentions(d.Quad, v) {
+			// cancels the addition
+			m.tx.RemoveQuad(d.Quad)
+			found++
+		}
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	} else if found == 0 {
+		return graph.ErrNodeNotExists
+	}
+	return nil
+}
+
+// mentions checks if the value is in any direction of the quad.
+func mentions(q quad.Quad, v quad.Value) bool {
+	for _, d := range quad.Directions {
+		if q.Get(d) == v {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveMutation wraps a mutation resolver to pass the mutation of the request and to check if the store is writable.
+func resolveMutation(fnc func(ctx context.Context, m *mutation, args map[string]interface{}) (interface{}, error)) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		m := executionOf(p.Context).mut
+		if m.qw == nil {
+			return nil, errReadOnly
+		}
+		return fnc(contextOf(p.Context), m, p.Args)
+	}
+}
+
+// changeValues returns a resolver that adds or removes property values of a node.
+func changeValues(add bool) gql.FieldResolveFn {
+	return resolveMutation(func(ctx context.Context, m *mutation, args map[string]interface{}) (interface{}, error) {
+		id, _ := args[ValueKey].(string)
+		quads, err := properties(parseID(id), args[setKey])
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range quads {
+			if add {
+				err = m.add(ctx, q)
+			} else {
+				err = m.remove(ctx, q)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return id, nil
+	})
+}
+
+// apply writes the accumulated changes to the store.
+func (m *mutation) apply() error {
+	if len(m.tx.Deltas) == 0 {
+		return nil
+	} else if m.qw == nil {
+		return errReadOnly
+	}
+	return m.qw.ApplyTransaction(m.tx)
+}
+
+// mutationObject returns a Mutation type of the schema.
+func mutationObject() *gql.Object {
+	props := gql.NewInputObject(gql.InputObjectConfig{
+		Name:        propertyInput,
+		Description: "Values of a single property.",
+		Fields: gql.InputObjectConfigFieldMap{
+			predicateKey: {Type: gql.NewNonNull(gql.ID), Description: "IRI of the predicate."},
+			valuesKey:    {Type: gql.NewList(gql.NewNonNull(valueScalar)), Description: "Literal values."},
+			nodesKey:     {Type: gql.NewList(gql.NewNonNull(gql.ID)), Description: "IDs of linked nodes."},
+		},
+	})
+	setArg := &gql.ArgumentConfig{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(props))), Description: "Property values to change."}
+	idArg := &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID), Description: "ID of the node."}
+	return gql.NewObject(gql.ObjectConfig{
+		Name:        "Mutation",
+		Description: "All mutations of the request are applied in a single transaction.",
+		Fields: gql.Fields{
+			"createNode": &gql.Field{
+				Type:        gql.NewNonNull(gql.ID),
+				Description: "Create a node with given types and properties. Returns the ID of the node.",
+				Args: gql.FieldConfigArgument{
+					ValueKey: {Type: gql.ID, Description: "ID of the node. A blank node is created, if not set."},
+					typeKey:  {Type: gql.NewList(gql.NewNonNull(gql.ID)), Description: "IRIs of the node classes."},
+					setKey:   {Type: gql.NewList(gql.NewNonNull(props)), Description: "Property values of the node."},
+				},
+				Resolve: resolveMutation(func(ctx context.Context, m *mutation, args map[string]interface{}) (interface{}, error) {
+					var id quad.Value = quad.RandomBlankNode()
+					if s, ok := args[ValueKey].(string); ok && s != "" {
+						id = parseID(s)
+					}
+					quads, err := properties(id, args[setKey])
+					if err != nil {
+						return nil, err
+					}
+					types, _ := args[typeKey].([]interface{})
+					for _, t := range types {
+						if t, ok := t.(string); ok {
+							quads = append(quads, quad.Quad{Subject: id, Predicate: quad.IRI(rdf.Type), Object: parseID(t)})
+						}
+					}
+					if len(quads) == 0 {
+						return nil, errors.New("node must have at least one type or property")
+					}
+					for _, q := range quads {
+						if err = m.add(ctx, q); err != nil {
+							return nil, err
+						}
+					}
+					return idOf(id), nil
+				}),
+			},
+			"addValues": &gql.Field{
+				Type:        gql.NewNonNull(gql.ID),
+				Description: "Add property values to the node. Returns the ID of the node.",
+				Args:        gql.FieldConfigArgument{ValueKey: idArg, setKey: setArg},
+				Resolve:     changeValues(true),
+			},
+			"removeValues": &gql.Field{
+				Type:        gql.NewNonNull(gql.ID),
+				Description: "Remove property values from the node. Returns the ID of the node.",
+				Args:        gql.FieldConfigArgument{ValueKey: idArg, setKey: setArg},
+				Resolve:     changeValues(false),
+			},
+			"deleteNode": &gql.Field{
+				Type:        gql.NewNonNull(gql.ID),
+				Description: "Delete all quads that mention the node. Returns the ID of the node.",
+				Args:        gql.FieldConfigArgument{ValueKey: idArg},
+				Resolve: resolveMutation(func(ctx context.Context, m *mutation, args map[string]interface{}) (interface{}, error) {
+					id, _ := args[ValueKey].(string)
+					if err := m.deleteNode(ctx, parseID(id)); err != nil {
+						return nil, err
+					}
+					return id, nil
+				}),
+			},
+		},
+	})
+}
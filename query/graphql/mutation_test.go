@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/reasoning"
+	"github.com/cayleygraph/cayley/query/path"
+	"github.com/cayleygraph/cayley/writer"
+)
+
+func typedHandle(t testing.TB) *graph.Handle {
+	qs := typedStore(t)
+	qw, err := writer.NewSingle(qs, graph.IgnoreOpts{})
+	require.NoError(t, err)
+	return &graph.Handle{QuadStore: qs, QuadWriter: qw}
+}
+
+func readValues(qs graph.QuadStore, id, pred quad.Value) ([]quad.Value, error) {
+	return path.StartPath(qs, id).Out(pred).Iterate(context.TODO()).AllValues(qs)
+}
+
+func TestTypedMutation(t *testing.T) {
+	h := typedHandle(t)
+
+	out := runTyped(t, h, request{Query: `mutation {
+	carol: createNode(id: "ex:carol", type: ["ex:Person"], set: [
+		{predicate: "ex:name", values: ["Carol"]},
+		{predicate: "ex:age", values: [41]},
+		{predicate: "ex:knows", nodes: ["ex:alice"]},
+	])
+	alice: addValues(id: "ex:alice", set: [{predicate: "ex:name", values: ["Alice", "Al"]}])
+	bob: removeValues(id: "ex:bob", set: [{predicate: "ex:knows", nodes: ["ex:alice", "ex:carol"]}])
+}`})
+	require.Equal(t, map[string]interface{}{
+		"carol": "ex:carol", "alice": "ex:alice", "bob": "ex:bob",
+	}, out)
+
+	out = runTyped(t, h, request{json: true, Query: `{
+	person(id: ["ex:alice", "ex:bob", "ex:carol"]) { id, name, age, knows { id } }
+}`})
+	require.Equal(t, map[string]interface{}{
+		"person": []interface{}{
+			map[string]interface{}{"id": "ex:alice", "name": []interface{}{"Alice", "Al"}, "age": []interface{}{30}, "knows": []interface{}{map[string]interface{}{"id": "ex:bob"}}},
+			map[string]interface{}{"id": "ex:bob", "name": []interface{}{"Bob"}, "age": []interface{}{25}, "knows": []interface{}{}},
+			map[string]interface{}{"id": "ex:carol", "name": []interface{}{"Carol"}, "age": []interface{}{41}, "knows": []interface{}{map[string]interface{}{"id": "ex:alice"}}},
+		},
+	}, out)
+
+	out = runTyped(t, h, request{
+		Query:     `mutation Delete($id: ID!) { deleteNode(id: $id) }`,
+		Variables: map[string]interface{}{"id": "ex:carol"},
+	})
+	require.Equal(t, map[string]interface{}{"deleteNode": "ex:carol"}, out)
+
+	out = runTyped(t, h, request{json: true, Query: `{ person(id: ["ex:carol"]) { id } }`})
+	require.Equal(t, map[string]interface{}{"person": []interface{}{}}, out)
+}
+
+func TestTypedMutationAtomic(t *testing.T) {
+	h := typedHandle(t)
+
+	// the second mutation fails, thus the first one must not be applied
+	res, typed, err := executeTyped(context.TODO(), h, request{Query: `mutation {
+	createNode(id: "ex:carol", set: [{predicate: "ex:name", values: ["Carol"]}])
+	deleteNode(id: "ex:nobody")
+}`})
+	require.NoError(t, err)
+	require.True(t, typed)
+	require.Error(t, resultError(res))
+
+	vals, err := readValues(h, quad.IRI("ex:carol"), quad.IRI("ex:name"))
+	require.NoError(t, err)
+	require.Empty(t, vals)
+}
+
+func TestTypedMutationReadOnly(t *testing.T) {
+	h := typedHandle(t)
+	for _, qs := range []graph.QuadStore{h.QuadStore, &graph.Handle{QuadStore: h.QuadStore}} {
+		res, typed, err := executeTyped(context.TODO(), qs, request{Query: `mutation { deleteNode(id: "ex:alice") }`})
+		require.NoError(t, err)
+		require.True(t, typed)
+		require.EqualError(t, resultError(res), errReadOnly.Error())
+	}
+	vals, err := readValues(h, quad.IRI("ex:alice"), quad.IRI("ex:name"))
+	require.NoError(t, err)
+	require.Equal(t, []quad.Value{quad.String("Alice")}, vals)
+}
+
+func TestTypedMutationExact(t *testing.T) {
+	base := typedStore(t)
+	base.AddQuad(quad.MakeIRI("ex:alice", "ex:knows", "ex:acme", "ex:g"))
+	rs, err := reasoning.New(context.TODO(), base)
+	require.NoError(t, err)
+	qw, err := writer.NewSingle(base, graph.IgnoreOpts{})
+	require.NoError(t, err)
+	h := &graph.Handle{QuadStore: rs, QuadWriter: qw}
+
+	// the type of bob is inferred and the link of alice to acme is in another graph,
+	// thus both quads must be written
+	runTyped(t, h, request{Query: `mutation {
+	addValues(id: "ex:bob", set: [{predicate: "rdf:type", nodes: ["ex:Person"]}])
+	alice: addValues(id: "ex:alice", set: [{predicate: "ex:knows", nodes: ["ex:acme"]}])
+}`})
+	for _, q := range []quad.Quad{
+		quad.MakeIRI("ex:bob", "rdf:type", "ex:Person", ""),
+		quad.MakeIRI("ex:alice", "ex:knows", "ex:acme", ""),
+		quad.MakeIRI("ex:alice", "ex:knows", "ex:acme", "ex:g"),
+	} {
+		ok, err := (&mutation{qs: h}).exists(context.TODO(), q)
+		require.NoError(t, err)
+		require.True(t, ok, q)
+	}
+
+	// removing a quad doesn't remove it from other graphs
+	runTyped(t, h, request{Query: `mutation {
+	removeValues(id: "ex:alice", set: [{predicate: "ex:knows", nodes: ["ex:acme"]}])
+}`})
+	vals, err := readValues(base, quad.IRI("ex:alice"), quad.IRI("ex:knows"))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []quad.Value{quad.IRI("ex:bob"), quad.IRI("ex:acme")}, vals)
+}
+
+func TestTypedMutationDeletePending(t *testing.T) {
+	h := typedHandle(t)
+
+	// the node and links to it only exist in the changes of the same request
+	out := runTyped(t, h, request{Query: `mutation {
+	carol: createNode(id: "ex:carol", type: ["ex:Person"], set: [{predicate: "ex:name", values: ["Carol"]}])
+	alice: addValues(id: "ex:alice", set: [{predicate: "ex:knows", nodes: ["ex:carol"]}])
+	deleteNode(id: "ex:carol")
+}`})
+	require.Equal(t, map[string]interface{}{
+		"carol": "ex:carol", "alice": "ex:alice", "deleteNode": "ex:carol",
+	}, out)
+
+	_, err := graph.NodeQuads(h, quad.IRI("ex:carol"))
+	require.Equal(t, graph.ErrNodeNotExists, err)
+}
@@ -0,0 +1,1186 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	gql "github.com/dennwc/graphql"
+	"github.com/dennwc/graphql/gqlerrors"
+	"github.com/dennwc/graphql/language/ast"
+	"github.com/dennwc/graphql/language/parser"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/internal/lru"
+	"github.com/cayleygraph/cayley/query/path"
+	"github.com/cayleygraph/cayley/schema"
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/owl"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+	vschema "github.com/cayleygraph/quad/voc/schema"
+	"github.com/cayleygraph/quad/voc/xsd"
+)
+
+// schema.org vocabulary that is not defined in the schema package.
+const (
+	schemaDomainIncludes = vschema.Prefix + "domainIncludes"
+	schemaRangeIncludes  = vschema.Prefix + "rangeIncludes"
+)
+
+// Special field names and arguments of the generated schema.
+const (
+	nodeType     = "Node"
+	resourceType = "Resource"
+	valueType    = "Value"
+	revPrefix    = "_"
+)
+
+// vocabularies are namespaces that are never mapped to GraphQL types.
+var vocabularies = []string{rdf.Prefix, rdfs.Prefix, owl.Prefix, xsd.Prefix}
+
+// datatypes maps XSD, RDF and schema.org datatypes to GraphQL scalars.
+var datatypes = map[quad.IRI]gql.Output{
+	xsd.String:                        gql.String,
+	xsd.Prefix + "normalizedString":   gql.String,
+	xsd.Prefix + "token":              gql.String,
+	xsd.Prefix + "anyURI":             gql.String,
+	xsd.Boolean:                       gql.Boolean,
+	xsd.Integer:                       gql.Int,
+	xsd.Long:                          gql.Int,
+	xsd.Int:                           gql.Int,
+	xsd.Prefix + "short":              gql.Int,
+	xsd.Prefix + "byte":               gql.Int,
+	xsd.Prefix + "nonNegativeInteger": gql.Int,
+	xsd.Prefix + "positiveInteger":    gql.Int,
+	xsd.Prefix + "unsignedInt":        gql.Int,
+	xsd.Prefix + "unsignedLong":       gql.Int,
+	xsd.Double:                        gql.Float,
+	xsd.Float:                         gql.Float,
+	xsd.Prefix + "decimal":            gql.Float,
+	xsd.DateTime:                      gql.String,
+	xsd.Prefix + "date":               gql.String,
+	rdf.LangString:                    gql.String,
+	vschema.Prefix + "Text":           gql.String,
+	vschema.Prefix + "URL":            gql.String,
+	vschema.Prefix + "Boolean":        gql.Boolean,
+	vschema.Prefix + "Integer":        gql.Int,
+	vschema.Prefix + "Number":         gql.Float,
+	vschema.Prefix + "Float":          gql.Float,
+	vschema.Prefix + "DateTime":       gql.String,
+	vschema.Prefix + "Date":           gql.String,
+}
+
+// valueScalar is used for fields without a known datatype. It accepts and returns any JSON value.
+var valueScalar = gql.NewScalar(gql.ScalarConfig{
+	Name:        valueType,
+	Description: "Any RDF value: an IRI, a string, a number or a boolean.",
+	Serialize: func(v interface{}) interface{} {
+		if qv, ok := v.(quad.Value); ok {
+			return toNative(qv)
+		}
+		return v
+	},
+	ParseValue: func(v interface{}) interface{} {
+		return v
+	},
+	ParseLiteral: func(v ast.Value) interface{} {
+		vals, err := convValue(v)
+		if err != nil || len(vals) != 1 {
+			return nil
+		}
+		return toNative(vals[0])
+	},
+})
+
+// forms returns all forms of the IRI that may be used in the graph.
+func forms(iri quad.IRI) []quad.Value {
+	out := []quad.Value{iri}
+	for _, f := range []quad.IRI{iri.Short(), iri.Full()} {
+		if f != iri && (len(out) == 1 || out[1] != f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// formsOf is the same as forms, but for multiple IRIs.
+func formsOf(iris ...quad.IRI) []quad.Value {
+	var out []quad.Value
+	for _, iri := range iris {
+		out = append(out, forms(iri)...)
+	}
+	return out
+}
+
+func isVocabulary(iri quad.IRI) bool {
+	s := string(iri)
+	for _, pref := range vocabularies {
+		if strings.HasPrefix(s, pref) {
+			return true
+		}
+	}
+	return false
+}
+
+// toNative converts a quad value to a JSON-compatible value.
+func toNative(v quad.Value) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case quad.IRI:
+		return string(v)
+	case quad.BNode:
+		return v.String()
+	case quad.String:
+		return string(v)
+	case quad.LangString:
+		return string(v.Value)
+	case quad.TypedString:
+		if pv, err := v.ParseValue(); err == nil {
+			if _, ok := pv.(quad.TypedString); !ok {
+				return toNative(pv)
+			}
+		}
+		return string(v.Value)
+	case quad.Int:
+		return int64(v)
+	case quad.Float:
+		return float64(v)
+	case quad.Bool:
+		return bool(v)
+	case quad.Time:
+		return time.Time(v).Format(time.RFC3339Nano)
+	}
+	return quad.ToString(v)
+}
+
+// idOf returns a value for the id field of the node.
+func idOf(v quad.Value) string {
+	switch v := v.(type) {
+	case quad.IRI:
+		return string(v)
+	case quad.BNode:
+		return v.String()
+	}
+	return quad.ToString(v)
+}
+
+// parseID is the reverse of idOf.
+func parseID(s string) quad.Value {
+	if len(s) > 2 && s[0] == '_' && s[1] == ':' {
+		return quad.BNode(s[2:])
+	} else if len(s) > 2 && s[0] == '<' && s[len(s)-1] == '>' {
+		s = s[1 : len(s)-1]
+	}
+	return quad.IRI(s)
+}
+
+// localName returns the last segment of the IRI.
+func localName(iri quad.IRI) string {
+	s := string(iri)
+	if i := strings.LastIndexAny(s, "#/:"); i >= 0 && i+1 < len(s) {
+		s = s[i+1:]
+	}
+	return s
+}
+
+// gqlName converts a string to a valid GraphQL name in camel case.
+func gqlName(s string, upper bool) string {
+	var (
+		sb   strings.Builder
+		next = upper
+	)
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			next = sb.Len() != 0 || upper
+			continue
+		}
+		if sb.Len() == 0 && unicode.IsDigit(r) {
+			sb.WriteRune('_')
+		}
+		if next {
+			r = unicode.ToUpper(r)
+			next = false
+		} else if sb.Len() == 0 && !upper {
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
+}
+
+// unique returns a name that is not yet in the set and adds it to the set.
+func unique(names map[string]struct{}, name string) string {
+	base := name
+	for i := 2; ; i++ {
+		if _, ok := names[name]; !ok {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+	names[name] = struct{}{}
+	return name
+}
+
+type class struct {
+	iri    quad.IRI
+	name   string
+	desc   string
+	supers []*class
+	subs   []*class
+	fields []*classField
+	goType reflect.Type
+	obj    *gql.Object
+}
+
+// all returns the class and all its super classes.
+func (c *class) all() []*class {
+	out := []*class{c}
+	seen := map[*class]bool{c: true}
+	for i := 0; i < len(out); i++ {
+		for _, s := range out[i].supers {
+			if !seen[s] {
+				seen[s] = true
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// instanceOf returns IRIs of the class and all its sub classes.
+func (c *class) instanceOf() []quad.IRI {
+	out := []*class{c}
+	seen := map[*class]bool{c: true}
+	for i := 0; i < len(out); i++ {
+		for _, s := range out[i].subs {
+			if !seen[s] {
+				seen[s] = true
+				out = append(out, s)
+			}
+		}
+	}
+	iris := make([]quad.IRI, 0, len(out))
+	for _, c := range out {
+		iris = append(iris, c.iri)
+	}
+	return iris
+}
+
+func (c *class) field(name string) *classField {
+	for _, f := range c.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// classField is a field of the object type that corresponds to a property.
+type classField struct {
+	name string
+	desc string
+	pred quad.IRI
+	rev  bool
+	list bool
+	// scalar is set for fields with literal values
+	scalar gql.Output
+	// target is set for links to nodes of a single class
+	target *class
+}
+
+// property is a property from the vocabulary.
+type property struct {
+	iri     quad.IRI
+	domains []quad.IRI
+	ranges  []quad.IRI
+}
+
+// typedSchema is a GraphQL schema generated from the vocabulary in the graph and from
+// the types registered in the schema package.
+//
+// The schema doesn't depend on a particular quad store: resolvers use the store of the request,
+// see withExecution. Thus it can be shared by all stores with the same vocabulary.
+type typedSchema struct {
+	classes map[quad.IRI]*class
+	list    []*class
+	props   map[quad.IRI]*property
+	// vocab lists all vocabulary quads and registered types the schema was generated from
+	vocab []string
+
+	node     *gql.Interface
+	resource *gql.Object
+	schema   gql.Schema
+}
+
+// readQuads reads all quads that have one of the values in a given direction.
+func readQuads(qs graph.QuadStore, dir quad.Direction, vals ...quad.Value) ([]quad.Quad, error) {
+	var out []quad.Quad
+	for _, v := range vals {
+		ref, err := qs.ValueOf(v)
+		if err != nil {
+			return nil, err
+		} else if ref == nil {
+			continue
+		}
+		r := graph.NewResultReader(qs, qs.QuadIterator(dir, ref).Iterate())
+		quads, err := quad.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, quads...)
+	}
+	return out, nil
+}
+
+func shortIRI(v quad.Value) (quad.IRI, bool) {
+	iri, ok := v.(quad.IRI)
+	if !ok {
+		return "", false
+	}
+	return iri.Short(), true
+}
+
+// newTypedSchema generates a GraphQL schema for a quad store.
+//
+// Classes declared as rdfs:Class or owl:Class, used in rdfs:subClassOf, or in domains and ranges of
+// properties become object types. Properties become fields of all classes in their domains and of their
+// sub classes, and links to nodes also get a reverse field on the classes in the property range.
+// Types registered in the schema package are added as well, and the mapping of their fields takes
+// precedence over the vocabulary.
+//
+// Generated schemas are cached by the vocabulary, thus the schema is only rebuilt when the vocabulary
+// or the registered types change. The vocabulary itself is only read again when the size of the store
+// changes, the store is changed by a mutation, or the schema is older than schemaRecheck.
+func newTypedSchema(ctx context.Context, qs graph.QuadStore) (*typedSchema, error) {
+	id, stamp, err := stampOf(ctx, qs)
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		if v, ok := stores.Get(id); ok {
+			if c := v.(*storeSchema); c.qs == qs && c.stamp == stamp && time.Since(c.loaded) < schemaRecheck {
+				return c.s, nil
+			}
+		}
+	}
+	s, err := loadTypedSchema(ctx, qs)
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		stores.Put(id, &storeSchema{qs: qs, stamp: stamp, loaded: time.Now(), s: s})
+	}
+	return s, nil
+}
+
+// schemaRecheck is the time after which the vocabulary is read again, even if the size of the store is the same.
+const schemaRecheck = time.Minute
+
+// storeSchema is the schema generated for a quad store.
+type storeSchema struct {
+	qs     graph.QuadStore
+	stamp  schemaStamp
+	loaded time.Time
+	s      *typedSchema
+}
+
+// schemaStamp is a cheap approximation of the state of the store and of the registered types.
+type schemaStamp struct {
+	quads, nodes int64
+	types        string
+}
+
+// stores caches generated schemas by the identity of the quad store.
+var stores = lru.New(16)
+
+// storeID returns a cache key for the quad store, or an empty string if the store cannot be identified.
+func storeID(qs graph.QuadStore) string {
+	if reflect.ValueOf(qs).Kind() != reflect.Ptr {
+		return ""
+	}
+	return fmt.Sprintf("%T:%p", qs, qs)
+}
+
+// stampOf returns the identity of the quad store and its current stamp.
+func stampOf(ctx context.Context, qs graph.QuadStore) (string, schemaStamp, error) {
+	id := storeID(qs)
+	if id == "" {
+		return "", schemaStamp{}, nil
+	}
+	st, err := qs.Stats(ctx, false)
+	if err != nil {
+		return "", schemaStamp{}, err
+	}
+	var types []string
+	for iri, rt := range schema.RegisteredTypes() {
+		types = append(types, string(iri)+" "+rt.PkgPath()+"."+rt.String())
+	}
+	sort.Strings(types)
+	return id, schemaStamp{quads: st.Quads.Value, nodes: st.Nodes.Value, types: strings.Join(types, "\n")}, nil
+}
+
+// forgetSchema drops the schema cached for the quad store, thus the vocabulary is read again by the next request.
+func forgetSchema(qs graph.QuadStore) {
+	if id := storeID(qs); id != "" {
+		stores.Del(id)
+	}
+}
+
+// loadTypedSchema reads the vocabulary of the store and returns the schema for it.
+func loadTypedSchema(ctx context.Context, qs graph.QuadStore) (*typedSchema, error) {
+	s := &typedSchema{
+		classes: make(map[quad.IRI]*class),
+		props:   make(map[quad.IRI]*property),
+	}
+	if err := s.loadVocabulary(ctx, qs); err != nil {
+		return nil, err
+	}
+	if err := s.loadRegistered(); err != nil {
+		return nil, err
+	}
+	key := s.key()
+	if cached, ok := schemas.Get(key); ok {
+		return cached.(*typedSchema), nil
+	}
+	s.attachProperties()
+	if err := s.build(); err != nil {
+		return nil, err
+	}
+	schemas.Put(key, s)
+	return s, nil
+}
+
+// schemas caches generated schemas by the key of their vocabulary.
+var schemas = lru.New(16)
+
+// key returns a cache key for the vocabulary of the schema.
+func (s *typedSchema) key() string {
+	sort.Strings(s.vocab)
+	h := sha256.New()
+	for _, v := range s.vocab {
+		h.Write([]byte(v))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// readVocabulary reads all quads that have one of the values in a given direction, and records them in the vocabulary.
+func (s *typedSchema) readVocabulary(qs graph.QuadStore, dir quad.Direction, vals ...quad.Value) ([]quad.Quad, error) {
+	quads, err := readQuads(qs, dir, vals...)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range quads {
+		s.vocab = append(s.vocab, q.String())
+	}
+	return quads, nil
+}
+
+func (s *typedSchema) addClass(v quad.Value) *class {
+	iri, ok := shortIRI(v)
+	if !ok || isVocabulary(iri) {
+		return nil
+	} else if _, ok := datatypes[iri]; ok {
+		return nil
+	}
+	if c, ok := s.classes[iri]; ok {
+		return c
+	}
+	c := &class{iri: iri}
+	s.classes[iri] = c
+	s.list = append(s.list, c)
+	return c
+}
+
+func (s *typedSchema) addProperty(v quad.Value) *property {
+	iri, ok := shortIRI(v)
+	if !ok {
+		return nil
+	}
+	p, ok := s.props[iri]
+	if !ok {
+		p = &property{iri: iri}
+		s.props[iri] = p
+	}
+	return p
+}
+
+func (s *typedSchema) loadVocabulary(ctx context.Context, qs graph.QuadStore) error {
+	typeForms := forms(quad.IRI(rdf.Type))
+	isType := func(p quad.Value) bool {
+		for _, t := range typeForms {
+			if p == t {
+				return true
+			}
+		}
+		return false
+	}
+	decl, err := s.readVocabulary(qs, quad.Object, formsOf(rdfs.Class, owl.Class)...)
+	if err != nil {
+		return err
+	}
+	for _, q := range decl {
+		if isType(q.Predicate) {
+			s.addClass(q.Subject)
+		}
+	}
+	sub, err := s.readVocabulary(qs, quad.Predicate, forms(rdfs.SubClassOf)...)
+	if err != nil {
+		return err
+	}
+	for _, q := range sub {
+		c, sc := s.addClass(q.Subject), s.addClass(q.Object)
+		if c != nil && sc != nil && c != sc {
+			c.supers = append(c.supers, sc)
+			sc.subs = append(sc.subs, c)
+		}
+	}
+	domains, err := s.readVocabulary(qs, quad.Predicate, formsOf(rdfs.Domain, schemaDomainIncludes)...)
+	if err != nil {
+		return err
+	}
+	for _, q := range domains {
+		if p, c := s.addProperty(q.Subject), s.addClass(q.Object); p != nil && c != nil {
+			p.domains = append(p.domains, c.iri)
+		}
+	}
+	ranges, err := s.readVocabulary(qs, quad.Predicate, formsOf(rdfs.Range, schemaRangeIncludes)...)
+	if err != nil {
+		return err
+	}
+	for _, q := range ranges {
+		p := s.addProperty(q.Subject)
+		iri, ok := shortIRI(q.Object)
+		if p == nil || !ok {
+			continue
+		}
+		p.ranges = append(p.ranges, iri)
+		s.addClass(iri)
+	}
+	// descriptions
+	for _, c := range s.list {
+		desc, err := path.StartPath(qs, forms(c.iri)...).Out(forms(rdfs.Comment)).Iterate(ctx).AllValues(qs)
+		if err != nil {
+			return err
+		} else if len(desc) != 0 {
+			c.desc = fmt.Sprint(toNative(desc[0]))
+			s.vocab = append(s.vocab, quad.Quad{Subject: c.iri, Predicate: quad.IRI(rdfs.Comment), Object: desc[0]}.String())
+		}
+	}
+	return nil
+}
+
+func (s *typedSchema) loadRegistered() error {
+	sch := schema.Global()
+	for iri, rt := range schema.RegisteredTypes() {
+		c := s.addClass(iri)
+		if c == nil {
+			continue
+		}
+		if _, err := sch.FieldsOf(rt); err != nil {
+			return err
+		}
+		c.goType = rt
+		s.vocab = append(s.vocab, string(iri)+" "+rt.PkgPath()+"."+rt.String())
+	}
+	return nil
+}
+
+// goField converts a field of a registered Go type to a field of the object type.
+func (s *typedSchema) goField(f schema.FieldInfo) *classField {
+	name := f.Name
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	cf := &classField{name: gqlName(name, false), pred: f.Predicate.Short(), rev: f.Reverse}
+	rt := f.Type
+	for rt.Kind() == reflect.Ptr || rt.Kind() == reflect.Slice {
+		if rt.Kind() == reflect.Slice {
+			cf.list = true
+		}
+		rt = rt.Elem()
+	}
+	switch {
+	case rt == reflect.TypeOf(quad.IRI("")) || rt == reflect.TypeOf(quad.BNode("")):
+		// link to any node
+	case rt.Implements(reflect.TypeOf((*quad.Value)(nil)).Elem()):
+		cf.scalar = valueScalar
+	case rt == reflect.TypeOf(time.Time{}):
+		cf.scalar = gql.String
+	case rt.Kind() == reflect.Struct:
+		for iri, t := range schema.RegisteredTypes() {
+			if t == rt {
+				cf.target = s.classes[iri.Short()]
+			}
+		}
+	case rt.Kind() == reflect.String:
+		cf.scalar = gql.String
+	case rt.Kind() == reflect.Bool:
+		cf.scalar = gql.Boolean
+	case rt.Kind() >= reflect.Int && rt.Kind() <= reflect.Uint64:
+		cf.scalar = gql.Int
+	case rt.Kind() == reflect.Float32 || rt.Kind() == reflect.Float64:
+		cf.scalar = gql.Float
+	default:
+		cf.scalar = valueScalar
+	}
+	return cf
+}
+
+// fieldFor creates a field of the object type for a property from the vocabulary.
+func (s *typedSchema) fieldFor(p *property, rev bool) *classField {
+	f := &classField{name: gqlName(localName(p.iri), false), pred: p.iri, rev: rev, list: true}
+	targets := p.ranges
+	if rev {
+		f.name = revPrefix + f.name
+		targets = p.domains
+	}
+	var (
+		scalars []gql.Output
+		classes []*class
+	)
+	for _, r := range targets {
+		if dt, ok := datatypes[r]; ok {
+			scalars = append(scalars, dt)
+		} else if c := s.classes[r]; c != nil {
+			classes = append(classes, c)
+		}
+	}
+	switch {
+	case len(targets) == 0:
+		if !rev {
+			f.scalar = valueScalar
+		}
+	case len(classes) == 0 && len(scalars) != 0:
+		f.scalar = scalars[0]
+		for _, dt := range scalars[1:] {
+			if dt != f.scalar {
+				f.scalar = valueScalar
+			}
+		}
+	case len(scalars) != 0:
+		f.scalar = valueScalar
+	case len(classes) == 1:
+		f.target = classes[0]
+	}
+	return f
+}
+
+func (s *typedSchema) attachProperties() {
+	props := make([]*property, 0, len(s.props))
+	for _, p := range s.props {
+		props = append(props, p)
+	}
+	sort.Slice(props, func(i, j int) bool { return props[i].iri < props[j].iri })
+	sort.Slice(s.list, func(i, j int) bool { return s.list[i].iri < s.list[j].iri })
+
+	hasClass := func(c *class, list []quad.IRI) bool {
+		for _, sc := range c.all() {
+			for _, iri := range list {
+				if sc.iri == iri {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, c := range s.list {
+		type key struct {
+			pred quad.IRI
+			rev  bool
+		}
+		seen := make(map[key]bool)
+		if c.goType != nil {
+			fields, _ := schema.Global().FieldsOf(c.goType)
+			for _, f := range fields {
+				cf := s.goField(f)
+				seen[key{cf.pred, cf.rev}] = true
+				c.fields = append(c.fields, cf)
+			}
+		}
+		for _, p := range props {
+			if hasClass(c, p.domains) && !seen[key{p.iri, false}] {
+				seen[key{p.iri, false}] = true
+				c.fields = append(c.fields, s.fieldFor(p, false))
+			}
+			if hasClass(c, p.ranges) && !seen[key{p.iri, true}] {
+				seen[key{p.iri, true}] = true
+				c.fields = append(c.fields, s.fieldFor(p, true))
+			}
+		}
+		names := map[string]struct{}{ValueKey: {}}
+		for _, f := range c.fields {
+			f.name = unique(names, f.name)
+		}
+	}
+}
+
+// args returns filter and pagination arguments for a list of nodes of a given class.
+func (s *typedSchema) args(c *class) gql.FieldConfigArgument {
+	args := gql.FieldConfigArgument{
+		ValueKey: &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(gql.ID)), Description: "Select nodes with given IDs."},
+		LimitKey: &gql.ArgumentConfig{Type: gql.Int, Description: "Maximal number of nodes to return."},
+		SkipKey:  &gql.ArgumentConfig{Type: gql.Int, Description: "Number of nodes to skip."},
+	}
+	if c == nil {
+		return args
+	}
+	for _, f := range c.fields {
+		typ := gql.Input(gql.ID)
+		if in, ok := f.scalar.(gql.Input); ok {
+			typ = in
+		}
+		args[f.name] = &gql.ArgumentConfig{Type: typ, Description: "Select nodes with a given value of the field."}
+	}
+	return args
+}
+
+// filter applies arguments created by args to the path.
+func (s *typedSchema) filter(p *path.Path, c *class, args map[string]interface{}) (*path.Path, error) {
+	if ids, ok := args[ValueKey].([]interface{}); ok {
+		vals := make([]quad.Value, 0, len(ids))
+		for _, id := range ids {
+			if str, ok := id.(string); ok {
+				vals = append(vals, parseID(str))
+			}
+		}
+		p = p.Is(vals...)
+	}
+	if c != nil {
+		names := make([]string, 0, len(args))
+		for name := range args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			f := c.field(name)
+			if f == nil || args[name] == nil {
+				continue
+			}
+			var v quad.Value
+			if f.scalar == nil {
+				str, _ := args[name].(string)
+				v = parseID(str)
+			} else if qv, ok := quad.AsValue(args[name]); ok {
+				v = qv
+			} else {
+				return nil, errors.New("unsupported value for argument " + name)
+			}
+			if f.rev {
+				p = p.HasReverse(forms(f.pred), v)
+			} else {
+				p = p.Has(forms(f.pred), v)
+			}
+		}
+	}
+	if n, ok := args[SkipKey].(int); ok && n > 0 {
+		p = p.Skip(int64(n))
+	}
+	if n, ok := args[LimitKey].(int); ok && n >= 0 {
+		p = p.Limit(int64(n))
+	}
+	return p, nil
+}
+
+func contextOf(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+type executionKey struct{}
+
+// execution is the state of a single request to the typed schema.
+type execution struct {
+	qs  graph.QuadStore
+	mut *mutation
+}
+
+// withExecution returns a context for resolvers of a request to a given quad store.
+func withExecution(ctx context.Context, qs graph.QuadStore) (context.Context, *execution) {
+	e := &execution{qs: qs, mut: &mutation{qs: qs, qw: writerOf(qs), tx: graph.NewTransaction()}}
+	return context.WithValue(ctx, executionKey{}, e), e
+}
+
+// executionOf returns the state of the request the resolver is called for.
+func executionOf(ctx context.Context) *execution {
+	return contextOf(ctx).Value(executionKey{}).(*execution)
+}
+
+// resolveField returns a resolver for a field of the object type.
+func (s *typedSchema) resolveField(f *classField) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		src, ok := p.Source.(quad.Value)
+		if !ok {
+			return nil, nil
+		}
+		qs := executionOf(p.Context).qs
+		pt := path.StartPath(qs, src)
+		if f.rev {
+			pt = pt.In(forms(f.pred))
+		} else {
+			pt = pt.Out(forms(f.pred))
+		}
+		if f.list && f.scalar == nil {
+			var err error
+			if pt, err = s.filter(pt, f.target, p.Args); err != nil {
+				return nil, err
+			}
+		}
+		vals, err := pt.Iterate(contextOf(p.Context)).AllValues(qs)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]interface{}, 0, len(vals))
+		for _, v := range vals {
+			if f.scalar != nil {
+				out = append(out, toNative(v))
+			} else {
+				out = append(out, v)
+			}
+		}
+		if f.list {
+			return out, nil
+		} else if len(out) == 0 {
+			return nil, nil
+		}
+		return out[0], nil
+	}
+}
+
+// resolveType finds the object type of a node by its rdf:type.
+func (s *typedSchema) resolveType(p gql.ResolveTypeParams) *gql.Object {
+	v, ok := p.Value.(quad.Value)
+	if !ok {
+		return s.resource
+	}
+	qs := executionOf(p.Context).qs
+	types, err := path.StartPath(qs, v).Out(forms(rdf.Type)).Iterate(contextOf(p.Context)).AllValues(qs)
+	if err != nil {
+		return s.resource
+	}
+	var found *class
+	for _, t := range types {
+		iri, ok := shortIRI(t)
+		if !ok {
+			continue
+		}
+		if c := s.classes[iri]; c != nil && (found == nil || c.name < found.name) {
+			found = c
+		}
+	}
+	if found == nil {
+		return s.resource
+	}
+	return found.obj
+}
+
+func (s *typedSchema) idField() *gql.Field {
+	return &gql.Field{
+		Type:        gql.NewNonNull(gql.ID),
+		Description: "ID of the node: an IRI or a blank node.",
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			v, _ := p.Source.(quad.Value)
+			return idOf(v), nil
+		},
+	}
+}
+
+func (s *typedSchema) build() error {
+	types := map[string]struct{}{
+		"Query": {}, "Mutation": {}, "Subscription": {},
+		nodeType: {}, resourceType: {}, valueType: {}, propertyInput: {},
+		"String": {}, "Int": {}, "Float": {}, "Boolean": {}, "ID": {},
+	}
+	for _, c := range s.list {
+		c.name = unique(types, gqlName(localName(c.iri), true))
+	}
+	s.node = gql.NewInterface(gql.InterfaceConfig{
+		Name:        nodeType,
+		Description: "A node in the graph.",
+		Fields:      gql.Fields{ValueKey: s.idField()},
+		ResolveType: s.resolveType,
+	})
+	s.resource = gql.NewObject(gql.ObjectConfig{
+		Name:        resourceType,
+		Description: "A node of an unknown class.",
+		Interfaces:  []*gql.Interface{s.node},
+		Fields:      gql.Fields{ValueKey: s.idField()},
+	})
+	for _, c := range s.list {
+		c := c
+		desc := c.desc
+		if desc == "" {
+			desc = "Generated from " + string(c.iri.Full()) + "."
+		}
+		c.obj = gql.NewObject(gql.ObjectConfig{
+			Name:        c.name,
+			Description: desc,
+			Interfaces:  []*gql.Interface{s.node},
+			Fields: gql.FieldsThunk(func() gql.Fields {
+				fields := gql.Fields{ValueKey: s.idField()}
+				for _, f := range c.fields {
+					gf := &gql.Field{
+						Description: f.desc,
+						Resolve:     s.resolveField(f),
+					}
+					if gf.Description == "" {
+						dir := "Values of"
+						if f.rev {
+							dir = "Nodes linked with"
+						}
+						gf.Description = dir + " " + string(f.pred.Full()) + "."
+					}
+					var typ gql.Output
+					switch {
+					case f.scalar != nil:
+						typ = f.scalar
+					case f.target != nil:
+						typ = f.target.obj
+					default:
+						typ = s.node
+					}
+					if f.list {
+						typ = gql.NewList(typ)
+						if f.scalar == nil {
+							gf.Args = s.args(f.target)
+						}
+					}
+					gf.Type = typ
+					fields[f.name] = gf
+				}
+				return fields
+			}),
+		})
+	}
+	query := gql.Fields{}
+	roots := map[string]struct{}{}
+	for _, c := range s.list {
+		c := c
+		name := unique(roots, gqlName(c.name, false))
+		query[name] = &gql.Field{
+			Type:        gql.NewList(c.obj),
+			Description: "Nodes of class " + string(c.iri.Full()) + " and its sub classes.",
+			Args:        s.args(c),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				qs := executionOf(p.Context).qs
+				pt := path.StartPath(qs).Has(forms(rdf.Type), formsOf(c.instanceOf()...)...)
+				pt, err := s.filter(pt, c, p.Args)
+				if err != nil {
+					return nil, err
+				}
+				vals, err := pt.Iterate(contextOf(p.Context)).AllValues(qs)
+				if err != nil {
+					return nil, err
+				}
+				out := make([]interface{}, 0, len(vals))
+				for _, v := range vals {
+					out = append(out, v)
+				}
+				return out, nil
+			},
+		}
+	}
+	if len(query) == 0 {
+		// schema requires at least one field in the query type
+		query["_empty"] = &gql.Field{
+			Type:        gql.Boolean,
+			Description: "Placeholder for an empty schema: no classes are defined in the graph.",
+			Resolve:     func(p gql.ResolveParams) (interface{}, error) { return nil, nil },
+		}
+	}
+	objects := []gql.Type{s.resource}
+	for _, c := range s.list {
+		objects = append(objects, c.obj)
+	}
+	var err error
+	s.schema, err = gql.NewSchema(gql.SchemaConfig{
+		Query:    gql.NewObject(gql.ObjectConfig{Name: "Query", Fields: query}),
+		Mutation: mutationObject(),
+		Types:    objects,
+	})
+	return err
+}
+
+// request is a GraphQL request in the JSON form.
+type request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+
+	// json is set for requests in the JSON form
+	json bool
+}
+
+// parseRequest accepts either a JSON request, or a query text.
+func parseRequest(data []byte) (request, error) {
+	if s := strings.TrimSpace(string(data)); strings.HasPrefix(s, "{") &&
+		strings.HasPrefix(strings.TrimSpace(s[1:]), `"`) {
+		req := request{json: true}
+		err := json.Unmarshal(data, &req)
+		return req, err
+	}
+	return request{Query: string(data)}, nil
+}
+
+// usesSchema checks if the document can only be executed against the generated schema:
+// it has variables, fragments, multiple or named operations, mutations, or introspection fields.
+func usesSchema(doc *ast.Document) bool {
+	if len(doc.Definitions) != 1 {
+		return true
+	}
+	def, ok := doc.Definitions[0].(*ast.OperationDefinition)
+	if !ok || def.Operation != "query" || def.Name != nil || len(def.VariableDefinitions) != 0 {
+		return true
+	}
+	var hasFragments func(set *ast.SelectionSet) bool
+	hasFragments = func(set *ast.SelectionSet) bool {
+		if set == nil {
+			return false
+		}
+		for _, s := range set.Selections {
+			f, ok := s.(*ast.Field)
+			if !ok {
+				return true
+			} else if hasFragments(f.SelectionSet) {
+				return true
+			}
+		}
+		return false
+	}
+	if hasFragments(def.SelectionSet) {
+		return true
+	}
+	for _, s := range def.SelectionSet.Selections {
+		if f := s.(*ast.Field); strings.HasPrefix(f.Name.Value, "__") {
+			return true
+		}
+	}
+	return false
+}
+
+// executeTyped runs the request against the schema generated for the quad store.
+//
+// Only requests in the JSON form, or queries that use features of the schema (see usesSchema) are executed
+// against the generated schema. It returns false if the request is a schema-less query that should be executed by Query.
+func executeTyped(ctx context.Context, qs graph.QuadStore, req request) (*gql.Result, bool, error) {
+	if !req.json && len(req.Variables) == 0 {
+		doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+		if err != nil || !usesSchema(doc) {
+			return nil, false, nil // let the Parse report the error
+		}
+	}
+	s, err := newTypedSchema(ctx, qs)
+	if err != nil {
+		return nil, false, err
+	}
+	ctx, e := withExecution(ctx, qs)
+	res := s.execute(ctx, req)
+	if !res.HasErrors() {
+		// mutations are only applied if all of them succeed
+		if err = e.mut.apply(); err != nil {
+			res = &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+		}
+		if len(e.mut.tx.Deltas) != 0 {
+			// mutations may change the vocabulary without changing the size of the store
+			forgetSchema(qs)
+		}
+	}
+	return res, true, nil
+}
+
+// execute validates and executes the request.
+//
+// Top-level fields of a mutation are executed one by one in the order of the document, as required by the spec,
+// since the executor resolves them in a random order.
+func (s *typedSchema) execute(ctx context.Context, req request) *gql.Result {
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if vr := gql.ValidateDocument(&s.schema, doc, nil); !vr.IsValid {
+		return &gql.Result{Errors: vr.Errors}
+	}
+	params := gql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	}
+	op, ind := operationOf(doc, req.OperationName)
+	if op == nil || op.Operation != ast.OperationTypeMutation || len(op.SelectionSet.Selections) < 2 {
+		return gql.Execute(params)
+	}
+	data := make(map[string]interface{})
+	for _, sel := range op.SelectionSet.Selections {
+		one := *op
+		one.SelectionSet = &ast.SelectionSet{
+			Kind: op.SelectionSet.Kind, Loc: op.SelectionSet.Loc,
+			Selections: []ast.Selection{sel},
+		}
+		defs := append([]ast.Node{}, doc.Definitions...)
+		defs[ind] = &one
+		params.AST = &ast.Document{Kind: doc.Kind, Loc: doc.Loc, Definitions: defs}
+		res := gql.Execute(params)
+		if m, ok := res.Data.(map[string]interface{}); ok {
+			for k, v := range m {
+				data[k] = v
+			}
+		}
+		if res.HasErrors() {
+			return &gql.Result{Data: data, Errors: res.Errors}
+		}
+	}
+	return &gql.Result{Data: data}
+}
+
+// operationOf returns the operation with a given name and its index in the document.
+// The name can be omitted if the document has a single operation.
+func operationOf(doc *ast.Document, name string) (*ast.OperationDefinition, int) {
+	var (
+		found *ast.OperationDefinition
+		ind   int
+	)
+	for i, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" && found != nil {
+			return nil, 0 // ambiguous, let the executor report it
+		}
+		if name == "" || (op.Name != nil && op.Name.Value == name) {
+			found, ind = op, i
+		}
+	}
+	return found, ind
+}
+
+// resultError converts errors of the result to a single error.
+func resultError(res *gql.Result) error {
+	if !res.HasErrors() {
+		return nil
+	}
+	msgs := make([]string, 0, len(res.Errors))
+	for _, e := range res.Errors {
+		msgs = append(msgs, e.Message)
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
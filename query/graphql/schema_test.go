@@ -0,0 +1,268 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/nquads"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/schema"
+	"github.com/cayleygraph/cayley/writer"
+)
+
+const typedData = `
+<ex:Person> <rdf:type> <rdfs:Class> .
+<ex:Person> <rdfs:comment> "A person." .
+<ex:Employee> <rdfs:subClassOf> <ex:Person> .
+<ex:Company> <rdf:type> <http://www.w3.org/2002/07/owl#Class> .
+<ex:name> <rdfs:domain> <ex:Person> .
+<ex:name> <rdfs:domain> <ex:Company> .
+<ex:name> <rdfs:range> <xsd:string> .
+<ex:age> <rdfs:domain> <ex:Person> .
+<ex:age> <rdfs:range> <xsd:integer> .
+<ex:knows> <rdfs:domain> <ex:Person> .
+<ex:knows> <rdfs:range> <ex:Person> .
+<ex:employer> <rdfs:domain> <ex:Employee> .
+<ex:employer> <rdfs:range> <ex:Company> .
+<ex:alice> <rdf:type> <ex:Person> .
+<ex:alice> <ex:name> "Alice" .
+<ex:alice> <ex:age> "30"^^<xsd:integer> .
+<ex:alice> <ex:knows> <ex:bob> .
+<ex:bob> <rdf:type> <ex:Employee> .
+<ex:bob> <ex:name> "Bob" .
+<ex:bob> <ex:age> "25"^^<xsd:integer> .
+<ex:bob> <ex:employer> <ex:acme> .
+<ex:bob> <ex:knows> <ex:alice> .
+<ex:acme> <rdf:type> <ex:Company> .
+<ex:acme> <ex:name> "ACME" .
+`
+
+func typedStore(t testing.TB) *memstore.QuadStore {
+	quads, err := quad.ReadAll(nquads.NewReader(strings.NewReader(typedData), false))
+	require.NoError(t, err)
+	return memstore.New(quads...)
+}
+
+func runTyped(t testing.TB, qs graph.QuadStore, req request) map[string]interface{} {
+	res, typed, err := executeTyped(context.TODO(), qs, req)
+	require.NoError(t, err)
+	require.True(t, typed)
+	require.NoError(t, resultError(res))
+	return res.Data.(map[string]interface{})
+}
+
+func TestTypedQuery(t *testing.T) {
+	qs := typedStore(t)
+
+	out := runTyped(t, qs, request{json: true, Query: `{
+	person(name: "Bob") {
+		id, name, age
+		knows { id }
+	}
+	employee { employer { name } }
+}`})
+	require.Equal(t, map[string]interface{}{
+		"person": []interface{}{
+			map[string]interface{}{
+				"id": "ex:bob", "name": []interface{}{"Bob"}, "age": []interface{}{25},
+				"knows": []interface{}{map[string]interface{}{"id": "ex:alice"}},
+			},
+		},
+		"employee": []interface{}{
+			map[string]interface{}{
+				"employer": []interface{}{map[string]interface{}{"name": []interface{}{"ACME"}}},
+			},
+		},
+	}, out)
+
+	// sub classes, variables, fragments and reverse fields
+	out = runTyped(t, qs, request{
+		Query: `query People($n: Int) {
+	person(first: $n) { ...P }
+	company { name, _employer { id } }
+}
+fragment P on Person { id }`,
+		Variables: map[string]interface{}{"n": 5},
+	})
+	require.Equal(t, map[string]interface{}{
+		"person": []interface{}{
+			map[string]interface{}{"id": "ex:alice"},
+			map[string]interface{}{"id": "ex:bob"},
+		},
+		"company": []interface{}{
+			map[string]interface{}{
+				"name":      []interface{}{"ACME"},
+				"_employer": []interface{}{map[string]interface{}{"id": "ex:bob"}},
+			},
+		},
+	}, out)
+
+	// introspection
+	out = runTyped(t, qs, request{Query: `{
+	__type(name: "Person") { name, description, fields { name } }
+}`})
+	typ := out["__type"].(map[string]interface{})
+	require.Equal(t, "Person", typ["name"])
+	var fields []string
+	for _, f := range typ["fields"].([]interface{}) {
+		fields = append(fields, f.(map[string]interface{})["name"].(string))
+	}
+	require.ElementsMatch(t, []string{"id", "age", "knows", "name", "_knows"}, fields)
+
+	// validation
+	res, typed, err := executeTyped(context.TODO(), qs, request{json: true, Query: `{ person { unknown } }`})
+	require.NoError(t, err)
+	require.True(t, typed)
+	require.Error(t, resultError(res))
+
+	// legacy queries are not affected, even if they use names of generated fields
+	for _, q := range []string{`{ nodes { id } }`, `{ person { id } }`} {
+		_, typed, err = executeTyped(context.TODO(), qs, request{Query: q})
+		require.NoError(t, err)
+		require.False(t, typed, q)
+	}
+}
+
+func TestTypedSchemaCache(t *testing.T) {
+	qs := typedStore(t)
+	// comments that are not strings are used as is
+	qs.AddQuad(quad.Make(quad.IRI("ex:Company"), quad.IRI("rdfs:comment"), quad.IRI("ex:about"), nil))
+	qs.AddQuad(quad.Make(quad.IRI("ex:Employee"), quad.IRI("rdfs:comment"), 42, nil))
+
+	s1, err := newTypedSchema(context.TODO(), qs)
+	require.NoError(t, err)
+	s2, err := newTypedSchema(context.TODO(), typedStore(t))
+	require.NoError(t, err)
+	require.NotSame(t, s1, s2)
+	s3, err := newTypedSchema(context.TODO(), qs)
+	require.NoError(t, err)
+	require.Same(t, s1, s3)
+
+	out := runTyped(t, qs, request{json: true, Query: `{
+	company: __type(name: "Company") { description }
+	employee: __type(name: "Employee") { description }
+}`})
+	require.Equal(t, map[string]interface{}{
+		"company":  map[string]interface{}{"description": "ex:about"},
+		"employee": map[string]interface{}{"description": "42"},
+	}, out)
+
+	// data changes don't rebuild the schema, while vocabulary changes do
+	qs.AddQuad(quad.MakeIRI("ex:carol", "rdf:type", "ex:Person", ""))
+	s3, err = newTypedSchema(context.TODO(), qs)
+	require.NoError(t, err)
+	require.Same(t, s1, s3)
+	qs.AddQuad(quad.MakeIRI("ex:Team", "rdf:type", "rdfs:Class", ""))
+	s3, err = newTypedSchema(context.TODO(), qs)
+	require.NoError(t, err)
+	require.NotSame(t, s1, s3)
+	out = runTyped(t, qs, request{json: true, Query: `{ team { id } }`})
+	require.Equal(t, map[string]interface{}{"team": []interface{}{}}, out)
+}
+
+// countingStore counts the quad iterators created by the store.
+type countingStore struct {
+	graph.QuadStore
+	n int
+}
+
+func (qs *countingStore) QuadIterator(d quad.Direction, v graph.Ref) iterator.Shape {
+	qs.n++
+	return qs.QuadStore.QuadIterator(d, v)
+}
+
+func TestTypedSchemaStamp(t *testing.T) {
+	qs := &countingStore{QuadStore: typedStore(t)}
+	qw, err := writer.NewSingle(qs.QuadStore, graph.IgnoreOpts{})
+	require.NoError(t, err)
+	h := &graph.Handle{QuadStore: qs, QuadWriter: qw}
+
+	s1, err := newTypedSchema(context.TODO(), h)
+	require.NoError(t, err)
+	require.NotZero(t, qs.n)
+
+	// the vocabulary is not read again if the store is not changed
+	qs.n = 0
+	s2, err := newTypedSchema(context.TODO(), h)
+	require.NoError(t, err)
+	require.Same(t, s1, s2)
+	require.Zero(t, qs.n)
+
+	// mutations that keep the size of the store still rebuild the schema
+	runTyped(t, h, request{Query: `mutation {
+	remove: removeValues(id: "ex:Person", set: [{predicate: "rdfs:comment", values: ["A person."]}])
+	add: addValues(id: "ex:Person", set: [{predicate: "rdfs:comment", values: ["A human."]}])
+}`})
+	out := runTyped(t, h, request{json: true, Query: `{ person: __type(name: "Person") { description } }`})
+	require.Equal(t, map[string]interface{}{
+		"person": map[string]interface{}{"description": "A human."},
+	}, out)
+}
+
+func TestTypedSession(t *testing.T) {
+	qs := typedStore(t)
+	s := NewSession(qs)
+	it, err := s.Execute(context.TODO(), `query Employees { employee { id } }`, query.Options{Collation: query.Raw})
+	require.NoError(t, err)
+	require.True(t, it.Next(context.TODO()))
+	require.Equal(t, map[string]interface{}{
+		"employee": []interface{}{map[string]interface{}{"id": "ex:bob"}},
+	}, it.Result())
+	require.NoError(t, it.Close())
+
+	req, err := parseRequest([]byte(`{"query": "{ company { id } }", "variables": {"a": 1}}`))
+	require.NoError(t, err)
+	require.Equal(t, "{ company { id } }", req.Query)
+	require.True(t, req.json)
+	req, err = parseRequest([]byte(`{ company { id } }`))
+	require.NoError(t, err)
+	require.Equal(t, "{ company { id } }", req.Query)
+	require.False(t, req.json)
+}
+
+type project struct {
+	ID      quad.IRI   `quad:"@id"`
+	Title   string     `quad:"ex:title"`
+	Members []quad.IRI `quad:"ex:member"`
+	Owner   quad.IRI   `quad:"ex:owner,optional"`
+}
+
+func TestTypedRegistered(t *testing.T) {
+	schema.RegisterType(quad.IRI("ex:Project"), project{})
+	defer schema.RegisterType(quad.IRI("ex:Project"), nil)
+
+	qs := typedStore(t)
+	for _, q := range []quad.Quad{
+		quad.MakeIRI("ex:cayley", "rdf:type", "ex:Project", ""),
+		quad.Make(quad.IRI("ex:cayley"), quad.IRI("ex:title"), "Cayley", nil),
+		quad.MakeIRI("ex:cayley", "ex:member", "ex:alice", ""),
+		quad.MakeIRI("ex:cayley", "ex:member", "ex:bob", ""),
+		quad.MakeIRI("ex:cayley", "ex:owner", "ex:acme", ""),
+	} {
+		qs.AddQuad(q)
+	}
+
+	out := runTyped(t, qs, request{Query: `{
+	project {
+		id, title
+		members(first: 1) { id, ... on Person { name } }
+		owner { __typename, id }
+	}
+}`})
+	require.Equal(t, map[string]interface{}{
+		"project": []interface{}{
+			map[string]interface{}{
+				"id": "ex:cayley", "title": "Cayley",
+				"members": []interface{}{map[string]interface{}{"id": "ex:alice", "name": []interface{}{"Alice"}}},
+				"owner":   map[string]interface{}{"__typename": "Company", "id": "ex:acme"},
+			},
+		},
+	}, out)
+}
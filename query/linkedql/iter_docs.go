@@ -63,6 +63,9 @@ func (it *DocumentIterator) Next(ctx context.Context) bool {
 // Result implements query.Iterator.
 func (it *DocumentIterator) Result() interface{} {
 	context := make(map[string]interface{})
+	if ns := query.LdContext(it.tagsIt.ValueIt.Namespaces); ns != nil {
+		context["@context"] = ns
+	}
 	opts := ld.NewJsonLdOptions("")
 	c, err := datasetToCompact(it.dataset, context, opts)
 	if err != nil {
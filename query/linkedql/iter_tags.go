@@ -8,6 +8,7 @@ import (
 	"github.com/cayleygraph/cayley/query"
 	"github.com/cayleygraph/quad"
 	"github.com/cayleygraph/quad/jsonld"
+	"github.com/cayleygraph/quad/voc"
 	"github.com/piprate/json-gold/ld"
 )
 
@@ -43,7 +44,7 @@ func (it *TagsIterator) addQuadFromRef(dataset *ld.RDFDataset, subject ld.Node,
 	if err != nil {
 		return err
 	}
-	o, err := jsonld.ToNode(rname)
+	o, err := jsonld.ToNode(query.CompactValue(rname, it.ValueIt.Namespaces))
 	if err != nil {
 		return err
 	}
@@ -52,11 +53,12 @@ func (it *TagsIterator) addQuadFromRef(dataset *ld.RDFDataset, subject ld.Node,
 	return nil
 }
 
-func toSubject(namer refs.Namer, result refs.Ref) (ld.Node, error) {
+func toSubject(namer refs.Namer, ns *voc.Namespaces, result refs.Ref) (ld.Node, error) {
 	v, err := namer.NameOf(result)
 	if err != nil {
 		return nil, err
 	}
+	v = query.CompactValue(v, ns)
 	id, ok := v.(quad.Identifier)
 	if !ok {
 		return nil, fmt.Errorf("Expected subject to be an entity identifier but instead received: %v", v)
@@ -65,7 +67,7 @@ func toSubject(namer refs.Namer, result refs.Ref) (ld.Node, error) {
 }
 
 func (it *TagsIterator) addResultsToDataset(dataset *ld.RDFDataset, result refs.Ref) error {
-	s, err := toSubject(it.ValueIt.Namer, result)
+	s, err := toSubject(it.ValueIt.Namer, it.ValueIt.Namespaces, result)
 	if err != nil {
 		return err
 	}
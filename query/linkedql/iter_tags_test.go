@@ -65,7 +65,7 @@ func TestToSubject(t *testing.T) {
 			store := memstore.New(testCase.data)
 			r, err := store.ValueOf(testCase.value)
 			require.NoError(t, err)
-			s, err := toSubject(store, r)
+			s, err := toSubject(store, nil, r)
 			if testCase.err == nil {
 				require.NoError(t, err)
 				require.Equal(t, testCase.expected, s)
@@ -17,10 +17,12 @@ var _ query.Iterator = (*ValueIterator)(nil)
 
 // ValueIterator is an iterator of values from the graph.
 type ValueIterator struct {
-	Namer   refs.Namer
-	path    *path.Path
-	scanner iterator.Scanner
-	err     error
+	Namer refs.Namer
+	// Namespaces are used to compact IRIs in results. If nil, IRIs are returned as is.
+	Namespaces *voc.Namespaces
+	path       *path.Path
+	scanner    iterator.Scanner
+	err        error
 }
 
 // NewValueIterator returns a new ValueIterator for a path and namer.
@@ -61,7 +63,7 @@ func (it *ValueIterator) Value() quad.Value {
 // Result implements query.Iterator.
 func (it *ValueIterator) Result() interface{} {
 	// FIXME(iddan): only convert when collation is JSON/JSON-LD, leave as Ref otherwise
-	return jsonld.FromValue(it.Value())
+	return jsonld.FromValue(query.CompactValue(it.Value(), it.Namespaces))
 }
 
 // Err implements query.Iterator.
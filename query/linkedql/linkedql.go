@@ -45,17 +45,41 @@ func NewSession(qs graph.QuadStore) *Session {
 }
 
 // Execute for a given context, query and options return an iterator of results.
-func (s *Session) Execute(ctx context.Context, query string, opt query.Options) (query.Iterator, error) {
-	item, err := Unmarshal([]byte(query))
+func (s *Session) Execute(ctx context.Context, qu string, opt query.Options) (query.Iterator, error) {
+	item, err := Unmarshal([]byte(qu))
 	if err != nil {
 		return nil, err
 	}
 	ns := voc.Namespaces{}
+	if opt.Namespaces != nil {
+		opt.Namespaces.CloneTo(&ns)
+	}
 	step, ok := item.(Step)
 	if !ok {
 		return nil, errors.New("must execute a Step")
 	}
-	return BuildIterator(step, s.qs, &ns)
+	s.qs = query.QuadStoreFor(s.qs, opt)
+	it, err := BuildIterator(step, s.qs, &ns)
+	if err != nil {
+		return nil, err
+	}
+	if vit := valueIterator(it); vit != nil {
+		vit.Namespaces = opt.Namespaces
+	}
+	return it, nil
+}
+
+// valueIterator returns the ValueIterator used by a given result iterator, if any.
+func valueIterator(it query.Iterator) *ValueIterator {
+	switch it := it.(type) {
+	case *ValueIterator:
+		return it
+	case *TagsIterator:
+		return it.ValueIt
+	case *DocumentIterator:
+		return it.tagsIt.ValueIt
+	}
+	return nil
 }
 
 // BuildIterator for given Step returns a query.Iterator
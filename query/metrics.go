@@ -0,0 +1,134 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/trace"
+)
+
+var (
+	mQueryParseSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "cayley_query_parse_seconds",
+		Help: "Time to parse a query and prepare the iterator.",
+	}, []string{"lang"})
+	mQuerySeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "cayley_query_seconds",
+		Help: "Total time to execute a query, including iteration.",
+	}, []string{"lang", "status"})
+	mQueryResults = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cayley_query_results",
+		Help:    "Number of results returned by a query.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"lang"})
+)
+
+// Query statuses reported in metrics.
+const (
+	statusOK    = "ok"
+	statusError = "error"
+)
+
+// measuredSession records metrics and traces for query execution.
+type measuredSession struct {
+	lang string
+	s    Session
+}
+
+func measureSession(lang string, newSession func(graph.QuadStore) Session) func(graph.QuadStore) Session {
+	return func(qs graph.QuadStore) Session {
+		s := newSession(qs)
+		if s == nil {
+			return nil
+		}
+		return &measuredSession{lang: lang, s: s}
+	}
+}
+
+// Execute implements Session.
+func (s *measuredSession) Execute(ctx context.Context, query string, opt Options) (Iterator, error) {
+	start := time.Now()
+	pctx, span := trace.Start(ctx, "query.parse")
+	span.SetAttr("lang", s.lang)
+	it, err := s.s.Execute(pctx, query, opt)
+	mQueryParseSeconds.WithLabelValues(s.lang).Observe(time.Since(start).Seconds())
+	if err == ErrParseMore {
+		span.End()
+		return nil, err
+	} else if err != nil {
+		span.SetError(err)
+		span.End()
+		mQuerySeconds.WithLabelValues(s.lang, statusError).Observe(time.Since(start).Seconds())
+		return nil, err
+	}
+	span.End()
+	_, ispan := trace.Start(ctx, "query.iterate")
+	ispan.SetAttr("lang", s.lang)
+	return &measuredIterator{lang: s.lang, it: it, start: start, span: ispan}, nil
+}
+
+// measuredIterator records the number of results and the total query time.
+type measuredIterator struct {
+	lang  string
+	it    Iterator
+	start time.Time
+	span  *trace.Span
+	n     int
+	done  bool
+}
+
+// Next implements Iterator.
+func (it *measuredIterator) Next(ctx context.Context) bool {
+	if !it.it.Next(trace.ContextWithSpan(ctx, it.span)) {
+		return false
+	}
+	it.n++
+	return true
+}
+
+// Result implements Iterator.
+func (it *measuredIterator) Result() interface{} {
+	return it.it.Result()
+}
+
+// Err implements Iterator.
+func (it *measuredIterator) Err() error {
+	return it.it.Err()
+}
+
+// Close implements Iterator.
+func (it *measuredIterator) Close() error {
+	err := it.it.Close()
+	if it.done {
+		return err
+	}
+	it.done = true
+	status := statusOK
+	if ierr := it.it.Err(); ierr != nil {
+		status = statusError
+		it.span.SetError(ierr)
+	}
+	mQuerySeconds.WithLabelValues(it.lang, status).Observe(time.Since(it.start).Seconds())
+	mQueryResults.WithLabelValues(it.lang).Observe(float64(it.n))
+	it.span.SetAttr("results", it.n)
+	it.span.End()
+	return err
+}
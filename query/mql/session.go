@@ -97,6 +97,7 @@ func (s *Session) Execute(ctx context.Context, input string, opt query.Options)
 	default:
 		return nil, &query.ErrUnsupportedCollation{Collation: opt.Collation}
 	}
+	s.qs = query.QuadStoreFor(s.qs, opt)
 	var mqlQuery interface{}
 	if err := json.Unmarshal([]byte(input), &mqlQuery); err != nil {
 		return nil, err
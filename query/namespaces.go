@@ -0,0 +1,68 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc"
+)
+
+// ExpandValue replaces known prefixes in IRIs and types of typed strings with full namespace IRIs.
+func ExpandValue(v quad.Value, ns *voc.Namespaces) quad.Value {
+	if ns == nil {
+		return v
+	}
+	switch v := v.(type) {
+	case quad.IRI:
+		return v.FullWith(ns)
+	case quad.TypedString:
+		return quad.TypedString{Value: v.Value, Type: v.Type.FullWith(ns)}
+	}
+	return v
+}
+
+// CompactValue replaces known namespaces in IRIs and types of typed strings with their prefixes.
+func CompactValue(v quad.Value, ns *voc.Namespaces) quad.Value {
+	if ns == nil {
+		return v
+	}
+	switch v := v.(type) {
+	case quad.IRI:
+		return v.ShortWith(ns)
+	case quad.TypedString:
+		return quad.TypedString{Value: v.Value, Type: v.Type.ShortWith(ns)}
+	}
+	return v
+}
+
+// LdContext returns a JSON-LD context that defines given namespaces as prefixes.
+func LdContext(ns *voc.Namespaces) map[string]interface{} {
+	if ns == nil {
+		return nil
+	}
+	list := ns.List()
+	if len(list) == 0 {
+		return nil
+	}
+	ctx := make(map[string]interface{}, len(list))
+	for _, n := range list {
+		pref := n.Prefix
+		if l := len(pref); l != 0 && pref[l-1] == ':' {
+			pref = pref[:l-1]
+		}
+		ctx[pref] = n.Full
+	}
+	return ctx
+}
@@ -22,6 +22,7 @@ import (
 	"io"
 
 	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad/voc"
 )
 
 var ErrParseMore = errors.New("query: more input required")
@@ -67,6 +68,29 @@ const (
 type Options struct {
 	Limit     int
 	Collation Collation
+	// Namespaces of the database. If set, prefixed IRIs in the query are expanded
+	// and IRIs in JSON and JSON-LD results are compacted using these namespaces.
+	Namespaces *voc.Namespaces
+	// NoReasoning disables inference for the query on quad stores that support it. See Reasoner.
+	NoReasoning bool
+}
+
+// Reasoner is an optional interface for quad stores that infer quads at query time.
+type Reasoner interface {
+	// WithReasoning returns a view of the quad store with inference enabled or disabled.
+	WithReasoning(on bool) graph.QuadStore
+}
+
+// QuadStoreFor returns a view of the quad store that a query with given options should run on.
+func QuadStoreFor(qs graph.QuadStore, opt Options) graph.QuadStore {
+	if h, ok := qs.(*graph.Handle); ok {
+		if r, ok := h.QuadStore.(Reasoner); ok {
+			return &graph.Handle{QuadStore: r.WithReasoning(!opt.NoReasoning), QuadWriter: h.QuadWriter}
+		}
+	} else if r, ok := qs.(Reasoner); ok {
+		return r.WithReasoning(!opt.NoReasoning)
+	}
+	return qs
 }
 
 type Session interface {
@@ -97,7 +121,12 @@ type Language struct {
 var languages = make(map[string]Language)
 
 // RegisterLanguage register a new query language.
+//
+// Sessions of the language are instrumented to record query metrics and traces.
 func RegisterLanguage(lang Language) {
+	if lang.Session != nil {
+		lang.Session = measureSession(lang.Name, lang.Session)
+	}
 	languages[lang.Name] = lang
 }
 
@@ -78,6 +78,7 @@ func (s *Session) Execute(ctx context.Context, input string, opt query.Options)
 	default:
 		return nil, &query.ErrUnsupportedCollation{Collation: opt.Collation}
 	}
+	s.qs = query.QuadStoreFor(s.qs, opt)
 	it := BuildIteratorTreeForQuery(ctx, s.qs, input).Iterate()
 	if err := it.Err(); err != nil {
 		return nil, err
@@ -13,6 +13,7 @@ import (
 	"github.com/cayleygraph/cayley/graph"
 	"github.com/cayleygraph/cayley/graph/iterator"
 	"github.com/cayleygraph/cayley/graph/refs"
+	"github.com/cayleygraph/cayley/trace"
 )
 
 var (
@@ -37,6 +38,13 @@ type Optimizer interface {
 	OptimizeShape(ctx context.Context, s Shape) (Shape, bool)
 }
 
+// Rewriter is an optional interface for quad stores that wrap another quad store and modify queries to it.
+type Rewriter interface {
+	// RewriteShape is called before the shape is optimized. It returns a new shape and the quad store
+	// that the shape should be optimized for and built on.
+	RewriteShape(ctx context.Context, s Shape) (Shape, graph.QuadStore)
+}
+
 // Composite shape can be simplified to a tree of more basic shapes.
 type Composite interface {
 	Simplify() Shape
@@ -212,11 +220,17 @@ func IsNull(s Shape) bool {
 // BuildIterator optimizes the shape and builds a corresponding iterator tree.
 func BuildIterator(ctx context.Context, qs graph.QuadStore, s Shape) iterator.Shape {
 	qs = graph.Unwrap(qs)
+	if rw, ok := qs.(Rewriter); ok && s != nil {
+		s, qs = rw.RewriteShape(ctx, s)
+		qs = graph.Unwrap(qs)
+	}
 	if s != nil {
 		if debugShapes || clog.V(2) {
 			clog.Infof("shape: %#v", s)
 		}
-		s, _ = Optimize(ctx, s, qs)
+		octx, span := trace.Start(ctx, "shape.optimize")
+		s, _ = Optimize(octx, s, qs)
+		span.End()
 		if debugOptimizer || clog.V(2) {
 			clog.Infof("optimized: %#v", s)
 		}
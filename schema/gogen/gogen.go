@@ -0,0 +1,559 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gogen generates Go types for the schema package from RDFS and OWL vocabularies.
+//
+// Each class becomes a struct with an ID field and a field for each property that has the class in its domain.
+// The first super class of a class is embedded into the struct, properties of other super classes are copied.
+// Property ranges are mapped to Go types: XSD datatypes to native types, and classes to quad.IRI references.
+// Properties are mapped to slices, unless they are declared as owl:FunctionalProperty or have
+// owl:maxCardinality or owl:cardinality of 1 in a restriction of the class.
+package gogen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/owl"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+	"github.com/cayleygraph/quad/voc/schema"
+	"github.com/cayleygraph/quad/voc/xsd"
+)
+
+// OWL vocabulary that is not defined in the owl package.
+const (
+	owlFunctionalProperty = owl.Prefix + "FunctionalProperty"
+	owlMinCardinality     = owl.Prefix + "minCardinality"
+)
+
+// schema.org vocabulary that is not defined in the schema package.
+const (
+	schemaDomainIncludes = schema.Prefix + "domainIncludes"
+	schemaRangeIncludes  = schema.Prefix + "rangeIncludes"
+)
+
+// Options for the code generation.
+type Options struct {
+	// Package is the name of the generated package; "model" by default.
+	Package string
+}
+
+// datatypes maps XSD and RDF datatypes to Go types.
+var datatypes = map[quad.IRI]string{
+	xsd.String:                        "string",
+	rdfs.Literal:                      "string",
+	xsd.Prefix + "normalizedString":   "string",
+	xsd.Prefix + "token":              "string",
+	xsd.Prefix + "anyURI":             "quad.IRI",
+	xsd.Boolean:                       "bool",
+	xsd.Integer:                       "int64",
+	xsd.Long:                          "int64",
+	xsd.Int:                           "int64",
+	xsd.Prefix + "short":              "int64",
+	xsd.Prefix + "byte":               "int64",
+	xsd.Prefix + "nonNegativeInteger": "int64",
+	xsd.Prefix + "positiveInteger":    "int64",
+	xsd.Prefix + "unsignedInt":        "int64",
+	xsd.Prefix + "unsignedLong":       "int64",
+	xsd.Double:                        "float64",
+	xsd.Float:                         "float64",
+	xsd.Prefix + "decimal":            "float64",
+	xsd.DateTime:                      "time.Time",
+	xsd.Prefix + "date":               "time.Time",
+	rdf.LangString:                    "quad.LangString",
+	schema.Prefix + "Text":            "string",
+	schema.Prefix + "URL":             "quad.IRI",
+	schema.Prefix + "Boolean":         "bool",
+	schema.Prefix + "Integer":         "int64",
+	schema.Prefix + "Number":          "float64",
+	schema.Prefix + "Float":           "float64",
+	schema.Prefix + "DateTime":        "time.Time",
+	schema.Prefix + "Date":            "time.Time",
+}
+
+// vocab is an in-memory index of vocabulary quads.
+type vocab map[quad.Value]map[quad.IRI][]quad.Value
+
+func (v vocab) objects(s quad.Value, p string) []quad.Value {
+	return v[s][quad.IRI(p)]
+}
+
+func (v vocab) has(s quad.Value, p string, o string) bool {
+	for _, x := range v.objects(s, p) {
+		if x == quad.IRI(o) {
+			return true
+		}
+	}
+	return false
+}
+
+// short converts IRIs to the short form, to match them with vocabulary constants.
+func short(v quad.Value) quad.Value {
+	if iri, ok := v.(quad.IRI); ok {
+		return iri.Short()
+	}
+	return v
+}
+
+type class struct {
+	iri    quad.IRI
+	name   string
+	doc    string
+	supers []*class
+	props  []*property
+	// single and required are properties restricted by cardinality in the class
+	single   map[*property]bool
+	required map[*property]bool
+}
+
+type property struct {
+	iri    quad.IRI
+	name   string
+	doc    string
+	ranges []quad.IRI
+	single bool
+}
+
+type generator struct {
+	v vocab
+	// orig maps IRIs to the form used in the vocabulary
+	orig    map[quad.IRI]quad.IRI
+	classes map[quad.IRI]*class
+	props   map[quad.IRI]*property
+	imports map[string]bool
+}
+
+// Generate generates Go source code with types for classes defined by the quads.
+func Generate(quads []quad.Quad, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "model"
+	}
+	g := &generator{
+		v:       make(vocab),
+		orig:    make(map[quad.IRI]quad.IRI),
+		classes: make(map[quad.IRI]*class),
+		props:   make(map[quad.IRI]*property),
+		imports: map[string]bool{"github.com/cayleygraph/quad": true},
+	}
+	for _, q := range quads {
+		p, ok := short(q.Predicate).(quad.IRI)
+		if !ok {
+			continue
+		}
+		s := short(q.Subject)
+		if iri, ok := q.Subject.(quad.IRI); ok {
+			g.orig[iri.Short()] = iri
+		}
+		m := g.v[s]
+		if m == nil {
+			m = make(map[quad.IRI][]quad.Value)
+			g.v[s] = m
+		}
+		m[p] = append(m[p], short(q.Object))
+	}
+	g.collect()
+	if len(g.classes) == 0 {
+		return nil, errors.New("gogen: no classes found")
+	}
+	return g.generate(opts)
+}
+
+// iri returns the IRI in the form used in the vocabulary.
+func (g *generator) iri(iri quad.IRI) string {
+	if o, ok := g.orig[iri]; ok {
+		return string(o)
+	}
+	return string(iri)
+}
+
+func (g *generator) isDatatype(iri quad.IRI) bool {
+	if _, ok := datatypes[iri]; ok {
+		return true
+	}
+	return g.v.has(iri, rdf.Type, rdfs.Datatype)
+}
+
+func (g *generator) class(iri quad.IRI) *class {
+	c := g.classes[iri]
+	if c == nil {
+		c = &class{iri: iri, single: make(map[*property]bool), required: make(map[*property]bool)}
+		g.classes[iri] = c
+	}
+	return c
+}
+
+func (g *generator) property(iri quad.IRI) *property {
+	p := g.props[iri]
+	if p == nil {
+		p = &property{iri: iri}
+		g.props[iri] = p
+	}
+	return p
+}
+
+func (g *generator) collect() {
+	for s, m := range g.v {
+		iri, ok := s.(quad.IRI)
+		if !ok {
+			continue
+		}
+		for _, t := range m[rdf.Type] {
+			switch t {
+			case quad.IRI(rdfs.Class), quad.IRI(owl.Class), quad.IRI(schema.Class):
+				if !g.isDatatype(iri) {
+					g.class(iri)
+				}
+			case quad.IRI(rdf.Property), quad.IRI(owl.DatatypeProperty), quad.IRI(owl.ObjectProperty),
+				quad.IRI(owlFunctionalProperty), quad.IRI(schema.Property):
+				g.property(iri)
+			}
+		}
+		for _, p := range []string{rdfs.Domain, rdfs.Range, schemaDomainIncludes, schemaRangeIncludes} {
+			if len(m[quad.IRI(p)]) != 0 {
+				g.property(iri)
+			}
+		}
+	}
+	for iri, p := range g.props {
+		p.doc = g.comment(iri)
+		p.single = g.v.has(iri, rdf.Type, owlFunctionalProperty)
+		for _, r := range append(g.v.objects(iri, rdfs.Range), g.v.objects(iri, schemaRangeIncludes)...) {
+			if r, ok := r.(quad.IRI); ok {
+				p.ranges = append(p.ranges, r)
+			}
+		}
+		sortIRIs(p.ranges)
+		for _, d := range append(g.v.objects(iri, rdfs.Domain), g.v.objects(iri, schemaDomainIncludes)...) {
+			if d, ok := d.(quad.IRI); ok {
+				c := g.class(d)
+				c.props = append(c.props, p)
+			}
+		}
+	}
+	for iri, c := range g.classes {
+		c.doc = g.comment(iri)
+		for _, sup := range g.v.objects(iri, rdfs.SubClassOf) {
+			switch sup := sup.(type) {
+			case quad.IRI:
+				if sc := g.classes[sup]; sc != nil && sc != c {
+					c.supers = append(c.supers, sc)
+				}
+			case quad.BNode:
+				g.restriction(c, sup)
+			}
+		}
+		sort.Slice(c.supers, func(i, j int) bool { return c.supers[i].iri < c.supers[j].iri })
+		sort.Slice(c.props, func(i, j int) bool { return c.props[i].iri < c.props[j].iri })
+	}
+	g.names()
+}
+
+// restriction applies cardinality restrictions of the class.
+func (g *generator) restriction(c *class, r quad.Value) {
+	if !g.v.has(r, rdf.Type, owl.Restriction) {
+		return
+	}
+	for _, on := range g.v.objects(r, owl.OnProperty) {
+		iri, ok := on.(quad.IRI)
+		if !ok {
+			continue
+		}
+		p := g.props[iri]
+		if p == nil {
+			continue
+		}
+		card := func(pred string) (int, bool) {
+			for _, v := range g.v.objects(r, pred) {
+				if n, err := strconv.Atoi(lexical(v)); err == nil {
+					return n, true
+				}
+			}
+			return 0, false
+		}
+		if n, ok := card(owl.Cardinality); ok {
+			c.single[p] = n <= 1
+			c.required[p] = n >= 1
+		}
+		if n, ok := card(owl.MaxCardinality); ok && n <= 1 {
+			c.single[p] = true
+		}
+		if n, ok := card(owlMinCardinality); ok && n >= 1 {
+			c.required[p] = true
+		}
+	}
+}
+
+func (g *generator) comment(iri quad.IRI) string {
+	for _, p := range []string{rdfs.Comment, rdfs.Label} {
+		for _, v := range g.v.objects(iri, p) {
+			if s := strings.TrimSpace(lexical(v)); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// lexical returns the lexical form of a literal.
+func lexical(v quad.Value) string {
+	switch v := v.(type) {
+	case quad.String:
+		return string(v)
+	case quad.LangString:
+		return string(v.Value)
+	case quad.TypedString:
+		return string(v.Value)
+	case quad.TypedStringer:
+		return string(v.TypedString().Value)
+	}
+	return quad.ToString(v)
+}
+
+// names assigns unique Go names to classes and properties.
+func (g *generator) names() {
+	classes := make([]*class, 0, len(g.classes))
+	for _, c := range g.classes {
+		classes = append(classes, c)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].iri < classes[j].iri })
+	used := make(map[string]bool)
+	for _, c := range classes {
+		c.name = unique(used, goName(c.iri))
+	}
+	props := make([]*property, 0, len(g.props))
+	for _, p := range g.props {
+		props = append(props, p)
+	}
+	sort.Slice(props, func(i, j int) bool { return props[i].iri < props[j].iri })
+	for _, p := range props {
+		p.name = goName(p.iri)
+	}
+}
+
+func unique(used map[string]bool, name string) string {
+	out := name
+	for i := 2; used[out]; i++ {
+		out = name + strconv.Itoa(i)
+	}
+	used[out] = true
+	return out
+}
+
+// goName converts the local name of an IRI to an exported Go identifier.
+func goName(iri quad.IRI) string {
+	s := string(iri.Full())
+	if i := strings.LastIndexAny(s, "#/:"); i >= 0 && i < len(s)-1 {
+		s = s[i+1:]
+	}
+	var (
+		sb    strings.Builder
+		upper = true
+	)
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	name := sb.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	if strings.EqualFold(name, "id") {
+		name = "ID"
+	}
+	return name
+}
+
+// goType returns a Go type for values of the property.
+func (g *generator) goType(p *property) string {
+	if len(p.ranges) != 1 {
+		if len(p.ranges) != 0 && g.allClasses(p.ranges) {
+			return "quad.IRI"
+		}
+		return "quad.Value"
+	}
+	r := p.ranges[0]
+	if t, ok := datatypes[r]; ok {
+		if strings.HasPrefix(t, "time.") {
+			g.imports["time"] = true
+		}
+		return t
+	}
+	if g.isDatatype(r) {
+		return "quad.Value"
+	}
+	return "quad.IRI"
+}
+
+func (g *generator) allClasses(ranges []quad.IRI) bool {
+	for _, r := range ranges {
+		if g.isDatatype(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortIRIs(arr []quad.IRI) {
+	sort.Slice(arr, func(i, j int) bool { return arr[i] < arr[j] })
+}
+
+// inherited returns all properties of the class and its super classes.
+func (c *class) inherited(seen map[*class]bool) map[*property]bool {
+	out := make(map[*property]bool)
+	if seen[c] {
+		return out
+	}
+	seen[c] = true
+	for _, p := range c.props {
+		out[p] = true
+	}
+	for _, s := range c.supers {
+		for p := range s.inherited(seen) {
+			out[p] = true
+		}
+	}
+	return out
+}
+
+// embedded returns the super class that is embedded into the struct of the class.
+func (c *class) embedded() *class {
+	for _, s := range c.supers {
+		// embedding must not create a cycle of struct types
+		if !s.extends(c, make(map[*class]bool)) {
+			return s
+		}
+	}
+	return nil
+}
+
+// extends checks if the class is a subclass of a given class.
+func (c *class) extends(sup *class, seen map[*class]bool) bool {
+	if c == sup {
+		return true
+	}
+	if seen[c] {
+		return false
+	}
+	seen[c] = true
+	for _, s := range c.supers {
+		if s.extends(sup, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeDoc(buf *bytes.Buffer, name, doc, iri string) {
+	fmt.Fprintf(buf, "// %s is generated from %s.\n", name, iri)
+	if doc == "" {
+		return
+	}
+	buf.WriteString("//\n")
+	for _, line := range strings.Split(doc, "\n") {
+		fmt.Fprintf(buf, "// %s\n", strings.TrimSpace(line))
+	}
+}
+
+func (g *generator) generate(opts Options) ([]byte, error) {
+	classes := make([]*class, 0, len(g.classes))
+	for _, c := range g.classes {
+		classes = append(classes, c)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].name < classes[j].name })
+
+	var body bytes.Buffer
+	for _, c := range classes {
+		writeDoc(&body, c.name, c.doc, g.iri(c.iri))
+		fmt.Fprintf(&body, "type %s struct {\n", c.name)
+		var own map[*property]bool
+		if e := c.embedded(); e != nil {
+			fmt.Fprintf(&body, "\t%s\n", e.name)
+			own = e.inherited(make(map[*class]bool))
+		} else {
+			fmt.Fprintf(&body, "\tID quad.IRI `quad:\"@id\"`\n")
+			own = make(map[*property]bool)
+		}
+		var props []*property
+		for p := range c.inherited(make(map[*class]bool)) {
+			if !own[p] {
+				props = append(props, p)
+			}
+		}
+		sort.Slice(props, func(i, j int) bool { return props[i].iri < props[j].iri })
+		used := map[string]bool{"ID": true}
+		if e := c.embedded(); e != nil {
+			used[e.name] = true
+		}
+		for _, p := range props {
+			name := unique(used, p.name)
+			typ := g.goType(p)
+			single, ok := c.single[p]
+			if !ok {
+				single = p.single
+			}
+			tag := g.iri(p.iri)
+			if c.required[p] {
+				tag += ",required"
+			} else if single {
+				tag += ",optional"
+			}
+			if !single {
+				typ = "[]" + typ
+			}
+			if p.doc != "" {
+				for _, line := range strings.Split(p.doc, "\n") {
+					fmt.Fprintf(&body, "\t// %s\n", strings.TrimSpace(line))
+				}
+			}
+			fmt.Fprintf(&body, "\t%s %s `quad:%s`\n", name, typ, strconv.Quote(tag))
+		}
+		body.WriteString("}\n\n")
+	}
+	body.WriteString("func init() {\n")
+	for _, c := range classes {
+		fmt.Fprintf(&body, "\tschema.RegisterType(quad.IRI(%s), %s{})\n", strconv.Quote(g.iri(c.iri)), c.name)
+	}
+	body.WriteString("}\n")
+	g.imports["github.com/cayleygraph/cayley/schema"] = true
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by cayley schema gen-go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", opts.Package)
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&buf, "\t%q\n", imp)
+	}
+	buf.WriteString(")\n\n")
+	buf.Write(body.Bytes())
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,78 @@
+package gogen_test
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/nquads"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/schema/gogen"
+)
+
+const vocabNQ = `
+<http://example.com/Person> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2000/01/rdf-schema#Class> .
+<http://example.com/Person> <http://www.w3.org/2000/01/rdf-schema#comment> "A person." .
+<http://example.com/Employee> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#Class> .
+<http://example.com/Employee> <http://www.w3.org/2000/01/rdf-schema#subClassOf> <http://example.com/Person> .
+<http://example.com/Employee> <http://www.w3.org/2000/01/rdf-schema#subClassOf> _:r .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#Restriction> .
+_:r <http://www.w3.org/2002/07/owl#onProperty> <http://example.com/employer> .
+_:r <http://www.w3.org/2002/07/owl#cardinality> "1"^^<http://www.w3.org/2001/XMLSchema#nonNegativeInteger> .
+<http://example.com/name> <http://www.w3.org/2000/01/rdf-schema#domain> <http://example.com/Person> .
+<http://example.com/name> <http://www.w3.org/2000/01/rdf-schema#range> <http://www.w3.org/2001/XMLSchema#string> .
+<http://example.com/name> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#FunctionalProperty> .
+<http://example.com/birth-date> <http://www.w3.org/2000/01/rdf-schema#domain> <http://example.com/Person> .
+<http://example.com/birth-date> <http://www.w3.org/2000/01/rdf-schema#range> <http://www.w3.org/2001/XMLSchema#dateTime> .
+<http://example.com/knows> <http://www.w3.org/2000/01/rdf-schema#domain> <http://example.com/Person> .
+<http://example.com/knows> <http://www.w3.org/2000/01/rdf-schema#range> <http://example.com/Person> .
+<http://example.com/employer> <http://www.w3.org/2000/01/rdf-schema#domain> <http://example.com/Employee> .
+<http://example.com/employer> <http://www.w3.org/2000/01/rdf-schema#range> <http://example.com/Company> .
+<http://example.com/salary> <http://www.w3.org/2000/01/rdf-schema#domain> <http://example.com/Employee> .
+<http://example.com/salary> <http://www.w3.org/2000/01/rdf-schema#range> <http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.com/id> <http://www.w3.org/2000/01/rdf-schema#domain> <http://example.com/Company> .
+<http://schema.org/legalName> <http://schema.org/domainIncludes> <http://example.com/Company> .
+<http://schema.org/legalName> <http://schema.org/rangeIncludes> <http://schema.org/Text> .
+`
+
+func TestGenerate(t *testing.T) {
+	quads, err := quad.ReadAll(nquads.NewReader(strings.NewReader(vocabNQ), false))
+	require.NoError(t, err)
+	data, err := gogen.Generate(quads, gogen.Options{Package: "vocab"})
+	require.NoError(t, err)
+	src := string(data)
+
+	f, err := parser.ParseFile(token.NewFileSet(), "vocab.go", data, parser.ParseComments)
+	require.NoError(t, err, src)
+	require.Equal(t, "vocab", f.Name.Name)
+	var imports []string
+	for _, imp := range f.Imports {
+		imports = append(imports, imp.Path.Value)
+	}
+	require.ElementsMatch(t, []string{`"github.com/cayleygraph/cayley/schema"`, `"github.com/cayleygraph/quad"`, `"time"`}, imports)
+
+	for _, s := range []string{
+		"// Person is generated from http://example.com/Person.\n//\n// A person.\ntype Person struct {",
+		"ID        quad.IRI    `quad:\"@id\"`",
+		"BirthDate []time.Time `quad:\"http://example.com/birth-date\"`",
+		"Knows     []quad.IRI  `quad:\"http://example.com/knows\"`",
+		"Name      string      `quad:\"http://example.com/name,optional\"`",
+		"type Employee struct {\n\tPerson\n",
+		"Employer quad.IRI `quad:\"http://example.com/employer,required\"`",
+		"Salary   []int64  `quad:\"http://example.com/salary\"`",
+		// property named id must not collide with the ID field
+		"ID2       []quad.Value `quad:\"http://example.com/id\"`",
+		"LegalName []string     `quad:\"http://schema.org/legalName\"`",
+		`schema.RegisterType(quad.IRI("http://example.com/Company"), Company{})`,
+		`schema.RegisterType(quad.IRI("http://example.com/Employee"), Employee{})`,
+		`schema.RegisterType(quad.IRI("http://example.com/Person"), Person{})`,
+	} {
+		require.Contains(t, src, s)
+	}
+
+	_, err = gogen.Generate(nil, gogen.Options{})
+	require.Error(t, err)
+}
@@ -89,6 +89,9 @@ func IsNotFound(err error) bool {
 //		ThirdName string `quad:"thirdName,optional"` // can be empty
 //		FollowedBy []quad.IRI `quad:"follows"`
 // 	}
+//
+// A "cascade" tag marks nested objects that are owned by the parent. It has no effect on loading,
+// but UpdateObject and DeleteObject will remove quads of such objects together with the parent.
 func (c *Config) LoadTo(ctx context.Context, qs graph.QuadStore, dst interface{}, ids ...quad.Value) error {
 	return c.LoadToDepth(ctx, qs, dst, -1, ids...)
 }
@@ -236,27 +239,25 @@ func (l *loader) makePathForType(rt reflect.Type, tagPref string, rootOnly bool)
 		case saveRule:
 			tag := tagPref + name
 			if rule.Opt {
+				// at least one of optional fields must be set, even if they are not saved
+				if allOptional {
+					ap := path.StartMorphism()
+					if rule.Rev {
+						ap = ap.HasReverse(rule.Pred)
+					} else {
+						ap = ap.Has(rule.Pred)
+					}
+					if alt == nil {
+						alt = ap
+					} else {
+						alt = alt.Or(ap)
+					}
+				}
 				if !rootOnly {
 					if rule.Rev {
 						p = p.SaveOptionalReverse(rule.Pred, tag)
-						if allOptional {
-							ap := path.StartMorphism().HasReverse(rule.Pred)
-							if alt == nil {
-								alt = ap
-							} else {
-								alt = alt.Or(ap)
-							}
-						}
 					} else {
 						p = p.SaveOptional(rule.Pred, tag)
-						if allOptional {
-							ap := path.StartMorphism().Has(rule.Pred)
-							if alt == nil {
-								alt = ap
-							} else {
-								alt = alt.Or(ap)
-							}
-						}
 					}
 				}
 			} else if rootOnly { // do not save field, enforce constraint only
@@ -276,7 +277,7 @@ func (l *loader) makePathForType(rt reflect.Type, tagPref string, rootOnly bool)
 			}
 		}
 	}
-	if allOptional {
+	if allOptional && alt != nil {
 		p = p.And(alt.Unique())
 	}
 	if tagPref != "" {
@@ -359,10 +360,22 @@ func (l *loader) loadToValue(ctx context.Context, dst reflect.Value, depth int,
 				ptr = false
 			}
 		}
-		recursive := !native && ft.Kind() == reflect.Struct
+		lazy := !native && isLazyRef(ft)
+		recursive := !native && !lazy && ft.Kind() == reflect.Struct
 		for _, fv := range arr {
 			var sv reflect.Value
-			if recursive {
+			if lazy {
+				id, err := l.qs.NameOf(fv)
+				if err != nil {
+					return err
+				}
+				if id == nil {
+					continue
+				}
+				pv := reflect.New(ft)
+				pv.Interface().(lazyRef).bind(l.c, l.qs, id)
+				sv = pv.Elem()
+			} else if recursive {
 				if ptr {
 					fv, err := l.qs.NameOf(fv)
 					if err != nil {
@@ -2,8 +2,12 @@ package schema
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/cayleygraph/cayley/graph"
 	"github.com/cayleygraph/quad"
@@ -55,3 +59,167 @@ func (c *Config) LoadNamespaces(ctx context.Context, qs graph.QuadStore, dest *v
 	}
 	return nil
 }
+
+// NamespaceRegistry is a list of namespaces persisted in a graph.
+// It is safe for concurrent use.
+type NamespaceRegistry struct {
+	c *Config
+	h *graph.Handle
+
+	mu     sync.RWMutex
+	loaded bool
+	ns     map[string]voc.Namespace // by prefix
+}
+
+// NewNamespaceRegistry creates a registry of namespaces stored in a given graph.
+// Namespaces are loaded on the first access, or by calling Load.
+func (c *Config) NewNamespaceRegistry(h *graph.Handle) *NamespaceRegistry {
+	return &NamespaceRegistry{c: c, h: h}
+}
+
+// Load reloads the list of namespaces from the graph.
+func (r *NamespaceRegistry) Load(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.load(ctx)
+}
+
+func (r *NamespaceRegistry) load(ctx context.Context) error {
+	var list []namespace
+	if err := r.c.LoadTo(ctx, r.h.QuadStore, &list); err != nil {
+		return err
+	}
+	r.ns = make(map[string]voc.Namespace, len(list))
+	for _, n := range list {
+		r.ns[string(n.Prefix)] = voc.Namespace{Prefix: string(n.Prefix), Full: string(n.Full)}
+	}
+	r.loaded = true
+	return nil
+}
+
+func (r *NamespaceRegistry) ensureLoaded(ctx context.Context) error {
+	r.mu.RLock()
+	loaded := r.loaded
+	r.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loaded {
+		return nil
+	}
+	return r.load(ctx)
+}
+
+// List returns all namespaces stored in the graph, sorted by prefix.
+func (r *NamespaceRegistry) List(ctx context.Context) ([]voc.Namespace, error) {
+	if err := r.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]voc.Namespace, 0, len(r.ns))
+	for _, n := range r.ns {
+		out = append(out, n)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Prefix < out[j].Prefix
+	})
+	return out, nil
+}
+
+// Namespaces returns a copy of namespaces stored in the graph.
+func (r *NamespaceRegistry) Namespaces(ctx context.Context) (*voc.Namespaces, error) {
+	if err := r.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out voc.Namespaces
+	for _, n := range r.ns {
+		out.Register(n)
+	}
+	return &out, nil
+}
+
+// removeTx adds quads of given namespaces to the transaction for removal.
+func (r *NamespaceRegistry) removeTx(tx *graph.Transaction, list ...voc.Namespace) error {
+	var ns voc.Namespaces
+	for _, n := range list {
+		ns.Register(n)
+	}
+	return r.c.WriteNamespaces(graph.NewTxWriter(tx, graph.Delete), &ns)
+}
+
+// normPrefix adds a colon to the prefix, as expected by voc package.
+func normPrefix(prefix string) string {
+	if prefix != "" && !strings.HasSuffix(prefix, ":") {
+		prefix += ":"
+	}
+	return prefix
+}
+
+// Register stores the namespace in the graph. It replaces namespaces with the same prefix or IRI.
+// Prefix may be given with or without a trailing colon.
+func (r *NamespaceRegistry) Register(ctx context.Context, ns voc.Namespace) error {
+	ns.Prefix = normPrefix(ns.Prefix)
+	if ns.Prefix == "" || ns.Full == "" {
+		return errors.New("namespace prefix and IRI must be set")
+	}
+	if err := r.ensureLoaded(ctx); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var old []voc.Namespace
+	for _, n := range r.ns {
+		if n.Prefix == ns.Prefix || n.Full == ns.Full {
+			if n == ns {
+				return nil
+			}
+			old = append(old, n)
+		}
+	}
+	tx := graph.NewTransaction()
+	if err := r.removeTx(tx, old...); err != nil {
+		return err
+	}
+	var list voc.Namespaces
+	list.Register(ns)
+	if err := r.c.WriteNamespaces(graph.NewTxWriter(tx, graph.Add), &list); err != nil {
+		return err
+	}
+	if err := r.h.QuadWriter.ApplyTransaction(tx); err != nil {
+		return err
+	}
+	for _, n := range old {
+		delete(r.ns, n.Prefix)
+	}
+	r.ns[ns.Prefix] = ns
+	return nil
+}
+
+// Delete removes the namespace with a given prefix from the graph.
+// It returns false if the namespace is not stored in the graph.
+func (r *NamespaceRegistry) Delete(ctx context.Context, prefix string) (bool, error) {
+	prefix = normPrefix(prefix)
+	if err := r.ensureLoaded(ctx); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.ns[prefix]
+	if !ok {
+		return false, nil
+	}
+	tx := graph.NewTransaction()
+	if err := r.removeTx(tx, n); err != nil {
+		return false, err
+	}
+	if err := r.h.QuadWriter.ApplyTransaction(tx); err != nil {
+		return false, err
+	}
+	delete(r.ns, prefix)
+	return true, nil
+}
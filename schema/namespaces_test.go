@@ -9,6 +9,7 @@ import (
 	"github.com/cayleygraph/cayley/graph"
 	"github.com/cayleygraph/cayley/graph/memstore"
 	"github.com/cayleygraph/cayley/schema"
+	"github.com/cayleygraph/cayley/writer"
 	"github.com/cayleygraph/quad"
 	"github.com/cayleygraph/quad/voc"
 )
@@ -58,3 +59,61 @@ func TestSaveNamespaces(t *testing.T) {
 		t.Fatalf("wrong quads returned: got: %v, expect: %v", q, expect)
 	}
 }
+
+func TestNamespaceRegistry(t *testing.T) {
+	ctx := context.TODO()
+	sch := schema.NewConfig()
+	qs := memstore.New()
+	w, err := writer.NewSingleReplication(qs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &graph.Handle{QuadStore: qs, QuadWriter: w}
+
+	reg := sch.NewNamespaceRegistry(h)
+	for _, n := range []voc.Namespace{
+		{Full: "http://example.org/", Prefix: "ex"},
+		{Full: "http://cayley.io/", Prefix: "c:"},
+		// replaces the first one
+		{Full: "http://example.com/", Prefix: "ex:"},
+	} {
+		if err = reg.Register(ctx, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = reg.Register(ctx, voc.Namespace{Prefix: "x:"}); err == nil {
+		t.Fatal("expected an error for an empty namespace")
+	}
+	expect := []voc.Namespace{
+		{Full: "http://cayley.io/", Prefix: "c:"},
+		{Full: "http://example.com/", Prefix: "ex:"},
+	}
+	// namespaces should survive a restart
+	reg = sch.NewNamespaceRegistry(h)
+	got, err := reg.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("wrong namespaces returned: got: %v, expect: %v", got, expect)
+	}
+	ns, err := reg.Namespaces(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if iri := quad.IRI("ex:a").FullWith(ns); iri != "http://example.com/a" {
+		t.Fatalf("unexpected IRI: %v", iri)
+	}
+
+	if ok, err := reg.Delete(ctx, "ex"); err != nil || !ok {
+		t.Fatalf("delete failed: %v, %v", ok, err)
+	}
+	if ok, err := reg.Delete(ctx, "ex"); err != nil || ok {
+		t.Fatalf("second delete: %v, %v", ok, err)
+	}
+	q, err := quad.ReadAll(graph.NewQuadStoreReader(qs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q) != 2 {
+		t.Fatalf("unexpected quads left: %v", q)
+	}
+}
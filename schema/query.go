@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/query/path"
+	"github.com/cayleygraph/cayley/query/shape"
+	"github.com/cayleygraph/quad"
+)
+
+// Load loads objects of type T with given IDs (or all objects of this type, if no IDs are given)
+// using the global config. It's a shorthand for Query[T](qs).IDs(ids...).All(ctx).
+//
+// Objects that are missing or don't match type constraints are skipped. See LoadTo for a list
+// of mapping rules.
+func Load[T any](ctx context.Context, qs graph.QuadStore, ids ...quad.Value) ([]T, error) {
+	return Query[T](qs).IDs(ids...).All(ctx)
+}
+
+// Query starts a query for objects of type T. T must be a struct type.
+//
+// Constraints are added with Where and WhereFilter methods, and objects are loaded by All or First:
+//
+//	people, err := schema.Query[Person](qs).Where("Name", quad.String("Bob")).Limit(10).All(ctx)
+func Query[T any](qs graph.QuadStore) *TypedQuery[T] {
+	return &TypedQuery[T]{c: Global(), qs: qs, depth: -1}
+}
+
+// TypedQuery is a query that loads objects of type T.
+type TypedQuery[T any] struct {
+	c     *Config
+	qs    graph.QuadStore
+	ids   []quad.Value
+	where []func(p *path.Path, rules fieldRules) (*path.Path, error)
+	limit int64
+	depth int
+}
+
+// WithConfig sets the schema config used by the query. Global config is used by default.
+func (q *TypedQuery[T]) WithConfig(c *Config) *TypedQuery[T] {
+	q.c = c
+	return q
+}
+
+// IDs limits the query to objects with given IDs.
+func (q *TypedQuery[T]) IDs(ids ...quad.Value) *TypedQuery[T] {
+	q.ids = append(q.ids, ids...)
+	return q
+}
+
+// Limit sets the maximal number of objects to load. Zero means no limit.
+func (q *TypedQuery[T]) Limit(n int) *TypedQuery[T] {
+	q.limit = int64(n)
+	return q
+}
+
+// Depth limits the depth of nested objects to load. Negative value means unlimited depth,
+// and zero means top level only. See LoadToDepth.
+//
+// Ref fields are never loaded eagerly and are not affected by this setting.
+func (q *TypedQuery[T]) Depth(depth int) *TypedQuery[T] {
+	q.depth = depth
+	return q
+}
+
+func fieldPredicate(rules fieldRules, field string) (saveRule, error) {
+	r, ok := rules[field].(saveRule)
+	if !ok {
+		return saveRule{}, fmt.Errorf("schema: no field %q with a predicate", field)
+	}
+	return r, nil
+}
+
+// Where requires a field to have at least one of the given values. Field is a name of the Go struct field
+// as used in the rules, for example "Name" or "Embedded.Name" for fields of embedded structs.
+func (q *TypedQuery[T]) Where(field string, values ...quad.Value) *TypedQuery[T] {
+	q.where = append(q.where, func(p *path.Path, rules fieldRules) (*path.Path, error) {
+		r, err := fieldPredicate(rules, field)
+		if err != nil {
+			return nil, err
+		}
+		vals := make([]quad.Value, 0, len(values))
+		for _, v := range values {
+			if iri, ok := v.(quad.IRI); ok {
+				v = q.c.iri(iri)
+			}
+			vals = append(vals, v)
+		}
+		if r.Rev {
+			return p.HasReverse(r.Pred, vals...), nil
+		}
+		return p.Has(r.Pred, vals...), nil
+	})
+	return q
+}
+
+// WhereFilter requires a field to have at least one value that passes all the filters.
+// See Where for the format of field names.
+func (q *TypedQuery[T]) WhereFilter(field string, filters ...shape.ValueFilter) *TypedQuery[T] {
+	q.where = append(q.where, func(p *path.Path, rules fieldRules) (*path.Path, error) {
+		r, err := fieldPredicate(rules, field)
+		if err != nil {
+			return nil, err
+		}
+		return p.HasFilter(r.Pred, r.Rev, filters...), nil
+	})
+	return q
+}
+
+func (q *TypedQuery[T]) structType() (reflect.Type, error) {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	if rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema: expected struct type, got %v", rt)
+	}
+	return rt, nil
+}
+
+// Path returns a path for the nodes that satisfy the query constraints.
+func (q *TypedQuery[T]) Path() (*path.Path, error) {
+	rt, err := q.structType()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := q.c.rulesFor(rt)
+	if err != nil {
+		return nil, err
+	}
+	l := q.c.newLoader(q.qs)
+	// only constraints are needed to select nodes, values are loaded separately
+	tp, err := l.makePathForType(rt, "", true)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]quad.Value, 0, len(q.ids))
+	for _, id := range q.ids {
+		if iri, ok := id.(quad.IRI); ok {
+			id = q.c.iri(iri)
+		}
+		ids = append(ids, id)
+	}
+	p := path.StartPath(q.qs, ids...).Follow(tp)
+	for _, fnc := range q.where {
+		if p, err = fnc(p, rules); err != nil {
+			return nil, err
+		}
+	}
+	if q.limit > 0 {
+		p = p.Limit(q.limit)
+	}
+	return p, nil
+}
+
+// All loads all objects that satisfy the query constraints.
+func (q *TypedQuery[T]) All(ctx context.Context) ([]T, error) {
+	p, err := q.Path()
+	if err != nil {
+		return nil, err
+	}
+	var out []T
+	err = q.c.LoadIteratorToDepth(ctx, q.qs, reflect.ValueOf(&out), q.depth, p.BuildIterator(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// First loads the first object that satisfies the query constraints.
+// It returns an error for which IsNotFound is true if there are no such objects.
+func (q *TypedQuery[T]) First(ctx context.Context) (T, error) {
+	var zero T
+	p, err := q.Path()
+	if err != nil {
+		return zero, err
+	}
+	var out T
+	err = q.c.LoadIteratorToDepth(ctx, q.qs, reflect.ValueOf(&out), q.depth, p.BuildIterator(ctx))
+	if err != nil {
+		return zero, err
+	}
+	return out, nil
+}
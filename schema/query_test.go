@@ -0,0 +1,149 @@
+package schema_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph/iterator"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/query/shape"
+	"github.com/cayleygraph/cayley/schema"
+)
+
+type lazyPerson struct {
+	ID      quad.IRI                 `quad:"@id"`
+	Name    string                   `quad:"name"`
+	Age     int                      `quad:"age,optional"`
+	Friends []schema.Ref[lazyPerson] `quad:"friend"`
+	Boss    schema.Ref[lazyPerson]   `quad:"boss,optional"`
+}
+
+var peopleQuads = []quad.Quad{
+	quad.Make(iri("alice"), iri("name"), quad.String("Alice"), nil),
+	quad.Make(iri("alice"), iri("age"), quad.Int(30), nil),
+	quad.Make(iri("alice"), iri("friend"), iri("bob"), nil),
+	quad.Make(iri("alice"), iri("friend"), iri("carol"), nil),
+	quad.Make(iri("bob"), iri("name"), quad.String("Bob"), nil),
+	quad.Make(iri("bob"), iri("age"), quad.Int(25), nil),
+	quad.Make(iri("bob"), iri("boss"), iri("alice"), nil),
+	quad.Make(iri("carol"), iri("name"), quad.String("Carol"), nil),
+	quad.Make(iri("dave"), iri("age"), quad.Int(40), nil),
+}
+
+func names(list []lazyPerson) []string {
+	var out []string
+	for _, p := range list {
+		out = append(out, p.Name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestTypedQuery(t *testing.T) {
+	ctx := context.TODO()
+	qs := memstore.New(peopleQuads...)
+
+	all, err := schema.Load[lazyPerson](ctx, qs)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice", "Bob", "Carol"}, names(all))
+
+	list, err := schema.Load[lazyPerson](ctx, qs, iri("bob"), iri("dave"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Bob"}, names(list))
+
+	list, err = schema.Query[lazyPerson](qs).Where("Name", quad.String("Carol"), quad.String("Alice")).All(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice", "Carol"}, names(list))
+
+	list, err = schema.Query[lazyPerson](qs).
+		WhereFilter("Age", shape.Comparison{Op: iterator.CompareGT, Val: quad.Int(26)}).All(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Alice"}, names(list))
+
+	list, err = schema.Query[lazyPerson](qs).Limit(2).All(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	p, err := schema.Query[lazyPerson](qs).Where("Boss", iri("alice")).First(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Bob", p.Name)
+
+	_, err = schema.Query[lazyPerson](qs).Where("Name", quad.String("Eve")).First(ctx)
+	require.True(t, schema.IsNotFound(err), "%v", err)
+
+	_, err = schema.Query[lazyPerson](qs).Where("Unknown", quad.String("Eve")).All(ctx)
+	require.Error(t, err)
+
+	_, err = schema.Load[*lazyPerson](ctx, qs)
+	require.Error(t, err)
+}
+
+type optPerson struct {
+	ID   quad.IRI `quad:"@id"`
+	Name string   `quad:"name,optional"`
+	Age  int      `quad:"age,optional"`
+}
+
+func TestTypedQueryOptional(t *testing.T) {
+	ctx := context.TODO()
+	qs := memstore.New(peopleQuads...)
+
+	// nodes must have at least one of the optional fields
+	all, err := schema.Load[optPerson](ctx, qs)
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+
+	list, err := schema.Query[optPerson](qs).
+		WhereFilter("Age", shape.Comparison{Op: iterator.CompareGT, Val: quad.Int(26)}).All(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []optPerson{
+		{ID: "alice", Name: "Alice", Age: 30},
+		{ID: "dave", Age: 40},
+	}, list)
+
+	list, err = schema.Query[optPerson](qs).Where("Name", quad.String("Carol")).All(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []optPerson{{ID: "carol", Name: "Carol"}}, list)
+}
+
+func TestLazyRef(t *testing.T) {
+	ctx := context.TODO()
+	qs := memstore.New(peopleQuads...)
+
+	bob, err := schema.Query[lazyPerson](qs).IDs(iri("bob")).First(ctx)
+	require.NoError(t, err)
+	require.Empty(t, bob.Friends)
+	require.Equal(t, iri("alice"), bob.Boss.ID())
+	require.False(t, bob.Boss.Loaded())
+
+	boss, err := bob.Boss.Get(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Alice", boss.Name)
+	require.True(t, bob.Boss.Loaded())
+	require.Len(t, boss.Friends, 2)
+	for _, f := range boss.Friends {
+		require.False(t, f.Loaded())
+	}
+	friend, err := boss.Friends[0].Get(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, friend.Name)
+
+	_, err = schema.NewRef[lazyPerson](iri("bob")).Get(ctx)
+	require.Error(t, err)
+
+	// references are written as links
+	var out quadSlice
+	_, err = schema.NewConfig().WriteAsQuads(&out, lazyPerson{
+		ID: "eve", Name: "Eve",
+		Friends: []schema.Ref[lazyPerson]{schema.NewRef[lazyPerson](iri("bob"))},
+	})
+	require.NoError(t, err)
+	require.Equal(t, quadSlice{
+		quad.Make(iri("eve"), iri("name"), quad.String("Eve"), nil),
+		quad.Make(iri("eve"), iri("friend"), iri("bob"), nil),
+	}, out)
+}
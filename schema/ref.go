@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// lazyRef is implemented by pointers to Ref values. Loader binds such fields to a quad store
+// instead of loading the referenced object.
+type lazyRef interface {
+	bind(c *Config, qs graph.QuadStore, id quad.Value)
+}
+
+// refValue is implemented by Ref values. Writer stores only the ID of the referenced object.
+type refValue interface {
+	refID() quad.Value
+}
+
+var (
+	reflLazyRef  = reflect.TypeOf((*lazyRef)(nil)).Elem()
+	reflRefValue = reflect.TypeOf((*refValue)(nil)).Elem()
+)
+
+func isLazyRef(rt reflect.Type) bool {
+	return rt.Kind() == reflect.Struct && reflect.PointerTo(rt).Implements(reflLazyRef)
+}
+
+// Ref is a reference to an object of type T that is loaded on first access.
+//
+// Fields of this type are not loaded together with the parent object, only the ID
+// of the referenced object is saved. The object is loaded by the Get method:
+//
+//	type Person struct{
+//		ID      quad.IRI       `quad:"@id"`
+//		Name    string         `quad:"name"`
+//		Friends []Ref[Person]  `quad:"friend"`
+//	}
+//
+// When written with WriteAsQuads, only a link to the referenced object is written.
+type Ref[T any] struct {
+	id quad.Value
+	st *refState[T]
+}
+
+type refState[T any] struct {
+	c  *Config
+	qs graph.QuadStore
+
+	mu     sync.Mutex
+	loaded bool
+	val    T
+}
+
+// NewRef creates a reference to an object with a given ID.
+//
+// The reference is not bound to any quad store, thus Get will fail. It can be used to write links to objects.
+func NewRef[T any](id quad.Value) Ref[T] {
+	return Ref[T]{id: id}
+}
+
+// ID returns an ID of the referenced object.
+func (r Ref[T]) ID() quad.Value {
+	return r.id
+}
+
+// Loaded checks if the referenced object was already loaded.
+func (r Ref[T]) Loaded() bool {
+	if r.st == nil {
+		return false
+	}
+	r.st.mu.Lock()
+	defer r.st.mu.Unlock()
+	return r.st.loaded
+}
+
+// Get loads the referenced object, or returns a cached value if it was already loaded.
+//
+// All copies of the reference share the same cached value. Failed loads are not cached.
+func (r Ref[T]) Get(ctx context.Context) (T, error) {
+	if r.st == nil {
+		var zero T
+		return zero, errors.New("schema: reference is not bound to a quad store")
+	}
+	r.st.mu.Lock()
+	defer r.st.mu.Unlock()
+	if r.st.loaded {
+		return r.st.val, nil
+	}
+	var v T
+	if err := r.st.c.LoadTo(ctx, r.st.qs, &v, r.id); err != nil {
+		return v, err
+	}
+	r.st.val, r.st.loaded = v, true
+	return v, nil
+}
+
+func (r *Ref[T]) bind(c *Config, qs graph.QuadStore, id quad.Value) {
+	r.id = id
+	r.st = &refState[T]{c: c, qs: qs}
+}
+
+func (r Ref[T]) refID() quad.Value {
+	return r.id
+}
@@ -92,9 +92,10 @@ type constraintRule struct {
 func (constraintRule) isRule() {}
 
 type saveRule struct {
-	Pred quad.IRI
-	Rev  bool
-	Opt  bool
+	Pred    quad.IRI
+	Rev     bool
+	Opt     bool
+	Cascade bool
 }
 
 func (saveRule) isRule() {}
@@ -152,6 +153,7 @@ func (c *Config) fieldRule(fld reflect.StructField) (rule, error) {
 	}
 	opt := false
 	req := false
+	cascade := false
 	for _, s := range sub {
 		if s == "opt" || s == "optional" {
 			opt = true
@@ -159,6 +161,9 @@ func (c *Config) fieldRule(fld reflect.StructField) (rule, error) {
 		if s == "req" || s == "required" {
 			req = true
 		}
+		if s == "cascade" {
+			cascade = true
+		}
 	}
 	if req {
 		opt = false
@@ -195,7 +200,7 @@ func (c *Config) fieldRule(fld reflect.StructField) (rule, error) {
 	}
 	p := c.toIRI(ps)
 	if vs == "" || vs == any && fld.Type != reflEmptyStruct {
-		return saveRule{Pred: p, Rev: rev, Opt: opt}, nil
+		return saveRule{Pred: p, Rev: rev, Opt: opt, Cascade: cascade}, nil
 	}
 	return constraintRule{Pred: p, Val: c.toIRI(vs), Rev: rev}, nil
 }
@@ -261,6 +266,72 @@ func RegisterType(iri quad.IRI, obj interface{}) {
 	iriToType[full] = rt
 }
 
+// RegisteredTypes returns all Go types registered with RegisterType, indexed by their IRIs.
+func RegisteredTypes() map[quad.IRI]reflect.Type {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	out := make(map[quad.IRI]reflect.Type, len(typeToIRI))
+	for rt, iri := range typeToIRI {
+		out[iri] = rt
+	}
+	return out
+}
+
+// FieldInfo describes how a field of a Go struct is mapped to quads.
+type FieldInfo struct {
+	// Name is the name of the field. Names of fields of embedded structs are prefixed
+	// with the name of the embedded field and a dot.
+	Name string
+	// Type is the Go type of the field.
+	Type reflect.Type
+	// Predicate is the IRI of the predicate the field is mapped to.
+	Predicate quad.IRI
+	// Reverse is set if the object is stored as the object of the quad, instead of the subject.
+	Reverse bool
+	// Optional is set if the field is allowed to have no values.
+	Optional bool
+}
+
+// FieldsOf returns a list of struct fields that are mapped to predicates.
+// ID fields and type constraints are not included.
+func (c *Config) FieldsOf(rt reflect.Type) ([]FieldInfo, error) {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct, got %v", rt)
+	}
+	rules, err := c.rulesFor(rt)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		out  []FieldInfo
+		walk func(rt reflect.Type, pref string)
+	)
+	walk = func(rt reflect.Type, pref string) {
+		for i := 0; i < rt.NumField(); i++ {
+			f := rt.Field(i)
+			if f.Anonymous {
+				if ft, ok := anonFieldType(f); ok {
+					walk(ft, pref+f.Name+".")
+				}
+				continue
+			}
+			r, ok := rules[pref+f.Name].(saveRule)
+			if !ok {
+				continue
+			}
+			out = append(out, FieldInfo{
+				Name: pref + f.Name, Type: f.Type,
+				Predicate: r.Pred, Reverse: r.Rev, Optional: r.Opt,
+			})
+		}
+	}
+	walk(rt, "")
+	return out, nil
+}
+
 // PathForType builds a path (morphism) for a given Go type.
 func (c *Config) PathForType(rt reflect.Type) (*path.Path, error) {
 	l := c.newLoader(nil)
@@ -0,0 +1,149 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// quadList collects quads written to it.
+type quadList []quad.Quad
+
+func (l *quadList) WriteQuad(q quad.Quad) error {
+	*l = append(*l, q)
+	return nil
+}
+
+func (l *quadList) WriteQuads(buf []quad.Quad) (int, error) {
+	*l = append(*l, buf...)
+	return len(buf), nil
+}
+
+// objectQuads contains all quads of an object as written by WriteAsQuads.
+type objectQuads struct {
+	all []quad.Quad
+	// owned is a subset of quads that are owned by the object: quads of the object itself,
+	// and quads of nested objects stored in fields with a "cascade" tag.
+	owned []quad.Quad
+	// nested maps the rest of quads to IDs of nested objects they belong to.
+	nested map[quad.Quad]quad.Value
+	// objects is a set of IDs of nested objects that are not owned by the object.
+	objects map[quad.Value]struct{}
+}
+
+func (c *Config) objectQuads(o interface{}) (*objectQuads, error) {
+	var out quadList
+	w := c.newWriter(&out)
+	w.track, w.own, w.noGen = true, true, true
+	w.nested, w.objects = make(map[quad.Quad]quad.Value), make(map[quad.Value]struct{})
+	if _, err := w.writeAsQuads(reflect.ValueOf(o)); err != nil {
+		return nil, err
+	}
+	return &objectQuads{all: out, owned: w.owned, nested: w.nested, objects: w.objects}, nil
+}
+
+// DiffObjects compares two versions of the same object and returns a transaction that
+// converts the old version to the new one. Old value can be nil, in which case all quads
+// of the new value are added.
+//
+// Quads of nested objects are only removed if they are stored in a field with a "cascade" tag:
+//
+//	type Order struct{
+//		ID       quad.IRI    `quad:"@id"`
+//		Customer *Customer   `quad:"customer"`       // only a link is removed
+//		Items    []OrderItem `quad:"items,cascade"`  // a link and all quads of an item are removed
+//	}
+//
+// Other nested objects are compared as well if they are referenced by both versions,
+// thus changes of their fields are written.
+//
+// Both values and all nested objects must have an ID field, since random IDs cannot be compared.
+// A GenerateID function can be set to produce stable IDs for objects without an ID field.
+func (c *Config) DiffObjects(old, new interface{}) (*graph.Transaction, error) {
+	if new == nil {
+		return nil, errors.New("schema: nil object")
+	}
+	next, err := c.objectQuads(new)
+	if err != nil {
+		return nil, err
+	}
+	prev := &objectQuads{}
+	if old != nil {
+		prev, err = c.objectQuads(old)
+		if err != nil {
+			return nil, err
+		}
+	}
+	inNext := make(map[quad.Quad]struct{}, len(next.all))
+	for _, q := range next.all {
+		inNext[q] = struct{}{}
+	}
+	inPrev := make(map[quad.Quad]struct{}, len(prev.all))
+	for _, q := range prev.all {
+		inPrev[q] = struct{}{}
+	}
+	tx := graph.NewTransaction()
+	remove := func(q quad.Quad) {
+		if _, ok := inNext[q]; !ok {
+			tx.RemoveQuad(q)
+		}
+	}
+	for _, q := range prev.owned {
+		remove(q)
+	}
+	for _, q := range prev.all {
+		id, ok := prev.nested[q]
+		if !ok {
+			continue
+		}
+		if _, ok = next.objects[id]; ok {
+			remove(q)
+		}
+	}
+	for _, q := range next.all {
+		if _, ok := inPrev[q]; !ok {
+			tx.AddQuad(q)
+		}
+	}
+	return tx, nil
+}
+
+// UpdateObject writes changes between two versions of the same object to the quad writer
+// in a single transaction. See DiffObjects for details.
+func (c *Config) UpdateObject(ctx context.Context, qw graph.QuadWriter, old, new interface{}) error {
+	tx, err := c.DiffObjects(old, new)
+	if err != nil {
+		return err
+	} else if len(tx.Deltas) == 0 {
+		return nil
+	} else if err = ctx.Err(); err != nil {
+		return err
+	}
+	return qw.ApplyTransaction(tx)
+}
+
+// DeleteObject removes all quads of an object in a single transaction.
+//
+// Nested objects stored in fields with a "cascade" tag are removed as well, while for other fields
+// only links to nested objects are removed. Quads that are not mapped to any field are not removed.
+// See DiffObjects for requirements on object IDs.
+func (c *Config) DeleteObject(ctx context.Context, qw graph.QuadWriter, o interface{}) error {
+	if o == nil {
+		return errors.New("schema: nil object")
+	}
+	oq, err := c.objectQuads(o)
+	if err != nil {
+		return err
+	}
+	tx := graph.NewTransactionN(len(oq.owned))
+	for _, q := range oq.owned {
+		tx.RemoveQuad(q)
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	return qw.ApplyTransaction(tx)
+}
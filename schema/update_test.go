@@ -0,0 +1,161 @@
+package schema_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/cayleygraph/quad"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/schema"
+	_ "github.com/cayleygraph/cayley/writer"
+)
+
+type orderItem struct {
+	ID    quad.IRI `quad:"@id"`
+	Name  string   `quad:"name"`
+	Count int      `quad:"count"`
+}
+
+type order struct {
+	ID       quad.IRI    `quad:"@id"`
+	Customer *item       `quad:"customer"`
+	Items    []orderItem `quad:"items,cascade"`
+	Tags     []string    `quad:"tags"`
+}
+
+func sortedQuads(t testing.TB, qs graph.QuadStore) []string {
+	r := graph.NewQuadStoreReader(qs)
+	defer r.Close()
+	quads, err := quad.ReadAll(r)
+	require.NoError(t, err)
+	var out []string
+	for _, q := range quads {
+		out = append(out, q.NQuad())
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestUpdateObject(t *testing.T) {
+	ctx := context.TODO()
+	sch := schema.NewConfig()
+	qs := memstore.New()
+	qw, err := graph.NewQuadWriter("single", qs, nil)
+	require.NoError(t, err)
+
+	old := order{
+		ID:       "o1",
+		Customer: &item{ID: "bob", Name: "Bob"},
+		Items: []orderItem{
+			{ID: "i1", Name: "Apple", Count: 1},
+			{ID: "i2", Name: "Pear", Count: 2},
+		},
+		Tags: []string{"a", "b"},
+	}
+	require.NoError(t, sch.UpdateObject(ctx, qw, nil, old))
+	require.Len(t, sortedQuads(t, qs), 11)
+
+	upd := old
+	upd.Customer = &item{ID: "alice", Name: "Alice"}
+	upd.Items = []orderItem{
+		{ID: "i1", Name: "Apple", Count: 3},
+	}
+	upd.Tags = []string{"b", "c"}
+
+	tx, err := sch.DiffObjects(old, upd)
+	require.NoError(t, err)
+	var added, removed []string
+	for _, d := range tx.Deltas {
+		if d.Action == graph.Add {
+			added = append(added, d.Quad.NQuad())
+		} else {
+			removed = append(removed, d.Quad.NQuad())
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	require.Equal(t, []string{
+		`<alice> <name> "Alice" .`,
+		`<alice> <rdf:type> <some:item> .`,
+		`<i1> <count> "3"^^<xsd:integer> .`,
+		`<o1> <customer> <alice> .`,
+		`<o1> <tags> "c" .`,
+	}, added)
+	// quads of bob are kept, since customer field has no cascade tag
+	require.Equal(t, []string{
+		`<i1> <count> "1"^^<xsd:integer> .`,
+		`<i2> <count> "2"^^<xsd:integer> .`,
+		`<i2> <name> "Pear" .`,
+		`<o1> <customer> <bob> .`,
+		`<o1> <items> <i2> .`,
+		`<o1> <tags> "a" .`,
+	}, removed)
+
+	require.NoError(t, sch.UpdateObject(ctx, qw, old, upd))
+	require.NoError(t, sch.UpdateObject(ctx, qw, upd, upd))
+
+	var got order
+	require.NoError(t, sch.LoadTo(ctx, qs, &got, quad.IRI("o1")))
+	sort.Strings(got.Tags)
+	require.Equal(t, upd, got)
+
+	require.NoError(t, sch.DeleteObject(ctx, qw, upd))
+	require.Equal(t, []string{
+		`<alice> <name> "Alice" .`,
+		`<alice> <rdf:type> <some:item> .`,
+		`<bob> <name> "Bob" .`,
+		`<bob> <rdf:type> <some:item> .`,
+	}, sortedQuads(t, qs))
+}
+
+func TestUpdateObjectNoID(t *testing.T) {
+	sch := schema.NewConfig()
+	_, err := sch.DiffObjects(nil, item2{Name: "a", Spec: "b"})
+	require.Error(t, err)
+
+	sch.GenerateID = func(o interface{}) quad.Value {
+		return quad.BNode(o.(item2).Name)
+	}
+	tx, err := sch.DiffObjects(item2{Name: "a", Spec: "b"}, item2{Name: "a", Spec: "c"})
+	require.NoError(t, err)
+	require.Equal(t, []graph.Delta{
+		{Quad: quad.Make(quad.BNode("a"), iri("spec"), quad.String("b"), nil), Action: graph.Delete},
+		{Quad: quad.Make(quad.BNode("a"), iri("spec"), quad.String("c"), nil), Action: graph.Add},
+	}, tx.Deltas)
+}
+
+func TestUpdateObjectNested(t *testing.T) {
+	ctx := context.TODO()
+	sch := schema.NewConfig()
+	qs := memstore.New()
+	qw, err := graph.NewQuadWriter("single", qs, nil)
+	require.NoError(t, err)
+
+	old := order{ID: "o1", Customer: &item{ID: "bob", Name: "Bob"}}
+	require.NoError(t, sch.UpdateObject(ctx, qw, nil, old))
+
+	// the customer is not owned by the order, but it's referenced by both versions,
+	// thus changes of its fields are written
+	upd := old
+	upd.Customer = &item{ID: "bob", Name: "Robert"}
+	tx, err := sch.DiffObjects(old, upd)
+	require.NoError(t, err)
+	require.Equal(t, []graph.Delta{
+		{Quad: quad.Make(quad.IRI("bob"), iri("name"), quad.String("Bob"), nil), Action: graph.Delete},
+		{Quad: quad.Make(quad.IRI("bob"), iri("name"), quad.String("Robert"), nil), Action: graph.Add},
+	}, tx.Deltas)
+	require.NoError(t, sch.UpdateObject(ctx, qw, old, upd))
+	require.Equal(t, []string{
+		`<bob> <name> "Robert" .`,
+		`<bob> <rdf:type> <some:item> .`,
+		`<o1> <customer> <bob> .`,
+	}, sortedQuads(t, qs))
+
+	var got order
+	require.NoError(t, sch.LoadTo(ctx, qs, &got, quad.IRI("o1")))
+	require.Equal(t, upd, got)
+}
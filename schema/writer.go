@@ -23,6 +23,21 @@ type writer struct {
 	c    *Config
 	w    quad.Writer
 	seen map[uintptr]quad.Value
+
+	// track enables collection of quads owned by the root object (see ownedQuads).
+	track bool
+	// own is set when quads being written belong to the root object or to objects
+	// reachable from it only through "cascade" fields.
+	own   bool
+	owned []quad.Quad
+	// obj is an ID of the object which fields are being written.
+	obj quad.Value
+	// nested maps quads that are not owned by the root object to IDs of objects they belong to.
+	nested map[quad.Quad]quad.Value
+	// objects is a set of IDs of nested objects that are not owned by the root object.
+	objects map[quad.Value]struct{}
+	// noGen disallows generation of random IDs for objects without an ID field.
+	noGen bool
 }
 
 func (c *Config) newWriter(w quad.Writer) *writer {
@@ -33,16 +48,27 @@ func (w *writer) writeQuad(s, p, o quad.Value, rev bool) error {
 	if rev {
 		s, o = o, s
 	}
-	return w.w.WriteQuad(quad.Quad{Subject: s, Predicate: p, Object: o, Label: w.c.Label})
+	q := quad.Quad{Subject: s, Predicate: p, Object: o, Label: w.c.Label}
+	if w.track && w.own {
+		w.owned = append(w.owned, q)
+	} else if w.track {
+		w.nested[q] = w.obj
+	}
+	return w.w.WriteQuad(q)
 }
 
 // writeOneValReflect writes a set of quads corresponding to a value. It may omit writing quads if value is zero.
-func (w *writer) writeOneValReflect(id quad.Value, pred quad.Value, rv reflect.Value, rev bool) error {
+//
+// Quads of the nested object are owned by the parent only if cascade is set.
+func (w *writer) writeOneValReflect(id quad.Value, pred quad.Value, rv reflect.Value, rev, cascade bool) error {
 	if isZero(rv) {
 		return nil
 	}
 	// write field value and get an ID
+	own := w.own
+	w.own = own && cascade
 	sid, err := w.writeAsQuads(rv)
+	w.own = own
 	if err != nil {
 		return err
 	}
@@ -95,7 +121,7 @@ func (w *writer) writeValueAs(id quad.Value, rv reflect.Value, pref string, rule
 			if f.Type.Kind() == reflect.Slice {
 				sl := rv.Field(i)
 				for j := 0; j < sl.Len(); j++ {
-					if err := w.writeOneValReflect(id, r.Pred, sl.Index(j), r.Rev); err != nil {
+					if err := w.writeOneValReflect(id, r.Pred, sl.Index(j), r.Rev, r.Cascade); err != nil {
 						return err
 					}
 				}
@@ -104,7 +130,7 @@ func (w *writer) writeValueAs(id quad.Value, rv reflect.Value, pref string, rule
 				if !r.Opt && isZero(fv) {
 					return ErrReqFieldNotSet{Field: f.Name}
 				}
-				if err := w.writeOneValReflect(id, r.Pred, fv, r.Rev); err != nil {
+				if err := w.writeOneValReflect(id, r.Pred, fv, r.Rev, r.Cascade); err != nil {
 					return err
 				}
 			}
@@ -119,6 +145,14 @@ func (w *writer) writeAsQuads(rv reflect.Value) (quad.Value, error) {
 	if rt.Implements(reflQuadValue) {
 		return rv.Interface().(quad.Value), nil
 	}
+	// lazy references are written as links
+	if rt.Implements(reflRefValue) {
+		id := rv.Interface().(refValue).refID()
+		if id == nil {
+			return nil, fmt.Errorf("reference without an ID: %v", rt)
+		}
+		return id, nil
+	}
 	prv := rv
 	kind := rt.Kind()
 	// check if we've seen this node already
@@ -168,6 +202,9 @@ func (w *writer) writeAsQuads(rv reflect.Value) (quad.Value, error) {
 		return nil, err
 	}
 	if id == nil {
+		if w.noGen && w.c.GenerateID == nil {
+			return nil, fmt.Errorf("object of type %v has no ID", rt)
+		}
 		id = w.c.genID(prv.Interface())
 	}
 	// save a node ID to avoid loops
@@ -176,7 +213,14 @@ func (w *writer) writeAsQuads(rv reflect.Value) (quad.Value, error) {
 		ptr := prv.Pointer()
 		w.seen[ptr] = id
 	}
-	if err = w.writeValueAs(id, rv, "", rules); err != nil {
+	if w.track && !w.own {
+		w.objects[id] = struct{}{}
+	}
+	obj := w.obj
+	w.obj = id
+	err = w.writeValueAs(id, rv, "", rules)
+	w.obj = obj
+	if err != nil {
 		return nil, err
 	}
 	return id, nil
@@ -0,0 +1,342 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v4.25.3
+// source: cayley.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	Cayley_Write_FullMethodName  = "/cayley.v2.Cayley/Write"
+	Cayley_Delete_FullMethodName = "/cayley.v2.Cayley/Delete"
+	Cayley_Read_FullMethodName   = "/cayley.v2.Cayley/Read"
+	Cayley_Query_FullMethodName  = "/cayley.v2.Cayley/Query"
+)
+
+// CayleyClient is the client API for Cayley service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CayleyClient interface {
+	Write(ctx context.Context, opts ...grpc.CallOption) (Cayley_WriteClient, error)
+	Delete(ctx context.Context, opts ...grpc.CallOption) (Cayley_DeleteClient, error)
+	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (Cayley_ReadClient, error)
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (Cayley_QueryClient, error)
+}
+
+type cayleyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCayleyClient(cc grpc.ClientConnInterface) CayleyClient {
+	return &cayleyClient{cc}
+}
+
+func (c *cayleyClient) Write(ctx context.Context, opts ...grpc.CallOption) (Cayley_WriteClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Cayley_ServiceDesc.Streams[0], Cayley_Write_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cayleyWriteClient{ClientStream: stream}
+	return x, nil
+}
+
+type Cayley_WriteClient interface {
+	Send(*WriteRequest) error
+	Recv() (*WriteResponse, error)
+	grpc.ClientStream
+}
+
+type cayleyWriteClient struct {
+	grpc.ClientStream
+}
+
+func (x *cayleyWriteClient) Send(m *WriteRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *cayleyWriteClient) Recv() (*WriteResponse, error) {
+	m := new(WriteResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *cayleyClient) Delete(ctx context.Context, opts ...grpc.CallOption) (Cayley_DeleteClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Cayley_ServiceDesc.Streams[1], Cayley_Delete_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cayleyDeleteClient{ClientStream: stream}
+	return x, nil
+}
+
+type Cayley_DeleteClient interface {
+	Send(*WriteRequest) error
+	Recv() (*WriteResponse, error)
+	grpc.ClientStream
+}
+
+type cayleyDeleteClient struct {
+	grpc.ClientStream
+}
+
+func (x *cayleyDeleteClient) Send(m *WriteRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *cayleyDeleteClient) Recv() (*WriteResponse, error) {
+	m := new(WriteResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *cayleyClient) Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (Cayley_ReadClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Cayley_ServiceDesc.Streams[2], Cayley_Read_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cayleyReadClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Cayley_ReadClient interface {
+	Recv() (*ReadResponse, error)
+	grpc.ClientStream
+}
+
+type cayleyReadClient struct {
+	grpc.ClientStream
+}
+
+func (x *cayleyReadClient) Recv() (*ReadResponse, error) {
+	m := new(ReadResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *cayleyClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (Cayley_QueryClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Cayley_ServiceDesc.Streams[3], Cayley_Query_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cayleyQueryClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Cayley_QueryClient interface {
+	Recv() (*QueryResponse, error)
+	grpc.ClientStream
+}
+
+type cayleyQueryClient struct {
+	grpc.ClientStream
+}
+
+func (x *cayleyQueryClient) Recv() (*QueryResponse, error) {
+	m := new(QueryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CayleyServer is the server API for Cayley service.
+// All implementations must embed UnimplementedCayleyServer
+// for forward compatibility
+type CayleyServer interface {
+	Write(Cayley_WriteServer) error
+	Delete(Cayley_DeleteServer) error
+	Read(*ReadRequest, Cayley_ReadServer) error
+	Query(*QueryRequest, Cayley_QueryServer) error
+	mustEmbedUnimplementedCayleyServer()
+}
+
+// UnimplementedCayleyServer must be embedded to have forward compatible implementations.
+type UnimplementedCayleyServer struct {
+}
+
+func (UnimplementedCayleyServer) Write(Cayley_WriteServer) error {
+	return status.Errorf(codes.Unimplemented, "method Write not implemented")
+}
+func (UnimplementedCayleyServer) Delete(Cayley_DeleteServer) error {
+	return status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedCayleyServer) Read(*ReadRequest, Cayley_ReadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedCayleyServer) Query(*QueryRequest, Cayley_QueryServer) error {
+	return status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedCayleyServer) mustEmbedUnimplementedCayleyServer() {}
+
+// UnsafeCayleyServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CayleyServer will
+// result in compilation errors.
+type UnsafeCayleyServer interface {
+	mustEmbedUnimplementedCayleyServer()
+}
+
+func RegisterCayleyServer(s grpc.ServiceRegistrar, srv CayleyServer) {
+	s.RegisterService(&Cayley_ServiceDesc, srv)
+}
+
+func _Cayley_Write_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CayleyServer).Write(&cayleyWriteServer{ServerStream: stream})
+}
+
+type Cayley_WriteServer interface {
+	Send(*WriteResponse) error
+	Recv() (*WriteRequest, error)
+	grpc.ServerStream
+}
+
+type cayleyWriteServer struct {
+	grpc.ServerStream
+}
+
+func (x *cayleyWriteServer) Send(m *WriteResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *cayleyWriteServer) Recv() (*WriteRequest, error) {
+	m := new(WriteRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Cayley_Delete_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CayleyServer).Delete(&cayleyDeleteServer{ServerStream: stream})
+}
+
+type Cayley_DeleteServer interface {
+	Send(*WriteResponse) error
+	Recv() (*WriteRequest, error)
+	grpc.ServerStream
+}
+
+type cayleyDeleteServer struct {
+	grpc.ServerStream
+}
+
+func (x *cayleyDeleteServer) Send(m *WriteResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *cayleyDeleteServer) Recv() (*WriteRequest, error) {
+	m := new(WriteRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Cayley_Read_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CayleyServer).Read(m, &cayleyReadServer{ServerStream: stream})
+}
+
+type Cayley_ReadServer interface {
+	Send(*ReadResponse) error
+	grpc.ServerStream
+}
+
+type cayleyReadServer struct {
+	grpc.ServerStream
+}
+
+func (x *cayleyReadServer) Send(m *ReadResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Cayley_Query_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CayleyServer).Query(m, &cayleyQueryServer{ServerStream: stream})
+}
+
+type Cayley_QueryServer interface {
+	Send(*QueryResponse) error
+	grpc.ServerStream
+}
+
+type cayleyQueryServer struct {
+	grpc.ServerStream
+}
+
+func (x *cayleyQueryServer) Send(m *QueryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Cayley_ServiceDesc is the grpc.ServiceDesc for Cayley service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Cayley_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cayley.v2.Cayley",
+	HandlerType: (*CayleyServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Write",
+			Handler:       _Cayley_Write_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Delete",
+			Handler:       _Cayley_Delete_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Read",
+			Handler:       _Cayley_Read_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Query",
+			Handler:       _Cayley_Query_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "cayley.proto",
+}
@@ -0,0 +1,5 @@
+// Package pb contains protobuf messages and gRPC service definitions of the Cayley gRPC API.
+package pb
+
+//go:generate curl -LO https://github.com/cayleygraph/quad/raw/v1.3.0/pquads/quads.proto
+//go:generate protoc --go_opt=paths=source_relative --go-grpc_opt=paths=source_relative --proto_path=. --go_out=. --go-grpc_out=. cayley.proto
@@ -0,0 +1,382 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cayleygrpc implements the gRPC API of the database, see pb.CayleyServer.
+package cayleygrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/cayleygraph/cayley/audit"
+	"github.com/cayleygraph/cayley/clog"
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/scope"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/shape"
+	"github.com/cayleygraph/cayley/schema"
+	"github.com/cayleygraph/cayley/server/grpc/pb"
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/pquads"
+
+	// Writer is imported for writers to be registered
+	_ "github.com/cayleygraph/cayley/writer"
+)
+
+const (
+	defaultLimit       = 100
+	defaultReplication = "single"
+	// readBatch is the number of quads sent in a single read response.
+	readBatch = 1000
+)
+
+var _ pb.CayleyServer = (*Server)(nil)
+
+// Server implements the gRPC API of the database.
+type Server struct {
+	pb.UnimplementedCayleyServer
+
+	h    *graph.Handle
+	auth *cayleyhttp.Auth
+	ns   *schema.NamespaceRegistry
+	ro   bool
+
+	// replication
+	wtyp string
+	wopt graph.Options
+
+	// query
+	timeout time.Duration
+	limit   int
+}
+
+// NewServer creates a new gRPC API server for a given database handle.
+func NewServer(h *graph.Handle) *Server {
+	return &Server{
+		h:     h,
+		ns:    schema.NewConfig().NewNamespaceRegistry(h),
+		wtyp:  defaultReplication,
+		limit: defaultLimit,
+	}
+}
+
+// Register registers the API on a gRPC server.
+func (s *Server) Register(gs *grpc.Server) {
+	pb.RegisterCayleyServer(gs, s)
+}
+
+// SetReadOnly disables writes.
+func (s *Server) SetReadOnly(ro bool) {
+	s.ro = ro
+}
+
+// SetAuth enables authentication of calls. Credentials are read from call metadata
+// in the same way as from HTTP headers: "authorization" or "x-api-key".
+// Client certificates are used if the server is configured with TLS credentials.
+func (s *Server) SetAuth(a *cayleyhttp.Auth) {
+	s.auth = a
+}
+
+// SetWriter sets the type and options of quad writers created for principals restricted to a subset of the graph.
+// Otherwise, the writer of the handle is used.
+func (s *Server) SetWriter(wtype string, wopts graph.Options) {
+	s.wtyp, s.wopt = wtype, wopts
+}
+
+// SetQueryTimeout sets query timeout.
+func (s *Server) SetQueryTimeout(dt time.Duration) {
+	s.timeout = dt
+}
+
+// SetQueryLimit sets the default limit of query results.
+func (s *Server) SetQueryLimit(n int) {
+	s.limit = n
+}
+
+// Namespaces returns the registry of namespace rules stored in the database.
+func (s *Server) Namespaces() *schema.NamespaceRegistry {
+	return s.ns
+}
+
+// principal authenticates the call and checks that the principal has a given permission.
+func (s *Server) principal(ctx context.Context, perm cayleyhttp.Permission) (*cayleyhttp.Principal, error) {
+	if s.auth == nil {
+		return nil, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	r := &http.Request{Header: make(http.Header)}
+	for _, k := range []string{"authorization", "x-api-key"} {
+		for _, v := range md.Get(k) {
+			r.Header.Add(k, v)
+		}
+	}
+	if pr, ok := peer.FromContext(ctx); ok {
+		// client certificates are verified by the transport
+		if ti, ok := pr.AuthInfo.(credentials.TLSInfo); ok {
+			st := ti.State
+			r.TLS = &st
+		}
+	}
+	p, err := s.auth.Authenticate(r.WithContext(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if !p.Can(perm) {
+		if p.Anonymous() {
+			return nil, status.Error(codes.Unauthenticated, "authentication required")
+		}
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return p, nil
+}
+
+// handleFor authenticates the call and returns a handle to access the part of the graph available to the principal.
+func (s *Server) handleFor(ctx context.Context, perm cayleyhttp.Permission) (*graph.Handle, error) {
+	p, err := s.principal(ctx, perm)
+	if err != nil {
+		return nil, err
+	}
+	h := s.h
+	if p != nil && p.Labels != nil {
+		qs := scope.New(h.QuadStore, p.Labels...)
+		qw, err := graph.NewQuadWriter(s.wtyp, qs, s.wopt)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		h = &graph.Handle{QuadStore: qs, QuadWriter: qw}
+	}
+	if _, ok := h.QuadWriter.(*audit.Writer); ok {
+		m := audit.Meta{Request: "grpc"}
+		if method, ok := grpc.Method(ctx); ok {
+			m.Request += " " + method
+		}
+		if p != nil {
+			m.Principal = p.Name
+		}
+		if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+			m.Remote = pr.Addr.String()
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		if ids := md.Get(audit.RequestIDHeader); len(ids) != 0 {
+			m.RequestID = ids[0]
+		}
+		h = &graph.Handle{QuadStore: h.QuadStore, QuadWriter: audit.WithMeta(h.QuadWriter, m)}
+	}
+	return h, nil
+}
+
+// writeError converts errors returned by quad writers to gRPC status.
+func writeError(err error) error {
+	switch {
+	case graph.IsQuadExist(err):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case graph.IsQuadNotExist(err):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, scope.ErrLabelNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+type writeStream interface {
+	Send(*pb.WriteResponse) error
+	Recv() (*pb.WriteRequest, error)
+	Context() context.Context
+}
+
+func (s *Server) applyStream(st writeStream, apply func(qw graph.QuadWriter, quads []quad.Quad) error) error {
+	if s.ro {
+		return status.Error(codes.FailedPrecondition, "database is read-only")
+	}
+	h, err := s.handleFor(st.Context(), cayleyhttp.PermWrite)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for {
+		req, err := st.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		quads := make([]quad.Quad, 0, len(req.Quads))
+		for _, q := range req.Quads {
+			nq := q.ToNative()
+			if !nq.IsValid() {
+				return status.Errorf(codes.InvalidArgument, "invalid quad: %v", nq)
+			}
+			quads = append(quads, nq)
+		}
+		if err = apply(h.QuadWriter, quads); err != nil {
+			return writeError(err)
+		}
+		total += int64(len(quads))
+		if err = st.Send(&pb.WriteResponse{Count: int64(len(quads)), Total: total}); err != nil {
+			return err
+		}
+	}
+}
+
+// Write implements pb.CayleyServer.
+func (s *Server) Write(st pb.Cayley_WriteServer) error {
+	return s.applyStream(st, func(qw graph.QuadWriter, quads []quad.Quad) error {
+		return qw.AddQuadSet(quads)
+	})
+}
+
+// Delete implements pb.CayleyServer.
+func (s *Server) Delete(st pb.Cayley_DeleteServer) error {
+	return s.applyStream(st, func(qw graph.QuadWriter, quads []quad.Quad) error {
+		tx := graph.NewTransactionN(len(quads))
+		for _, q := range quads {
+			tx.RemoveQuad(q)
+		}
+		return qw.ApplyTransaction(tx)
+	})
+}
+
+func toNativeValues(arr []*pquads.Value) []quad.Value {
+	if len(arr) == 0 {
+		return nil
+	}
+	out := make([]quad.Value, 0, len(arr))
+	for _, v := range arr {
+		out = append(out, v.ToNative())
+	}
+	return out
+}
+
+// Read implements pb.CayleyServer.
+func (s *Server) Read(req *pb.ReadRequest, st pb.Cayley_ReadServer) error {
+	ctx := st.Context()
+	h, err := s.handleFor(ctx, cayleyhttp.PermRead)
+	if err != nil {
+		return err
+	}
+	values := shape.FilterQuads(
+		toNativeValues(req.Subject),
+		toNativeValues(req.Predicate),
+		toNativeValues(req.Object),
+		toNativeValues(req.Label),
+	)
+	it := values.BuildIterator(h.QuadStore).Iterate()
+	qr := graph.NewResultReader(h.QuadStore, it)
+	defer qr.Close()
+
+	var (
+		n   int64
+		buf = make([]*pquads.Quad, 0, readBatch)
+	)
+	for req.Limit <= 0 || n < req.Limit {
+		q, err := qr.ReadQuad()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		buf = append(buf, pquads.MakeQuad(q))
+		n++
+		if len(buf) == readBatch {
+			if err = st.Send(&pb.ReadResponse{Quads: buf}); err != nil {
+				return err
+			}
+			buf = make([]*pquads.Quad, 0, readBatch)
+		}
+	}
+	if len(buf) != 0 {
+		return st.Send(&pb.ReadResponse{Quads: buf})
+	}
+	return nil
+}
+
+// Query implements pb.CayleyServer.
+func (s *Server) Query(req *pb.QueryRequest, st pb.Cayley_QueryServer) error {
+	ctx := st.Context()
+	if s.timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	if req.Lang == "" {
+		return status.Error(codes.InvalidArgument, "query language not specified")
+	} else if req.Query == "" {
+		return status.Error(codes.InvalidArgument, "query is empty")
+	}
+	l := query.GetLanguage(req.Lang)
+	if l == nil {
+		return status.Errorf(codes.InvalidArgument, "unknown query language: %q", req.Lang)
+	} else if l.Session == nil {
+		return status.Errorf(codes.Unimplemented, "query language %q is only supported over HTTP", req.Lang)
+	}
+	h, err := s.handleFor(ctx, cayleyhttp.PermRead)
+	if err != nil {
+		return err
+	}
+	if clog.V(1) {
+		clog.Infof("query: %s: %q", req.Lang, req.Query)
+	}
+	ns, err := s.ns.Namespaces(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	opt := query.Options{
+		Collation:  query.JSON,
+		Limit:      s.limit,
+		Namespaces: ns,
+	}
+	if req.Limit > 0 {
+		opt.Limit = int(req.Limit)
+	}
+	it, err := l.Session(h.QuadStore).Execute(ctx, req.Query, opt)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+	defer it.Close()
+	for it.Next(ctx) {
+		data, err := json.Marshal(it.Result())
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err = st.Send(&pb.QueryResponse{Result: data}); err != nil {
+			return err
+		}
+	}
+	if err = it.Err(); err != nil {
+		return queryError(ctx, err)
+	}
+	return nil
+}
+
+// queryError converts query errors to gRPC status.
+func queryError(ctx context.Context, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	} else if ctx.Err() == context.Canceled {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
@@ -0,0 +1,154 @@
+package cayleygrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/cayleygraph/cayley/client"
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	_ "github.com/cayleygraph/cayley/query/gizmo"
+	cayleygrpc "github.com/cayleygraph/cayley/server/grpc"
+	cayleyhttp "github.com/cayleygraph/cayley/server/http"
+	"github.com/cayleygraph/cayley/writer"
+	"github.com/cayleygraph/quad"
+)
+
+func newClient(t testing.TB, api *cayleygrpc.Server) *client.GRPCClient {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	api.Register(s)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return client.NewGRPCClient(conn)
+}
+
+func newHandle(t testing.TB) *graph.Handle {
+	qs := memstore.New()
+	qw, err := writer.NewSingleReplication(qs, nil)
+	require.NoError(t, err)
+	return &graph.Handle{QuadStore: qs, QuadWriter: qw}
+}
+
+var quads = []quad.Quad{
+	quad.MakeIRI("alice", "follows", "bob", ""),
+	quad.MakeIRI("bob", "follows", "charlie", ""),
+	quad.Make(quad.IRI("alice"), quad.IRI("age"), quad.Int(30), nil),
+	quad.Make(quad.IRI("bob"), quad.IRI("name"), quad.LangString{Value: "Bob", Lang: "en"}, quad.IRI("people")),
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	h := newHandle(t)
+	c := newClient(t, cayleygrpc.NewServer(h))
+	c.SetBatchSize(3)
+
+	w, err := c.QuadWriter(ctx)
+	require.NoError(t, err)
+	n, err := w.WriteQuads(quads)
+	require.NoError(t, err)
+	require.Equal(t, len(quads), n)
+	require.NoError(t, w.Close())
+
+	r, err := c.QuadReader(ctx, client.QuadFilter{})
+	require.NoError(t, err)
+	got, err := quad.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	require.ElementsMatch(t, quads, got)
+
+	r, err = c.QuadReader(ctx, client.QuadFilter{Subject: []quad.Value{quad.IRI("bob")}})
+	require.NoError(t, err)
+	got, err = quad.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []quad.Quad{quads[1], quads[3]}, got)
+
+	res, err := c.Query(ctx, "gizmo", `g.V("<alice>").Out("<follows>").All()`, 0)
+	require.NoError(t, err)
+	var out []map[string]interface{}
+	for res.Next() {
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(res.Result(), &m))
+		out = append(out, m)
+	}
+	require.NoError(t, res.Err())
+	res.Close()
+	require.Equal(t, []map[string]interface{}{{"id": "<bob>"}}, out)
+
+	res, err = c.Query(ctx, "gizmo", `g.V(`, 0)
+	require.NoError(t, err)
+	require.False(t, res.Next())
+	require.Equal(t, codes.InvalidArgument, status.Code(res.Err()))
+
+	rm, err := c.QuadRemover(ctx)
+	require.NoError(t, err)
+	require.NoError(t, rm.WriteQuad(quads[0]))
+	require.NoError(t, rm.Close())
+
+	// removing a missing quad fails
+	rm, err = c.QuadRemover(ctx)
+	require.NoError(t, err)
+	require.NoError(t, rm.WriteQuad(quads[0]))
+	require.Equal(t, codes.NotFound, status.Code(rm.Close()))
+
+	st, err := h.QuadStore.Stats(ctx, true)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(quads)-1), st.Quads.Value)
+}
+
+func TestServerAuth(t *testing.T) {
+	ctx := context.Background()
+	auth, err := cayleyhttp.NewAuth(cayleyhttp.AuthConfig{
+		APIKeys: []cayleyhttp.APIKey{
+			{Key: "reader-key", Name: "reader", Roles: []string{"read"}},
+			{Key: "writer-key", Name: "writer", Roles: []string{"write"}},
+		},
+	})
+	require.NoError(t, err)
+	api := cayleygrpc.NewServer(newHandle(t))
+	api.SetAuth(auth)
+	c := newClient(t, api)
+
+	write := func(ctx context.Context) error {
+		w, err := c.QuadWriter(ctx)
+		if err != nil {
+			return err
+		}
+		if err = w.WriteQuad(quads[0]); err != nil {
+			return err
+		}
+		return w.Close()
+	}
+	require.Equal(t, codes.Unauthenticated, status.Code(write(ctx)))
+	rctx := metadata.AppendToOutgoingContext(ctx, "x-api-key", "reader-key")
+	require.Equal(t, codes.PermissionDenied, status.Code(write(rctx)))
+	wctx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer writer-key")
+	require.NoError(t, write(wctx))
+
+	r, err := c.QuadReader(rctx, client.QuadFilter{})
+	require.NoError(t, err)
+	got, err := quad.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	require.Equal(t, quads[:1], got)
+}
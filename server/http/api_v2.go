@@ -23,6 +23,8 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,11 +33,15 @@ import (
 	"github.com/cayleygraph/cayley/clog"
 	"github.com/cayleygraph/cayley/graph"
 	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/gizmo"
 	"github.com/cayleygraph/cayley/query/shape"
+	"github.com/cayleygraph/cayley/schema"
+	"github.com/cayleygraph/cayley/validate"
 
 	// Writer is imported for writers to be registered
 	_ "github.com/cayleygraph/cayley/writer"
 	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/jsonld"
 	"github.com/cayleygraph/quad/voc"
 )
 
@@ -53,6 +59,8 @@ func NewAPIv2(h *graph.Handle, wrappers ...HandlerWrapper) *APIv2 {
 // NewBoundAPIv2 creates a new instance of APIv2 bound to a given httprouter.Router
 func NewBoundAPIv2(h *graph.Handle, r *httprouter.Router) *APIv2 {
 	api := &APIv2{h: h, wtyp: defaultReplication, wopt: nil, limit: defaultLimit, handler: r}
+	api.ns = schema.NewConfig().NewNamespaceRegistry(h)
+	api.procs = gizmo.NewModules(h)
 	api.registerOn(r)
 	return api
 }
@@ -61,6 +69,8 @@ func NewBoundAPIv2(h *graph.Handle, r *httprouter.Router) *APIv2 {
 func NewAPIv2Writer(h *graph.Handle, wtype string, wopts graph.Options, wrappers ...HandlerWrapper) *APIv2 {
 	r := httprouter.New()
 	api := &APIv2{h: h, wtyp: wtype, wopt: wopts, limit: defaultLimit}
+	api.ns = schema.NewConfig().NewNamespaceRegistry(h)
+	api.procs = gizmo.NewModules(h)
 	api.registerOn(r)
 	var handler http.Handler = r
 	for _, wrapper := range wrappers {
@@ -76,6 +86,8 @@ type APIv2 struct {
 	ro      bool
 	batch   int
 	handler http.Handler
+	ns      *schema.NamespaceRegistry
+	procs   *gizmo.Modules
 
 	// replication
 	wtyp string
@@ -91,6 +103,12 @@ func (api *APIv2) SetReadOnly(ro bool) {
 	api.ro = ro
 }
 
+// SetWriter sets the type and options of quad writers created for requests to stores that implement
+// httpgraph.QuadStore. Otherwise, the writer of the handle is used.
+func (api *APIv2) SetWriter(wtype string, wopts graph.Options) {
+	api.wtyp, api.wopt = wtype, wopts
+}
+
 // SetBatchSize sets batch-size mode for the request
 func (api *APIv2) SetBatchSize(n int) {
 	api.batch = n
@@ -106,6 +124,11 @@ func (api *APIv2) SetQueryLimit(n int) {
 	api.limit = n
 }
 
+// Namespaces returns the registry of namespace rules stored in the database.
+func (api *APIv2) Namespaces() *schema.NamespaceRegistry {
+	return api.ns
+}
+
 // ServeHTTP implements http.Handler
 func (api *APIv2) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	api.handler.ServeHTTP(w, r)
@@ -122,25 +145,53 @@ func toHandle(handler http.HandlerFunc) httprouter.Handle {
 	}
 }
 
-func (api *APIv2) registerDataOn(r *httprouter.Router) {
+// WrapHandle applies HandlerWrapper to httprouter.Handle.
+func WrapHandle(wrapper HandlerWrapper, handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		wrapper(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handle(w, r, params)
+		})).ServeHTTP(w, r)
+	}
+}
+
+// handle wraps a http.HandlerFunc to check a given permission of the request principal
+func handle(perm Permission, handler http.HandlerFunc) httprouter.Handle {
+	h := RequirePermission(perm)(handler)
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		h.ServeHTTP(w, r)
+	}
+}
+
+func (api *APIv2) registerDataOn(r Routes) {
 	if !api.ro {
-		r.POST(prefix+"/write", toHandle(api.ServeWrite))
-		r.POST(prefix+"/delete", toHandle(api.ServeDelete))
-		r.POST(prefix+"/node/delete", toHandle(api.ServeNodeDelete))
+		r.POST(prefix+"/write", handle(PermWrite, api.ServeWrite))
+		r.POST(prefix+"/delete", handle(PermWrite, api.ServeDelete))
+		r.POST(prefix+"/node/delete", handle(PermWrite, api.ServeNodeDelete))
 	}
-	r.POST(prefix+"/read", toHandle(api.ServeRead))
-	r.GET(prefix+"/read", toHandle(api.ServeRead))
-	r.GET(prefix+"/formats", toHandle(api.ServeFormats))
+	r.POST(prefix+"/read", handle(PermRead, api.ServeRead))
+	r.GET(prefix+"/read", handle(PermRead, api.ServeRead))
+	r.GET(prefix+"/formats", handle(PermRead, api.ServeFormats))
 }
 
-func (api *APIv2) registerQueryOn(r *httprouter.Router) {
-	r.POST(prefix+"/query", toHandle(api.ServeQuery))
-	r.GET(prefix+"/query", toHandle(api.ServeQuery))
+func (api *APIv2) registerQueryOn(r Routes) {
+	r.POST(prefix+"/query", handle(PermRead, api.ServeQuery))
+	r.GET(prefix+"/query", handle(PermRead, api.ServeQuery))
+}
+
+func (api *APIv2) registerNamespacesOn(r Routes) {
+	r.GET(prefix+"/namespace-rules", handle(PermRead, api.ServeNamespaceRules))
+	if !api.ro {
+		r.POST(prefix+"/namespace-rules", handle(PermAdmin, api.ServeNamespaceRules))
+		r.DELETE(prefix+"/namespace-rules/:prefix", WrapHandle(RequirePermission(PermAdmin), api.ServeDeleteNamespaceRule))
+	}
 }
 
 func (api *APIv2) registerOn(r *httprouter.Router) {
-	api.registerDataOn(r)
-	api.registerQueryOn(r)
+	rt := Routes{Router: r}
+	api.registerDataOn(rt)
+	api.registerQueryOn(rt)
+	api.registerNamespacesOn(rt)
+	api.registerProceduresOn(rt)
 }
 
 const (
@@ -221,6 +272,19 @@ func newWriteResponse(count int) writeResponse {
 	}
 }
 
+// copyQuads writes quads from the request in batches. If the quad writer validates changes,
+// all quads are written at once, thus the whole request is validated before any change is applied.
+func (api *APIv2) copyQuads(dst quad.BatchWriter, src quad.Reader, qw graph.QuadWriter) (int, error) {
+	if !validate.Validating(qw) {
+		return quad.CopyBatch(dst, src, api.batch)
+	}
+	quads, err := quad.ReadAll(src)
+	if err != nil {
+		return 0, err
+	}
+	return dst.WriteQuads(quads)
+}
+
 // ServeWrite writes data received in the request body to the database
 func (api *APIv2) ServeWrite(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
@@ -246,16 +310,14 @@ func (api *APIv2) ServeWrite(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, http.StatusBadRequest, err)
 		return
 	}
-	qw := graph.NewWriter(h.QuadWriter)
+	qw := graph.NewWriter(RequestWriter(r, h.QuadWriter))
 	defer qw.Close()
-	n, err := quad.CopyBatch(qw, qr, api.batch)
-	if err != nil {
-		jsonResponse(w, http.StatusInternalServerError, err)
-		return
+	n, err := api.copyQuads(qw, qr, h.QuadWriter)
+	if err == nil {
+		err = qw.Close()
 	}
-	err = qw.Close()
 	if err != nil {
-		jsonResponse(w, http.StatusInternalServerError, err)
+		jsonResponse(w, writeErrorStatus(err), err)
 		return
 	}
 	w.Header().Set(hdrContentType, contentTypeJSON)
@@ -290,11 +352,11 @@ func (api *APIv2) ServeDelete(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, http.StatusBadRequest, err)
 		return
 	}
-	qw := graph.NewRemover(h.QuadWriter)
+	qw := graph.NewRemover(RequestWriter(r, h.QuadWriter))
 	defer qw.Close()
-	n, err := quad.CopyBatch(qw, qr, api.batch)
+	n, err := api.copyQuads(qw, qr, h.QuadWriter)
 	if err != nil {
-		jsonResponse(w, http.StatusInternalServerError, err)
+		jsonResponse(w, writeErrorStatus(err), err)
 		return
 	}
 	w.Header().Set(hdrContentType, contentTypeJSON)
@@ -337,9 +399,9 @@ func (api *APIv2) ServeNodeDelete(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, http.StatusBadRequest, err)
 		return
 	}
-	err = h.RemoveNode(v)
+	err = RequestWriter(r, h.QuadWriter).RemoveNode(v)
 	if err != nil {
-		jsonResponse(w, http.StatusInternalServerError, err)
+		jsonResponse(w, writeErrorStatus(err), err)
 		return
 	}
 	w.Header().Set(hdrContentType, contentTypeJSON)
@@ -399,6 +461,16 @@ func (api *APIv2) ServeRead(w http.ResponseWriter, r *http.Request) {
 	qwc := format.Writer(cw)
 	defer qwc.Close()
 	var qw quad.Writer = qwc
+	if jw, ok := qwc.(*jsonld.Writer); ok {
+		ns, err := api.ns.Namespaces(r.Context())
+		if err != nil {
+			jsonResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		if ctx := query.LdContext(ns); ctx != nil {
+			jw.SetLdContext(map[string]interface{}{"@context": ctx})
+		}
+	}
 	if len(format.Mime) != 0 {
 		w.Header().Set(hdrContentType, format.Mime[0])
 	}
@@ -467,7 +539,11 @@ func (api *APIv2) queryContext(r *http.Request) (ctx context.Context, cancel fun
 
 func defaultErrorFunc(w query.ResponseWriter, err error) {
 	data, _ := json.Marshal(err.Error())
-	w.WriteHeader(http.StatusBadRequest)
+	if errors.Is(err, ErrLimited) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	} else {
+		w.WriteHeader(http.StatusBadRequest)
+	}
 	w.Write([]byte(`{"error": `))
 	w.Write(data)
 	w.Write([]byte("}\n"))
@@ -481,6 +557,20 @@ func writeResults(w io.Writer, r interface{}) {
 	})
 }
 
+// writeResultsLD is similar to writeResults, but adds a JSON-LD context, if it is set.
+func writeResultsLD(w io.Writer, r interface{}, ctx map[string]interface{}) {
+	if ctx == nil {
+		writeResults(w, r)
+		return
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(map[string]interface{}{
+		"@context": ctx,
+		"result":   r,
+	})
+}
+
 const maxQuerySize = 1024 * 1024 // 1 MB
 func readLimit(r io.Reader) ([]byte, error) {
 	lr := io.LimitReader(r, maxQuerySize).(*io.LimitedReader)
@@ -491,6 +581,20 @@ func readLimit(r io.Reader) ([]byte, error) {
 	return data, err
 }
 
+// queryHandle returns a quad store for query sessions. It includes a quad writer for languages
+// that support writes (like GraphQL mutations or Gizmo graph.addQuad), unless the database
+// is read-only, the request principal has no write permission or the request is not a POST.
+// Changes are subject to write limits of the client.
+func (api *APIv2) queryHandle(r *http.Request, h *graph.Handle) graph.QuadStore {
+	if api.ro || h.QuadWriter == nil || r.Method != http.MethodPost {
+		return h.QuadStore
+	}
+	if p, ok := PrincipalFromContext(r.Context()); ok && !p.Can(PermWrite) {
+		return h.QuadStore
+	}
+	return &graph.Handle{QuadStore: h.QuadStore, QuadWriter: LimitWriter(r, RequestWriter(r, h.QuadWriter))}
+}
+
 // ServeQuery executes a query received in the request and responds with the result
 func (api *APIv2) ServeQuery(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := api.queryContext(r)
@@ -506,6 +610,15 @@ func (api *APIv2) ServeQuery(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, http.StatusBadRequest, "unknown query language")
 		return
 	}
+	var noReasoning bool
+	if v := vals.Get("reasoning"); v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			jsonResponse(w, http.StatusBadRequest, "invalid reasoning value")
+			return
+		}
+		noReasoning = !on
+	}
 	errFunc := defaultErrorFunc
 	if l.HTTPError != nil {
 		errFunc = l.HTTPError
@@ -523,14 +636,14 @@ func (api *APIv2) ServeQuery(w http.ResponseWriter, r *http.Request) {
 	}
 	if l.HTTPQuery != nil {
 		defer r.Body.Close()
-		l.HTTPQuery(ctx, h.QuadStore, w, r.Body)
+		l.HTTPQuery(ctx, query.QuadStoreFor(api.queryHandle(r, h), query.Options{NoReasoning: noReasoning}), w, r.Body)
 		return
 	}
 	if l.Session == nil {
 		errFunc(w, errors.New("HTTP interface is not supported for this query language"))
 		return
 	}
-	ses := l.Session(h.QuadStore)
+	ses := l.Session(api.queryHandle(r, h))
 	var qu string
 	if r.Method == "GET" {
 		qu = vals.Get("qu")
@@ -550,9 +663,16 @@ func (api *APIv2) ServeQuery(w http.ResponseWriter, r *http.Request) {
 		clog.Infof("query: %s: %q", lang, qu)
 	}
 
+	ns, err := api.ns.Namespaces(ctx)
+	if err != nil {
+		errFunc(w, err)
+		return
+	}
 	opt := query.Options{
-		Collation: query.JSON, // TODO: switch to JSON-LD by default when the time comes
-		Limit:     api.limit,
+		Collation:   query.JSON, // TODO: switch to JSON-LD by default when the time comes
+		Limit:       api.limit,
+		Namespaces:  ns,
+		NoReasoning: noReasoning,
 	}
 	if specs := ParseAccept(r.Header, hdrAccept); len(specs) != 0 {
 		// TODO: sort by Q
@@ -563,7 +683,8 @@ func (api *APIv2) ServeQuery(w http.ResponseWriter, r *http.Request) {
 			opt.Collation = query.JSONLD
 		}
 	}
-	it, err := ses.Execute(ctx, qu, opt)
+	// Gizmo queries may import stored procedures as modules
+	it, err := ses.Execute(gizmo.WithModules(ctx, api.procs), qu, opt)
 	if err != nil {
 		errFunc(w, err)
 		return
@@ -580,9 +701,10 @@ func (api *APIv2) ServeQuery(w http.ResponseWriter, r *http.Request) {
 	}
 	if opt.Collation == query.JSONLD {
 		w.Header().Set(hdrContentType, contentTypeJSONLD)
-	} else {
-		w.Header().Set(hdrContentType, contentTypeJSON)
+		writeResultsLD(w, out, query.LdContext(ns))
+		return
 	}
+	w.Header().Set(hdrContentType, contentTypeJSON)
 	writeResults(w, out)
 }
 
@@ -593,48 +715,104 @@ type NamespaceRule struct {
 	Namespace string `json:"namespace"`
 }
 
-// getNamespaceRules returns all the registered rules
-func getNamespaceRules() []NamespaceRule {
-	var rules []NamespaceRule
+// getNamespaceRules returns all the registered rules, including the ones stored in the database.
+// Rules stored in the database take precedence over the built-in ones.
+func (api *APIv2) getNamespaceRules(ctx context.Context) ([]NamespaceRule, error) {
+	stored, err := api.ns.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byPrefix := make(map[string]string)
 	for _, n := range voc.List() {
+		byPrefix[n.Prefix] = n.Full
+	}
+	for _, n := range stored {
+		byPrefix[n.Prefix] = n.Full
+	}
+	rules := make([]NamespaceRule, 0, len(byPrefix))
+	for pref, full := range byPrefix {
 		rules = append(rules, NamespaceRule{
-			Prefix:    strings.TrimSuffix(n.Prefix, ":"),
-			Namespace: n.Full,
+			Prefix:    strings.TrimSuffix(pref, ":"),
+			Namespace: full,
 		})
 	}
-	return rules
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Prefix < rules[j].Prefix
+	})
+	return rules, nil
 }
 
 // serveGetNamespaceRules responds with all the registered rules encoded to JSON
-func serveGetNamespaceRules(w http.ResponseWriter) {
-	rules := getNamespaceRules()
+func (api *APIv2) serveGetNamespaceRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := api.getNamespaceRules(r.Context())
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, err)
+		return
+	}
 	encoder := json.NewEncoder(w)
 	w.Header().Set(hdrContentType, contentTypeJSON)
 	w.WriteHeader(http.StatusOK)
 	encoder.Encode(rules)
 }
 
-// serveGetNamespaceRules registers received rule encoded in JSON
-func servePostNamespaceRules(w http.ResponseWriter, r *http.Request) {
+// servePostNamespaceRules stores received rule encoded in JSON in the database
+func (api *APIv2) servePostNamespaceRules(w http.ResponseWriter, r *http.Request) {
+	if api.ro {
+		jsonResponse(w, http.StatusForbidden, errors.New("database is read-only"))
+		return
+	}
 	var rule NamespaceRule
 	decoder := json.NewDecoder(r.Body)
-	decoder.Decode(&rule)
-	voc.RegisterPrefix(rule.Prefix, rule.Namespace)
+	if err := decoder.Decode(&rule); err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	pref := strings.TrimSuffix(rule.Prefix, ":")
+	if pref == "" || strings.ContainsAny(pref, ":/ ") {
+		jsonResponse(w, http.StatusBadRequest, fmt.Errorf("invalid namespace prefix: %q", rule.Prefix))
+		return
+	} else if rule.Namespace == "" {
+		jsonResponse(w, http.StatusBadRequest, errors.New("namespace is not set"))
+		return
+	}
+	err := api.ns.Register(r.Context(), voc.Namespace{Prefix: pref + ":", Full: rule.Namespace})
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, err)
+		return
+	}
 	w.WriteHeader(http.StatusCreated)
 }
 
 // ServeNamespaceRules serves requests for the namespace rules resource.
-// The resource supports getting all registered rules and registering a rule.
-// The resource wraps the quad/voc module.
+// The resource supports getting all registered rules and storing a rule in the database.
 func (api *APIv2) ServeNamespaceRules(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		serveGetNamespaceRules(w)
+		api.serveGetNamespaceRules(w, r)
 		return
 	case http.MethodPost:
-		servePostNamespaceRules(w, r)
+		api.servePostNamespaceRules(w, r)
 		return
 	default:
 		jsonResponse(w, http.StatusMethodNotAllowed, nil)
 	}
 }
+
+// ServeDeleteNamespaceRule removes a rule with a given prefix from the database.
+// Built-in rules cannot be removed.
+func (api *APIv2) ServeDeleteNamespaceRule(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	prefix := params.ByName("prefix")
+	if api.ro {
+		jsonResponse(w, http.StatusForbidden, errors.New("database is read-only"))
+		return
+	}
+	ok, err := api.ns.Delete(r.Context(), prefix)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, err)
+		return
+	} else if !ok {
+		jsonResponse(w, http.StatusNotFound, fmt.Errorf("namespace rule is not found: %q", prefix))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,15 +2,23 @@ package cayleyhttp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/cayleygraph/cayley/graph"
 	"github.com/cayleygraph/cayley/graph/memstore"
+	_ "github.com/cayleygraph/cayley/query/gizmo"
+	_ "github.com/cayleygraph/cayley/query/graphql"
+	"github.com/cayleygraph/cayley/query/path"
+	"github.com/cayleygraph/cayley/validate"
+	"github.com/cayleygraph/cayley/validate/shacl"
 	"github.com/cayleygraph/cayley/writer"
 	"github.com/cayleygraph/quad"
 	"github.com/cayleygraph/quad/jsonld"
@@ -75,6 +83,60 @@ func TestV2Write(t *testing.T) {
 	require.Equal(t, expectedResponse, response)
 }
 
+func TestV2WriteValidation(t *testing.T) {
+	// every subject of likes must have a name
+	shapes, err := validate.LoadShapes([]quad.Quad{
+		quad.MakeIRI("http://example.com/LikerShape", shacl.NS+"targetSubjectsOf", "http://example.com/likes", ""),
+		quad.Make(quad.IRI("http://example.com/LikerShape"), quad.IRI(shacl.NS+"property"), quad.BNode("name"), nil),
+		quad.Make(quad.BNode("name"), quad.IRI(shacl.NS+"path"), quad.IRI("http://example.com/name"), nil),
+		quad.Make(quad.BNode("name"), quad.IRI(shacl.NS+"minCount"), quad.Int(1), nil),
+	})
+	require.NoError(t, err)
+	h := makeHandle(t)
+	h.QuadWriter = validate.NewWriter(h.QuadStore, h.QuadWriter, shapes)
+	api := NewAPIv2(h)
+	buf, err := newQuadsBuffer(quads)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, prefix+"/write", buf)
+	require.NoError(t, err)
+	req.Header.Set(hdrContentType, mime)
+	rr := httptest.NewRecorder()
+	api.ServeWrite(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	require.Contains(t, rr.Body.String(), "less than 1 values")
+
+	// the whole request is validated, regardless of the batch size
+	api.SetBatchSize(1)
+	nquads := quad.FormatByName("nquads")
+	write := func(quads ...quad.Quad) *httptest.ResponseRecorder {
+		buf := bytes.NewBuffer(nil)
+		qw := nquads.Writer(buf)
+		_, err := qw.WriteQuads(quads)
+		require.NoError(t, err)
+		require.NoError(t, qw.Close())
+		req := httptest.NewRequest(http.MethodPost, prefix+"/write", buf)
+		req.Header.Set(hdrContentType, nquads.Mime[0])
+		rr := httptest.NewRecorder()
+		api.ServeWrite(rr, req)
+		return rr
+	}
+	name := func(id, name string) quad.Quad {
+		return quad.Make(quad.IRI(id), quad.IRI("http://example.com/name"), quad.String(name), nil)
+	}
+	rr = write(name("http://example.com/bob", "Bob"), quads[0], quads[1])
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	st, err := h.QuadStore.Stats(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), st.Quads.Value)
+
+	rr = write(quads[0], quads[1], name("http://example.com/bob", "Bob"), name("http://example.com/alice", "Alice"))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	st, err = h.QuadStore.Stats(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), st.Quads.Value)
+}
+
 func TestV2Read(t *testing.T) {
 	api := makeServerV2(t, quads...)
 	buf := bytes.NewBuffer(nil)
@@ -161,3 +223,183 @@ func TestV2PostNamespaceRules(t *testing.T) {
 	require.Equal(t, contentTypeJSON, rr.Header().Get(hdrContentType))
 	require.Contains(t, rules, rule)
 }
+
+func TestV2NamespaceRulesPersist(t *testing.T) {
+	h := makeHandle(t, quads...)
+	api := NewAPIv2(h)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, prefix+path, strings.NewReader(body))
+		req.Header.Set(hdrAccept, contentTypeJSON)
+		rr := httptest.NewRecorder()
+		api.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodPost, "/namespace-rules", `{"prefix":`)
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	rr = do(http.MethodPost, "/namespace-rules", `{"prefix":"ex","namespace":"http://example.com/"}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+
+	// rules are stored in the database and are visible to a new instance
+	api = NewAPIv2(h)
+	rr = do(http.MethodGet, "/namespace-rules", "")
+	var rules []NamespaceRule
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rules))
+	require.Contains(t, rules, NamespaceRule{Prefix: "ex", Namespace: "http://example.com/"})
+
+	// prefixes are expanded in queries and IRIs are compacted in results
+	rr = do(http.MethodPost, "/query?lang=gizmo", `g.V("<ex:bob>").Out("<ex:likes>").All()`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.JSONEq(t, `{"result":[{"id":"<ex:alice>"}]}`, rr.Body.String())
+
+	rr = do(http.MethodDelete, "/namespace-rules/ex", "")
+	require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	rr = do(http.MethodDelete, "/namespace-rules/ex", "")
+	require.Equal(t, http.StatusNotFound, rr.Code, rr.Body.String())
+
+	rr = do(http.MethodPost, "/query?lang=gizmo", `g.V("<http://example.com/bob>").Out("<http://example.com/likes>").All()`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.JSONEq(t, `{"result":[{"id":"<http://example.com/alice>"}]}`, rr.Body.String())
+}
+
+func TestV2GraphQLMutation(t *testing.T) {
+	h := makeHandle(t, quads...)
+
+	query := func(api *APIv2, ctx context.Context, body string) string {
+		req := httptest.NewRequest(http.MethodPost, prefix+"/query?lang=graphql", strings.NewReader(body))
+		req = req.WithContext(ctx)
+		rr := httptest.NewRecorder()
+		api.ServeQuery(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		return rr.Body.String()
+	}
+	const mutation = `mutation { deleteNode(id: "http://example.com/alice") }`
+	readOnly := `{"data":null,"errors":[{"message":"database is read-only","locations":[]}]}`
+
+	api := NewAPIv2(h)
+	api.SetReadOnly(true)
+	require.JSONEq(t, readOnly, query(api, context.Background(), mutation))
+
+	// principal without a write permission
+	api = NewAPIv2(h)
+	ctx := WithPrincipal(context.Background(), &Principal{Name: "reader", Perm: PermRead})
+	require.JSONEq(t, readOnly, query(api, ctx, mutation))
+
+	require.JSONEq(t, `{"data":{"deleteNode":"http://example.com/alice"}}`, query(api, context.Background(), mutation))
+
+	st, err := h.QuadStore.Stats(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), st.Quads.Value)
+}
+
+func TestV2Procedures(t *testing.T) {
+	h := makeHandle(t, quads...)
+	api := NewAPIv2(h)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, prefix+path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		api.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodPut, "/procedures/ex/likes", `exports.likes = function(id) { return g.V(id).out("<http://example.com/likes>") }`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	require.JSONEq(t, `{"name":"ex/likes","version":1}`, rr.Body.String())
+	rr = do(http.MethodPut, "/procedures/likes", `module.exports = function(p) { require("ex/likes").likes(p.id).all() }`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	rr = do(http.MethodPut, "/procedures/broken", `module.exports = (`)
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+
+	rr = do(http.MethodGet, "/procedures", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.JSONEq(t, `[{"name":"ex/likes","version":1},{"name":"likes","version":1}]`, rr.Body.String())
+
+	rr = do(http.MethodPost, "/procedures/likes", `{"id": "<http://example.com/bob>"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.JSONEq(t, `{"result":[{"id":"<http://example.com/alice>"}]}`, rr.Body.String())
+
+	rr = do(http.MethodPut, "/procedures/likes", `module.exports = function(p) { return "v2" }`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	rr = do(http.MethodPost, "/procedures/likes", `{}`)
+	require.JSONEq(t, `{"result":["v2"]}`, rr.Body.String())
+	rr = do(http.MethodPost, "/procedures/likes?version=1", `{"id": "<http://example.com/alice>"}`)
+	require.JSONEq(t, `{"result":[{"id":"<http://example.com/bob>"}]}`, rr.Body.String())
+	rr = do(http.MethodGet, "/procedures/likes@1", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Contains(t, rr.Body.String(), `require(\"ex/likes\")`)
+
+	// modules can be imported by queries
+	rr = do(http.MethodPost, "/query?lang=gizmo", `require("ex/likes").likes("<http://example.com/alice>").all()`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.JSONEq(t, `{"result":[{"id":"<http://example.com/bob>"}]}`, rr.Body.String())
+
+	rr = do(http.MethodDelete, "/procedures/likes", "")
+	require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	rr = do(http.MethodPost, "/procedures/likes", `{}`)
+	require.Equal(t, http.StatusNotFound, rr.Code, rr.Body.String())
+
+	api.SetReadOnly(true)
+	rr = do(http.MethodPut, "/procedures/likes", `module.exports = function(p) {}`)
+	require.Equal(t, http.StatusForbidden, rr.Code, rr.Body.String())
+}
+
+func TestV2GizmoWrite(t *testing.T) {
+	h := makeHandle(t, quads...)
+	api := NewAPIv2(h)
+
+	query := func(ctx context.Context, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, prefix+"/query?lang=gizmo", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		api.ServeQuery(rr, req.WithContext(ctx))
+		return rr
+	}
+	const write = `g.addQuad("<http://example.com/bob>", "<http://example.com/name>", "Bob")`
+	names := func() []quad.Value {
+		vals, err := path.StartPath(h, quad.IRI("http://example.com/bob")).Out(quad.IRI("http://example.com/name")).
+			Iterate(context.Background()).AllValues(h)
+		require.NoError(t, err)
+		return vals
+	}
+
+	// principal without a write permission
+	ctx := WithPrincipal(context.Background(), &Principal{Name: "reader", Perm: PermRead})
+	rr := query(ctx, write)
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	require.Empty(t, names())
+
+	api.SetReadOnly(true)
+	rr = query(context.Background(), write)
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	require.Empty(t, names())
+
+	api.SetReadOnly(false)
+	// GET requests cannot change the graph
+	req := httptest.NewRequest(http.MethodGet, prefix+"/query?lang=gizmo&qu="+url.QueryEscape(write), nil)
+	rr = httptest.NewRecorder()
+	api.ServeQuery(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	require.Empty(t, names())
+
+	rr = query(context.Background(), write)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, []quad.Value{quad.String("Bob")}, names())
+
+	// changes are limited as writes
+	l, err := NewLimiter(LimitConfig{ClassLimits: ClassLimits{Write: Limit{Rate: 1}}})
+	require.NoError(t, err)
+	limited := l.Wrap(func(*http.Request) string { return "" }, http.HandlerFunc(api.ServeQuery))
+	for _, code := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req = httptest.NewRequest(http.MethodPost, prefix+"/query?lang=gizmo", strings.NewReader(`g.addQuad("<http://example.com/bob>", "<http://example.com/name>", "Robert")`))
+		rr = httptest.NewRecorder()
+		limited.ServeHTTP(rr, req)
+		require.Equal(t, code, rr.Code, rr.Body.String())
+	}
+	require.ElementsMatch(t, []quad.Value{quad.String("Bob"), quad.String("Robert")}, names())
+	// queries that don't change the graph are not limited as writes
+	req = httptest.NewRequest(http.MethodPost, prefix+"/query?lang=gizmo", strings.NewReader(`g.V().all()`))
+	rr = httptest.NewRecorder()
+	limited.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
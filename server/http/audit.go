@@ -0,0 +1,52 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cayleyhttp
+
+import (
+	"net/http"
+
+	"github.com/cayleygraph/cayley/audit"
+	"github.com/cayleygraph/cayley/graph"
+)
+
+// AuditWriter returns a writer that records the principal, the client address and the ID of the request
+// with each change, if qw is an audit writer. Otherwise, qw is returned as-is.
+func AuditWriter(r *http.Request, qw graph.QuadWriter) graph.QuadWriter {
+	if _, ok := qw.(*audit.Writer); !ok {
+		return qw
+	}
+	ctx := r.Context()
+	m, _ := audit.MetaFromContext(ctx)
+	if m.Remote == "" {
+		m.Remote = r.RemoteAddr
+	}
+	if m.RequestID == "" {
+		m.RequestID = r.Header.Get(audit.RequestIDHeader)
+	}
+	if p, ok := PrincipalFromContext(ctx); ok && p != nil {
+		m.Principal = p.Name
+	}
+	m.Request = r.Method + " " + r.URL.Path
+	if db, ok := DatabaseFromContext(ctx); ok {
+		m.Request = r.Method + " /db/" + db + r.URL.Path
+	}
+	return audit.WithMeta(qw, m)
+}
+
+// RequestWriter returns a writer for the changes made by the request. Changes are recorded
+// by AuditWriter and traced as children of the request span.
+func RequestWriter(r *http.Request, qw graph.QuadWriter) graph.QuadWriter {
+	return graph.TraceWriter(r.Context(), AuditWriter(r, qw))
+}
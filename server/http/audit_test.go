@@ -0,0 +1,66 @@
+package cayleyhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/audit"
+	"github.com/cayleygraph/quad"
+)
+
+type memSink struct {
+	mu   sync.Mutex
+	recs []*audit.Record
+}
+
+func (s *memSink) WriteRecord(r *audit.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recs = append(s.recs, r)
+	return nil
+}
+
+func (s *memSink) Close() error { return nil }
+
+func TestV2Audit(t *testing.T) {
+	h := makeHandle(t)
+	sink := &memSink{}
+	h.QuadWriter = audit.NewWriter(h.QuadStore, h.QuadWriter, sink)
+	api := NewAPIv2(h)
+
+	buf, err := newQuadsBuffer(quads)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, prefix+"/write", buf)
+	req.Header.Set(hdrContentType, mime)
+	req.Header.Set(audit.RequestIDHeader, "req-1")
+	req = req.WithContext(WithPrincipal(req.Context(), &Principal{Name: "loader", Perm: PermWrite}))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(api.ServeWrite).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, prefix+"/node/delete", strings.NewReader(`<http://example.com/bob>`))
+	req.Header.Set(hdrContentType, quad.FormatByName("nquads").Mime[0])
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(api.ServeNodeDelete).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	require.Len(t, sink.recs, 2)
+	r := sink.recs[0]
+	require.Equal(t, "loader", r.Principal)
+	require.Equal(t, "req-1", r.RequestID)
+	require.Equal(t, req.RemoteAddr, r.Remote)
+	require.Equal(t, "POST "+prefix+"/write", r.Request)
+	require.ElementsMatch(t, quads, []quad.Quad(r.Added))
+
+	r = sink.recs[1]
+	require.Equal(t, audit.OpRemoveNode, r.Op)
+	require.Equal(t, "", r.Principal)
+	require.ElementsMatch(t, quads, []quad.Quad(r.Removed))
+	n, _ := h.QuadStore.Stats(req.Context(), false)
+	require.Equal(t, int64(0), n.Quads.Value)
+}
@@ -0,0 +1,431 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cayleyhttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cayleygraph/quad"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Permission is a set of operations allowed for a principal.
+type Permission uint8
+
+const (
+	// PermRead allows reading data and running queries.
+	PermRead = Permission(1 << iota)
+	// PermWrite allows writing and deleting data.
+	PermWrite
+	// PermAdmin allows changing the server configuration, for example namespace rules.
+	PermAdmin
+)
+
+// Names of built-in roles. Each of them has a permission with the same name,
+// and all permissions of the roles listed before it.
+const (
+	RoleRead  = "read"
+	RoleWrite = "write"
+	RoleAdmin = "admin"
+)
+
+var builtinRoles = map[string]Permission{
+	RoleRead:  PermRead,
+	RoleWrite: PermRead | PermWrite,
+	RoleAdmin: PermRead | PermWrite | PermAdmin,
+}
+
+// Principal is an authenticated user or a service.
+type Principal struct {
+	Name  string
+	Roles []string
+	Perm  Permission
+	// Labels restricts the principal to quads with given labels. No restriction is applied if it's nil.
+	Labels []quad.Value
+}
+
+// Can checks if principal is allowed to perform operations with a given permission.
+func (p *Principal) Can(perm Permission) bool {
+	return p != nil && p.Perm&perm == perm
+}
+
+// Anonymous checks if principal was not authenticated.
+func (p *Principal) Anonymous() bool {
+	return p == nil || p.Name == ""
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a new context with a given principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns a principal of the request. It returns false if the authentication is disabled.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// RequirePermission returns a HandlerWrapper that rejects requests of principals without a given permission.
+//
+// All requests are allowed if the authentication is disabled, see Auth.Wrap.
+func RequirePermission(perm Permission) HandlerWrapper {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFromContext(r.Context())
+			if ok && !p.Can(perm) {
+				if p.Anonymous() {
+					if realm, ok := r.Context().Value(realmKey{}).(string); ok {
+						w.Header().Set("WWW-Authenticate", `Basic realm="`+realm+`"`)
+					}
+					jsonResponse(w, http.StatusUnauthorized, "authentication required")
+				} else {
+					jsonResponse(w, http.StatusForbidden, "permission denied")
+				}
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Authenticator checks credentials of HTTP requests.
+type Authenticator interface {
+	// Authenticate returns a name and roles of the principal that sent the request.
+	// It returns an empty name if request has no credentials supported by the authenticator,
+	// and an error if credentials are invalid.
+	Authenticate(r *http.Request) (name string, roles []string, err error)
+}
+
+// ErrInvalidCredentials is returned by authenticators if credentials are not valid.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// AuthConfig is a configuration of the authentication.
+type AuthConfig struct {
+	// APIKeys is a list of static API keys.
+	APIKeys []APIKey `mapstructure:"api_keys" json:"api_keys"`
+	// BasicFile is a path to the file with users for HTTP basic authentication.
+	// Each line contains a user name, a bcrypt hash of a password and an optional
+	// comma-separated list of roles, separated by colons.
+	BasicFile string `mapstructure:"basic_file" json:"basic_file"`
+	// JWT configures the validation of bearer tokens.
+	JWT *JWTConfig `mapstructure:"jwt" json:"jwt"`
+	// ClientCert configures authentication with client certificates, verified by the TLS config of the server.
+	ClientCert *ClientCertConfig `mapstructure:"client_cert" json:"client_cert"`
+	// Roles maps names of custom roles to the list of permissions: read, write or admin.
+	Roles map[string][]string `mapstructure:"roles" json:"roles"`
+	// Anonymous is a list of roles for requests without credentials.
+	Anonymous []string `mapstructure:"anonymous" json:"anonymous"`
+	// Labels maps principal names and role names to the list of quad labels (IRIs) available to them.
+	// Principals without an entry for their name or any of their roles can access all quads.
+	Labels map[string][]string `mapstructure:"labels" json:"labels"`
+}
+
+// Enabled checks if any authentication method is configured.
+func (c *AuthConfig) Enabled() bool {
+	return c != nil && (len(c.APIKeys) != 0 || c.BasicFile != "" || c.JWT != nil || c.ClientCert != nil)
+}
+
+// APIKey is a static key sent in X-API-Key header or as a bearer token.
+type APIKey struct {
+	Key   string   `mapstructure:"key" json:"key"`
+	Name  string   `mapstructure:"name" json:"name"`
+	Roles []string `mapstructure:"roles" json:"roles"`
+}
+
+// Auth authenticates HTTP requests and assigns permissions to principals.
+type Auth struct {
+	auth   []Authenticator
+	roles  map[string]Permission
+	labels map[string][]quad.Value
+	anon   *Principal
+	realm  string
+}
+
+// NewAuth creates an authentication from the config.
+func NewAuth(c AuthConfig) (*Auth, error) {
+	a := &Auth{roles: make(map[string]Permission)}
+	if len(c.Labels) != 0 {
+		a.labels = make(map[string][]quad.Value, len(c.Labels))
+		for name, labels := range c.Labels {
+			vals := make([]quad.Value, 0, len(labels))
+			for _, l := range labels {
+				vals = append(vals, quad.IRI(l))
+			}
+			a.labels[name] = vals
+		}
+	}
+	for name, perm := range builtinRoles {
+		a.roles[name] = perm
+	}
+	for name, perms := range c.Roles {
+		var perm Permission
+		for _, s := range perms {
+			p, ok := builtinRoles[s]
+			if !ok {
+				return nil, fmt.Errorf("unknown permission %q for role %q", s, name)
+			}
+			perm |= p
+		}
+		a.roles[name] = perm
+	}
+	if len(c.APIKeys) != 0 {
+		keys, err := NewAPIKeys(c.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		a.auth = append(a.auth, keys)
+	}
+	if c.JWT != nil {
+		v, err := NewJWTValidator(*c.JWT)
+		if err != nil {
+			return nil, err
+		}
+		a.auth = append(a.auth, v)
+	}
+	if c.BasicFile != "" {
+		b, err := LoadBasicAuth(c.BasicFile)
+		if err != nil {
+			return nil, err
+		}
+		a.auth = append(a.auth, b)
+		a.realm = "cayley"
+	}
+	if c.ClientCert != nil {
+		a.auth = append(a.auth, NewClientCerts(*c.ClientCert))
+	}
+	anon, err := a.principal("", c.Anonymous)
+	if err != nil {
+		return nil, err
+	}
+	a.anon = anon
+	return a, nil
+}
+
+// Scoped checks if any principal is restricted to a set of labels.
+func (a *Auth) Scoped() bool {
+	return a != nil && len(a.labels) != 0
+}
+
+func (a *Auth) principal(name string, roles []string) (*Principal, error) {
+	p := &Principal{Name: name, Roles: roles}
+	for _, r := range roles {
+		perm, ok := a.roles[r]
+		if !ok {
+			return nil, fmt.Errorf("unknown role: %q", r)
+		}
+		p.Perm |= perm
+	}
+	if name != "" {
+		if labels, ok := a.labels[name]; ok {
+			p.Labels = append([]quad.Value{}, labels...)
+		}
+	}
+	for _, r := range roles {
+		if labels, ok := a.labels[r]; ok {
+			p.Labels = append(p.Labels, labels...)
+			if p.Labels == nil {
+				p.Labels = []quad.Value{}
+			}
+		}
+	}
+	return p, nil
+}
+
+// Authenticate returns a principal for the request. Requests without credentials are
+// authenticated as an anonymous principal, while requests with a bearer token that is not
+// accepted by any authenticator fail with ErrInvalidCredentials.
+func (a *Auth) Authenticate(r *http.Request) (*Principal, error) {
+	for _, au := range a.auth {
+		name, roles, err := au.Authenticate(r)
+		if err != nil {
+			return nil, err
+		} else if name == "" {
+			continue
+		}
+		var known []string
+		for _, r := range roles {
+			// ignore unrelated roles from external identity providers
+			if _, ok := a.roles[r]; ok {
+				known = append(known, r)
+			}
+		}
+		return a.principal(name, known)
+	}
+	if bearerToken(r) != "" {
+		return nil, ErrInvalidCredentials
+	}
+	return a.anon, nil
+}
+
+type realmKey struct{}
+
+// Wrap is a HandlerWrapper that authenticates requests and stores the principal in the request context.
+// Permissions are checked by handlers wrapped with RequirePermission.
+func (a *Auth) Wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.Authenticate(r)
+		if err != nil {
+			if a.realm != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="`+a.realm+`"`)
+			}
+			jsonResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+		ctx := WithPrincipal(r.Context(), p)
+		if a.realm != "" {
+			ctx = context.WithValue(ctx, realmKey{}, a.realm)
+		}
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+const hdrAPIKey = "X-API-Key"
+
+func bearerToken(r *http.Request) string {
+	const pref = "bearer "
+	h := r.Header.Get("Authorization")
+	if len(h) > len(pref) && strings.EqualFold(h[:len(pref)], pref) {
+		return strings.TrimSpace(h[len(pref):])
+	}
+	return ""
+}
+
+// APIKeys authenticates requests with static keys.
+type APIKeys struct {
+	keys map[[sha256.Size]byte]APIKey
+}
+
+// NewAPIKeys creates an authenticator for a given list of keys.
+func NewAPIKeys(keys []APIKey) (*APIKeys, error) {
+	a := &APIKeys{keys: make(map[[sha256.Size]byte]APIKey, len(keys))}
+	for i, k := range keys {
+		if k.Key == "" {
+			return nil, fmt.Errorf("api key %d is empty", i)
+		}
+		if k.Name == "" {
+			k.Name = fmt.Sprintf("key-%d", i)
+		}
+		// keys are looked up by hash to make the time of the check independent of the key
+		a.keys[sha256.Sum256([]byte(k.Key))] = k
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *APIKeys) Authenticate(r *http.Request) (string, []string, error) {
+	key := r.Header.Get(hdrAPIKey)
+	explicit := key != ""
+	if !explicit {
+		key = bearerToken(r)
+	}
+	if key == "" {
+		return "", nil, nil
+	}
+	k, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		if explicit {
+			return "", nil, ErrInvalidCredentials
+		}
+		return "", nil, nil // might be a JWT
+	}
+	return k.Name, k.Roles, nil
+}
+
+type basicUser struct {
+	hash  []byte
+	roles []string
+}
+
+// BasicAuth authenticates requests with HTTP basic authentication.
+type BasicAuth struct {
+	users map[string]basicUser
+
+	mu sync.Mutex
+	ok map[string][sha256.Size]byte // cache of verified passwords, bcrypt is slow
+}
+
+// LoadBasicAuth loads users from a file. See AuthConfig.BasicFile for the format.
+func LoadBasicAuth(path string) (*BasicAuth, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	a := &BasicAuth{
+		users: make(map[string]basicUser),
+		ok:    make(map[string][sha256.Size]byte),
+	}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%s:%d: expected user:hash[:roles]", path, n)
+		}
+		if _, err := bcrypt.Cost([]byte(parts[1])); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+		u := basicUser{hash: []byte(parts[1])}
+		if len(parts) == 3 && parts[2] != "" {
+			for _, r := range strings.Split(parts[2], ",") {
+				u.roles = append(u.roles, strings.TrimSpace(r))
+			}
+		}
+		a.users[parts[0]] = u
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *BasicAuth) Authenticate(r *http.Request) (string, []string, error) {
+	name, pass, ok := r.BasicAuth()
+	if !ok {
+		return "", nil, nil
+	}
+	u, ok := a.users[name]
+	if !ok {
+		return "", nil, ErrInvalidCredentials
+	}
+	sum := sha256.Sum256([]byte(pass))
+	a.mu.Lock()
+	cached, ok := a.ok[name]
+	a.mu.Unlock()
+	if ok && cached == sum {
+		return name, u.roles, nil
+	}
+	if err := bcrypt.CompareHashAndPassword(u.hash, []byte(pass)); err != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+	a.mu.Lock()
+	a.ok[name] = sum
+	a.mu.Unlock()
+	return name, u.roles, nil
+}
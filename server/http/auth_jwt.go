@@ -0,0 +1,329 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cayleyhttp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTConfig configures the validation of JWT bearer tokens, for example issued by an OIDC provider.
+type JWTConfig struct {
+	// JWKSFile is a path to the JSON Web Key Set used to verify signatures.
+	JWKSFile string `mapstructure:"jwks_file" json:"jwks_file"`
+	// Issuer is the expected value of "iss" claim. Not checked if empty.
+	Issuer string `mapstructure:"issuer" json:"issuer"`
+	// Audience is the value expected in "aud" claim. Not checked if empty.
+	Audience string `mapstructure:"audience" json:"audience"`
+	// RolesClaim is the name of the claim with the list of roles. Default is "roles".
+	RolesClaim string `mapstructure:"roles_claim" json:"roles_claim"`
+	// NameClaim is the name of the claim with the principal name. Default is "sub".
+	NameClaim string `mapstructure:"name_claim" json:"name_claim"`
+	// Leeway is the allowed clock skew for "exp" and "nbf" claims.
+	Leeway time.Duration `mapstructure:"leeway" json:"leeway"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	// symmetric
+	K string `json:"k"`
+}
+
+type jwtKey struct {
+	kid string
+	alg string
+	key interface{} // *rsa.PublicKey, *ecdsa.PublicKey or []byte
+}
+
+// JWTValidator authenticates requests with JWT bearer tokens.
+type JWTValidator struct {
+	conf JWTConfig
+	keys []jwtKey
+	now  func() time.Time
+}
+
+// NewJWTValidator creates a JWT authenticator and loads the key set.
+func NewJWTValidator(c JWTConfig) (*JWTValidator, error) {
+	if c.JWKSFile == "" {
+		return nil, errors.New("jwt: jwks_file is not set")
+	}
+	if c.RolesClaim == "" {
+		c.RolesClaim = "roles"
+	}
+	if c.NameClaim == "" {
+		c.NameClaim = "sub"
+	}
+	data, err := os.ReadFile(c.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: %s: %v", c.JWKSFile, err)
+	}
+	return &JWTValidator{conf: c, keys: keys, now: time.Now}, nil
+}
+
+func b64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func parseJWKS(data []byte) ([]jwtKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	var keys []jwtKey
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		jk := jwtKey{kid: k.Kid, alg: k.Alg}
+		switch k.Kty {
+		case "RSA":
+			n, err := b64(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("key %d: %v", i, err)
+			}
+			e, err := b64(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("key %d: %v", i, err)
+			}
+			jk.key = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return nil, fmt.Errorf("key %d: unsupported curve: %q", i, k.Crv)
+			}
+			x, err := b64(k.X)
+			if err != nil {
+				return nil, fmt.Errorf("key %d: %v", i, err)
+			}
+			y, err := b64(k.Y)
+			if err != nil {
+				return nil, fmt.Errorf("key %d: %v", i, err)
+			}
+			jk.key = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		case "oct":
+			s, err := b64(k.K)
+			if err != nil {
+				return nil, fmt.Errorf("key %d: %v", i, err)
+			}
+			jk.key = s
+		default:
+			return nil, fmt.Errorf("key %d: unsupported key type: %q", i, k.Kty)
+		}
+		keys = append(keys, jk)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	return keys, nil
+}
+
+func jwtHash(alg string) crypto.Hash {
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256
+	case "384":
+		return crypto.SHA384
+	case "512":
+		return crypto.SHA512
+	}
+	return 0
+}
+
+// verify checks the signature of the token with a given key.
+func (k *jwtKey) verify(alg string, signed, sig []byte) bool {
+	if k.alg != "" && k.alg != alg {
+		return false
+	}
+	if len(alg) != 5 {
+		return false
+	}
+	h := jwtHash(alg)
+	if h == 0 {
+		return false
+	}
+	hw := h.New()
+	hw.Write(signed)
+	sum := hw.Sum(nil)
+	switch key := k.key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(key, h, sum, sig) == nil
+		case "PS":
+			return rsa.VerifyPSS(key, h, sum, sig, nil) == nil
+		}
+	case *ecdsa.PublicKey:
+		if alg[:2] != "ES" {
+			return false
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(key, sum, r, s)
+	case []byte:
+		if alg[:2] != "HS" {
+			return false
+		}
+		m := hmac.New(h.New, key)
+		m.Write(signed)
+		return hmac.Equal(m.Sum(nil), sig)
+	}
+	return false
+}
+
+// Validate checks the token and returns its claims.
+func (v *JWTValidator) Validate(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jwt: malformed token")
+	}
+	hdata, err := b64(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("jwt: malformed header: %v", err)
+	}
+	var hdr struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err = json.Unmarshal(hdata, &hdr); err != nil {
+		return nil, fmt.Errorf("jwt: malformed header: %v", err)
+	}
+	sig, err := b64(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("jwt: malformed signature: %v", err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	ok := false
+	for i := range v.keys {
+		k := &v.keys[i]
+		if hdr.Kid != "" && k.kid != "" && k.kid != hdr.Kid {
+			continue
+		}
+		if k.verify(hdr.Alg, signed, sig) {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return nil, errors.New("jwt: invalid signature")
+	}
+	cdata, err := b64(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("jwt: malformed claims: %v", err)
+	}
+	var claims map[string]interface{}
+	if err = json.Unmarshal(cdata, &claims); err != nil {
+		return nil, fmt.Errorf("jwt: malformed claims: %v", err)
+	}
+	now := v.now()
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(v.conf.Leeway)) {
+		return nil, errors.New("jwt: token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.conf.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("jwt: token is not valid yet")
+	}
+	if v.conf.Issuer != "" && claims["iss"] != v.conf.Issuer {
+		return nil, errors.New("jwt: unexpected issuer")
+	}
+	if v.conf.Audience != "" && !containsString(claims["aud"], v.conf.Audience) {
+		return nil, errors.New("jwt: unexpected audience")
+	}
+	return claims, nil
+}
+
+func containsString(v interface{}, s string) bool {
+	switch v := v.(type) {
+	case string:
+		return v == s
+	case []interface{}:
+		for _, a := range v {
+			if a == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authenticate implements Authenticator.
+func (v *JWTValidator) Authenticate(r *http.Request) (string, []string, error) {
+	tok := bearerToken(r)
+	if tok == "" {
+		return "", nil, nil
+	}
+	claims, err := v.Validate(tok)
+	if err != nil {
+		return "", nil, err
+	}
+	name, _ := claims[v.conf.NameClaim].(string)
+	if name == "" {
+		return "", nil, fmt.Errorf("jwt: no %q claim", v.conf.NameClaim)
+	}
+	var roles []string
+	switch rc := claims[v.conf.RolesClaim].(type) {
+	case string:
+		roles = strings.Fields(rc) // as in "scope" claim
+	case []interface{}:
+		for _, r := range rc {
+			if s, ok := r.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	}
+	return name, roles, nil
+}
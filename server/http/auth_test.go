@@ -0,0 +1,256 @@
+package cayleyhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/nquads"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/graph/reasoning"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/path"
+)
+
+func signJWT(t testing.TB, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	enc := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	s := enc(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + enc(claims)
+	sum := sha256.Sum256([]byte(s))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	require.NoError(t, err)
+	return s + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func makeAuth(t testing.TB) (*Auth, *rsa.PrivateKey) {
+	dir := t.TempDir()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	users := "# user:hash:roles\nbob:" + string(hash) + ":editor\nalice:" + string(hash) + "\n"
+	basic := filepath.Join(dir, "users")
+	require.NoError(t, os.WriteFile(basic, []byte(users), 0600))
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA", "kid": "k1", "alg": "RS256", "use": "sig",
+		"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(dir, "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0600))
+
+	a, err := NewAuth(AuthConfig{
+		APIKeys: []APIKey{
+			{Key: "rkey", Name: "reader", Roles: []string{RoleRead}},
+			{Key: "akey", Name: "ops", Roles: []string{RoleAdmin}},
+		},
+		BasicFile: basic,
+		JWT:       &JWTConfig{JWKSFile: jwksFile, Issuer: "https://idp", Audience: "cayley"},
+		Roles:     map[string][]string{"editor": {"read", "write"}},
+	})
+	require.NoError(t, err)
+	return a, key
+}
+
+func TestAuthRoutes(t *testing.T) {
+	a, key := makeAuth(t)
+	api := NewAPIv2(makeHandle(t, quads...), a.Wrap)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	token := signJWT(t, key, "k1", map[string]interface{}{
+		"sub": "carol", "iss": "https://idp", "aud": []string{"cayley"}, "exp": exp,
+		"roles": []string{"write", "unrelated"},
+	})
+	expired := signJWT(t, key, "k1", map[string]interface{}{
+		"sub": "carol", "iss": "https://idp", "aud": "cayley", "exp": time.Now().Add(-time.Hour).Unix(),
+		"roles": []string{"write"},
+	})
+	foreign := signJWT(t, key, "k1", map[string]interface{}{
+		"sub": "carol", "iss": "https://other", "aud": "cayley", "exp": exp,
+	})
+
+	type cred func(r *http.Request)
+	apiKey := func(k string) cred { return func(r *http.Request) { r.Header.Set(hdrAPIKey, k) } }
+	bearer := func(k string) cred { return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+k) } }
+	basic := func(u, p string) cred { return func(r *http.Request) { r.SetBasicAuth(u, p) } }
+
+	for _, c := range []struct {
+		name   string
+		method string
+		path   string
+		cred   cred
+		code   int
+	}{
+		{"anonymous read", "GET", "/read", nil, http.StatusUnauthorized},
+		{"key read", "GET", "/read", apiKey("rkey"), http.StatusOK},
+		{"key bearer read", "GET", "/formats", bearer("rkey"), http.StatusOK},
+		{"wrong key", "GET", "/read", apiKey("nope"), http.StatusUnauthorized},
+		{"key write", "POST", "/write", apiKey("rkey"), http.StatusForbidden},
+		{"admin write", "POST", "/write", apiKey("akey"), http.StatusOK},
+		{"basic write", "POST", "/write", basic("bob", "secret"), http.StatusOK},
+		{"basic write again", "POST", "/write", basic("bob", "secret"), http.StatusOK},
+		{"basic wrong password", "GET", "/read", basic("bob", "wrong"), http.StatusUnauthorized},
+		{"basic no roles", "GET", "/read", basic("alice", "secret"), http.StatusForbidden},
+		{"jwt write", "POST", "/write", bearer(token), http.StatusOK},
+		{"jwt admin", "POST", "/namespace-rules", bearer(token), http.StatusForbidden},
+		{"jwt expired", "GET", "/read", bearer(expired), http.StatusUnauthorized},
+		{"jwt issuer", "GET", "/read", bearer(foreign), http.StatusUnauthorized},
+		{"admin namespaces", "POST", "/namespace-rules", apiKey("akey"), http.StatusCreated},
+		{"read namespaces", "GET", "/namespace-rules", apiKey("rkey"), http.StatusOK},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var body []byte
+			switch c.path {
+			case "/write":
+				buf, err := newQuadsBuffer(quads)
+				require.NoError(t, err)
+				body = buf.Bytes()
+			case "/namespace-rules":
+				body = []byte(`{"prefix":"ex","namespace":"http://example.com/"}`)
+			}
+			req, err := http.NewRequest(c.method, prefix+c.path, bytes.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set(hdrContentType, mime)
+			req.Header.Set(hdrAccept, mime)
+			if c.cred != nil {
+				c.cred(req)
+			}
+			rr := httptest.NewRecorder()
+			api.ServeHTTP(rr, req)
+			require.Equal(t, c.code, rr.Code, rr.Body.String())
+			if rr.Code == http.StatusUnauthorized {
+				require.Equal(t, `Basic realm="cayley"`, rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthAnonymous(t *testing.T) {
+	a, err := NewAuth(AuthConfig{
+		APIKeys:   []APIKey{{Key: "wkey", Roles: []string{RoleWrite}}},
+		Anonymous: []string{RoleRead},
+	})
+	require.NoError(t, err)
+	api := NewAPIv2(makeHandle(t, quads...), a.Wrap)
+
+	req := httptest.NewRequest("GET", prefix+"/read", nil)
+	req.Header.Set(hdrAccept, mime)
+	rr := httptest.NewRecorder()
+	api.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	req = httptest.NewRequest("POST", prefix+"/delete", nil)
+	rr = httptest.NewRecorder()
+	api.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnauthorized, rr.Code, rr.Body.String())
+
+	// unknown tokens are rejected instead of falling back to anonymous access
+	req = httptest.NewRequest("GET", prefix+"/read", nil)
+	req.Header.Set(hdrAccept, mime)
+	req.Header.Set("Authorization", "Bearer nope")
+	rr = httptest.NewRecorder()
+	api.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnauthorized, rr.Code, rr.Body.String())
+
+	_, err = NewAuth(AuthConfig{Roles: map[string][]string{"x": {"root"}}})
+	require.Error(t, err)
+	_, err = NewAuth(AuthConfig{Anonymous: []string{"x"}})
+	require.Error(t, err)
+}
+
+func TestAuthLabels(t *testing.T) {
+	a, err := NewAuth(AuthConfig{
+		APIKeys: []APIKey{
+			{Key: "acme", Name: "acme", Roles: []string{RoleWrite}},
+			{Key: "ops", Name: "ops", Roles: []string{RoleAdmin}},
+		},
+		Labels: map[string][]string{"acme": {"http://example.com/acme"}},
+	})
+	require.NoError(t, err)
+	require.True(t, a.Scoped())
+
+	acme := quad.MakeIRI("http://example.com/alice", "http://example.com/likes", "http://example.com/bob", "http://example.com/acme")
+	other := quad.MakeIRI("http://example.com/bob", "http://example.com/likes", "http://example.com/carol", "http://example.com/globex")
+	h := makeHandle(t, acme, other)
+	h.QuadStore = ScopedQuadStore{QuadStore: h.QuadStore}
+	api := NewAPIv2(h, a.Wrap)
+
+	read := func(key string) []quad.Quad {
+		req := httptest.NewRequest("GET", prefix+"/read", nil)
+		req.Header.Set(hdrAccept, "application/n-quads")
+		req.Header.Set(hdrAPIKey, key)
+		rr := httptest.NewRecorder()
+		api.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		out, err := quad.ReadAll(nquads.NewReader(rr.Body, false))
+		require.NoError(t, err)
+		sort.Sort(quad.ByQuadString(out))
+		return out
+	}
+	require.Equal(t, []quad.Quad{acme}, read("acme"))
+	require.Len(t, read("ops"), 2)
+
+	write := func(q quad.Quad) int {
+		req := httptest.NewRequest("POST", prefix+"/write", strings.NewReader(q.NQuad()+"\n"))
+		req.Header.Set(hdrContentType, "application/n-quads")
+		req.Header.Set(hdrAPIKey, "acme")
+		rr := httptest.NewRecorder()
+		api.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	require.NotEqual(t, http.StatusOK, write(quad.MakeIRI("http://example.com/x", "http://example.com/y", "http://example.com/z", "http://example.com/globex")))
+	added := quad.MakeIRI("http://example.com/x", "http://example.com/y", "http://example.com/z", "http://example.com/acme")
+	require.Equal(t, http.StatusOK, write(added))
+	exp := []quad.Quad{acme, added}
+	sort.Sort(quad.ByQuadString(exp))
+	require.Equal(t, exp, read("acme"))
+}
+
+func TestAuthLabelsReasoning(t *testing.T) {
+	ctx := context.TODO()
+	base := memstore.New(
+		quad.MakeIRI("Employee", rdfs.SubClassOf, "Person", ""),
+		quad.MakeIRI("alice", rdf.Type, "Employee", ""),
+	)
+	rs, err := reasoning.New(ctx, base)
+	require.NoError(t, err)
+	qs := ScopedQuadStore{QuadStore: rs}
+
+	people := func(qs graph.QuadStore) []quad.Value {
+		out, err := path.StartPath(qs).Has(quad.IRI(rdf.Type), quad.IRI("Person")).Iterate(ctx).AllValues(qs)
+		require.NoError(t, err)
+		return out
+	}
+	require.Equal(t, []quad.Value{quad.IRI("alice")}, people(qs))
+	// disabling inference keeps the scope
+	off := query.QuadStoreFor(qs, query.Options{NoReasoning: true})
+	require.IsType(t, ScopedQuadStore{}, off)
+	require.Empty(t, people(off))
+}
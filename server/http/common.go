@@ -1,12 +1,18 @@
 package cayleyhttp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/cayleygraph/cayley/graph"
 	httpgraph "github.com/cayleygraph/cayley/graph/http"
+	"github.com/cayleygraph/cayley/graph/scope"
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/shape"
+	"github.com/cayleygraph/cayley/validate"
 )
 
 func jsonResponse(w http.ResponseWriter, code int, err interface{}) {
@@ -27,6 +33,59 @@ func jsonResponse(w http.ResponseWriter, code int, err interface{}) {
 	w.Write([]byte(`}`))
 }
 
+// writeErrorStatus returns the HTTP status for an error of a write request.
+func writeErrorStatus(err error) int {
+	var verr *validate.ValidationError
+	if errors.As(err, &verr) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ScopedQuadStore restricts requests of principals to quad labels assigned to them, see Principal.Labels.
+type ScopedQuadStore struct {
+	graph.QuadStore
+}
+
+var (
+	_ httpgraph.QuadStore = ScopedQuadStore{}
+	_ shape.Rewriter      = ScopedQuadStore{}
+	_ query.Reasoner      = ScopedQuadStore{}
+)
+
+// RewriteShape implements shape.Rewriter by passing the shape to the underlying quad store.
+func (qs ScopedQuadStore) RewriteShape(ctx context.Context, s shape.Shape) (shape.Shape, graph.QuadStore) {
+	if rw, ok := qs.QuadStore.(shape.Rewriter); ok {
+		return rw.RewriteShape(ctx, s)
+	}
+	return s, qs
+}
+
+// WithReasoning implements query.Reasoner by enabling or disabling inference of the underlying quad store.
+func (qs ScopedQuadStore) WithReasoning(on bool) graph.QuadStore {
+	if r, ok := qs.QuadStore.(query.Reasoner); ok {
+		return ScopedQuadStore{QuadStore: r.WithReasoning(on)}
+	}
+	return qs
+}
+
+// ForRequest implements httpgraph.QuadStore.
+func (qs ScopedQuadStore) ForRequest(r *http.Request) (graph.QuadStore, error) {
+	base := qs.QuadStore
+	if g, ok := base.(httpgraph.QuadStore); ok {
+		var err error
+		base, err = g.ForRequest(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p, ok := PrincipalFromContext(r.Context())
+	if !ok || p == nil || p.Labels == nil {
+		return base, nil
+	}
+	return scope.New(base, p.Labels...), nil
+}
+
 // HandleForRequest returns new graph.Handle for given writer name, options and request
 func HandleForRequest(h *graph.Handle, wtyp string, wopt graph.Options, r *http.Request) (*graph.Handle, error) {
 	g, ok := h.QuadStore.(httpgraph.QuadStore)
@@ -0,0 +1,343 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cayleyhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/query/gizmo"
+	"github.com/cayleygraph/cayley/schema"
+)
+
+// DefaultDatabase is the name of the main database of the server, served at the root.
+// It cannot be used as a name of other databases.
+const DefaultDatabase = "default"
+
+// DatabaseRoute is the route of the API of named databases.
+const DatabaseRoute = "/db/:name/*path"
+
+var (
+	// ErrDatabaseExists is returned when opening a database with a name that is already in use.
+	ErrDatabaseExists = errors.New("database already exists")
+	// ErrDatabaseNotFound is returned when a database with a given name is not open.
+	ErrDatabaseNotFound = errors.New("database not found")
+)
+
+var reDatabaseName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// DatabaseConfig describes a named database.
+type DatabaseConfig struct {
+	Name     string        `mapstructure:"name" json:"name"`
+	Backend  string        `mapstructure:"backend" json:"backend"`
+	Address  string        `mapstructure:"address" json:"address,omitempty"`
+	Options  graph.Options `mapstructure:"options" json:"options,omitempty"`
+	ReadOnly bool          `mapstructure:"read_only" json:"read_only"`
+}
+
+// DatabaseInfo is a public description of a database. Unlike DatabaseConfig, it doesn't include
+// an address and options, since they may contain credentials.
+type DatabaseInfo struct {
+	Name     string `json:"name"`
+	Backend  string `json:"backend"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// OpenFunc opens a database, initializing it if necessary.
+type OpenFunc func(c DatabaseConfig) (*graph.Handle, error)
+
+type databaseKey struct{}
+
+// DatabaseFromContext returns the name of the database the request is addressed to.
+// It returns false for requests to the default database.
+func DatabaseFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(databaseKey{}).(string)
+	return name, ok
+}
+
+type database struct {
+	conf DatabaseConfig
+	h    *graph.Handle
+	api  *APIv2
+	reqs sync.WaitGroup // requests being served
+}
+
+// close waits for requests to the database to finish and closes it.
+func (db *database) close() error {
+	db.reqs.Wait()
+	return db.h.Close()
+}
+
+// Databases serves APIv2 of multiple named databases under /db/{name}/api/v2.
+//
+// Databases can be opened and dropped at runtime, either directly or via /api/v2/databases.
+type Databases struct {
+	open     OpenFunc
+	setup    func(api *APIv2, h *graph.Handle) error
+	notFound http.Handler
+	ro       bool
+	scoped   bool
+	dir      string
+
+	mu  sync.RWMutex
+	dbs map[string]*database
+}
+
+// NewDatabases creates an empty set of databases, that uses a given function to open them.
+func NewDatabases(open OpenFunc) *Databases {
+	return &Databases{open: open, dbs: make(map[string]*database)}
+}
+
+// SetSetup sets a function that is called to configure the API of each database before it's served.
+func (d *Databases) SetSetup(fn func(api *APIv2, h *graph.Handle) error) {
+	d.setup = fn
+}
+
+// SetNotFound sets a handler for requests to named databases that don't match any API route.
+// The database name is available via DatabaseFromContext and the path is relative to the database root.
+func (d *Databases) SetNotFound(h http.Handler) {
+	d.notFound = h
+}
+
+// SetReadOnly disables creating and dropping databases via API.
+func (d *Databases) SetReadOnly(ro bool) {
+	d.ro = ro
+}
+
+// SetDataDir sets a directory for persistent databases created via API. Each of them is stored at the path
+// named after the database. Only databases of non-persistent backends can be created via API if it's not set.
+func (d *Databases) SetDataDir(dir string) {
+	d.dir = dir
+}
+
+// SetScoped restricts principals to their labels in all databases, see ScopedQuadStore.
+func (d *Databases) SetScoped(scoped bool) {
+	d.scoped = scoped
+}
+
+// Open opens a database and starts serving its API.
+func (d *Databases) Open(c DatabaseConfig) error {
+	if !reDatabaseName.MatchString(c.Name) {
+		return fmt.Errorf("invalid database name: %q", c.Name)
+	} else if c.Name == DefaultDatabase {
+		return fmt.Errorf("database name %q is reserved", c.Name)
+	} else if c.Backend == "" {
+		return errors.New("database backend is not set")
+	}
+	d.mu.RLock()
+	_, exists := d.dbs[c.Name]
+	d.mu.RUnlock()
+	if exists {
+		return ErrDatabaseExists
+	}
+	h, err := d.open(c)
+	if err != nil {
+		return err
+	}
+	api := &APIv2{h: h, wtyp: defaultReplication, limit: defaultLimit}
+	api.ns = schema.NewConfig().NewNamespaceRegistry(h)
+	api.procs = gizmo.NewModules(h)
+	if d.scoped {
+		api.h = &graph.Handle{QuadStore: ScopedQuadStore{QuadStore: h.QuadStore}, QuadWriter: h.QuadWriter}
+	}
+	if d.setup != nil {
+		if err = d.setup(api, h); err != nil {
+			h.Close()
+			return err
+		}
+	}
+	if c.ReadOnly {
+		api.ro = true
+	}
+	r := httprouter.New()
+	api.registerOn(r)
+	r.NotFound = d.notFound
+	api.handler = r
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, exists = d.dbs[c.Name]; exists {
+		h.Close()
+		return ErrDatabaseExists
+	}
+	d.dbs[c.Name] = &database{conf: c, h: h, api: api}
+	return nil
+}
+
+// Drop stops serving a database and closes it after requests that are being served finish. The data is not removed.
+func (d *Databases) Drop(name string) error {
+	d.mu.Lock()
+	db, ok := d.dbs[name]
+	delete(d.dbs, name)
+	d.mu.Unlock()
+	if !ok {
+		return ErrDatabaseNotFound
+	}
+	return db.close()
+}
+
+// Handle returns a handle of a database with a given name.
+func (d *Databases) Handle(name string) (*graph.Handle, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	db, ok := d.dbs[name]
+	if !ok {
+		return nil, false
+	}
+	return db.h, true
+}
+
+// List returns descriptions of all open databases, sorted by name.
+func (d *Databases) List() []DatabaseInfo {
+	d.mu.RLock()
+	out := make([]DatabaseInfo, 0, len(d.dbs))
+	for _, db := range d.dbs {
+		out = append(out, DatabaseInfo{Name: db.conf.Name, Backend: db.conf.Backend, ReadOnly: db.api.ro})
+	}
+	d.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Close closes all databases, after requests that are being served finish.
+func (d *Databases) Close() error {
+	d.mu.Lock()
+	dbs := d.dbs
+	d.dbs = make(map[string]*database)
+	d.mu.Unlock()
+	var last error
+	for _, db := range dbs {
+		if err := db.close(); err != nil {
+			last = err
+		}
+	}
+	return last
+}
+
+// RegisterOn registers routes for the API of databases and for managing them on a router.
+func (d *Databases) RegisterOn(router *httprouter.Router) {
+	r := Routes{Router: router}
+	r.GET(prefix+"/databases", handle(PermRead, d.ServeList))
+	if !d.ro {
+		r.POST(prefix+"/databases", handle(PermAdmin, d.ServeCreate))
+		r.DELETE(prefix+"/databases/:name", WrapHandle(RequirePermission(PermAdmin), d.ServeDrop))
+	}
+	for _, m := range []string{
+		http.MethodGet, http.MethodHead, http.MethodPost,
+		http.MethodPut, http.MethodPatch, http.MethodDelete,
+	} {
+		r.Handle(m, DatabaseRoute, d.ServeDatabase)
+	}
+}
+
+// ServeList responds with the list of databases.
+func (d *Databases) ServeList(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(hdrContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(d.List())
+}
+
+// createRequest describes a database to create via API. Unlike DatabaseConfig, it doesn't allow
+// to set an address and options, thus clients cannot open stores at arbitrary paths.
+type createRequest struct {
+	Name     string `json:"name"`
+	Backend  string `json:"backend"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// ServeCreate opens a database described by createRequest in the request body.
+// Persistent databases are stored in the data directory, see SetDataDir.
+func (d *Databases) ServeCreate(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	c := DatabaseConfig{Name: req.Name, Backend: req.Backend, ReadOnly: req.ReadOnly}
+	if !reDatabaseName.MatchString(c.Name) || c.Name == DefaultDatabase {
+		jsonResponse(w, http.StatusBadRequest, fmt.Errorf("invalid database name: %q", c.Name))
+		return
+	} else if c.Backend == "" {
+		jsonResponse(w, http.StatusBadRequest, errors.New("database backend is not set"))
+		return
+	} else if !graph.IsRegistered(c.Backend) {
+		jsonResponse(w, http.StatusBadRequest, fmt.Errorf("unknown database backend: %q", c.Backend))
+		return
+	} else if graph.IsPersistent(c.Backend) {
+		if d.dir == "" {
+			jsonResponse(w, http.StatusForbidden, fmt.Errorf("data directory is not configured, cannot create a database using backend %q", c.Backend))
+			return
+		}
+		c.Address = filepath.Join(d.dir, c.Name)
+	}
+	if err := d.Open(c); err == ErrDatabaseExists {
+		jsonResponse(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set(hdrContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(DatabaseInfo{Name: c.Name, Backend: c.Backend, ReadOnly: c.ReadOnly})
+}
+
+// ServeDrop closes a database with a given name.
+func (d *Databases) ServeDrop(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	if err := d.Drop(params.ByName("name")); err == ErrDatabaseNotFound {
+		jsonResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeDatabase serves a request to the API of a named database.
+func (d *Databases) ServeDatabase(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	name := params.ByName("name")
+	d.mu.RLock()
+	db, ok := d.dbs[name]
+	if ok {
+		// the database is not closed until the request is served
+		db.reqs.Add(1)
+		defer db.reqs.Done()
+	}
+	d.mu.RUnlock()
+	if !ok {
+		jsonResponse(w, http.StatusNotFound, fmt.Errorf("%v: %q", ErrDatabaseNotFound, name))
+		return
+	}
+	r2 := r.WithContext(context.WithValue(r.Context(), databaseKey{}, name))
+	u := *r.URL
+	u.Path, u.RawPath = params.ByName("path"), ""
+	r2.URL = &u
+	db.api.ServeHTTP(w, r2)
+}
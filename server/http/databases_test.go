@@ -0,0 +1,207 @@
+package cayleyhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+)
+
+func TestDatabases(t *testing.T) {
+	opened := make(map[string]*graph.Handle)
+	dbs := NewDatabases(func(c DatabaseConfig) (*graph.Handle, error) {
+		require.Equal(t, memstore.QuadStoreType, c.Backend)
+		h := makeHandle(t)
+		opened[c.Name] = h
+		return h, nil
+	})
+	var limits []int
+	dbs.SetSetup(func(api *APIv2, h *graph.Handle) error {
+		api.SetQueryLimit(7)
+		limits = append(limits, api.limit)
+		return nil
+	})
+	def := makeHandle(t)
+	r := httprouter.New()
+	NewBoundAPIv2(def, r)
+	dbs.RegisterOn(r)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if strings.HasSuffix(path, "/write") {
+			req.Header.Set(hdrContentType, mime)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+	list := func() []DatabaseInfo {
+		rr := do("GET", "/api/v2/databases", "")
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out []DatabaseInfo
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		return out
+	}
+	require.Empty(t, list())
+
+	require.NoError(t, dbs.Open(DatabaseConfig{Name: "movies", Backend: memstore.QuadStoreType, ReadOnly: true}))
+	require.Equal(t, ErrDatabaseExists, dbs.Open(DatabaseConfig{Name: "movies", Backend: memstore.QuadStoreType}))
+	require.Error(t, dbs.Open(DatabaseConfig{Name: DefaultDatabase, Backend: memstore.QuadStoreType}))
+	require.Error(t, dbs.Open(DatabaseConfig{Name: "a/b", Backend: memstore.QuadStoreType}))
+
+	rr := do("POST", "/api/v2/databases", `{"name":"people","backend":"memstore"}`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	rr = do("POST", "/api/v2/databases", `{"name":"people","backend":"memstore"}`)
+	require.Equal(t, http.StatusConflict, rr.Code)
+	rr = do("POST", "/api/v2/databases", `{"name":"other","backend":"unknown"}`)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, []DatabaseInfo{
+		{Name: "movies", Backend: memstore.QuadStoreType, ReadOnly: true},
+		{Name: "people", Backend: memstore.QuadStoreType},
+	}, list())
+	require.Equal(t, []int{7, 7}, limits)
+
+	// writes are routed to the named database
+	buf, err := newQuadsBuffer(quads)
+	require.NoError(t, err)
+	rr = do("POST", "/db/people/api/v2/write", buf.String())
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	st, err := opened["people"].QuadStore.Stats(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(quads)), st.Quads.Value)
+	st, err = def.QuadStore.Stats(context.Background(), true)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), st.Quads.Value)
+
+	rr = do("GET", "/db/people/api/v2/read?format=nquads", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, len(quads), strings.Count(rr.Body.String(), "\n"))
+
+	q := url.Values{"lang": {"gizmo"}, "qu": {`g.V("<http://example.com/bob>").Out().All()`}}
+	rr = do("GET", "/db/people/api/v2/query?"+q.Encode(), "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Contains(t, rr.Body.String(), "http://example.com/alice")
+
+	// named databases have their own procedures
+	rr = do("PUT", "/db/people/api/v2/procedures/out", `module.exports = function(p) { g.V(p.id).out().all() }`)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	rr = do("POST", "/db/people/api/v2/procedures/out", `{"id":"<http://example.com/bob>"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Contains(t, rr.Body.String(), "http://example.com/alice")
+	rr = do("POST", "/api/v2/procedures/out", `{}`)
+	require.Equal(t, http.StatusNotFound, rr.Code, rr.Body.String())
+
+	// read-only databases don't accept writes
+	rr = do("POST", "/db/movies/api/v2/write", buf.String())
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = do("GET", "/db/unknown/api/v2/read", "")
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = do("DELETE", "/api/v2/databases/people", "")
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	rr = do("DELETE", "/api/v2/databases/people", "")
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	rr = do("GET", "/db/people/api/v2/read", "")
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Equal(t, []DatabaseInfo{{Name: "movies", Backend: memstore.QuadStoreType, ReadOnly: true}}, list())
+
+	require.NoError(t, dbs.Close())
+	require.Empty(t, list())
+}
+
+type closeQuadStore struct {
+	graph.QuadStore
+	closed chan struct{}
+}
+
+func (qs closeQuadStore) Close() error {
+	close(qs.closed)
+	return qs.QuadStore.Close()
+}
+
+func TestDatabasesDropInFlight(t *testing.T) {
+	closed := make(chan struct{})
+	dbs := NewDatabases(func(c DatabaseConfig) (*graph.Handle, error) {
+		h := makeHandle(t)
+		h.QuadStore = closeQuadStore{QuadStore: h.QuadStore, closed: closed}
+		return h, nil
+	})
+	started, release := make(chan struct{}), make(chan struct{})
+	dbs.SetNotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	r := httprouter.New()
+	dbs.RegisterOn(r)
+	require.NoError(t, dbs.Open(DatabaseConfig{Name: "people", Backend: memstore.QuadStoreType}))
+
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/db/people/ui", nil))
+	}()
+	<-started
+
+	dropped := make(chan error, 1)
+	go func() {
+		dropped <- dbs.Drop("people")
+	}()
+	select {
+	case <-closed:
+		t.Fatal("database closed while a request is served")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-served
+	require.NoError(t, <-dropped)
+	<-closed
+}
+
+func TestDatabasesCreate(t *testing.T) {
+	const backend = "test-persistent"
+	graph.RegisterQuadStore(backend, graph.QuadStoreRegistration{
+		NewFunc: func(string, graph.Options) (graph.QuadStore, error) {
+			return memstore.New(), nil
+		},
+		IsPersistent: true,
+	})
+	var opened []DatabaseConfig
+	dbs := NewDatabases(func(c DatabaseConfig) (*graph.Handle, error) {
+		opened = append(opened, c)
+		return makeHandle(t), nil
+	})
+	r := httprouter.New()
+	dbs.RegisterOn(r)
+	create := func(body string) int {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v2/databases", strings.NewReader(body)))
+		return rr.Code
+	}
+
+	// clients cannot choose where the database is stored
+	require.Equal(t, http.StatusBadRequest, create(`{"name":"a","backend":"memstore","address":"/tmp/a"}`))
+	require.Equal(t, http.StatusBadRequest, create(`{"name":"a","backend":"memstore","options":{"path":"/tmp/a"}}`))
+	require.Equal(t, http.StatusForbidden, create(`{"name":"a","backend":"`+backend+`"}`))
+	require.Equal(t, http.StatusCreated, create(`{"name":"a","backend":"memstore"}`))
+
+	dir := t.TempDir()
+	dbs.SetDataDir(dir)
+	require.Equal(t, http.StatusCreated, create(`{"name":"b","backend":"`+backend+`"}`))
+	require.Equal(t, []DatabaseConfig{
+		{Name: "a", Backend: memstore.QuadStoreType},
+		{Name: "b", Backend: backend, Address: filepath.Join(dir, "b")},
+	}, opened)
+	require.NoError(t, dbs.Close())
+}
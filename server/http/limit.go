@@ -0,0 +1,327 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cayleyhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/quad"
+)
+
+// Classes of requests with separate limits.
+const (
+	LimitQuery = "query"
+	LimitWrite = "write"
+)
+
+// ErrLimited is returned by writers of LimitWriter if the change exceeds the write limits of the client.
+var ErrLimited = errors.New("too many write requests")
+
+var (
+	mLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cayley_http_limited_requests_total",
+		Help: "Number of HTTP requests rejected because of rate or concurrency limits.",
+	}, []string{"class", "reason"})
+	mLimitActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cayley_http_limited_active_requests",
+		Help: "Number of HTTP requests subject to limits that are being served.",
+	}, []string{"class"})
+)
+
+// Limit configures the limits of a single class of requests for each client.
+type Limit struct {
+	// Rate is the number of requests per second. Requests are not limited if it's zero.
+	Rate float64 `mapstructure:"rate" json:"rate"`
+	// Burst is the number of requests that can be sent at once. Defaults to Rate, but at least 1.
+	Burst int `mapstructure:"burst" json:"burst"`
+	// Concurrent is the maximal number of requests served at the same time. Not limited if it's zero.
+	Concurrent int `mapstructure:"concurrent" json:"concurrent"`
+}
+
+func (l Limit) enabled() bool {
+	return l.Rate > 0 || l.Concurrent > 0
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Max(1, math.Ceil(l.Rate))
+}
+
+// ClassLimits configures the limits of each class of requests.
+type ClassLimits struct {
+	// Query limits query requests.
+	Query Limit `mapstructure:"query" json:"query"`
+	// Write limits write and delete requests.
+	Write Limit `mapstructure:"write" json:"write"`
+}
+
+func (c ClassLimits) get(class string) Limit {
+	switch class {
+	case LimitQuery:
+		return c.Query
+	case LimitWrite:
+		return c.Write
+	}
+	return Limit{}
+}
+
+// LimitConfig configures the limits of requests for each client.
+//
+// Clients are identified by the principal name, if the request is authenticated, or by the remote IP otherwise.
+type LimitConfig struct {
+	ClassLimits `mapstructure:",squash"`
+	// Clients overrides limits for principals (by name) and remote IPs.
+	Clients map[string]ClassLimits `mapstructure:"clients" json:"clients"`
+}
+
+// Enabled checks if any limit is configured.
+func (c *LimitConfig) Enabled() bool {
+	if c == nil {
+		return false
+	}
+	if c.Query.enabled() || c.Write.enabled() {
+		return true
+	}
+	for _, l := range c.Clients {
+		if l.Query.enabled() || l.Write.enabled() {
+			return true
+		}
+	}
+	return false
+}
+
+type limitKey struct {
+	class  string
+	client string
+}
+
+// bucket is a state of limits for a single client and a class of requests.
+type bucket struct {
+	lim    Limit
+	tokens float64
+	last   time.Time
+	active int
+}
+
+// Limiter enforces rate and concurrency limits for each client.
+type Limiter struct {
+	conf LimitConfig
+	now  func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[limitKey]*bucket
+	lastSweep time.Time
+}
+
+// NewLimiter creates a new limiter with a given config.
+func NewLimiter(c LimitConfig) (*Limiter, error) {
+	check := func(name string, l ClassLimits) error {
+		for _, v := range []Limit{l.Query, l.Write} {
+			if v.Rate < 0 || v.Burst < 0 || v.Concurrent < 0 {
+				return fmt.Errorf("limits for %s cannot be negative", name)
+			}
+		}
+		return nil
+	}
+	if err := check("all clients", c.ClassLimits); err != nil {
+		return nil, err
+	}
+	for name, l := range c.Clients {
+		if err := check(name, l); err != nil {
+			return nil, err
+		}
+	}
+	return &Limiter{conf: c, now: time.Now, buckets: make(map[limitKey]*bucket)}, nil
+}
+
+// clientOf returns the client ID and the limit for the request.
+func (l *Limiter) clientOf(r *http.Request, class string) (string, Limit) {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if p, ok := PrincipalFromContext(r.Context()); ok && !p.Anonymous() {
+		if c, ok := l.conf.Clients[p.Name]; ok {
+			return "principal:" + p.Name, c.get(class)
+		}
+		return "principal:" + p.Name, l.conf.get(class)
+	}
+	if c, ok := l.conf.Clients[ip]; ok {
+		return "ip:" + ip, c.get(class)
+	}
+	return "ip:" + ip, l.conf.get(class)
+}
+
+// sweep removes the state of clients that have no active requests and have a full bucket.
+func (l *Limiter) sweep(now time.Time) {
+	for k, b := range l.buckets {
+		if b.active == 0 && (b.lim.Rate <= 0 || b.tokens+now.Sub(b.last).Seconds()*b.lim.Rate >= b.lim.burst()) {
+			delete(l.buckets, k)
+		}
+	}
+	l.lastSweep = now
+}
+
+// acquire checks if a request of a given class from a given client is allowed.
+//
+// If the request is allowed, the caller must call the release function after the request is served.
+// Otherwise, it returns the duration after which the request can be retried.
+func (l *Limiter) acquire(class, client string, lim Limit) (release func(), retry time.Duration, reason string) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) > time.Minute {
+		l.sweep(now)
+	}
+	k := limitKey{class: class, client: client}
+	b := l.buckets[k]
+	if b == nil {
+		b = &bucket{lim: lim, tokens: lim.burst(), last: now}
+		l.buckets[k] = b
+	}
+	if lim.Concurrent > 0 && b.active >= lim.Concurrent {
+		return nil, time.Second, "concurrency"
+	}
+	if lim.Rate > 0 {
+		b.tokens = math.Min(lim.burst(), b.tokens+now.Sub(b.last).Seconds()*lim.Rate)
+		b.last = now
+		if b.tokens < 1 {
+			return nil, time.Duration((1 - b.tokens) / lim.Rate * float64(time.Second)), "rate"
+		}
+		b.tokens--
+	}
+	b.active++
+	return func() {
+		l.mu.Lock()
+		b.active--
+		l.mu.Unlock()
+	}, 0, ""
+}
+
+// Wrap returns a handler that enforces the limits for requests.
+//
+// The class of each request is determined by a given function. Requests with an empty class are not limited.
+// Limits are enforced by the principal, thus the handler must be wrapped with Auth.Wrap, if authentication is enabled.
+//
+// The limiter is also available to the handler, to enforce write limits with LimitWriter.
+func (l *Limiter) Wrap(class func(r *http.Request) string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), limiterKey{}, l))
+		c := class(r)
+		if c == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		client, lim := l.clientOf(r, c)
+		if !lim.enabled() {
+			h.ServeHTTP(w, r)
+			return
+		}
+		release, retry, reason := l.acquire(c, client, lim)
+		if release == nil {
+			mLimited.WithLabelValues(c, reason).Inc()
+			sec := int(math.Ceil(retry.Seconds()))
+			if sec < 1 {
+				sec = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(sec))
+			jsonResponse(w, http.StatusTooManyRequests, fmt.Sprintf("too many %s requests, retry in %ds", c, sec))
+			return
+		}
+		mLimitActive.WithLabelValues(c).Inc()
+		defer func() {
+			mLimitActive.WithLabelValues(c).Dec()
+			release()
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
+
+type limiterKey struct{}
+
+// LimitWriter returns a writer that enforces the write limits of the client for each change, if the request
+// is served by Limiter.Wrap. Otherwise, qw is returned as-is.
+//
+// It allows to limit queries and procedures that change the graph, as writes.
+func LimitWriter(r *http.Request, qw graph.QuadWriter) graph.QuadWriter {
+	l, ok := r.Context().Value(limiterKey{}).(*Limiter)
+	if !ok || qw == nil {
+		return qw
+	}
+	return &limitWriter{QuadWriter: qw, l: l, r: r}
+}
+
+type limitWriter struct {
+	graph.QuadWriter
+	l *Limiter
+	r *http.Request
+}
+
+// Unwrap returns the underlying writer.
+func (w *limitWriter) Unwrap() graph.QuadWriter {
+	return w.QuadWriter
+}
+
+func (w *limitWriter) apply(fnc func() error) error {
+	client, lim := w.l.clientOf(w.r, LimitWrite)
+	if !lim.enabled() {
+		return fnc()
+	}
+	release, retry, reason := w.l.acquire(LimitWrite, client, lim)
+	if release == nil {
+		mLimited.WithLabelValues(LimitWrite, reason).Inc()
+		return fmt.Errorf("%w, retry in %v", ErrLimited, retry.Round(time.Millisecond))
+	}
+	mLimitActive.WithLabelValues(LimitWrite).Inc()
+	defer func() {
+		mLimitActive.WithLabelValues(LimitWrite).Dec()
+		release()
+	}()
+	return fnc()
+}
+
+func (w *limitWriter) AddQuad(q quad.Quad) error {
+	return w.apply(func() error { return w.QuadWriter.AddQuad(q) })
+}
+
+func (w *limitWriter) AddQuadSet(quads []quad.Quad) error {
+	return w.apply(func() error { return w.QuadWriter.AddQuadSet(quads) })
+}
+
+func (w *limitWriter) RemoveQuad(q quad.Quad) error {
+	return w.apply(func() error { return w.QuadWriter.RemoveQuad(q) })
+}
+
+func (w *limitWriter) ApplyTransaction(tx *graph.Transaction) error {
+	return w.apply(func() error { return w.QuadWriter.ApplyTransaction(tx) })
+}
+
+func (w *limitWriter) RemoveNode(v quad.Value) error {
+	return w.apply(func() error { return w.QuadWriter.RemoveNode(v) })
+}
@@ -0,0 +1,96 @@
+package cayleyhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	l, err := NewLimiter(LimitConfig{
+		ClassLimits: ClassLimits{
+			Query: Limit{Rate: 1, Burst: 2},
+			Write: Limit{Concurrent: 1},
+		},
+		Clients: map[string]ClassLimits{
+			"loader":   {Query: Limit{Rate: 100}},
+			"10.0.0.9": {},
+		},
+	})
+	require.NoError(t, err)
+	now := time.Unix(100, 0)
+	l.now = func() time.Time { return now }
+
+	var (
+		block   = make(chan struct{})
+		started = make(chan struct{})
+	)
+	h := l.Wrap(func(r *http.Request) string {
+		switch r.URL.Path {
+		case "/query":
+			return LimitQuery
+		case "/write":
+			return LimitWrite
+		}
+		return ""
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/write" {
+			started <- struct{}{}
+			<-block
+		}
+	}))
+	do := func(path, addr string, p *Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = addr + ":1234"
+		if p != nil {
+			req = req.WithContext(WithPrincipal(req.Context(), p))
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// burst, then limited
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusOK, do("/query", "10.0.0.1", nil).Code)
+	}
+	rr := do("/query", "10.0.0.1", nil)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "1", rr.Header().Get("Retry-After"))
+
+	// other clients and unlimited routes are not affected
+	require.Equal(t, http.StatusOK, do("/query", "10.0.0.2", nil).Code)
+	require.Equal(t, http.StatusOK, do("/read", "10.0.0.1", nil).Code)
+
+	// principals are limited by name, and can have their own limits
+	loader := &Principal{Name: "loader"}
+	for i := 0; i < 10; i++ {
+		require.Equal(t, http.StatusOK, do("/query", "10.0.0.1", loader).Code)
+	}
+	require.Equal(t, http.StatusOK, do("/query", "10.0.0.1", &Principal{Name: "bob"}).Code)
+	// empty override removes the limits
+	for i := 0; i < 10; i++ {
+		require.Equal(t, http.StatusOK, do("/query", "10.0.0.9", nil).Code)
+	}
+
+	// tokens are refilled over time
+	now = now.Add(time.Second)
+	require.Equal(t, http.StatusOK, do("/query", "10.0.0.1", nil).Code)
+	require.Equal(t, http.StatusTooManyRequests, do("/query", "10.0.0.1", nil).Code)
+
+	// concurrency limit
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		do("/write", "10.0.0.1", nil)
+	}()
+	<-started
+	require.Equal(t, http.StatusTooManyRequests, do("/write", "10.0.0.1", nil).Code)
+	close(block)
+	<-done
+	go func() { <-started }()
+	require.Equal(t, http.StatusOK, do("/write", "10.0.0.1", nil).Code)
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cayleyhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/cayleygraph/cayley/query"
+	"github.com/cayleygraph/cayley/query/gizmo"
+)
+
+// Procedures returns the registry of Gizmo modules (stored procedures) of the database.
+func (api *APIv2) Procedures() *gizmo.Modules {
+	return api.procs
+}
+
+func (api *APIv2) registerProceduresOn(r Routes) {
+	r.GET(prefix+"/procedures", handle(PermRead, api.ServeProcedures))
+	r.GET(prefix+"/procedures/*name", WrapHandle(RequirePermission(PermRead), api.ServeProcedure))
+	r.POST(prefix+"/procedures/*name", WrapHandle(RequirePermission(PermRead), api.ServeProcedureCall))
+	if !api.ro {
+		r.PUT(prefix+"/procedures/*name", WrapHandle(RequirePermission(PermAdmin), api.ServeProcedureSave))
+		r.DELETE(prefix+"/procedures/*name", WrapHandle(RequirePermission(PermAdmin), api.ServeProcedureDelete))
+	}
+}
+
+// procedureParams returns the name and the version of the procedure from the request.
+func procedureParams(r *http.Request, params httprouter.Params) (string, int, error) {
+	name := strings.TrimPrefix(params.ByName("name"), "/")
+	name, vers, err := gizmo.ParseModuleRef(name)
+	if err != nil {
+		return "", 0, err
+	}
+	if s := r.URL.Query().Get("version"); s != "" {
+		if vers, err = strconv.Atoi(s); err != nil || vers <= 0 {
+			return "", 0, fmt.Errorf("invalid version: %q", s)
+		}
+	}
+	return name, vers, nil
+}
+
+func procedureErrorStatus(err error) int {
+	if errors.Is(err, gizmo.ErrModuleNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// ServeProcedures lists the latest versions of all stored procedures.
+func (api *APIv2) ServeProcedures(w http.ResponseWriter, r *http.Request) {
+	list, err := api.procs.List(r.Context())
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set(hdrContentType, contentTypeJSON)
+	json.NewEncoder(w).Encode(list)
+}
+
+// ServeProcedure responds with the source of the stored procedure.
+// The latest version is returned, unless the version is set in the query.
+func (api *APIv2) ServeProcedure(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	name, vers, err := procedureParams(r, params)
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	mod, err := api.procs.Get(r.Context(), name, vers)
+	if err != nil {
+		jsonResponse(w, procedureErrorStatus(err), err)
+		return
+	}
+	w.Header().Set(hdrContentType, contentTypeJSON)
+	json.NewEncoder(w).Encode(mod)
+}
+
+// ServeProcedureSave stores a new version of the procedure. The request body is the Gizmo source of the module.
+func (api *APIv2) ServeProcedureSave(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	defer r.Body.Close()
+	if api.ro {
+		jsonResponse(w, http.StatusForbidden, errors.New("database is read-only"))
+		return
+	}
+	name, vers, err := procedureParams(r, params)
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	} else if vers != 0 {
+		jsonResponse(w, http.StatusBadRequest, errors.New("version is assigned automatically"))
+		return
+	}
+	data, err := readLimit(r.Body)
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	mod, err := api.procs.Save(r.Context(), name, string(data))
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set(hdrContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(gizmo.Module{Name: mod.Name, Version: mod.Version})
+}
+
+// ServeProcedureDelete removes all versions of the procedure.
+func (api *APIv2) ServeProcedureDelete(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	if api.ro {
+		jsonResponse(w, http.StatusForbidden, errors.New("database is read-only"))
+		return
+	}
+	name, vers, err := procedureParams(r, params)
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	} else if vers != 0 {
+		jsonResponse(w, http.StatusBadRequest, errors.New("cannot delete a single version"))
+		return
+	}
+	ok, err := api.procs.Delete(r.Context(), name)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, err)
+		return
+	} else if !ok {
+		jsonResponse(w, http.StatusNotFound, fmt.Errorf("procedure is not found: %q", name))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeProcedureCall calls the procedure with parameters from the JSON request body and responds with the results.
+// The latest version is called, unless the version is set in the query.
+func (api *APIv2) ServeProcedureCall(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	defer r.Body.Close()
+	ctx, cancel := api.queryContext(r)
+	defer cancel()
+	name, vers, err := procedureParams(r, params)
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	data, err := readLimit(r.Body)
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	var args interface{}
+	if len(strings.TrimSpace(string(data))) != 0 {
+		if err = json.Unmarshal(data, &args); err != nil {
+			jsonResponse(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	h, err := api.handleForRequest(r)
+	if err != nil {
+		jsonResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	ns, err := api.ns.Namespaces(ctx)
+	if err != nil {
+		jsonResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	opt := query.Options{
+		Collation:  query.JSON,
+		Limit:      api.limit,
+		Namespaces: ns,
+	}
+	if p, ok := PrincipalFromContext(r.Context()); ok && p != nil {
+		// module state must not leak between principals with different scopes
+		ctx = gizmo.WithScope(ctx, p.Name)
+	}
+	out, err := api.procs.Call(ctx, api.queryHandle(r, h), name, vers, args, opt)
+	if err != nil {
+		jsonResponse(w, procedureErrorStatus(err), err)
+		return
+	}
+	w.Header().Set(hdrContentType, contentTypeJSON)
+	writeResults(w, out)
+}
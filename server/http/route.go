@@ -0,0 +1,103 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cayleyhttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type routeHookKey struct{}
+
+// RouteHook is called with the route pattern of a handler that serves the request.
+// It returns a handler to call instead, for example to limit or to measure requests to the route.
+//
+// Hooks are called for each router the request is dispatched by. Requests to named databases
+// call it for DatabaseRoute first, and then for the route of the database API.
+type RouteHook func(route string, h httprouter.Handle) httprouter.Handle
+
+// WithRouteHook returns a copy of the context with a route hook added.
+// Hooks that were added earlier wrap handlers returned by later ones.
+func WithRouteHook(ctx context.Context, hook RouteHook) context.Context {
+	if prev, ok := ctx.Value(routeHookKey{}).(RouteHook); ok {
+		next := hook
+		hook = func(route string, h httprouter.Handle) httprouter.Handle {
+			return prev(route, next(route, h))
+		}
+	}
+	return context.WithValue(ctx, routeHookKey{}, hook)
+}
+
+// Route wraps a handler registered on a given route pattern to pass the pattern to the route hook of the request.
+func Route(route string, h httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if hook, ok := r.Context().Value(routeHookKey{}).(RouteHook); ok {
+			hook(route, h)(w, r, params)
+			return
+		}
+		h(w, r, params)
+	}
+}
+
+// Routes registers handlers on the router together with their route patterns. See Route.
+type Routes struct {
+	*httprouter.Router
+}
+
+// Handle registers a handler for a given method and route pattern.
+func (r Routes) Handle(method, route string, h httprouter.Handle) {
+	r.Router.Handle(method, route, Route(route, h))
+}
+
+// HandlerFunc registers a http.HandlerFunc for a given method and route pattern.
+func (r Routes) HandlerFunc(method, route string, h http.HandlerFunc) {
+	r.Handle(method, route, func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		h(w, req)
+	})
+}
+
+// GET is a shortcut for Handle(http.MethodGet, route, h).
+func (r Routes) GET(route string, h httprouter.Handle) {
+	r.Handle(http.MethodGet, route, h)
+}
+
+// POST is a shortcut for Handle(http.MethodPost, route, h).
+func (r Routes) POST(route string, h httprouter.Handle) {
+	r.Handle(http.MethodPost, route, h)
+}
+
+// PUT is a shortcut for Handle(http.MethodPut, route, h).
+func (r Routes) PUT(route string, h httprouter.Handle) {
+	r.Handle(http.MethodPut, route, h)
+}
+
+// DELETE is a shortcut for Handle(http.MethodDelete, route, h).
+func (r Routes) DELETE(route string, h httprouter.Handle) {
+	r.Handle(http.MethodDelete, route, h)
+}
+
+// OPTIONS is a shortcut for Handle(http.MethodOptions, route, h).
+func (r Routes) OPTIONS(route string, h httprouter.Handle) {
+	r.Handle(http.MethodOptions, route, h)
+}
+
+// Wrap returns a handler that adds the hook to the context of each request.
+func (hook RouteHook) Wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(WithRouteHook(r.Context(), hook)))
+	})
+}
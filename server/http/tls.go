@@ -0,0 +1,209 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cayleyhttp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cayleygraph/cayley/clog"
+)
+
+// Modes of client certificate verification.
+const (
+	// ClientAuthRequire rejects clients without a valid certificate.
+	ClientAuthRequire = "require"
+	// ClientAuthOptional verifies client certificates, if they are sent.
+	ClientAuthOptional = "optional"
+)
+
+// TLSConfig is a configuration of TLS for the server.
+type TLSConfig struct {
+	// CertFile and KeyFile are paths to PEM-encoded certificate chain and private key of the server.
+	// Both files are reloaded when they change.
+	CertFile string `mapstructure:"cert_file" json:"cert_file"`
+	KeyFile  string `mapstructure:"key_file" json:"key_file"`
+	// MinVersion is the minimal TLS version accepted by the server: 1.0, 1.1, 1.2 or 1.3. Defaults to 1.2.
+	MinVersion string `mapstructure:"min_version" json:"min_version"`
+	// ClientCA is a path to PEM-encoded bundle of CA certificates used to verify client certificates.
+	ClientCA string `mapstructure:"client_ca" json:"client_ca"`
+	// ClientAuth is the mode of client certificate verification: require (default) or optional.
+	ClientAuth string `mapstructure:"client_auth" json:"client_auth"`
+}
+
+// Enabled checks if TLS is configured.
+func (c *TLSConfig) Enabled() bool {
+	return c != nil && (c.CertFile != "" || c.KeyFile != "")
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewTLSConfig creates a TLS config for the server. Certificate files are checked for changes at most once per second.
+func NewTLSConfig(c TLSConfig) (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.New("both certificate and key files must be set")
+	}
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.MinVersion != "" {
+		v, ok := tlsVersions[strings.TrimPrefix(c.MinVersion, "TLS")]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS version: %q", c.MinVersion)
+		}
+		conf.MinVersion = v
+	}
+	if c.ClientCA != "" {
+		data, err := os.ReadFile(c.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", c.ClientCA)
+		}
+		conf.ClientCAs = pool
+		switch c.ClientAuth {
+		case "", ClientAuthRequire:
+			conf.ClientAuth = tls.RequireAndVerifyClientCert
+		case ClientAuthOptional:
+			conf.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("unsupported client auth mode: %q", c.ClientAuth)
+		}
+	} else if c.ClientAuth != "" {
+		return nil, errors.New("client CA must be set to verify client certificates")
+	}
+	r := &certReloader{certFile: c.CertFile, keyFile: c.KeyFile, interval: time.Second}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	conf.GetCertificate = r.GetCertificate
+	return conf, nil
+}
+
+// certReloader reloads the certificate when files change.
+type certReloader struct {
+	certFile, keyFile string
+	interval          time.Duration
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	mod     [2]time.Time
+	checked time.Time
+}
+
+func (r *certReloader) modTimes() ([2]time.Time, error) {
+	var mod [2]time.Time
+	for i, name := range []string{r.certFile, r.keyFile} {
+		st, err := os.Stat(name)
+		if err != nil {
+			return mod, err
+		}
+		mod[i] = st.ModTime()
+	}
+	return mod, nil
+}
+
+func (r *certReloader) load() error {
+	mod, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert, r.mod = &cert, mod
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate. If new files cannot be loaded, the previous certificate is used.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if now.Sub(r.checked) < r.interval {
+		return r.cert, nil
+	}
+	r.checked = now
+	if mod, err := r.modTimes(); err == nil && mod == r.mod {
+		return r.cert, nil
+	}
+	if err := r.load(); err != nil {
+		clog.Errorf("cannot reload TLS certificate: %v", err)
+	} else {
+		clog.Infof("reloaded TLS certificate from %s", r.certFile)
+	}
+	return r.cert, nil
+}
+
+// ClientCertificate returns a verified certificate of the client, or nil if the client has not sent one.
+func ClientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+// ClientCertConfig configures authentication with verified client certificates.
+// The principal name is the common name of the certificate subject.
+type ClientCertConfig struct {
+	// Roles is a list of roles for all verified clients.
+	Roles []string `mapstructure:"roles" json:"roles"`
+	// Names maps common names to the list of additional roles.
+	Names map[string][]string `mapstructure:"names" json:"names"`
+}
+
+// ClientCerts authenticates requests with verified client certificates.
+type ClientCerts struct {
+	roles []string
+	names map[string][]string
+}
+
+// NewClientCerts creates an authenticator for a given config.
+func NewClientCerts(c ClientCertConfig) *ClientCerts {
+	a := &ClientCerts{roles: c.Roles, names: make(map[string][]string, len(c.Names))}
+	for name, roles := range c.Names {
+		// config keys are case-insensitive
+		a.names[strings.ToLower(name)] = roles
+	}
+	return a
+}
+
+// Authenticate implements Authenticator.
+func (a *ClientCerts) Authenticate(r *http.Request) (string, []string, error) {
+	cert := ClientCertificate(r)
+	if cert == nil {
+		return "", nil, nil
+	}
+	name := cert.Subject.CommonName
+	if name == "" {
+		return "", nil, ErrInvalidCredentials
+	}
+	roles := append([]string{}, a.roles...)
+	roles = append(roles, a.names[strings.ToLower(name)]...)
+	return name, roles, nil
+}
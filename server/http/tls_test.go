@@ -0,0 +1,173 @@
+package cayleyhttp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/client"
+)
+
+type testCA struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	serial int64
+}
+
+func newTestCA(t testing.TB) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, serial: 1}
+}
+
+func writePEM(t testing.TB, path, typ string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600))
+}
+
+// issue writes a certificate signed by the CA and its key to files with a given prefix.
+func (ca *testCA) issue(t testing.TB, prefix, name string, usage x509.ExtKeyUsage) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ca.serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	kder, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile, keyFile = prefix+".crt", prefix+".key"
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", kder)
+	return certFile, keyFile
+}
+
+func TestTLSClientCert(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.crt")
+	writePEM(t, caFile, "CERTIFICATE", ca.cert.Raw)
+	srvCert, srvKey := ca.issue(t, filepath.Join(dir, "server"), "server", x509.ExtKeyUsageServerAuth)
+	aliceCert, aliceKey := ca.issue(t, filepath.Join(dir, "alice"), "alice", x509.ExtKeyUsageClientAuth)
+
+	_, err := NewTLSConfig(TLSConfig{CertFile: srvCert, KeyFile: srvKey, MinVersion: "1.4"})
+	require.Error(t, err)
+	conf, err := NewTLSConfig(TLSConfig{
+		CertFile:   srvCert,
+		KeyFile:    srvKey,
+		ClientCA:   caFile,
+		ClientAuth: ClientAuthOptional,
+	})
+	require.NoError(t, err)
+
+	auth, err := NewAuth(AuthConfig{
+		ClientCert: &ClientCertConfig{
+			Roles: []string{RoleRead},
+			Names: map[string][]string{"Alice": {RoleWrite}},
+		},
+	})
+	require.NoError(t, err)
+	h := auth.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := PrincipalFromContext(r.Context())
+		fmt.Fprintf(w, "%s %v", p.Name, p.Can(PermWrite))
+	}))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{Handler: h, TLSConfig: conf}
+	go srv.ServeTLS(lis, "", "")
+	t.Cleanup(func() { srv.Close() })
+	addr := "https://" + lis.Addr().String()
+
+	get := func(o client.TLSOptions) (string, error) {
+		cli, err := client.NewHTTPClient(o)
+		require.NoError(t, err)
+		resp, err := cli.Get(addr)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		return string(data), err
+	}
+
+	// server certificate is not trusted
+	_, err = get(client.TLSOptions{})
+	require.Error(t, err)
+
+	out, err := get(client.TLSOptions{CAFile: caFile})
+	require.NoError(t, err)
+	require.Equal(t, " false", out)
+
+	out, err = get(client.TLSOptions{CAFile: caFile, CertFile: aliceCert, KeyFile: aliceKey})
+	require.NoError(t, err)
+	require.Equal(t, "alice true", out)
+
+	// certificates signed by other CAs are rejected
+	other := newTestCA(t)
+	bobCert, bobKey := other.issue(t, filepath.Join(dir, "bob"), "bob", x509.ExtKeyUsageClientAuth)
+	_, err = get(client.TLSOptions{CAFile: caFile, CertFile: bobCert, KeyFile: bobKey})
+	require.Error(t, err)
+}
+
+func TestTLSReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, filepath.Join(dir, "server"), "server", x509.ExtKeyUsageServerAuth)
+
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	require.NoError(t, r.load())
+	serial := func() int64 {
+		cert, err := r.GetCertificate(nil)
+		require.NoError(t, err)
+		c, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return c.SerialNumber.Int64()
+	}
+	require.Equal(t, int64(2), serial())
+
+	ca.issue(t, filepath.Join(dir, "server"), "server", x509.ExtKeyUsageServerAuth)
+	next := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, next, next))
+	require.NoError(t, os.Chtimes(keyFile, next, next))
+	require.Equal(t, int64(3), serial())
+
+	// invalid files are ignored
+	require.NoError(t, os.WriteFile(keyFile, []byte("invalid"), 0600))
+	next = next.Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, next, next))
+	require.Equal(t, int64(3), serial())
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/cayleygraph/cayley/clog"
+)
+
+var _ Exporter = (*WriterExporter)(nil)
+
+// WriterExporter writes spans as JSON lines.
+type WriterExporter struct {
+	mu  sync.Mutex
+	c   io.Closer
+	enc *json.Encoder
+}
+
+// NewWriterExporter creates an exporter that writes spans to w as JSON lines.
+// If w is an io.Closer, it will be closed when the exporter is closed.
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	e := &WriterExporter{enc: json.NewEncoder(w)}
+	e.c, _ = w.(io.Closer)
+	return e
+}
+
+// NewFileExporter creates an exporter that appends spans to a file, one JSON object per line.
+func NewFileExporter(path string) (*WriterExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterExporter(f), nil
+}
+
+// ExportSpan implements Exporter.
+func (e *WriterExporter) ExportSpan(s *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.enc.Encode(s); err != nil {
+		clog.Errorf("cannot export span: %v", err)
+	}
+}
+
+// Close closes the underlying writer.
+func (e *WriterExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.c == nil {
+		return nil
+	}
+	return e.c.Close()
+}
@@ -0,0 +1,283 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trace provides span-based tracing for cayley packages.
+//
+// Spans are recorded only if an exporter is set with SetExporter. Trace context is propagated
+// over HTTP using W3C Trace Context traceparent header.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Header is the name of the HTTP header used to propagate trace context.
+const Header = "traceparent"
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid checks if the ID is not zero.
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanID identifies a span within the trace.
+type SpanID [8]byte
+
+// IsValid checks if the ID is not zero.
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext is a part of the span that is propagated to child spans and other processes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid checks if both trace and span IDs are set.
+func (c SpanContext) IsValid() bool {
+	return c.TraceID.IsValid() && c.SpanID.IsValid()
+}
+
+// Traceparent encodes the span context as a value of traceparent header.
+func (c SpanContext) Traceparent() string {
+	flags := "00"
+	if c.Sampled {
+		flags = "01"
+	}
+	return "00-" + c.TraceID.String() + "-" + c.SpanID.String() + "-" + flags
+}
+
+// ErrInvalidTraceparent is returned when the traceparent header cannot be parsed.
+var ErrInvalidTraceparent = errors.New("trace: invalid traceparent")
+
+// ParseTraceparent decodes a span context from the value of traceparent header.
+func ParseTraceparent(s string) (SpanContext, error) {
+	var c SpanContext
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return c, ErrInvalidTraceparent
+	} else if parts[0] == "00" && len(parts) != 4 {
+		return c, ErrInvalidTraceparent
+	}
+	if len(parts[1]) != 2*len(c.TraceID) || len(parts[2]) != 2*len(c.SpanID) || len(parts[3]) != 2 {
+		return c, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(c.TraceID[:], []byte(parts[1])); err != nil {
+		return c, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(c.SpanID[:], []byte(parts[2])); err != nil {
+		return c, ErrInvalidTraceparent
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return c, ErrInvalidTraceparent
+	}
+	if !c.IsValid() {
+		return c, ErrInvalidTraceparent
+	}
+	c.Sampled = flags[0]&1 != 0
+	return c, nil
+}
+
+// SpanData is a finished span, as passed to the exporter.
+type SpanData struct {
+	Name     string                 `json:"name"`
+	TraceID  string                 `json:"trace_id"`
+	SpanID   string                 `json:"span_id"`
+	ParentID string                 `json:"parent_id,omitempty"`
+	Start    time.Time              `json:"start"`
+	End      time.Time              `json:"end"`
+	Duration time.Duration          `json:"duration_ns"`
+	Attrs    map[string]interface{} `json:"attrs,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// Exporter receives finished spans. It must be safe for concurrent use.
+type Exporter interface {
+	ExportSpan(s *SpanData)
+}
+
+var exporter struct {
+	sync.RWMutex
+	e Exporter
+}
+
+// SetExporter sets the exporter for finished spans. Tracing is disabled if it is nil.
+func SetExporter(e Exporter) {
+	exporter.Lock()
+	exporter.e = e
+	exporter.Unlock()
+}
+
+func getExporter() Exporter {
+	exporter.RLock()
+	e := exporter.e
+	exporter.RUnlock()
+	return e
+}
+
+// Enabled checks if spans are recorded.
+func Enabled() bool {
+	return getExporter() != nil
+}
+
+// Span is a single traced operation. All methods are safe to call on a nil span.
+type Span struct {
+	ctx    SpanContext
+	parent SpanID
+	name   string
+	start  time.Time
+
+	mu    sync.Mutex
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// FromContext returns the current span, or nil if there is none.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// ContextWithSpan returns a context that contains a given span. Nil spans are ignored.
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// ContextWithRemoteParent returns a context with a span context received from another process.
+// Spans started from this context will become its children.
+func ContextWithRemoteParent(ctx context.Context, c SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, c)
+}
+
+// SpanContextFromContext returns the context of the current span, or a remote parent span.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if s := FromContext(ctx); s != nil {
+		return s.ctx
+	}
+	c, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return c
+}
+
+func randomID(p []byte) {
+	if _, err := rand.Read(p); err != nil {
+		panic(fmt.Errorf("trace: cannot generate ID: %v", err))
+	}
+}
+
+// Start creates a new span as a child of the current one and returns a context that contains it.
+// Caller must call End on the span.
+//
+// If tracing is disabled, or the parent is not sampled, it returns the same context and a nil span.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	if !Enabled() {
+		return ctx, nil
+	}
+	parent := SpanContextFromContext(ctx)
+	s := &Span{name: name, start: time.Now()}
+	if parent.IsValid() {
+		if !parent.Sampled {
+			return ctx, nil
+		}
+		s.ctx.TraceID = parent.TraceID
+		s.parent = parent.SpanID
+	} else {
+		randomID(s.ctx.TraceID[:])
+	}
+	randomID(s.ctx.SpanID[:])
+	s.ctx.Sampled = true
+	return ContextWithSpan(ctx, s), s
+}
+
+// Context returns the span context that can be propagated to other processes.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.ctx
+}
+
+// SetAttr sets an attribute of the span.
+func (s *Span) SetAttr(key string, val interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.attrs == nil {
+		s.attrs = make(map[string]interface{})
+	}
+	s.attrs[key] = val
+	s.mu.Unlock()
+}
+
+// SetError marks the span as failed. Nil errors are ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// End finishes the span and passes it to the exporter. Only the first call has an effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	d := &SpanData{
+		Name:     s.name,
+		TraceID:  s.ctx.TraceID.String(),
+		SpanID:   s.ctx.SpanID.String(),
+		Start:    s.start,
+		End:      end,
+		Duration: end.Sub(s.start),
+		Attrs:    s.attrs,
+	}
+	if s.parent.IsValid() {
+		d.ParentID = s.parent.String()
+	}
+	if s.err != nil {
+		d.Error = s.err.Error()
+	}
+	s.mu.Unlock()
+	if e := getExporter(); e != nil {
+		e.ExportSpan(d)
+	}
+}
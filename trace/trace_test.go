@@ -0,0 +1,85 @@
+package trace_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/trace"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const tp = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	c, err := trace.ParseTraceparent(tp)
+	require.NoError(t, err)
+	require.True(t, c.Sampled)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", c.TraceID.String())
+	require.Equal(t, "00f067aa0ba902b7", c.SpanID.String())
+	require.Equal(t, tp, c.Traceparent())
+
+	for _, s := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-ext",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-zzf067aa0ba902b7-01",
+	} {
+		_, err = trace.ParseTraceparent(s)
+		require.Equal(t, trace.ErrInvalidTraceparent, err, s)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	ctx := context.Background()
+
+	// no exporter - no spans
+	_, s := trace.Start(ctx, "noop")
+	require.Nil(t, s)
+	s.SetAttr("k", "v")
+	s.End()
+
+	buf := bytes.NewBuffer(nil)
+	trace.SetExporter(trace.NewWriterExporter(buf))
+	defer trace.SetExporter(nil)
+
+	remote, err := trace.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	ctx = trace.ContextWithRemoteParent(ctx, remote)
+
+	pctx, parent := trace.Start(ctx, "parent")
+	require.NotNil(t, parent)
+	_, child := trace.Start(pctx, "child")
+	child.SetAttr("n", 1)
+	child.SetError(errors.New("failed"))
+	child.End()
+	parent.End()
+	parent.End() // ignored
+
+	_, unsampled := trace.Start(trace.ContextWithRemoteParent(context.Background(), trace.SpanContext{
+		TraceID: remote.TraceID, SpanID: remote.SpanID,
+	}), "unsampled")
+	require.Nil(t, unsampled)
+
+	var spans []trace.SpanData
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var s trace.SpanData
+		require.NoError(t, dec.Decode(&s))
+		spans = append(spans, s)
+	}
+	require.Len(t, spans, 2)
+	c, p := spans[0], spans[1]
+	require.Equal(t, "child", c.Name)
+	require.Equal(t, "parent", p.Name)
+	require.Equal(t, remote.TraceID.String(), p.TraceID)
+	require.Equal(t, remote.TraceID.String(), c.TraceID)
+	require.Equal(t, remote.SpanID.String(), p.ParentID)
+	require.Equal(t, p.SpanID, c.ParentID)
+	require.Equal(t, "failed", c.Error)
+	require.Equal(t, map[string]interface{}{"n": 1.0}, c.Attrs)
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/jsonld"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/xsd"
+
+	"github.com/cayleygraph/cayley/validate/shacl"
+)
+
+// Result is a single result of the validation.
+type Result struct {
+	Focus     quad.Value
+	Path      quad.Value
+	Inverse   bool
+	Value     quad.Value
+	Shape     quad.Value
+	Component quad.IRI
+	Severity  quad.IRI
+	Message   string
+}
+
+// String returns a human-readable description of the result.
+func (r Result) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.Focus.String())
+	if r.Path != nil {
+		if r.Inverse {
+			sb.WriteString(" ^")
+		} else {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(r.Path.String())
+	}
+	if r.Value != nil {
+		sb.WriteString(" ")
+		sb.WriteString(r.Value.String())
+	}
+	sb.WriteString(": ")
+	sb.WriteString(r.Message)
+	return sb.String()
+}
+
+// Report is a SHACL validation report.
+type Report struct {
+	// Conforms is set if there are no results with sh:Violation severity.
+	Conforms bool
+	Results  []Result
+}
+
+func (r *Report) add(res ...Result) {
+	for _, v := range res {
+		if norm(v.Severity) == quad.IRI(shacl.Violation) {
+			r.Conforms = false
+		}
+	}
+	r.Results = append(r.Results, res...)
+}
+
+// Quads returns the report in the form of a SHACL validation report graph.
+func (r *Report) Quads() []quad.Quad {
+	var (
+		report = quad.BNode("report")
+		typ    = quad.IRI(rdf.Type).Full()
+		out    []quad.Quad
+	)
+	add := func(s quad.Value, p string, o quad.Value) {
+		out = append(out, quad.Quad{Subject: s, Predicate: quad.IRI(p).Full(), Object: full(o)})
+	}
+	out = append(out, quad.Quad{Subject: report, Predicate: typ, Object: quad.IRI(shacl.ValidationReport).Full()})
+	add(report, shacl.Conforms, quad.TypedString{Value: quad.String(strconv.FormatBool(r.Conforms)), Type: quad.IRI(xsd.Boolean).Full()})
+	for i, res := range r.Results {
+		b := quad.BNode(fmt.Sprintf("result%d", i))
+		add(report, shacl.Result, b)
+		out = append(out, quad.Quad{Subject: b, Predicate: typ, Object: quad.IRI(shacl.ValidationResult).Full()})
+		add(b, shacl.FocusNode, res.Focus)
+		if res.Path != nil {
+			if res.Inverse {
+				p := quad.BNode(fmt.Sprintf("result%dpath", i))
+				add(b, shacl.ResultPath, p)
+				add(p, shacl.InversePath, res.Path)
+			} else {
+				add(b, shacl.ResultPath, res.Path)
+			}
+		}
+		if res.Value != nil {
+			add(b, shacl.Value, res.Value)
+		}
+		add(b, shacl.SourceShape, res.Shape)
+		add(b, shacl.SourceConstraintComponent, res.Component)
+		add(b, shacl.ResultSeverity, res.Severity)
+		if res.Message != "" {
+			add(b, shacl.ResultMessage, quad.String(res.Message))
+		}
+	}
+	return out
+}
+
+// WriteJSONLD writes the report as JSON-LD.
+func (r *Report) WriteJSONLD(w io.Writer) error {
+	jw := jsonld.NewWriter(w)
+	if _, err := jw.WriteQuads(r.Quads()); err != nil {
+		return err
+	}
+	return jw.Close()
+}
+
+// full converts IRIs and datatypes of literals to the full form.
+func full(v quad.Value) quad.Value {
+	switch v := v.(type) {
+	case quad.IRI:
+		return v.Full()
+	case quad.String, quad.LangString, quad.BNode:
+		return v
+	case quad.TypedString:
+		v.Type = v.Type.Full()
+		return v
+	case quad.TypedStringer:
+		ts := v.TypedString()
+		ts.Type = ts.Type.Full()
+		return ts
+	}
+	return v
+}
+
+// ValidationError is returned by the Writer when the changes don't conform to the shapes.
+type ValidationError struct {
+	Report *Report
+}
+
+func (e *ValidationError) Error() string {
+	var (
+		n     int
+		first string
+	)
+	for _, r := range e.Report.Results {
+		if norm(r.Severity) == quad.IRI(shacl.Violation) {
+			if n == 0 {
+				first = r.String()
+			}
+			n++
+		}
+	}
+	if n == 1 {
+		return "validate: changes do not conform to shapes: " + first
+	}
+	return fmt.Sprintf("validate: changes do not conform to shapes: %s (and %d more)", first, n-1)
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shacl contains constants of the Shapes Constraint Language (SHACL) https://www.w3.org/TR/shacl/
+package shacl
+
+import "github.com/cayleygraph/quad/voc"
+
+func init() {
+	voc.RegisterPrefix(Prefix, NS)
+}
+
+const (
+	NS     = "http://www.w3.org/ns/shacl#"
+	Prefix = "sh:"
+)
+
+// Shapes
+const (
+	NodeShape     = Prefix + "NodeShape"
+	PropertyShape = Prefix + "PropertyShape"
+
+	TargetClass      = Prefix + "targetClass"
+	TargetNode       = Prefix + "targetNode"
+	TargetSubjectsOf = Prefix + "targetSubjectsOf"
+	TargetObjectsOf  = Prefix + "targetObjectsOf"
+
+	Property    = Prefix + "property"
+	Path        = Prefix + "path"
+	InversePath = Prefix + "inversePath"
+	Deactivated = Prefix + "deactivated"
+	Message     = Prefix + "message"
+	Severity    = Prefix + "severity"
+)
+
+// Constraint parameters
+const (
+	MinCount = Prefix + "minCount"
+	MaxCount = Prefix + "maxCount"
+	Datatype = Prefix + "datatype"
+	Class    = Prefix + "class"
+	Pattern  = Prefix + "pattern"
+	Flags    = Prefix + "flags"
+	In       = Prefix + "in"
+	Node     = Prefix + "node"
+)
+
+// Constraint components
+const (
+	MinCountConstraintComponent = Prefix + "MinCountConstraintComponent"
+	MaxCountConstraintComponent = Prefix + "MaxCountConstraintComponent"
+	DatatypeConstraintComponent = Prefix + "DatatypeConstraintComponent"
+	ClassConstraintComponent    = Prefix + "ClassConstraintComponent"
+	PatternConstraintComponent  = Prefix + "PatternConstraintComponent"
+	InConstraintComponent       = Prefix + "InConstraintComponent"
+	NodeConstraintComponent     = Prefix + "NodeConstraintComponent"
+)
+
+// Validation report
+const (
+	ValidationReport          = Prefix + "ValidationReport"
+	ValidationResult          = Prefix + "ValidationResult"
+	Conforms                  = Prefix + "conforms"
+	Result                    = Prefix + "result"
+	FocusNode                 = Prefix + "focusNode"
+	ResultPath                = Prefix + "resultPath"
+	Value                     = Prefix + "value"
+	SourceShape               = Prefix + "sourceShape"
+	SourceConstraintComponent = Prefix + "sourceConstraintComponent"
+	ResultSeverity            = Prefix + "resultSeverity"
+	ResultMessage             = Prefix + "resultMessage"
+
+	Violation = Prefix + "Violation"
+	Warning   = Prefix + "Warning"
+	Info      = Prefix + "Info"
+)
@@ -0,0 +1,392 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package validate implements validation of the graph against SHACL shapes.
+//
+// Supported features are node and property shapes with predicate and inverse paths, all kinds of targets,
+// and sh:minCount, sh:maxCount, sh:datatype, sh:class, sh:pattern, sh:in and sh:node constraints.
+package validate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/validate/shacl"
+)
+
+// Shape is a SHACL node or property shape.
+type Shape struct {
+	// ID is the IRI or the blank node of the shape.
+	ID quad.Value
+	// Path is the predicate of a property shape; it's nil for node shapes.
+	Path quad.Value
+	// Inverse is set if the path of a property shape is sh:inversePath.
+	Inverse bool
+
+	TargetClass      []quad.Value
+	TargetNode       []quad.Value
+	TargetSubjectsOf []quad.Value
+	TargetObjectsOf  []quad.Value
+
+	// Properties are property shapes of the shape.
+	Properties []*Shape
+
+	// MinCount is the minimal number of values of the path; zero means no limit.
+	MinCount int
+	// MaxCount is the maximal number of values of the path; negative value means no limit.
+	MaxCount int
+	Datatype quad.IRI
+	Class    []quad.Value
+	Pattern  *regexp.Regexp
+	In       []quad.Value
+	Node     []*Shape
+
+	Message  string
+	Severity quad.IRI
+}
+
+// IsProperty checks whether the shape is a property shape.
+func (s *Shape) IsProperty() bool {
+	return s.Path != nil
+}
+
+func (s *Shape) hasTargets() bool {
+	return len(s.TargetClass)+len(s.TargetNode)+len(s.TargetSubjectsOf)+len(s.TargetObjectsOf) > 0
+}
+
+// Shapes is a set of SHACL shapes loaded from a shapes graph.
+type Shapes struct {
+	// Targeted are the shapes that have at least one target.
+	Targeted []*Shape
+	byID     map[quad.Value]*Shape
+
+	// mu serializes validation and application of changes by writers that use the shapes.
+	mu sync.Mutex
+}
+
+// Shape returns the shape by its ID, or nil if it doesn't exist.
+func (s *Shapes) Shape(id quad.Value) *Shape {
+	return s.byID[norm(id)]
+}
+
+// Len returns the number of loaded shapes.
+func (s *Shapes) Len() int {
+	return len(s.byID)
+}
+
+// norm converts IRIs of known vocabularies to the short form, for values to be compared.
+func norm(v quad.Value) quad.Value {
+	if iri, ok := v.(quad.IRI); ok {
+		return iri.Short()
+	}
+	return v
+}
+
+// forms returns all forms of the value that can be stored in the graph.
+func forms(v quad.Value) []quad.Value {
+	if iri, ok := v.(quad.IRI); ok {
+		if s, f := iri.Short(), iri.Full(); s != f {
+			return []quad.Value{s, f}
+		}
+	}
+	return []quad.Value{v}
+}
+
+// index is an in-memory index of quads of the shapes graph.
+type index map[quad.Value]map[quad.Value][]quad.Value
+
+func (x index) add(q quad.Quad) {
+	s, p := norm(q.Subject), norm(q.Predicate)
+	m := x[s]
+	if m == nil {
+		m = make(map[quad.Value][]quad.Value)
+		x[s] = m
+	}
+	o := norm(q.Object)
+	for _, v := range m[p] {
+		if v == o {
+			return
+		}
+	}
+	m[p] = append(m[p], o)
+}
+
+func (x index) objects(s quad.Value, p string) []quad.Value {
+	return x[s][quad.IRI(p)]
+}
+
+func (x index) object(s quad.Value, p string) quad.Value {
+	if vals := x.objects(s, p); len(vals) != 0 {
+		return vals[0]
+	}
+	return nil
+}
+
+// LoadShapes loads SHACL shapes from quads of the shapes graph.
+//
+// IRIs of the SHACL vocabulary are recognized both in the full and the short form.
+func LoadShapes(quads []quad.Quad) (*Shapes, error) {
+	x := make(index)
+	for _, q := range quads {
+		x.add(q)
+	}
+	l := &loader{x: x, shapes: &Shapes{byID: make(map[quad.Value]*Shape)}}
+	// collect all the nodes that are shapes by the SHACL definition
+	roots := make(map[quad.Value]struct{})
+	for s, m := range x {
+		for _, t := range m[quad.IRI(rdf.Type)] {
+			if t == quad.IRI(shacl.NodeShape) || t == quad.IRI(shacl.PropertyShape) {
+				roots[s] = struct{}{}
+			}
+		}
+		for _, p := range []string{shacl.TargetClass, shacl.TargetNode, shacl.TargetSubjectsOf, shacl.TargetObjectsOf} {
+			if len(m[quad.IRI(p)]) != 0 {
+				roots[s] = struct{}{}
+			}
+		}
+		for _, o := range m[quad.IRI(shacl.Node)] {
+			roots[o] = struct{}{}
+		}
+	}
+	ids := make([]quad.Value, 0, len(roots))
+	for id := range roots {
+		ids = append(ids, id)
+	}
+	// make the order of the results stable
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	for _, id := range ids {
+		sh, err := l.shape(id)
+		if err != nil {
+			return nil, err
+		}
+		if sh.hasTargets() {
+			l.shapes.Targeted = append(l.shapes.Targeted, sh)
+		}
+	}
+	return l.shapes, nil
+}
+
+// ReadShapes loads SHACL shapes from a quad reader.
+func ReadShapes(r quad.Reader) (*Shapes, error) {
+	quads, err := quad.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return LoadShapes(quads)
+}
+
+// shapePredicates are followed when collecting quads of the shapes graph from the store.
+var shapePredicates = []string{
+	shacl.Property, shacl.Path, shacl.Node, shacl.In, rdf.First, rdf.Rest,
+}
+
+// LoadShapesFrom loads SHACL shapes stored in the quad store.
+func LoadShapesFrom(ctx context.Context, qs graph.QuadStore) (*Shapes, error) {
+	src := storeSource{qs: qs}
+	var roots []quad.Value
+	for _, t := range []string{shacl.NodeShape, shacl.PropertyShape} {
+		vals, err := src.subjects(ctx, quad.IRI(rdf.Type), quad.IRI(t))
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, vals...)
+	}
+	for _, p := range []string{shacl.TargetClass, shacl.TargetNode, shacl.TargetSubjectsOf, shacl.TargetObjectsOf} {
+		vals, err := src.all(ctx, quad.IRI(p), true)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, vals...)
+	}
+	var (
+		quads []quad.Quad
+		seen  = make(map[quad.Value]struct{})
+	)
+	for len(roots) > 0 {
+		s := norm(roots[0])
+		roots = roots[1:]
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		sq, err := src.quads(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		quads = append(quads, sq...)
+		for _, q := range sq {
+			for _, p := range shapePredicates {
+				if norm(q.Predicate) == quad.IRI(p) {
+					roots = append(roots, q.Object)
+				}
+			}
+		}
+	}
+	return LoadShapes(quads)
+}
+
+type loader struct {
+	x      index
+	shapes *Shapes
+}
+
+func (l *loader) shape(id quad.Value) (*Shape, error) {
+	if sh := l.shapes.byID[id]; sh != nil {
+		return sh, nil
+	}
+	x := l.x
+	sh := &Shape{
+		ID:               id,
+		TargetClass:      x.objects(id, shacl.TargetClass),
+		TargetNode:       x.objects(id, shacl.TargetNode),
+		TargetSubjectsOf: x.objects(id, shacl.TargetSubjectsOf),
+		TargetObjectsOf:  x.objects(id, shacl.TargetObjectsOf),
+		Class:            x.objects(id, shacl.Class),
+		MaxCount:         -1,
+		Severity:         shacl.Violation,
+	}
+	// register the shape before loading nested shapes, since they may refer to it
+	l.shapes.byID[id] = sh
+	for _, t := range x.objects(id, rdf.Type) {
+		if t == quad.IRI(rdfs.Class) {
+			// implicit class target
+			sh.TargetClass = append(sh.TargetClass, id)
+		}
+	}
+	if p := x.object(id, shacl.Path); p != nil {
+		if _, ok := p.(quad.BNode); ok {
+			p = x.object(p, shacl.InversePath)
+			if p == nil {
+				return nil, fmt.Errorf("validate: unsupported path of shape %v", id)
+			}
+			sh.Inverse = true
+		}
+		if _, ok := p.(quad.IRI); !ok {
+			return nil, fmt.Errorf("validate: unsupported path of shape %v", id)
+		}
+		sh.Path = p
+	}
+	var err error
+	if sh.MinCount, err = intParam(x, id, shacl.MinCount, 0); err != nil {
+		return nil, err
+	}
+	if sh.MaxCount, err = intParam(x, id, shacl.MaxCount, -1); err != nil {
+		return nil, err
+	}
+	if v := x.object(id, shacl.Datatype); v != nil {
+		iri, ok := v.(quad.IRI)
+		if !ok {
+			return nil, fmt.Errorf("validate: datatype of shape %v must be an IRI", id)
+		}
+		sh.Datatype = iri
+	}
+	if v := x.object(id, shacl.Pattern); v != nil {
+		pattern := lexical(v)
+		if f := x.object(id, shacl.Flags); f != nil && lexical(f) != "" {
+			pattern = "(?" + lexical(f) + ")" + pattern
+		}
+		if sh.Pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("validate: invalid pattern of shape %v: %v", id, err)
+		}
+	}
+	if v := x.object(id, shacl.In); v != nil {
+		if sh.In, err = list(x, v); err != nil {
+			return nil, err
+		}
+	}
+	if v := x.object(id, shacl.Message); v != nil {
+		sh.Message = lexical(v)
+	}
+	if v, ok := x.object(id, shacl.Severity).(quad.IRI); ok {
+		sh.Severity = v
+	}
+	for _, p := range x.objects(id, shacl.Property) {
+		ps, err := l.shape(p)
+		if err != nil {
+			return nil, err
+		}
+		if !ps.IsProperty() {
+			return nil, fmt.Errorf("validate: property shape %v has no path", p)
+		}
+		sh.Properties = append(sh.Properties, ps)
+	}
+	for _, n := range x.objects(id, shacl.Node) {
+		ns, err := l.shape(n)
+		if err != nil {
+			return nil, err
+		}
+		sh.Node = append(sh.Node, ns)
+	}
+	return sh, nil
+}
+
+func intParam(x index, id quad.Value, p string, def int) (int, error) {
+	v := x.object(id, p)
+	if v == nil {
+		return def, nil
+	}
+	var n int
+	if _, err := fmt.Sscan(lexical(v), &n); err != nil || n < 0 {
+		return 0, fmt.Errorf("validate: invalid value of %s in shape %v: %v", p, id, v)
+	}
+	return n, nil
+}
+
+// list reads values of an RDF list.
+func list(x index, head quad.Value) ([]quad.Value, error) {
+	var (
+		out  []quad.Value
+		seen = make(map[quad.Value]struct{})
+	)
+	for head != quad.IRI(rdf.Nil) {
+		if _, ok := seen[head]; ok || head == nil {
+			return nil, fmt.Errorf("validate: malformed list: %v", head)
+		}
+		seen[head] = struct{}{}
+		v := x.object(head, rdf.First)
+		if v == nil {
+			return nil, fmt.Errorf("validate: malformed list: %v", head)
+		}
+		out = append(out, v)
+		head = x.object(head, rdf.Rest)
+	}
+	return out, nil
+}
+
+// lexical returns the lexical form of the value.
+func lexical(v quad.Value) string {
+	switch v := v.(type) {
+	case quad.IRI:
+		return string(v.Full())
+	case quad.BNode:
+		return string(v)
+	case quad.String:
+		return string(v)
+	case quad.LangString:
+		return string(v.Value)
+	case quad.TypedString:
+		return string(v.Value)
+	case quad.TypedStringer:
+		return string(v.TypedString().Value)
+	}
+	return v.String()
+}
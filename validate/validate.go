@@ -0,0 +1,491 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/voc/rdf"
+	"github.com/cayleygraph/quad/voc/rdfs"
+	"github.com/cayleygraph/quad/voc/xsd"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/query/path"
+	"github.com/cayleygraph/cayley/validate/shacl"
+)
+
+// source provides access to the data graph.
+type source interface {
+	// objects returns objects of quads with a given subject and predicate.
+	objects(ctx context.Context, s, p quad.Value) ([]quad.Value, error)
+	// subjects returns subjects of quads with a given predicate and object.
+	subjects(ctx context.Context, p, o quad.Value) ([]quad.Value, error)
+}
+
+// storeSource reads the data graph from a quad store.
+type storeSource struct {
+	qs graph.QuadStore
+}
+
+func (s storeSource) values(ctx context.Context, p *path.Path) ([]quad.Value, error) {
+	vals, err := p.Iterate(ctx).AllValues(s.qs)
+	if err != nil {
+		return nil, err
+	}
+	return unique(vals), nil
+}
+
+func (s storeSource) objects(ctx context.Context, sub, pred quad.Value) ([]quad.Value, error) {
+	return s.values(ctx, path.StartPath(s.qs, forms(sub)...).Out(valuesOf(forms(pred))...))
+}
+
+func (s storeSource) subjects(ctx context.Context, pred, obj quad.Value) ([]quad.Value, error) {
+	return s.values(ctx, path.StartPath(s.qs, forms(obj)...).In(valuesOf(forms(pred))...))
+}
+
+// all returns all subjects (or objects, if rev is set) of quads with a given predicate.
+func (s storeSource) all(ctx context.Context, pred quad.Value, rev bool) ([]quad.Value, error) {
+	p := path.StartPath(s.qs)
+	if rev {
+		p = p.In(valuesOf(forms(pred))...)
+	} else {
+		p = p.Out(valuesOf(forms(pred))...)
+	}
+	return s.values(ctx, p)
+}
+
+// quads returns all quads with a given subject.
+func (s storeSource) quads(ctx context.Context, sub quad.Value) ([]quad.Quad, error) {
+	var out []quad.Quad
+	for _, v := range forms(sub) {
+		ref, err := s.qs.ValueOf(v)
+		if err != nil {
+			return nil, err
+		} else if ref == nil {
+			continue
+		}
+		r := graph.NewResultReader(s.qs, s.qs.QuadIterator(quad.Subject, ref).Iterate())
+		quads, err := quad.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, quads...)
+	}
+	return out, nil
+}
+
+func valuesOf(vals []quad.Value) []interface{} {
+	out := make([]interface{}, 0, len(vals))
+	for _, v := range vals {
+		out = append(out, v)
+	}
+	return out
+}
+
+// unique normalizes values and removes duplicates.
+func unique(vals []quad.Value) []quad.Value {
+	seen := make(map[quad.Value]struct{}, len(vals))
+	out := vals[:0]
+	for _, v := range vals {
+		v = norm(v)
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// triple is a normalized quad without a label.
+type triple struct {
+	s, p, o quad.Value
+}
+
+func tripleOf(q quad.Quad) triple {
+	return triple{s: norm(q.Subject), p: norm(q.Predicate), o: norm(q.Object)}
+}
+
+// deltaSource is a view of the data graph with the deltas applied.
+type deltaSource struct {
+	base    source
+	added   []triple
+	removed map[triple]struct{}
+}
+
+func newDeltaSource(base source, deltas []graph.Delta) *deltaSource {
+	s := &deltaSource{base: base, removed: make(map[triple]struct{})}
+	for _, d := range deltas {
+		t := tripleOf(d.Quad)
+		switch d.Action {
+		case graph.Add:
+			delete(s.removed, t)
+			s.added = append(s.added, t)
+		case graph.Delete:
+			s.removed[t] = struct{}{}
+		}
+	}
+	return s
+}
+
+func (s *deltaSource) objects(ctx context.Context, sub, pred quad.Value) ([]quad.Value, error) {
+	vals, err := s.base.objects(ctx, sub, pred)
+	if err != nil {
+		return nil, err
+	}
+	sub, pred = norm(sub), norm(pred)
+	out := make([]quad.Value, 0, len(vals))
+	for _, o := range vals {
+		if _, ok := s.removed[triple{s: sub, p: pred, o: o}]; !ok {
+			out = append(out, o)
+		}
+	}
+	for _, t := range s.added {
+		if t.s == sub && t.p == pred {
+			if _, ok := s.removed[t]; !ok {
+				out = append(out, t.o)
+			}
+		}
+	}
+	return unique(out), nil
+}
+
+func (s *deltaSource) subjects(ctx context.Context, pred, obj quad.Value) ([]quad.Value, error) {
+	vals, err := s.base.subjects(ctx, pred, obj)
+	if err != nil {
+		return nil, err
+	}
+	pred, obj = norm(pred), norm(obj)
+	out := make([]quad.Value, 0, len(vals))
+	for _, v := range vals {
+		if _, ok := s.removed[triple{s: v, p: pred, o: obj}]; !ok {
+			out = append(out, v)
+		}
+	}
+	for _, t := range s.added {
+		if t.p == pred && t.o == obj {
+			if _, ok := s.removed[t]; !ok {
+				out = append(out, t.s)
+			}
+		}
+	}
+	return unique(out), nil
+}
+
+// Validate checks all the target nodes of the shapes in the quad store.
+func (s *Shapes) Validate(ctx context.Context, qs graph.QuadStore) (*Report, error) {
+	src := storeSource{qs: qs}
+	v := newValidator(src)
+	for _, sh := range s.Targeted {
+		var nodes []quad.Value
+		nodes = append(nodes, sh.TargetNode...)
+		for _, c := range sh.TargetClass {
+			vals, err := v.instances(ctx, c)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, vals...)
+		}
+		for _, p := range sh.TargetSubjectsOf {
+			vals, err := src.all(ctx, p, true)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, vals...)
+		}
+		for _, p := range sh.TargetObjectsOf {
+			vals, err := src.all(ctx, p, false)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, vals...)
+		}
+		for _, n := range unique(nodes) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err := v.validate(ctx, sh, n); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v.report, nil
+}
+
+// ValidateDeltas checks the state of the quad store after applying the deltas.
+//
+// Only the subjects and objects of the deltas are checked, thus the shapes that refer to these nodes via sh:node
+// are not checked.
+func (s *Shapes) ValidateDeltas(ctx context.Context, qs graph.QuadStore, deltas []graph.Delta) (*Report, error) {
+	src := newDeltaSource(storeSource{qs: qs}, deltas)
+	v := newValidator(src)
+	var nodes []quad.Value
+	for _, d := range deltas {
+		nodes = append(nodes, d.Quad.Subject)
+		switch d.Quad.Object.(type) {
+		case quad.IRI, quad.BNode:
+			nodes = append(nodes, d.Quad.Object)
+		}
+	}
+	for _, n := range unique(nodes) {
+		for _, sh := range s.Targeted {
+			ok, err := v.isTarget(ctx, sh, n)
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+			if err := v.validate(ctx, sh, n); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v.report, nil
+}
+
+type validator struct {
+	src    source
+	report *Report
+	// checking are shapes and nodes that are being checked for sh:node constraint
+	checking map[[2]quad.Value]struct{}
+}
+
+func newValidator(src source) *validator {
+	return &validator{
+		src:      src,
+		report:   &Report{Conforms: true},
+		checking: make(map[[2]quad.Value]struct{}),
+	}
+}
+
+func (v *validator) validate(ctx context.Context, sh *Shape, node quad.Value) error {
+	res, err := v.check(ctx, sh, node)
+	if err != nil {
+		return err
+	}
+	v.report.add(res...)
+	return nil
+}
+
+// isTarget checks whether the node is a target of the shape.
+func (v *validator) isTarget(ctx context.Context, sh *Shape, node quad.Value) (bool, error) {
+	node = norm(node)
+	for _, n := range sh.TargetNode {
+		if n == node {
+			return true, nil
+		}
+	}
+	for _, c := range sh.TargetClass {
+		if ok, err := v.isInstance(ctx, node, c); err != nil || ok {
+			return ok, err
+		}
+	}
+	for _, p := range sh.TargetSubjectsOf {
+		vals, err := v.src.objects(ctx, node, p)
+		if err != nil || len(vals) != 0 {
+			return len(vals) != 0, err
+		}
+	}
+	for _, p := range sh.TargetObjectsOf {
+		vals, err := v.src.subjects(ctx, p, node)
+		if err != nil || len(vals) != 0 {
+			return len(vals) != 0, err
+		}
+	}
+	return false, nil
+}
+
+// subClasses returns the class with all its subclasses.
+func (v *validator) subClasses(ctx context.Context, class quad.Value) ([]quad.Value, error) {
+	out := []quad.Value{norm(class)}
+	seen := map[quad.Value]struct{}{out[0]: {}}
+	for i := 0; i < len(out); i++ {
+		subs, err := v.src.subjects(ctx, quad.IRI(rdfs.SubClassOf), out[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range subs {
+			if _, ok := seen[s]; !ok {
+				seen[s] = struct{}{}
+				out = append(out, s)
+			}
+		}
+	}
+	return out, nil
+}
+
+// instances returns all instances of the class and its subclasses.
+func (v *validator) instances(ctx context.Context, class quad.Value) ([]quad.Value, error) {
+	classes, err := v.subClasses(ctx, class)
+	if err != nil {
+		return nil, err
+	}
+	var out []quad.Value
+	for _, c := range classes {
+		vals, err := v.src.subjects(ctx, quad.IRI(rdf.Type), c)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vals...)
+	}
+	return unique(out), nil
+}
+
+// isInstance checks whether the node is an instance of the class or one of its subclasses.
+func (v *validator) isInstance(ctx context.Context, node, class quad.Value) (bool, error) {
+	class = norm(class)
+	types, err := v.src.objects(ctx, node, quad.IRI(rdf.Type))
+	if err != nil {
+		return false, err
+	}
+	seen := make(map[quad.Value]struct{})
+	for i := 0; i < len(types); i++ {
+		t := types[i]
+		if t == class {
+			return true, nil
+		}
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		supers, err := v.src.objects(ctx, t, quad.IRI(rdfs.SubClassOf))
+		if err != nil {
+			return false, err
+		}
+		types = append(types, supers...)
+	}
+	return false, nil
+}
+
+// check validates the node against the shape and returns failed results.
+func (v *validator) check(ctx context.Context, sh *Shape, node quad.Value) ([]Result, error) {
+	node = norm(node)
+	var (
+		out    []Result
+		values = []quad.Value{node}
+		err    error
+	)
+	fail := func(comp quad.IRI, val quad.Value, msg string, args ...interface{}) {
+		if sh.Message != "" {
+			msg = sh.Message
+		} else {
+			msg = fmt.Sprintf(msg, args...)
+		}
+		out = append(out, Result{
+			Focus: node, Path: sh.Path, Inverse: sh.Inverse, Value: val,
+			Shape: sh.ID, Component: comp, Severity: sh.Severity, Message: msg,
+		})
+	}
+	if sh.IsProperty() {
+		if sh.Inverse {
+			values, err = v.src.subjects(ctx, sh.Path, node)
+		} else {
+			values, err = v.src.objects(ctx, node, sh.Path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(values) < sh.MinCount {
+			fail(shacl.MinCountConstraintComponent, nil, "less than %d values", sh.MinCount)
+		}
+		if sh.MaxCount >= 0 && len(values) > sh.MaxCount {
+			fail(shacl.MaxCountConstraintComponent, nil, "more than %d values", sh.MaxCount)
+		}
+	}
+	for _, val := range values {
+		if sh.Datatype != "" {
+			if dt := datatypeOf(val); dt == nil || norm(dt) != norm(sh.Datatype) {
+				fail(shacl.DatatypeConstraintComponent, val, "value does not have datatype %v", sh.Datatype)
+			}
+		}
+		for _, c := range sh.Class {
+			ok, err := v.isInstance(ctx, val, c)
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				fail(shacl.ClassConstraintComponent, val, "value is not an instance of %v", c)
+			}
+		}
+		if sh.Pattern != nil {
+			if _, ok := val.(quad.BNode); ok || !sh.Pattern.MatchString(lexical(val)) {
+				fail(shacl.PatternConstraintComponent, val, "value does not match pattern %q", sh.Pattern.String())
+			}
+		}
+		if len(sh.In) != 0 && !contains(sh.In, val) {
+			fail(shacl.InConstraintComponent, val, "value is not in the list of allowed values")
+		}
+		for _, ns := range sh.Node {
+			ok, err := v.conforms(ctx, ns, val)
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				fail(shacl.NodeConstraintComponent, val, "value does not conform to shape %v", ns.ID)
+			}
+		}
+	}
+	for _, ps := range sh.Properties {
+		res, err := v.check(ctx, ps, node)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+	}
+	return out, nil
+}
+
+// conforms checks whether the node conforms to the shape.
+// Recursive checks of the same node and shape are assumed to conform.
+func (v *validator) conforms(ctx context.Context, sh *Shape, node quad.Value) (bool, error) {
+	key := [2]quad.Value{sh.ID, norm(node)}
+	if _, ok := v.checking[key]; ok {
+		return true, nil
+	}
+	v.checking[key] = struct{}{}
+	defer delete(v.checking, key)
+	res, err := v.check(ctx, sh, node)
+	if err != nil {
+		return false, err
+	}
+	return len(res) == 0, nil
+}
+
+func contains(vals []quad.Value, v quad.Value) bool {
+	v = norm(v)
+	for _, v2 := range vals {
+		if norm(v2) == v {
+			return true
+		}
+	}
+	return false
+}
+
+// datatypeOf returns the datatype of a literal, or nil for IRIs and blank nodes.
+func datatypeOf(v quad.Value) quad.Value {
+	switch v := v.(type) {
+	case quad.IRI, quad.BNode:
+		return nil
+	case quad.String:
+		return quad.IRI(xsd.String)
+	case quad.LangString:
+		return quad.IRI(rdf.LangString)
+	case quad.TypedString:
+		return v.Type
+	case quad.TypedStringer:
+		return v.TypedString().Type
+	}
+	return nil
+}
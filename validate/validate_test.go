@@ -0,0 +1,291 @@
+package validate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cayleygraph/quad"
+	"github.com/cayleygraph/quad/nquads"
+	"github.com/cayleygraph/quad/voc/xsd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/graph/memstore"
+	"github.com/cayleygraph/cayley/validate"
+	"github.com/cayleygraph/cayley/validate/shacl"
+)
+
+const shapesNQ = `
+<PersonShape> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/ns/shacl#NodeShape> .
+<PersonShape> <http://www.w3.org/ns/shacl#targetClass> <Person> .
+<PersonShape> <http://www.w3.org/ns/shacl#property> _:name .
+<PersonShape> <http://www.w3.org/ns/shacl#property> _:email .
+<PersonShape> <http://www.w3.org/ns/shacl#property> _:status .
+<PersonShape> <http://www.w3.org/ns/shacl#property> _:employer .
+_:name <http://www.w3.org/ns/shacl#path> <name> .
+_:name <http://www.w3.org/ns/shacl#minCount> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:name <http://www.w3.org/ns/shacl#maxCount> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:name <http://www.w3.org/ns/shacl#datatype> <http://www.w3.org/2001/XMLSchema#string> .
+_:email <http://www.w3.org/ns/shacl#path> <email> .
+_:email <http://www.w3.org/ns/shacl#pattern> "^[^@]+@example\\.com$" .
+_:email <http://www.w3.org/ns/shacl#flags> "i" .
+_:status <http://www.w3.org/ns/shacl#path> <status> .
+_:status <http://www.w3.org/ns/shacl#in> _:l1 .
+_:l1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "active" .
+_:l1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:l2 .
+_:l2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "retired" .
+_:l2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+_:employer <http://www.w3.org/ns/shacl#path> <employer> .
+_:employer <http://www.w3.org/ns/shacl#class> <Company> .
+_:employer <http://www.w3.org/ns/shacl#node> <CompanyShape> .
+<CompanyShape> <http://www.w3.org/ns/shacl#property> _:cname .
+_:cname <http://www.w3.org/ns/shacl#path> <name> .
+_:cname <http://www.w3.org/ns/shacl#minCount> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<EmployeeOfShape> <http://www.w3.org/ns/shacl#targetObjectsOf> <employer> .
+<EmployeeOfShape> <http://www.w3.org/ns/shacl#property> _:staff .
+_:staff <http://www.w3.org/ns/shacl#path> _:inv .
+_:inv <http://www.w3.org/ns/shacl#inversePath> <employer> .
+_:staff <http://www.w3.org/ns/shacl#maxCount> "2"^^<http://www.w3.org/2001/XMLSchema#integer> .
+`
+
+func readShapes(t testing.TB) []quad.Quad {
+	quads, err := quad.ReadAll(nquads.NewReader(strings.NewReader(shapesNQ), false))
+	require.NoError(t, err)
+	return quads
+}
+
+var (
+	rdfType = quad.IRI("http://www.w3.org/1999/02/22-rdf-syntax-ns#type")
+	person  = quad.IRI("Person")
+	company = quad.IRI("Company")
+	name    = quad.IRI("name")
+)
+
+func makeQuad(s, p string, o quad.Value) quad.Quad {
+	return quad.Make(quad.IRI(s), quad.IRI(p), o, nil)
+}
+
+var validData = []quad.Quad{
+	quad.Make(quad.IRI("acme"), rdfType, company, nil),
+	makeQuad("acme", "name", quad.String("ACME")),
+	quad.Make(quad.IRI("alice"), rdfType, person, nil),
+	makeQuad("alice", "name", quad.String("Alice")),
+	makeQuad("alice", "email", quad.String("Alice@EXAMPLE.com")),
+	makeQuad("alice", "status", quad.String("active")),
+	makeQuad("alice", "employer", quad.IRI("acme")),
+}
+
+func components(rep *validate.Report) []string {
+	var out []string
+	for _, r := range rep.Results {
+		out = append(out, string(r.Component))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.TODO()
+	shapes, err := validate.LoadShapes(readShapes(t))
+	require.NoError(t, err)
+	require.Len(t, shapes.Targeted, 2)
+
+	rep, err := shapes.Validate(ctx, memstore.New(validData...))
+	require.NoError(t, err)
+	require.True(t, rep.Conforms, "%v", rep.Results)
+	require.Empty(t, rep.Results)
+
+	bad := append([]quad.Quad{}, validData...)
+	bad = append(bad,
+		quad.Make(quad.IRI("bob"), rdfType, person, nil),
+		makeQuad("bob", "email", quad.String("bob@other.com")),
+		makeQuad("bob", "status", quad.String("unknown")),
+		makeQuad("bob", "employer", quad.IRI("acme")),
+		makeQuad("carol", "employer", quad.IRI("acme")),
+		makeQuad("dave", "employer", quad.IRI("nobody")),
+		makeQuad("nobody", "name", quad.Int(42)),
+	)
+	rep, err = shapes.Validate(ctx, memstore.New(bad...))
+	require.NoError(t, err)
+	require.False(t, rep.Conforms)
+	require.Equal(t, []string{
+		shacl.InConstraintComponent,
+		shacl.MaxCountConstraintComponent,
+		shacl.MinCountConstraintComponent,
+		shacl.PatternConstraintComponent,
+	}, components(rep))
+
+	// quads of the report
+	var conforms bool
+	for _, q := range rep.Quads() {
+		if q.Predicate == quad.IRI(shacl.Conforms).Full() {
+			conforms = true
+			require.Equal(t, quad.TypedString{Value: "false", Type: quad.IRI(xsd.Boolean).Full()}, q.Object)
+		}
+	}
+	require.True(t, conforms)
+	var buf bytes.Buffer
+	require.NoError(t, rep.WriteJSONLD(&buf))
+	var doc interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Contains(t, buf.String(), shacl.NS+"ValidationReport")
+}
+
+func TestValidateClass(t *testing.T) {
+	ctx := context.TODO()
+	shapes, err := validate.LoadShapes(readShapes(t))
+	require.NoError(t, err)
+
+	data := append([]quad.Quad{}, validData...)
+	data = append(data,
+		quad.Make(quad.IRI("Employee"), quad.IRI("http://www.w3.org/2000/01/rdf-schema#subClassOf"), person, nil),
+		quad.Make(quad.IRI("erin"), rdfType, quad.IRI("Employee"), nil),
+		makeQuad("erin", "employer", quad.IRI("nobody")),
+		makeQuad("nobody", "name", quad.String("Nobody")),
+	)
+	rep, err := shapes.Validate(ctx, memstore.New(data...))
+	require.NoError(t, err)
+	// erin is a person via subclass, has no name, and is employed by a node that is not a company
+	require.Equal(t, []string{
+		shacl.ClassConstraintComponent,
+		shacl.MinCountConstraintComponent,
+	}, components(rep))
+	for _, r := range rep.Results {
+		require.Equal(t, quad.IRI("erin"), r.Focus)
+	}
+}
+
+func TestLoadShapesFrom(t *testing.T) {
+	ctx := context.TODO()
+	qs := memstore.New(append(readShapes(t), validData...)...)
+	shapes, err := validate.LoadShapesFrom(ctx, qs)
+	require.NoError(t, err)
+	require.Len(t, shapes.Targeted, 2)
+	sh := shapes.Shape(quad.IRI("PersonShape"))
+	require.NotNil(t, sh)
+	require.Len(t, sh.Properties, 4)
+
+	path := filepath.Join(t.TempDir(), "shapes.nq")
+	require.NoError(t, os.WriteFile(path, []byte(shapesNQ), 0644))
+	shapes, err = validate.ReadShapesFile(path)
+	require.NoError(t, err)
+	require.Len(t, shapes.Targeted, 2)
+}
+
+func TestWriter(t *testing.T) {
+	shapes, err := validate.LoadShapes(readShapes(t))
+	require.NoError(t, err)
+	qs := memstore.New()
+	qw, err := graph.NewQuadWriter(validate.WriterName, qs, graph.Options{"validate_shapes": shapes})
+	require.NoError(t, err)
+	defer qw.Close()
+
+	require.NoError(t, qw.AddQuadSet(validData))
+
+	// a person without a name is rejected
+	err = qw.AddQuad(quad.Make(quad.IRI("bob"), rdfType, person, nil))
+	var verr *validate.ValidationError
+	require.True(t, errors.As(err, &verr), "%v", err)
+	require.False(t, verr.Report.Conforms)
+	ref, err := qs.ValueOf(quad.IRI("bob"))
+	require.NoError(t, err)
+	require.Nil(t, ref, "rejected quads must not be written")
+
+	// removing a required value is rejected
+	err = qw.RemoveQuad(makeQuad("alice", "name", quad.String("Alice")))
+	require.True(t, errors.As(err, &verr), "%v", err)
+
+	// replacing it in one transaction is accepted
+	tx := graph.NewTransaction()
+	tx.RemoveQuad(makeQuad("alice", "name", quad.String("Alice")))
+	tx.AddQuad(makeQuad("alice", "name", quad.String("Alice Smith")))
+	require.NoError(t, qw.ApplyTransaction(tx))
+
+	// nodes that refer to the changed node via sh:node are not checked
+	require.NoError(t, qw.RemoveQuad(makeQuad("acme", "name", quad.String("ACME"))))
+
+	require.NoError(t, qw.RemoveNode(quad.IRI("alice")))
+	require.Equal(t, graph.ErrNodeNotExists, qw.RemoveNode(quad.IRI("alice")))
+
+	// shapes are loaded from the store if not set
+	qs = memstore.New(readShapes(t)...)
+	qw2, err := graph.NewQuadWriter(validate.WriterName, qs, nil)
+	require.NoError(t, err)
+	defer qw2.Close()
+	require.Error(t, qw2.AddQuad(quad.Make(quad.IRI("bob"), rdfType, person, nil)))
+	require.NoError(t, qw2.AddQuadSet([]quad.Quad{
+		quad.Make(quad.IRI("bob"), rdfType, person, nil),
+		quad.Make(quad.IRI("bob"), name, quad.String("Bob"), nil),
+	}))
+}
+
+// slowWriter delays writes to make concurrent writers overlap.
+type slowWriter struct {
+	graph.QuadWriter
+}
+
+func (w slowWriter) AddQuadSet(set []quad.Quad) error {
+	time.Sleep(10 * time.Millisecond)
+	return w.QuadWriter.AddQuadSet(set)
+}
+
+func init() {
+	graph.RegisterWriter("test-slow", func(qs graph.QuadStore, opts graph.Options) (graph.QuadWriter, error) {
+		qw, err := graph.NewQuadWriter("single", qs, opts)
+		if err != nil {
+			return nil, err
+		}
+		return slowWriter{qw}, nil
+	})
+}
+
+func TestWriterConcurrent(t *testing.T) {
+	shapes, err := validate.LoadShapes(readShapes(t))
+	require.NoError(t, err)
+	qs := memstore.New(validData...)
+
+	// acme can have at most 2 employees and already has one, thus only one of the writers may succeed
+	const n = 20
+	var (
+		start = make(chan struct{})
+		errs  = make(chan error, n)
+	)
+	for i := 0; i < n; i++ {
+		qw, err := graph.NewQuadWriter(validate.WriterName, qs, graph.Options{
+			"validate_shapes": shapes,
+			"validate_writer": "test-slow",
+		})
+		require.NoError(t, err)
+		defer qw.Close()
+		go func(i int) {
+			<-start
+			id := fmt.Sprintf("p%d", i)
+			errs <- qw.AddQuadSet([]quad.Quad{
+				quad.Make(quad.IRI(id), rdfType, person, nil),
+				makeQuad(id, "name", quad.String(id)),
+				makeQuad(id, "employer", quad.IRI("acme")),
+			})
+		}(i)
+	}
+	close(start)
+	added := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		var verr *validate.ValidationError
+		if err == nil {
+			added++
+		} else {
+			require.True(t, errors.As(err, &verr), "%v", err)
+		}
+	}
+	require.Equal(t, 1, added)
+}
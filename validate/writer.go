@@ -0,0 +1,208 @@
+// Copyright 2026 The Cayley Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cayleygraph/quad"
+
+	"github.com/cayleygraph/cayley/graph"
+	"github.com/cayleygraph/cayley/internal"
+
+	// Writer is imported for the default underlying writer to be registered
+	_ "github.com/cayleygraph/cayley/writer"
+)
+
+// WriterName is the name of the validating writer in the writer registry.
+const WriterName = "validate"
+
+func init() {
+	graph.RegisterWriter(WriterName, NewWriterFromOptions)
+}
+
+var _ graph.QuadWriter = (*Writer)(nil)
+
+// Writer is a graph.QuadWriter that rejects changes that don't conform to the shapes.
+//
+// Each call is validated separately, thus quads of a single node must be written in the same batch
+// for count constraints to be satisfied. Changes are rejected with ValidationError.
+//
+// Writers that use the same shapes validate and apply changes one at a time. All writers of a store
+// must share the shapes for constraints to hold under concurrent writes.
+type Writer struct {
+	qs     graph.QuadStore
+	qw     graph.QuadWriter
+	shapes *Shapes
+}
+
+// NewWriter wraps a quad writer for a given store and validates all changes made through it.
+func NewWriter(qs graph.QuadStore, qw graph.QuadWriter, shapes *Shapes) *Writer {
+	return &Writer{qs: qs, qw: qw, shapes: shapes}
+}
+
+// NewWriterFromOptions creates a validating writer from the options. It's registered as "validate" writer.
+//
+// Supported options:
+//
+//	validate_writer  - name of the underlying writer; "single" by default
+//	validate_shapes  - an instance of *Shapes to use
+//	validate_file    - path of the file with shapes; used if validate_shapes is not set
+//
+// If neither of shapes options is set, shapes are loaded from the quad store.
+// Shapes loaded by the writer are not shared with other writers, thus concurrent writers
+// of the same store should use validate_shapes. All options are passed to the underlying writer as well.
+func NewWriterFromOptions(qs graph.QuadStore, opts graph.Options) (graph.QuadWriter, error) {
+	wtyp, err := opts.StringKey("validate_writer", "single")
+	if err != nil {
+		return nil, err
+	} else if wtyp == WriterName {
+		return nil, errors.New("validate: cannot wrap a validating writer")
+	}
+	var shapes *Shapes
+	if v, ok := opts["validate_shapes"]; ok {
+		if shapes, ok = v.(*Shapes); !ok {
+			return nil, fmt.Errorf("validate: invalid shapes type: %T", v)
+		}
+	} else if path, err := opts.StringKey("validate_file", ""); err != nil {
+		return nil, err
+	} else if path != "" {
+		if shapes, err = ReadShapesFile(path); err != nil {
+			return nil, err
+		}
+	} else if shapes, err = LoadShapesFrom(context.Background(), qs); err != nil {
+		return nil, err
+	}
+	qw, err := graph.NewQuadWriter(wtyp, qs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriter(qs, qw, shapes), nil
+}
+
+// ReadShapesFile loads SHACL shapes from a quad file in any supported format.
+func ReadShapesFile(path string) (*Shapes, error) {
+	r, err := internal.QuadReaderFor(path, "")
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadShapes(r)
+}
+
+// Shapes returns the shapes used for validation.
+func (w *Writer) Shapes() *Shapes {
+	return w.shapes
+}
+
+// Unwrap returns the underlying writer.
+func (w *Writer) Unwrap() graph.QuadWriter {
+	return w.qw
+}
+
+// Validating checks if the writer validates changes, or if it wraps a writer that does.
+func Validating(qw graph.QuadWriter) bool {
+	for qw != nil {
+		if _, ok := qw.(*Writer); ok {
+			return true
+		}
+		u, ok := qw.(interface{ Unwrap() graph.QuadWriter })
+		if !ok {
+			return false
+		}
+		qw = u.Unwrap()
+	}
+	return false
+}
+
+// apply validates the changes and applies them if they conform to the shapes.
+// No other writer that uses the same shapes can write in the meantime.
+func (w *Writer) apply(deltas []graph.Delta, fnc func() error) error {
+	w.shapes.mu.Lock()
+	defer w.shapes.mu.Unlock()
+	if err := w.check(deltas); err != nil {
+		return err
+	}
+	return fnc()
+}
+
+func (w *Writer) check(deltas []graph.Delta) error {
+	rep, err := w.shapes.ValidateDeltas(context.TODO(), w.qs, deltas)
+	if err != nil {
+		return err
+	} else if !rep.Conforms {
+		return &ValidationError{Report: rep}
+	}
+	return nil
+}
+
+func deltas(set []quad.Quad, act graph.Procedure) []graph.Delta {
+	out := make([]graph.Delta, 0, len(set))
+	for _, q := range set {
+		out = append(out, graph.Delta{Quad: q, Action: act})
+	}
+	return out
+}
+
+// AddQuad implements graph.QuadWriter.
+func (w *Writer) AddQuad(q quad.Quad) error {
+	return w.apply(deltas([]quad.Quad{q}, graph.Add), func() error {
+		return w.qw.AddQuad(q)
+	})
+}
+
+// AddQuadSet implements graph.QuadWriter.
+func (w *Writer) AddQuadSet(set []quad.Quad) error {
+	return w.apply(deltas(set, graph.Add), func() error {
+		return w.qw.AddQuadSet(set)
+	})
+}
+
+// RemoveQuad implements graph.QuadWriter.
+func (w *Writer) RemoveQuad(q quad.Quad) error {
+	return w.apply(deltas([]quad.Quad{q}, graph.Delete), func() error {
+		return w.qw.RemoveQuad(q)
+	})
+}
+
+// ApplyTransaction implements graph.QuadWriter.
+func (w *Writer) ApplyTransaction(tx *graph.Transaction) error {
+	return w.apply(tx.Deltas, func() error {
+		return w.qw.ApplyTransaction(tx)
+	})
+}
+
+// RemoveNode implements graph.QuadWriter.
+//
+// Quads of the node are collected before removing them, to validate the state after the removal.
+func (w *Writer) RemoveNode(v quad.Value) error {
+	w.shapes.mu.Lock()
+	defer w.shapes.mu.Unlock()
+	removed, err := graph.NodeQuads(w.qs, v)
+	if err != nil {
+		return err
+	}
+	if err := w.check(deltas(removed, graph.Delete)); err != nil {
+		return err
+	}
+	return w.qw.RemoveNode(v)
+}
+
+// Close closes the underlying writer.
+func (w *Writer) Close() error {
+	return w.qw.Close()
+}
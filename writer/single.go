@@ -15,6 +15,9 @@
 package writer
 
 import (
+	"context"
+	"time"
+
 	"github.com/cayleygraph/cayley/graph"
 	"github.com/cayleygraph/quad"
 )
@@ -52,6 +55,14 @@ func NewSingleReplication(qs graph.QuadStore, opts graph.Options) (graph.QuadWri
 	})
 }
 
+var _ graph.ReplicationLagger = (*Single)(nil)
+
+// ReplicationLag implements graph.ReplicationLagger. It always returns zero,
+// since changes are applied to the quad store before the write returns.
+func (s *Single) ReplicationLag(ctx context.Context) (time.Duration, error) {
+	return 0, nil
+}
+
 func (s *Single) AddQuad(q quad.Quad) error {
 	deltas := make([]graph.Delta, 1)
 	deltas[0] = graph.Delta{